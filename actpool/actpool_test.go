@@ -53,7 +53,7 @@ func TestActPool_validateTsf(t *testing.T) {
 	logger.SetLogger(&l)
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	ap := NewActPool(sf).(*actPool)
@@ -93,7 +93,7 @@ func TestActPool_validateVote(t *testing.T) {
 	logger.SetLogger(&l)
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	ap := NewActPool(sf).(*actPool)
@@ -127,7 +127,7 @@ func TestActPool_AddActs(t *testing.T) {
 	logger.SetLogger(&l)
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	sf.CreateState(addr2.RawAddress, uint64(10))
@@ -219,7 +219,7 @@ func TestActPool_PickActs(t *testing.T) {
 	logger.SetLogger(&l)
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	sf.CreateState(addr2.RawAddress, uint64(10))
@@ -260,7 +260,7 @@ func TestActPool_removeCommittedActs(t *testing.T) {
 	logger.SetLogger(&l)
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	// Create actpool
@@ -293,7 +293,7 @@ func TestActPool_Reset(t *testing.T) {
 
 	tr, _ := trie.NewTrie("", true)
 	assert.NotNil(tr)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	sf.CreateState(addr1.RawAddress, uint64(100))
 	sf.CreateState(addr2.RawAddress, uint64(200))
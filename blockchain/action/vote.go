@@ -48,6 +48,19 @@ func NewVote(nonce uint64, selfPubKey []byte, votePubKey []byte) *Vote {
 	return &Vote{pbVote}
 }
 
+// NewUnvote returns a Vote instance withdrawing the vote of the voter. A candidate withdrawing its vote stops being a
+// candidate as well.
+func NewUnvote(nonce uint64, selfPubKey []byte) *Vote {
+	vote := NewVote(nonce, selfPubKey, nil)
+	vote.Unvote = true
+	return vote
+}
+
+// IsUnvote returns true if the Vote withdraws the vote of the voter instead of voting for someone
+func (v *Vote) IsUnvote() bool {
+	return v.Unvote
+}
+
 // TotalSize returns the total size of this Vote
 func (v *Vote) TotalSize() uint32 {
 	size := TimestampSizeInBytes
@@ -56,6 +69,9 @@ func (v *Vote) TotalSize() uint32 {
 	size += len(v.SelfPubkey)
 	size += len(v.VotePubkey)
 	size += len(v.Signature)
+	if v.Unvote {
+		size++
+	}
 	return uint32(size)
 }
 
@@ -71,6 +87,10 @@ func (v *Vote) ByteStream() []byte {
 	temp = make([]byte, 4)
 	common.MachineEndian.PutUint32(temp, v.Version)
 	stream = append(stream, temp...)
+	// the unvote flag is appended only if set, so that the hashes of the votes stay as they were before it
+	if v.Unvote {
+		stream = append(stream, 1)
+	}
 	// Signature = Sign(hash(ByteStream())), so not included
 	return stream
 }
@@ -127,6 +147,10 @@ func (v *Vote) Sign(sender *iotxaddress.Address) (*Vote, error) {
 
 // Verify verifies the Vote using sender's public key
 func (v *Vote) Verify(sender *iotxaddress.Address) error {
+	// a vote is for someone unless it's an unvote, so that a vote losing its votee isn't taken as an unvote
+	if v.Unvote != (len(v.VotePubkey) == 0) {
+		return errors.Wrapf(ErrVoteError, "unvote = %t with vote pubKey %x", v.Unvote, v.VotePubkey)
+	}
 	hash := v.Hash()
 	if success := cp.Verify(sender.PublicKey, hash[:], v.Signature); success {
 		return nil
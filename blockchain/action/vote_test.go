@@ -9,6 +9,7 @@ package action
 import (
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/iotexproject/iotex-core/iotxaddress"
@@ -44,3 +45,37 @@ func TestVoteSerializedDeserialize(t *testing.T) {
 	require.Equal(v.Hash(), newv.Hash())
 	require.Equal(v.TotalSize(), newv.TotalSize())
 }
+
+func TestUnvote(t *testing.T) {
+	require := require.New(t)
+	sender, err := iotxaddress.NewAddress(true, chainid)
+	require.Nil(err)
+	recipient, err := iotxaddress.NewAddress(true, chainid)
+	require.Nil(err)
+	require.False(NewVote(0, sender.PublicKey, recipient.PublicKey).IsUnvote())
+
+	v := NewUnvote(1, sender.PublicKey)
+	require.True(v.IsUnvote())
+	signedv, err := v.Sign(sender)
+	require.Nil(err)
+	require.Nil(signedv.Verify(sender))
+	raw, err := signedv.Serialize()
+	require.Nil(err)
+	newv := &Vote{}
+	require.Nil(newv.Deserialize(raw))
+	require.True(newv.IsUnvote())
+	require.Equal(v.Hash(), newv.Hash())
+
+	// a vote without the votee isn't taken as an unvote, and neither of them verifies
+	v = NewVote(1, sender.PublicKey, nil)
+	require.False(v.IsUnvote())
+	require.NotEqual(newv.Hash(), v.Hash())
+	signedv, err = v.Sign(sender)
+	require.Nil(err)
+	require.Equal(ErrVoteError, errors.Cause(signedv.Verify(sender)))
+	v = NewUnvote(1, sender.PublicKey)
+	v.VotePubkey = recipient.PublicKey
+	signedv, err = v.Sign(sender)
+	require.Nil(err)
+	require.Equal(ErrVoteError, errors.Cause(signedv.Verify(sender)))
+}
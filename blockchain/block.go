@@ -37,7 +37,7 @@ type BlockHeader struct {
 	timestamp     uint64         // timestamp
 	prevBlockHash common.Hash32B // hash of previous block
 	txRoot        common.Hash32B // merkle root of all transactions
-	stateRoot     common.Hash32B // merkle root of all states
+	stateRoot     common.Hash32B // merkle root of all states before applying the block
 	blockSig      []byte         // block signature
 	Pubkey        []byte         // block miner's public key
 
@@ -81,6 +81,11 @@ func (b *Block) PrevHash() common.Hash32B {
 	return b.Header.prevBlockHash
 }
 
+// StateRoot returns the merkle root of all states which the block is applied on
+func (b *Block) StateRoot() common.Hash32B {
+	return b.Header.stateRoot
+}
+
 // ByteStreamHeader returns a byte stream of the block header
 func (b *Block) ByteStreamHeader() []byte {
 	stream := make([]byte, 4)
@@ -231,6 +236,17 @@ func (b *Block) HashBlock() common.Hash32B {
 	return hash
 }
 
+// VerifySignature verifies the producer's signature of the block header
+func (b *Block) VerifySignature() bool {
+	blkHash := b.HashBlock()
+	return cp.Verify(b.Header.Pubkey, blkHash[:], b.Header.blockSig)
+}
+
+// VerifyTxRoot checks whether the actions of the block match the merkle root in the header
+func (b *Block) VerifyTxRoot() bool {
+	return b.TxRoot() == b.Header.txRoot
+}
+
 // SignBlock allows signer to sign the block b
 func (b *Block) SignBlock(signer *iotxaddress.Address) error {
 	if signer.PrivateKey == nil {
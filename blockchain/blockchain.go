@@ -25,6 +25,9 @@ import (
 	"github.com/iotexproject/iotex-core/trie"
 )
 
+// pruneBatchSize is the number of blocks out of the retention which are pruned at once
+const pruneBatchSize = 100
+
 // Blockchain represents the blockchain data structure and hosts the APIs to access it
 type Blockchain interface {
 	service.Service
@@ -38,6 +41,8 @@ type Blockchain interface {
 	GetBlockByHeight(height uint64) (*Block, error)
 	// GetBlockByHash returns Block by hash
 	GetBlockByHash(hash common.Hash32B) (*Block, error)
+	// GetBlockHeaderByHeight returns Block holding only its header by height, which is kept even if it is pruned
+	GetBlockHeaderByHeight(height uint64) (*Block, error)
 	// GetTotalTransfers returns the total number of transfers
 	GetTotalTransfers() (uint64, error)
 	// GetTotalVotes returns the total number of votes
@@ -64,6 +69,24 @@ type Blockchain interface {
 	TipHeight() (uint64, error)
 	// StateByAddr returns state of a given address
 	StateByAddr(address string) (*state.State, error)
+	// GetRewardReceiptsByAddress returns the receipts of the voter rewards credited to the address
+	GetRewardReceiptsByAddress(address string) ([]*state.RewardReceipt, error)
+	// GetStateDiff returns the changes of the accounts made by the block at the height
+	GetStateDiff(height uint64) (*state.StateDiff, error)
+	// GetStateHistoryByAddress returns the changes of the account made by the blocks, in the order of the heights
+	GetStateHistoryByAddress(address string) ([]*state.StateDiff, error)
+	// SimulateActions returns the states of the accounts involved in the actions, which they would lead to if they
+	// were in the next block, without committing them
+	SimulateActions(tsf []*action.Transfer, vote []*action.Vote) ([]*state.State, error)
+	// IterateStates calls the function on the state of every account at the tip, and stops at the first error
+	IterateStates(fn func(*state.State) error) error
+	// VoterRewards returns the voter rewards accrued by each delegate at the tip, which aren't distributed yet
+	VoterRewards() (map[string]*big.Int, error)
+	// StateSnapshot returns the snapshot of all states at the tip
+	StateSnapshot() (*state.Snapshot, error)
+	// ImportSnapshot starts the chain holding only the genesis block from the state snapshot, which is vouched by the
+	// state root of the next block produced by a trusted producer
+	ImportSnapshot(ss *state.Snapshot, next *Block) error
 
 	// For block operations
 	// MintNewBlock creates a new block with given actions
@@ -103,6 +126,10 @@ type blockchain struct {
 	tipHeight uint64
 	tipHash   common.Hash32B
 	validator Validator
+	// pruneCh wakes up the routine pruning the blocks in the background, which is stopped by closing pruneQuit
+	pruneCh   chan struct{}
+	pruneQuit chan struct{}
+	pruneWG   sync.WaitGroup
 
 	// used by account-based model
 	sf state.Factory
@@ -110,29 +137,12 @@ type blockchain struct {
 
 // NewBlockchain creates a new blockchain instance
 func NewBlockchain(dao *blockDAO, cfg *config.Config, sf state.Factory) Blockchain {
-	if sf != nil {
-		// add Genesis block miner into Trie
-		if _, err := sf.CreateState(Gen.CreatorAddr, Gen.TotalSupply); err != nil {
-			logger.Error().Err(err).Msg("Failed to add Creator into StateFactory")
+	// the state kept in the trie DB after committing blocks is either resumed from or rebuilt on start
+	if sf != nil && !stateCommitted(sf) {
+		if err := createGenesisStates(sf); err != nil {
+			logger.Error().Err(err).Msg("Failed to add genesis states into StateFactory")
 			return nil
 		}
-		// add initial delegates into Trie
-		for _, pk := range Gen.InitDelegatesPubKey {
-			pubk, err := hex.DecodeString(pk)
-			if err != nil {
-				logger.Error().Err(err).Msg("Failed to denoce public key")
-				return nil
-			}
-			address, err := iotxaddress.GetAddress(pubk, iotxaddress.IsTestnet, iotxaddress.ChainID)
-			if err != nil {
-				logger.Error().Err(err).Msg("Failed to get address from public key")
-				return nil
-			}
-			if _, err := sf.CreateState(address.RawAddress, uint64(0)); err != nil {
-				logger.Error().Err(err).Msg("Failed to add initial delegates state into StateFactory")
-				return nil
-			}
-		}
 	}
 
 	chain := &blockchain{
@@ -146,11 +156,40 @@ func NewBlockchain(dao *blockDAO, cfg *config.Config, sf state.Factory) Blockcha
 	return chain
 }
 
+// createGenesisStates adds the states of the genesis block creator and the initial delegates into the state factory
+func createGenesisStates(sf state.Factory) error {
+	// add Genesis block miner into Trie
+	if _, err := sf.CreateState(Gen.CreatorAddr, Gen.TotalSupply); err != nil {
+		return errors.Wrap(err, "failed to add creator")
+	}
+	// add initial delegates into Trie
+	for _, pk := range Gen.InitDelegatesPubKey {
+		pubk, err := hex.DecodeString(pk)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode public key")
+		}
+		address, err := iotxaddress.GetAddress(pubk, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get address from public key")
+		}
+		if _, err := sf.CreateState(address.RawAddress, uint64(0)); err != nil {
+			return errors.Wrap(err, "failed to add initial delegate")
+		}
+	}
+	return nil
+}
+
 // Start starts the blockchain
 func (bc *blockchain) Start() (err error) {
 	if err = bc.CompositeService.Start(); err != nil {
 		return err
 	}
+	if bc.config != nil && bc.config.Chain.PruneRetention > 0 {
+		bc.pruneCh = make(chan struct{}, 1)
+		bc.pruneQuit = make(chan struct{})
+		bc.pruneWG.Add(1)
+		go bc.pruneLoop(bc.pruneQuit)
+	}
 
 	// get blockchain tip height
 	bc.mu.Lock()
@@ -159,6 +198,10 @@ func (bc *blockchain) Start() (err error) {
 		return err
 	}
 	if bc.tipHeight == 0 {
+		// the genesis block is committed again, onto the genesis states
+		if bc.sf != nil && stateCommitted(bc.sf) {
+			return bc.rebuildState()
+		}
 		return nil
 	}
 	// get blockchain tip hash
@@ -166,21 +209,121 @@ func (bc *blockchain) Start() (err error) {
 		return err
 	}
 
+	// the chain started from a state snapshot doesn't have the blocks before it
+	start := uint64(0)
+	ss, err := bc.dao.getSnapshot()
+	if err != nil {
+		return err
+	}
+	if bc.sf != nil {
+		if start, err = bc.resumeState(ss); err != nil {
+			return err
+		}
+	} else if ss != nil {
+		start = ss.Height + 1
+	}
+	if bc.sf == nil && start < bc.dao.prunedHeight() {
+		start = bc.dao.prunedHeight()
+	}
+
 	// populate state factory
-	for i := uint64(0); i <= bc.tipHeight; i++ {
+	for i := start; i <= bc.tipHeight; i++ {
 		blk, err := bc.GetBlockByHeight(i)
 		if err != nil {
 			return err
 		}
 		if blk != nil {
 			if bc.sf != nil && blk.Transfers != nil {
-				if err := bc.sf.CommitStateChanges(bc.tipHeight, blk.Transfers, blk.Votes); err != nil {
+				if err := bc.commitState(blk); err != nil {
 					return err
 				}
 			}
 		}
 	}
-	return nil
+	// the blocks out of the retention are pruned right away, when pruning is enabled on an existing chain DB
+	return bc.prune(0)
+}
+
+// Stop stops the routine pruning the blocks, and then the blockchain
+func (bc *blockchain) Stop() error {
+	if bc.pruneQuit != nil {
+		close(bc.pruneQuit)
+		bc.pruneWG.Wait()
+		bc.pruneQuit = nil
+	}
+	return bc.CompositeService.Stop()
+}
+
+// resumeState returns the height of the first block to replay into the state factory. The state kept in the trie DB is
+// resumed from if it is committed at a block of the chain, whose next block is applied on the same state root. Otherwise
+// the state is rebuilt from the genesis states, or from the snapshot if the chain starts from one. It is called with the
+// lock held.
+func (bc *blockchain) resumeState(ss *state.Snapshot) (uint64, error) {
+	height, committed := bc.sf.Height()
+	if committed && height <= bc.tipHeight && (ss == nil || height >= ss.Height) {
+		next, err := bc.nextStateRoot(height)
+		if err != nil {
+			return 0, err
+		}
+		if next == common.ZeroHash32B || next == bc.sf.RootHash() {
+			logger.Info().Uint64("height", height).Msg("Resume the state kept in the trie DB")
+			return height + 1, nil
+		}
+	}
+	if committed || bc.sf.RootHash() != trie.EmptyRoot {
+		logger.Warn().
+			Uint64("height", height).
+			Bool("committed", committed).
+			Uint64("tipHeight", bc.tipHeight).
+			Msg("The state kept in the trie DB doesn't match the chain, rebuild it")
+	}
+	start := uint64(0)
+	if ss != nil {
+		start = ss.Height + 1
+	}
+	if start < bc.dao.prunedHeight() {
+		return 0, errors.Errorf(
+			"cannot rebuild the state, as the blocks from height %d to %d are pruned",
+			start,
+			bc.dao.prunedHeight()-1)
+	}
+	if err := bc.rebuildState(); err != nil {
+		return 0, err
+	}
+	if ss == nil {
+		return 0, nil
+	}
+	if err := bc.sf.LoadSnapshot(ss); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// nextStateRoot returns the state root of the block after the height, or zero if it isn't committed or doesn't have a
+// state root
+func (bc *blockchain) nextStateRoot(height uint64) (common.Hash32B, error) {
+	if height == bc.tipHeight {
+		return common.ZeroHash32B, nil
+	}
+	blk, err := bc.GetBlockHeaderByHeight(height + 1)
+	if err != nil {
+		return common.ZeroHash32B, errors.Wrapf(err, "failed to get the block at height %d", height+1)
+	}
+	return blk.StateRoot(), nil
+}
+
+// stateCommitted tells if the state changes of a block are committed to the state factory
+func stateCommitted(sf state.Factory) bool {
+	_, committed := sf.Height()
+	return committed
+}
+
+// rebuildState empties the state factory and adds the genesis states into it again
+func (bc *blockchain) rebuildState() error {
+	if err := bc.sf.Reset(); err != nil {
+		return err
+	}
+	return createGenesisStates(bc.sf)
 }
 
 // GetHeightByHash returns block's height by hash
@@ -207,6 +350,15 @@ func (bc *blockchain) GetBlockByHash(hash common.Hash32B) (*Block, error) {
 	return bc.dao.getBlock(hash)
 }
 
+// GetBlockHeaderByHeight returns block holding only its header by height, which is kept even if the block is pruned
+func (bc *blockchain) GetBlockHeaderByHeight(height uint64) (*Block, error) {
+	hash, err := bc.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return bc.dao.getBlockHeader(hash)
+}
+
 func (bc *blockchain) GetTotalTransfers() (uint64, error) {
 	totalTransfers, err := bc.dao.getTotalTransfers()
 	if err != nil {
@@ -336,6 +488,9 @@ func (bc *blockchain) MintNewBlock(tsf []*action.Transfer, vote []*action.Vote,
 	tsf = append(tsf, action.NewCoinBaseTransfer(big.NewInt(int64(bc.genesis.BlockReward)), producer.RawAddress))
 
 	blk := NewBlock(bc.chainID, bc.tipHeight+1, bc.tipHash, tsf, vote)
+	if bc.sf != nil {
+		blk.Header.stateRoot = bc.sf.RootHash()
+	}
 	if producer.PrivateKey == nil {
 		logger.Warn().Msg("Unsigned block...")
 		return blk, nil
@@ -390,19 +545,59 @@ func CreateBlockchain(cfg *config.Config, sf state.Factory) Blockchain {
 		if len(cfg.Chain.TrieDBPath) == 0 {
 			sf = nil
 		} else {
-			trie, err := trie.NewTrie("", true)
+			trie, err := trie.NewTrie("", true, trie.GCOption(cfg.Chain.TrieRetainedRoots, cfg.Chain.TrieGCInterval))
 			if err != nil {
 				logger.Error().Err(err).Msg("Failed to initialize in-memory trie")
 				return nil
 			}
 			if sf == nil {
-				sf = state.NewFactory(trie)
+				if sf, err = state.NewFactory(
+					trie,
+					state.CandidatePoolOption(Gen.CandidateSize, Gen.CandidateBufferSize),
+					state.UnbondingPeriodOption(Gen.VoteUnbondingPeriod),
+					state.VoterRewardOption(Gen.VoterRewardPercentage, Gen.RewardEpoch),
+				); err != nil {
+					logger.Error().Err(err).Msg("Failed to create state factory")
+					return nil
+				}
 			}
 		}
 	} else {
 		kvStore = db.NewBoltDB(cfg.Chain.ChainDBPath, nil)
+		if cfg.Chain.BlockStore == config.FileBlockStore {
+			return createAndInitBlockchain(newFileBlockDAO(kvStore, cfg.Chain.BlockFilePath, cfg.Chain.BlocksPerFile), sf, cfg)
+		}
 	}
-	return createAndInitBlockchain(kvStore, sf, cfg)
+	return createAndInitBlockchain(newBlockDAO(kvStore), sf, cfg)
+}
+
+// GetStateDiff returns the changes of the accounts made by the block at the height
+func (bc *blockchain) GetStateDiff(height uint64) (*state.StateDiff, error) {
+	return bc.dao.getStateDiff(height)
+}
+
+// GetStateHistoryByAddress returns the changes of the account made by the blocks, in the order of the heights. Each of
+// them is the state diff of a block holding only the change of the account.
+func (bc *blockchain) GetStateHistoryByAddress(address string) ([]*state.StateDiff, error) {
+	return bc.dao.getStateHistory(address)
+}
+
+// SimulateActions returns the states of the accounts involved in the actions, which they would lead to if they were in
+// the next block, without committing them. The states at the tip are read with the lock held, so that a block isn't
+// committed in the middle of the simulation.
+func (bc *blockchain) SimulateActions(tsf []*action.Transfer, vote []*action.Vote) ([]*state.State, error) {
+	if bc.sf == nil {
+		return nil, errors.New("state factory is nil")
+	}
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.sf.SimulateStateChanges(tsf, vote)
+}
+
+// GetRewardReceiptsByAddress returns the receipts of the voter rewards credited to the address
+func (bc *blockchain) GetRewardReceiptsByAddress(address string) ([]*state.RewardReceipt, error) {
+	return bc.dao.getRewardReceiptsByAddress(address)
 }
 
 // StateByAddr returns the state of an address
@@ -420,6 +615,93 @@ func (bc *blockchain) StateByAddr(address string) (*state.State, error) {
 	return nil, errors.New("state factory is nil")
 }
 
+// IterateStates calls the function on the state of every account at the tip, and stops at the first error
+func (bc *blockchain) IterateStates(fn func(*state.State) error) error {
+	if bc.sf == nil {
+		return errors.New("state factory is nil")
+	}
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.sf.IterateStates(fn)
+}
+
+// VoterRewards returns the voter rewards accrued by each delegate at the tip, which aren't distributed yet
+func (bc *blockchain) VoterRewards() (map[string]*big.Int, error) {
+	if bc.sf == nil {
+		return nil, errors.New("state factory is nil")
+	}
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.sf.VoterRewards(), nil
+}
+
+// StateSnapshot returns the snapshot of all states at the tip
+func (bc *blockchain) StateSnapshot() (*state.Snapshot, error) {
+	if bc.sf == nil {
+		return nil, errors.New("state factory is nil")
+	}
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	ss, err := bc.sf.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	// the state factory only tracks the height of the blocks with actions
+	ss.Height = bc.tipHeight
+	return ss, nil
+}
+
+// ImportSnapshot starts the chain holding only the genesis block from the state snapshot. The snapshot is vouched by
+// the state root of the next block, which must be produced by a trusted producer and is expected to be committed right
+// after.
+func (bc *blockchain) ImportSnapshot(ss *state.Snapshot, next *Block) error {
+	if bc.sf == nil {
+		return errors.New("state factory is nil")
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.tipHeight != 0 {
+		return errors.Errorf("cannot import state snapshot into the chain at height %d", bc.tipHeight)
+	}
+	if ss.Height == 0 || next.Height() != ss.Height+1 {
+		return errors.Wrapf(
+			ErrInvalidBlock,
+			"block %d doesn't follow state snapshot at height %d",
+			next.Height(),
+			ss.Height)
+	}
+	// the block vouching for the snapshot must come from a trusted producer, as its signature alone proves nothing
+	producers, err := NewProducers(bc.config)
+	if err != nil {
+		return err
+	}
+	if err := producers.Verify(next); err != nil {
+		return err
+	}
+	if next.StateRoot() != ss.Root {
+		return errors.Wrapf(
+			state.ErrSnapshotMismatch,
+			"state root %x of block %d, expecting %x",
+			next.StateRoot(),
+			next.Height(),
+			ss.Root)
+	}
+	if err := bc.sf.LoadSnapshot(ss); err != nil {
+		return err
+	}
+	if err := bc.dao.putSnapshot(ss, next.PrevHash()); err != nil {
+		return err
+	}
+	bc.tipHeight = ss.Height
+	bc.tipHash = next.PrevHash()
+	logger.Info().Uint64("height", ss.Height).Hex("root", ss.Root[:]).Msg("Import state snapshot")
+	return nil
+}
+
 // SetValidator sets the current validator object
 func (bc *blockchain) SetValidator(val Validator) {
 	bc.validator = val
@@ -482,14 +764,96 @@ func (bc *blockchain) commitBlock(blk *Block) error {
 	bc.tipHash = blk.HashBlock()
 
 	// update state factory
-	if bc.sf == nil || (blk.Transfers == nil && blk.Votes == nil) {
+	if bc.sf != nil && (blk.Transfers != nil || blk.Votes != nil) {
+		if err := bc.commitState(blk); err != nil {
+			return err
+		}
+	}
+	bc.schedulePrune()
+	return nil
+}
+
+// commitState commits the actions of the block to the state factory at the height of the block, and keeps the changes
+// of the accounts and the receipts of the voter rewards credited by them
+func (bc *blockchain) commitState(blk *Block) error {
+	if err := bc.sf.CommitStateChanges(blk.Height(), blk.Transfers, blk.Votes); err != nil {
+		return err
+	}
+	if err := bc.dao.putStateDiff(bc.sf.StateDiff()); err != nil {
+		return err
+	}
+	return bc.dao.putRewardReceipts(bc.sf.RewardReceipts())
+}
+
+// prune prunes the blocks out of the retention once there are at least the given number of them. The state factory is
+// resumed from the state committed in the trie DB on restart, as the pruned blocks can no longer be replayed. It is
+// called with the lock held.
+func (bc *blockchain) prune(batch uint64) error {
+	keepFrom := bc.pruneKeepFrom(batch)
+	if keepFrom == 0 {
 		return nil
 	}
-	return bc.sf.CommitStateChanges(bc.tipHeight, blk.Transfers, blk.Votes)
+	return bc.dao.pruneBlocks(keepFrom)
+}
+
+// pruneKeepFrom returns the height from which the blocks are kept if there are at least the given number of blocks out
+// of the retention to prune, or else 0. The blocks after the state committed to the state factory are kept as well, so
+// that they can be replayed onto it. It is called with the lock held.
+func (bc *blockchain) pruneKeepFrom(batch uint64) uint64 {
+	if bc.config == nil || bc.config.Chain.PruneRetention == 0 || bc.tipHeight < bc.config.Chain.PruneRetention {
+		return 0
+	}
+	keepFrom := bc.tipHeight + 1 - bc.config.Chain.PruneRetention
+	if bc.sf != nil {
+		height, committed := bc.sf.Height()
+		if !committed {
+			return 0
+		}
+		if height+1 < keepFrom {
+			keepFrom = height + 1
+		}
+	}
+	if keepFrom <= bc.dao.prunedHeight() || keepFrom-bc.dao.prunedHeight() < batch {
+		return 0
+	}
+	return keepFrom
+}
+
+// schedulePrune wakes up the pruning routine once there are a batch of blocks out of the retention, so that committing
+// the block doesn't wait for the pruning. It is called with the lock held.
+func (bc *blockchain) schedulePrune() {
+	if bc.pruneCh == nil || bc.pruneKeepFrom(pruneBatchSize) == 0 {
+		return
+	}
+	select {
+	case bc.pruneCh <- struct{}{}:
+	default:
+	}
+}
+
+// pruneLoop prunes the blocks whenever it's woken up, until it's told to quit
+func (bc *blockchain) pruneLoop(quit chan struct{}) {
+	defer bc.pruneWG.Done()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-bc.pruneCh:
+			if err := bc.pruneInBackground(); err != nil {
+				logger.Error().Err(err).Msg("Failed to prune the blocks")
+			}
+		}
+	}
+}
+
+// pruneInBackground prunes a batch of blocks out of the retention
+func (bc *blockchain) pruneInBackground() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.prune(pruneBatchSize)
 }
 
-func createAndInitBlockchain(kvstore db.KVStore, sf state.Factory, cfg *config.Config) Blockchain {
-	dao := newBlockDAO(kvstore)
+func createAndInitBlockchain(dao *blockDAO, sf state.Factory, cfg *config.Config) Blockchain {
 	// create the Blockchain
 	chain := NewBlockchain(dao, cfg, sf)
 	if err := chain.Init(); err != nil {
@@ -7,11 +7,14 @@
 package blockchain
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -199,7 +202,7 @@ func TestLoadBlockchainfromDB(t *testing.T) {
 	config.Chain.ChainDBPath = testDBPath
 
 	tr, _ := trie.NewTrie(testTriePath, false)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	sf.CreateState(ta.Addrinfo["miner"].RawAddress, Gen.TotalSupply)
 	// Disable block reward to make bookkeeping easier
 	Gen.BlockReward = uint64(0)
@@ -367,6 +370,190 @@ func TestLoadBlockchainfromDB(t *testing.T) {
 	require.Equal(totalVotes, uint64(23))
 }
 
+func TestBlockchain_ResumeState(t *testing.T) {
+	require := require.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	util.CleanupPath(t, testDBPath)
+	defer util.CleanupPath(t, testDBPath)
+	config.Chain.TrieDBPath = testTriePath
+	config.Chain.InMemTest = false
+	config.Chain.ChainDBPath = testDBPath
+	defer func(reward uint64) { Gen.BlockReward = reward }(Gen.BlockReward)
+	Gen.BlockReward = uint64(5)
+
+	start := func() (trie.Trie, state.Factory, Blockchain) {
+		tr, err := trie.NewTrie(testTriePath, false)
+		require.Nil(err)
+		sf, err := state.NewFactory(tr)
+		require.Nil(err)
+		bc := CreateBlockchain(config, sf)
+		require.NotNil(bc)
+		return tr, sf, bc
+	}
+	balances := func(bc Blockchain) map[string]string {
+		balances := map[string]string{}
+		for name, addr := range ta.Addrinfo {
+			s, err := bc.StateByAddr(addr.RawAddress)
+			if err == nil {
+				balances[name] = s.Balance.String()
+			}
+		}
+		return balances
+	}
+
+	tr, sf, bc := start()
+	for i := 0; i < 4; i++ {
+		blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(bc.CommitBlock(blk))
+	}
+	root := sf.RootHash()
+	expected := balances(bc)
+	require.Nil(bc.Stop())
+	require.Nil(tr.Close())
+
+	// the state kept in the trie DB is resumed from, rather than replaying the blocks onto it
+	tr, sf, bc = start()
+	height, committed := sf.Height()
+	require.True(committed)
+	require.Equal(uint64(4), height)
+	require.Equal(root, sf.RootHash())
+	require.Equal(expected, balances(bc))
+	require.Equal("20", expected["miner"])
+	// the state changes committed past the tip, which doesn't match the chain
+	tsf := action.NewCoinBaseTransfer(big.NewInt(100), ta.Addrinfo["bravo"].RawAddress)
+	require.Nil(sf.CommitStateChanges(5, []*action.Transfer{tsf}, nil))
+	require.Nil(bc.Stop())
+	require.Nil(tr.Close())
+
+	// the state which doesn't match the chain is rebuilt
+	tr, sf, bc = start()
+	defer tr.Close()
+	defer bc.Stop()
+	require.Equal(root, sf.RootHash())
+	require.Equal(expected, balances(bc))
+}
+
+func TestBlockchain_ImportSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	config.Chain.TrieDBPath = "trie.test"
+	config.Chain.InMemTest = true
+	config.Chain.TrustedProducers = []string{hex.EncodeToString(ta.Addrinfo["miner"].PublicKey)}
+
+	src := CreateBlockchain(config, nil)
+	require.NotNil(src)
+	defer src.Stop()
+	for i := 0; i < 3; i++ {
+		blk, err := src.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(src.CommitBlock(blk))
+	}
+	ss, err := src.StateSnapshot()
+	require.Nil(err)
+
+	bc := CreateBlockchain(config, nil)
+	require.NotNil(bc)
+	defer bc.Stop()
+	// the next block signed by itself doesn't vouch for the snapshot unless the producer is trusted
+	forged, err := src.MintNewBlock(nil, nil, ta.Addrinfo["alfa"], "")
+	require.Nil(err)
+	require.Equal(ErrUnknownProducer, errors.Cause(bc.ImportSnapshot(ss, forged)))
+	// neither does the block whose state root doesn't match
+	next, err := src.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+	require.Nil(err)
+	next.Header.stateRoot = common.Hash32B{1}
+	require.Nil(next.SignBlock(ta.Addrinfo["miner"]))
+	require.Equal(state.ErrSnapshotMismatch, errors.Cause(bc.ImportSnapshot(ss, next)))
+
+	next, err = src.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+	require.Nil(err)
+	require.Nil(bc.ImportSnapshot(ss, next))
+	height, err := bc.TipHeight()
+	require.Nil(err)
+	require.Equal(uint64(3), height)
+}
+
+func TestBlockchain_ValidateStateRoot(t *testing.T) {
+	require := require.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	config.Chain.TrieDBPath = "trie.test"
+	config.Chain.InMemTest = true
+
+	bc := CreateBlockchain(config, nil)
+	require.NotNil(bc)
+	defer bc.Stop()
+	blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+	require.Nil(err)
+	require.Nil(bc.ValidateBlock(blk))
+
+	// the block isn't built on the current states
+	blk.Header.stateRoot = common.Hash32B{1}
+	require.Nil(blk.SignBlock(ta.Addrinfo["miner"]))
+	require.Equal(ErrInvalidBlock, errors.Cause(bc.ValidateBlock(blk)))
+}
+
+func TestBlockchain_PruneResumeState(t *testing.T) {
+	require := require.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	util.CleanupPath(t, testDBPath)
+	defer util.CleanupPath(t, testDBPath)
+	config.Chain.TrieDBPath = testTriePath
+	config.Chain.InMemTest = false
+	config.Chain.ChainDBPath = testDBPath
+	config.Chain.PruneRetention = 2
+
+	start := func() (trie.Trie, state.Factory, Blockchain) {
+		tr, err := trie.NewTrie(testTriePath, false)
+		require.Nil(err)
+		sf, err := state.NewFactory(tr)
+		require.Nil(err)
+		return tr, sf, CreateBlockchain(config, sf)
+	}
+
+	tr, sf, bc := start()
+	require.NotNil(bc)
+	for i := 0; i < 6; i++ {
+		blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(bc.CommitBlock(blk))
+	}
+	root := sf.RootHash()
+	require.Nil(bc.Stop())
+	require.Nil(tr.Close())
+
+	// the blocks out of the retention are pruned on restart, without keeping a state snapshot
+	tr, sf, bc = start()
+	require.NotNil(bc)
+	require.Equal(uint64(5), bc.(*blockchain).dao.prunedHeight())
+	ss, err := bc.(*blockchain).dao.getSnapshot()
+	require.Nil(err)
+	require.Nil(ss)
+	require.Equal(root, sf.RootHash())
+	// the state changes committed past the tip, which doesn't match the chain
+	tsf := action.NewCoinBaseTransfer(big.NewInt(100), ta.Addrinfo["bravo"].RawAddress)
+	require.Nil(sf.CommitStateChanges(7, []*action.Transfer{tsf}, nil))
+	require.Nil(bc.Stop())
+	require.Nil(tr.Close())
+
+	// the state can't be rebuilt without the pruned blocks
+	tr, _, bc = start()
+	defer tr.Close()
+	require.Nil(bc)
+}
+
 func TestBlockchain_Validator(t *testing.T) {
 	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
 	assert.Nil(t, err)
@@ -400,6 +587,44 @@ func TestBlockchain_MintNewDummyBlock(t *testing.T) {
 	assert.Equal(t, uint64(1), blk.Height())
 }
 
+func TestBlockchain_Prune(t *testing.T) {
+	require := require.New(t)
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	// disable account-based testing
+	config.Chain.TrieDBPath = ""
+	config.Chain.InMemTest = true
+	config.Chain.PruneRetention = 10
+
+	bc := CreateBlockchain(config, nil)
+	require.NotNil(bc)
+	defer bc.Stop()
+	for i := 0; i < pruneBatchSize+10; i++ {
+		blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(bc.CommitBlock(blk))
+	}
+
+	// the blocks are pruned in the background once there are a batch of them out of the retention
+	require.Nil(util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		_, err := bc.GetBlockByHeight(pruneBatchSize - 1)
+		return errors.Cause(err) == ErrPruned, nil
+	}))
+	// up to the retention, depending on the tip when the pruning runs
+	prunedTo := bc.(*blockchain).dao.prunedHeight()
+	require.True(prunedTo <= pruneBatchSize+1)
+	_, err = bc.GetBlockByHeight(prunedTo)
+	require.Nil(err)
+	transfers, err := bc.GetTransfersToAddress(ta.Addrinfo["miner"].RawAddress)
+	require.Nil(err)
+	require.Equal(int(pruneBatchSize+11-prunedTo), len(transfers))
+	hash, err := bc.GetHashByHeight(1)
+	require.Nil(err)
+	header, err := bc.GetBlockHeaderByHeight(1)
+	require.Nil(err)
+	require.Equal(hash, header.HashBlock())
+}
+
 func TestBlockchainInitialCandidate(t *testing.T) {
 	require := require.New(t)
 
@@ -417,14 +642,13 @@ func TestBlockchainInitialCandidate(t *testing.T) {
 	Gen.BlockReward = uint64(0)
 
 	tr, _ := trie.NewTrie(testTriePath, false)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr, state.CandidatePoolOption(2, 10))
 
 	height, candidate := sf.Candidates()
 	require.True(height == 0)
 	require.True(len(candidate) == 0)
 	bc := CreateBlockchain(config, sf)
 	require.NotNil(t, bc)
-	// TODO: change the value when Candidates size is changed
 	height, candidate = sf.Candidates()
 	require.True(height == 0)
 	require.True(len(candidate) == 2)
@@ -444,7 +668,7 @@ func TestCoinbaseTransfer(t *testing.T) {
 	config.Chain.ChainDBPath = testDBPath
 
 	tr, _ := trie.NewTrie(testTriePath, false)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	sf.CreateState(ta.Addrinfo["miner"].RawAddress, Gen.TotalSupply)
 
 	Gen.BlockReward = uint64(10)
@@ -494,3 +718,57 @@ func TestBlockchain_StateByAddr(t *testing.T) {
 	require.Equal("", s.Votee)
 	require.Equal(map[string]*big.Int(map[string]*big.Int(nil)), s.Voters)
 }
+
+func TestBlockchain_SimulateActions(t *testing.T) {
+	require := require.New(t)
+
+	config, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	config.Chain.TrieDBPath = "trie.test"
+	config.Chain.InMemTest = true
+	defer func(reward uint64) { Gen.BlockReward = reward }(Gen.BlockReward)
+	Gen.BlockReward = uint64(5)
+	bc := CreateBlockchain(config, nil)
+	require.NotNil(bc)
+	defer bc.Stop()
+
+	// the simulation runs along with the blocks committed, and sees the states of a tip
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 20; i++ {
+			blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+			if err == nil {
+				err = bc.CommitBlock(blk)
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	tsf := action.NewTransfer(1, big.NewInt(5), ta.Addrinfo["miner"].RawAddress, ta.Addrinfo["alfa"].RawAddress)
+	tsf, err = tsf.Sign(ta.Addrinfo["miner"])
+	require.Nil(err)
+	for committed := false; !committed; {
+		select {
+		case err := <-done:
+			require.Nil(err)
+			committed = true
+		default:
+		}
+		states, err := bc.SimulateActions([]*action.Transfer{tsf}, nil)
+		if err != nil {
+			// the miner has no balance before the first block
+			require.Equal(state.ErrNotEnoughBalance, errors.Cause(err))
+			continue
+		}
+		require.Equal(2, len(states))
+		for _, s := range states {
+			if s.Address == ta.Addrinfo["miner"].RawAddress {
+				// the block rewards of the tip less the amount transferred
+				require.Equal(int64(0), s.Balance.Int64()%5)
+			}
+		}
+	}
+}
@@ -7,6 +7,12 @@
 package blockchain
 
 import (
+	"bytes"
+	"sort"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/common"
@@ -14,6 +20,9 @@ import (
 	"github.com/iotexproject/iotex-core/common/utils"
 	"github.com/iotexproject/iotex-core/db"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
 )
 
 const (
@@ -25,8 +34,20 @@ const (
 	blockAddressTransferCountMappingNS = "address<->transfercount"
 	blockAddressVoteMappingNS          = "address<->vote"
 	blockAddressVoteCountMappingNS     = "address<->votecount"
+	blockAddressRewardMappingNS        = "address<->rewardreceipt"
+	blockAddressRewardCountMappingNS   = "address<->rewardreceiptcount"
+	blockRewardReceiptNS               = "rewardreceipts"
+	blockAddressHistoryMappingNS       = "address<->history"
+	blockAddressHistoryCountMappingNS  = "address<->historycount"
+	blockStateDiffNS                   = "statediffs"
+	// blockAddressRewardLegacyNS keeps the receipts of the voter rewards of each address in a single record, before
+	// they are kept by the heights
+	blockAddressRewardLegacyNS = "address<->reward"
 )
 
+// ErrPruned indicates the block body or the index asked for is pruned, while the block header is still kept
+var ErrPruned = errors.New("pruned from DB")
+
 var (
 	hashPrefix     = []byte("hash.")
 	transferPrefix = []byte("transfer.")
@@ -37,15 +58,56 @@ var (
 	// mutate this field is not thread safe, pls only mutate it in putBlock!
 	totalTransfersKey  = []byte("total-transfers")
 	totalVotesKey      = []byte("total-votes")
+	snapshotKey        = []byte("state-snapshot")
+	prunedToKey        = []byte("pruned-to")
+	migratedKey        = []byte("migrated-to-files")
+	startPrefix        = []byte("start.")
+	headerPrefix       = []byte("header.")
 	transferFromPrefix = []byte("transfer-from.")
 	transferToPrefix   = []byte("transfer-to.")
 	voteFromPrefix     = []byte("vote-from.")
 	voteToPrefix       = []byte("vote-to.")
+	rewardToPrefix     = []byte("reward-to.")
+	rewardPrefix       = []byte("reward.")
+	stateDiffPrefix    = []byte("diff.")
+	stateHistoryPrefix = []byte("history.")
 )
 
+// blockDAOMigrations are the migrations of the chain DB schema in the order of their versions. A migration is appended
+// for every change to the keys or the values kept in the chain DB.
+var blockDAOMigrations = []db.Migration{
+	{
+		Version:     1,
+		Description: "version the chain DB schema",
+		Migrate:     func(db.KVStore) error { return nil },
+	},
+	{
+		Version:     2,
+		Description: "encode the states in the state snapshot into AccountStatePb",
+		Migrate:     migrateSnapshotStates,
+	},
+	{
+		Version:     3,
+		Description: "keep the receipts of the voter rewards",
+		Migrate:     func(db.KVStore) error { return nil },
+	},
+	{
+		Version:     4,
+		Description: "keep the state diffs of the blocks",
+		Migrate:     func(db.KVStore) error { return nil },
+	},
+	{
+		Version:     5,
+		Description: "keep the receipts of the voter rewards by the heights",
+		Migrate:     migrateRewardReceipts,
+	},
+}
+
 type blockDAO struct {
 	service.CompositeService
-	kvstore db.KVStore
+	kvstore  db.KVStore
+	files    *blockFiles // nil if the blocks are kept in the KV store
+	prunedTo uint64      // the blocks below this height are pruned, which is accessed atomically
 }
 
 // newBlockDAO instantiates a block DAO
@@ -55,31 +117,139 @@ func newBlockDAO(kvstore db.KVStore) *blockDAO {
 	return blockDAO
 }
 
+// newFileBlockDAO instantiates a block DAO which keeps the blocks in the block files under the path, and the rest in
+// the KV store
+func newFileBlockDAO(kvstore db.KVStore, path string, blocksPerFile uint64) *blockDAO {
+	blockDAO := newBlockDAO(kvstore)
+	blockDAO.files = newBlockFiles(kvstore, path, blocksPerFile)
+	blockDAO.AddService(blockDAO.files)
+	return blockDAO
+}
+
 // Start starts block DAO and initiates the top height if it doesn't exist
 func (dao *blockDAO) Start() error {
 	err := dao.CompositeService.Start()
 	if err != nil {
 		return errors.Wrap(err, "failed to start child services")
 	}
+	if err = db.Migrate(dao.kvstore, blockDAOMigrations); err != nil {
+		return err
+	}
 
 	// set init height value
 	err = dao.kvstore.PutIfNotExists(blockNS, topHeightKey, make([]byte, 8))
-	if err != nil {
+	if err != nil && errors.Cause(err) != db.ErrAlreadyExist {
 		return errors.Wrap(err, "failed to write initial value for top height")
 	}
 
 	// set init total transfer to be 0
 	err = dao.kvstore.PutIfNotExists(blockNS, totalTransfersKey, make([]byte, 8))
-	if err != nil {
+	if err != nil && errors.Cause(err) != db.ErrAlreadyExist {
 		return errors.Wrap(err, "failed to write initial value for total transfers")
 	}
 
 	// set init total vote to be 0
 	err = dao.kvstore.PutIfNotExists(blockNS, totalVotesKey, make([]byte, 8))
-	if err != nil {
+	if err != nil && errors.Cause(err) != db.ErrAlreadyExist {
 		return errors.Wrap(err, "failed to write initial value for total votes")
 	}
 
+	if err := dao.loadPrunedTo(); err != nil {
+		return err
+	}
+
+	if dao.files != nil {
+		return dao.migrateToFiles()
+	}
+	return nil
+}
+
+// startReadOnly starts block DAO on the chain DB opened read-only, which is neither migrated nor initiated. The chain DB
+// is expected to be of the latest schema version, that is, the node has been started on it once.
+func (dao *blockDAO) startReadOnly() error {
+	if err := dao.CompositeService.Start(); err != nil {
+		return errors.Wrap(err, "failed to start child services")
+	}
+	version, err := db.SchemaVersion(dao.kvstore)
+	if err == nil {
+		if latest := blockDAOMigrations[len(blockDAOMigrations)-1].Version; version != latest {
+			err = errors.Errorf("chain DB schema version %d, expecting %d, start the node on it to migrate", version, latest)
+		}
+	}
+	if err == nil {
+		err = dao.loadPrunedTo()
+	}
+	if err != nil {
+		// release the lock of the chain DB file
+		dao.CompositeService.Stop()
+		return err
+	}
+	return nil
+}
+
+// prunedHeight returns the height below which the blocks are pruned
+func (dao *blockDAO) prunedHeight() uint64 {
+	return atomic.LoadUint64(&dao.prunedTo)
+}
+
+// loadPrunedTo loads the height to which the blocks are pruned
+func (dao *blockDAO) loadPrunedTo() error {
+	value, err := dao.kvstore.Get(blockNS, prunedToKey)
+	switch {
+	case err == nil:
+		atomic.StoreUint64(&dao.prunedTo, common.MachineEndian.Uint64(value))
+	case errors.Cause(err) != db.ErrNotExist && errors.Cause(err) != bolt.ErrBucketNotFound:
+		return errors.Wrap(err, "failed to get pruned height")
+	}
+	return nil
+}
+
+// migrateToFiles moves the blocks kept in the KV store into the block files. Each block is deleted from the KV store
+// only after it is in the block files, so that the migration can be resumed if it is interrupted. Once it's done, a flag
+// is stored so that the blocks aren't scanned again on the next start.
+func (dao *blockDAO) migrateToFiles() error {
+	_, err := dao.kvstore.Get(blockNS, migratedKey)
+	if err == nil {
+		return nil
+	}
+	if errors.Cause(err) != db.ErrNotExist {
+		return errors.Wrap(err, "failed to get the migration flag")
+	}
+	top, err := dao.getBlockchainHeight()
+	if err != nil {
+		return err
+	}
+	migrated := 0
+	for height := uint64(0); height <= top; height++ {
+		hash, err := dao.getBlockHash(height)
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			// the chain started from a state snapshot doesn't have the blocks before it
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		value, err := dao.kvstore.Get(blockNS, hash[:])
+		if errors.Cause(err) == db.ErrNotExist {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get block %x", hash)
+		}
+		if err := dao.files.put(height, value); err != nil && errors.Cause(err) != db.ErrAlreadyExist {
+			return errors.Wrapf(err, "failed to migrate block %d", height)
+		}
+		if err := dao.kvstore.Delete(blockNS, hash[:]); err != nil {
+			return errors.Wrapf(err, "failed to delete migrated block %d", height)
+		}
+		migrated++
+	}
+	if err := dao.kvstore.Put(blockNS, migratedKey, []byte{1}); err != nil {
+		return errors.Wrap(err, "failed to put the migration flag")
+	}
+	if migrated > 0 {
+		logger.Info().Int("blocks", migrated).Msg("Migrated blocks from chain DB to block files")
+	}
 	return nil
 }
 
@@ -113,7 +283,10 @@ func (dao *blockDAO) getBlockHeight(hash common.Hash32B) (uint64, error) {
 
 // getBlock returns a block
 func (dao *blockDAO) getBlock(hash common.Hash32B) (*Block, error) {
-	value, err := dao.kvstore.Get(blockNS, hash[:])
+	if err := dao.checkPruned(hash); err != nil {
+		return nil, err
+	}
+	value, err := dao.getSerializedBlock(hash)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get block %x", hash)
 	}
@@ -127,6 +300,57 @@ func (dao *blockDAO) getBlock(hash common.Hash32B) (*Block, error) {
 	return &blk, nil
 }
 
+// getBlockHeader returns the block holding only its header, which is kept even if the block is pruned
+func (dao *blockDAO) getBlockHeader(hash common.Hash32B) (*Block, error) {
+	value, err := dao.kvstore.Get(blockNS, append(headerPrefix, hash[:]...))
+	if err != nil && errors.Cause(err) != db.ErrNotExist {
+		return nil, errors.Wrapf(err, "failed to get block header %x", hash)
+	}
+	if err != nil {
+		// the header of the block not pruned is kept along with the block
+		blk, err := dao.getBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		return &Block{Header: blk.Header}, nil
+	}
+	header := &iproto.BlockHeaderPb{}
+	if err := proto.Unmarshal(value, header); err != nil {
+		return nil, errors.Wrap(err, "failed to deserialize block header")
+	}
+	blk := &Block{}
+	blk.ConvertFromBlockHeaderPb(&iproto.BlockPb{Header: header})
+	return blk, nil
+}
+
+// checkPruned returns ErrPruned if the block is pruned
+func (dao *blockDAO) checkPruned(hash common.Hash32B) error {
+	prunedTo := dao.prunedHeight()
+	if prunedTo == 0 {
+		return nil
+	}
+	height, err := dao.getBlockHeight(hash)
+	if err != nil {
+		return err
+	}
+	if height < prunedTo {
+		return errors.Wrapf(ErrPruned, "block %d", height)
+	}
+	return nil
+}
+
+// getSerializedBlock returns the serialized block from the block files or the KV store
+func (dao *blockDAO) getSerializedBlock(hash common.Hash32B) ([]byte, error) {
+	if dao.files == nil {
+		return dao.kvstore.Get(blockNS, hash[:])
+	}
+	height, err := dao.getBlockHeight(hash)
+	if err != nil {
+		return nil, err
+	}
+	return dao.files.get(height)
+}
+
 func (dao *blockDAO) getBlockHashByTransferHash(hash common.Hash32B) (common.Hash32B, error) {
 	blkHash := common.ZeroHash32B
 	key := append(transferPrefix, hash[:]...)
@@ -200,7 +424,8 @@ func (dao *blockDAO) getTransfersByRecipientAddress(address string) ([]common.Ha
 func (dao *blockDAO) getTransfersByAddress(address string, count uint64, keyPrefix []byte) ([]common.Hash32B, error) {
 	var res []common.Hash32B
 
-	for i := uint64(0); i < count; i++ {
+	// the entries before the start index are pruned
+	for i := dao.getStartIndex(blockAddressTransferCountMappingNS, keyPrefix, address); i < count; i++ {
 		// put new transfer to recipient
 		key := append(keyPrefix, address...)
 		key = append(key, utils.Uint64ToBytes(i)...)
@@ -278,7 +503,8 @@ func (dao *blockDAO) getVotesByRecipientAddress(address string) ([]common.Hash32
 func (dao *blockDAO) getVotesByAddress(address string, count uint64, keyPrefix []byte) ([]common.Hash32B, error) {
 	var res []common.Hash32B
 
-	for i := uint64(0); i < count; i++ {
+	// the entries before the start index are pruned
+	for i := dao.getStartIndex(blockAddressVoteCountMappingNS, keyPrefix, address); i < count; i++ {
 		// put new vote to recipient
 		key := append(keyPrefix, address...)
 		key = append(key, utils.Uint64ToBytes(i)...)
@@ -346,6 +572,499 @@ func (dao *blockDAO) getTotalVotes() (uint64, error) {
 	return common.MachineEndian.Uint64(value), nil
 }
 
+// getSnapshot returns the state snapshot which the chain starts from, or nil if the chain starts from genesis
+func (dao *blockDAO) getSnapshot() (*state.Snapshot, error) {
+	value, err := dao.kvstore.Get(blockNS, snapshotKey)
+	if errors.Cause(err) == db.ErrNotExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state snapshot")
+	}
+	ss := &state.Snapshot{}
+	if err := ss.Deserialize(value); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// putSnapshot puts the state snapshot which the chain starts from, and makes the block at its height, whose hash is
+// given, the top of the chain. The blocks before it are not stored.
+func (dao *blockDAO) putSnapshot(ss *state.Snapshot, hash common.Hash32B) error {
+	serialized, err := ss.Serialize()
+	if err != nil {
+		return err
+	}
+	if err = dao.kvstore.PutIfNotExists(blockNS, snapshotKey, serialized); err != nil {
+		return errors.Wrap(err, "failed to put state snapshot")
+	}
+	height := utils.Uint64ToBytes(ss.Height)
+	hashKey := append(hashPrefix, hash[:]...)
+	if err = dao.kvstore.Put(blockHashHeightMappingNS, hashKey, height); err != nil {
+		return errors.Wrap(err, "failed to put hash -> height mapping")
+	}
+	heightKey := append(heightPrefix, height...)
+	if err = dao.kvstore.Put(blockHashHeightMappingNS, heightKey, hash[:]); err != nil {
+		return errors.Wrap(err, "failed to put height -> hash mapping")
+	}
+	if err = dao.kvstore.Put(blockNS, topHeightKey, height); err != nil {
+		return errors.Wrap(err, "failed to put top height")
+	}
+	return nil
+}
+
+// getRewardReceipts returns the receipts of the voter rewards credited by the block at the height
+func (dao *blockDAO) getRewardReceipts(height uint64) ([]*state.RewardReceipt, error) {
+	value, err := dao.kvstore.Get(blockRewardReceiptNS, append(rewardPrefix, utils.Uint64ToBytes(height)...))
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get reward receipts at height %d", height)
+	}
+	receiptsPb := &iproto.RewardReceiptsPb{}
+	if err := proto.Unmarshal(value, receiptsPb); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode reward receipts at height %d", height)
+	}
+	receipts := make([]*state.RewardReceipt, 0, len(receiptsPb.Receipts))
+	for _, pb := range receiptsPb.Receipts {
+		receipts = append(receipts, state.RewardReceiptFromPb(pb))
+	}
+	return receipts, nil
+}
+
+// getRewardReceiptsByAddress returns the receipts of the voter rewards credited to the address by the blocks kept, in
+// the order of the heights
+func (dao *blockDAO) getRewardReceiptsByAddress(address string) ([]*state.RewardReceipt, error) {
+	count, err := dao.getAddressEntryCount(blockAddressRewardCountMappingNS, rewardToPrefix, address)
+	if err != nil {
+		return nil, err
+	}
+	var receipts []*state.RewardReceipt
+	// the entries before the start index are pruned
+	for i := dao.getStartIndex(blockAddressRewardCountMappingNS, rewardToPrefix, address); i < count; i++ {
+		height, err := dao.getAddressEntryHeight(blockAddressRewardMappingNS, rewardToPrefix, address, i)
+		if err != nil {
+			return nil, err
+		}
+		if height < dao.prunedHeight() {
+			continue
+		}
+		kept, err := dao.getRewardReceipts(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, receipt := range kept {
+			if receipt.Voter == address {
+				receipts = append(receipts, receipt)
+			}
+		}
+	}
+	return receipts, nil
+}
+
+// putRewardReceipts puts the receipts of the voter rewards credited by a block, and appends its height to the list of
+// each voter credited. The heights already in the lists are skipped, so that replaying the blocks doesn't list them
+// twice.
+func (dao *blockDAO) putRewardReceipts(receipts []*state.RewardReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	height := receipts[0].Height
+	receiptsPb := &iproto.RewardReceiptsPb{}
+	for _, receipt := range receipts {
+		if receipt.Height != height {
+			return errors.Errorf("reward receipts of heights %d and %d put together", height, receipt.Height)
+		}
+		receiptsPb.Receipts = append(receiptsPb.Receipts, state.RewardReceiptToPb(receipt))
+	}
+	value, err := proto.Marshal(receiptsPb)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode reward receipts at height %d", height)
+	}
+	key := append(rewardPrefix, utils.Uint64ToBytes(height)...)
+	if err := dao.kvstore.Put(blockRewardReceiptNS, key, value); err != nil {
+		return errors.Wrapf(err, "failed to put reward receipts at height %d", height)
+	}
+	for _, receipt := range receipts {
+		if err := dao.appendAddressHeight(blockAddressRewardMappingNS, blockAddressRewardCountMappingNS, rewardToPrefix,
+			receipt.Voter, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneRewardReceipts deletes the receipts of the voter rewards credited by the block at the height, and the entries of
+// the height in the lists of the voters credited
+func (dao *blockDAO) pruneRewardReceipts(height uint64) error {
+	receipts, err := dao.getRewardReceipts(height)
+	if err != nil {
+		return err
+	}
+	if receipts == nil {
+		return nil
+	}
+	for _, receipt := range receipts {
+		if err := dao.pruneAddressEntry(blockAddressRewardMappingNS, blockAddressRewardCountMappingNS,
+			rewardToPrefix, receipt.Voter, utils.Uint64ToBytes(height)); err != nil {
+			return err
+		}
+	}
+	key := append(rewardPrefix, utils.Uint64ToBytes(height)...)
+	if err := dao.kvstore.Delete(blockRewardReceiptNS, key); err != nil {
+		return errors.Wrapf(err, "failed to delete reward receipts at height %d", height)
+	}
+	return nil
+}
+
+// getStateDiff returns the changes of the accounts made by the block at the height
+func (dao *blockDAO) getStateDiff(height uint64) (*state.StateDiff, error) {
+	if height < dao.prunedHeight() {
+		return nil, errors.Wrapf(ErrPruned, "state diff at height %d", height)
+	}
+	value, err := dao.kvstore.Get(blockStateDiffNS, append(stateDiffPrefix, utils.Uint64ToBytes(height)...))
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil, errors.Wrapf(db.ErrNotExist, "state diff at height %d", height)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get state diff at height %d", height)
+	}
+	diff := &state.StateDiff{}
+	if err := diff.Deserialize(value); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// getStateHistory returns the changes of the account made by the blocks kept, in the order of the heights. Each of
+// them is the state diff of a block holding only the change of the account.
+func (dao *blockDAO) getStateHistory(address string) ([]*state.StateDiff, error) {
+	count, err := dao.getStateHistoryCount(address)
+	if err != nil {
+		return nil, err
+	}
+	var history []*state.StateDiff
+	// the entries before the start index are pruned
+	for i := dao.getStartIndex(blockAddressHistoryCountMappingNS, stateHistoryPrefix, address); i < count; i++ {
+		height, err := dao.getStateHistoryHeight(address, i)
+		if err != nil {
+			return nil, err
+		}
+		if height < dao.prunedHeight() {
+			continue
+		}
+		diff, err := dao.getStateDiff(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range diff.Accounts {
+			if account.Address() == address {
+				history = append(history, &state.StateDiff{Height: height, Accounts: []*state.AccountDiff{account}})
+				break
+			}
+		}
+	}
+	return history, nil
+}
+
+// getStateHistoryCount returns the number of the blocks which have changed the account, including the pruned ones
+func (dao *blockDAO) getStateHistoryCount(address string) (uint64, error) {
+	return dao.getAddressEntryCount(blockAddressHistoryCountMappingNS, stateHistoryPrefix, address)
+}
+
+// getStateHistoryHeight returns the height of the block at the index of the state history of the account
+func (dao *blockDAO) getStateHistoryHeight(address string, index uint64) (uint64, error) {
+	return dao.getAddressEntryHeight(blockAddressHistoryMappingNS, stateHistoryPrefix, address, index)
+}
+
+// putStateDiff puts the changes of the accounts made by a block, and appends its height to the history of each account
+// changed. The heights already in the history are skipped, so that replaying the blocks doesn't list them twice.
+func (dao *blockDAO) putStateDiff(diff *state.StateDiff) error {
+	if diff == nil {
+		return nil
+	}
+	serialized, err := diff.Serialize()
+	if err != nil {
+		return err
+	}
+	key := append(stateDiffPrefix, utils.Uint64ToBytes(diff.Height)...)
+	if err := dao.kvstore.Put(blockStateDiffNS, key, serialized); err != nil {
+		return errors.Wrapf(err, "failed to put state diff at height %d", diff.Height)
+	}
+	for _, account := range diff.Accounts {
+		if err := dao.appendAddressHeight(blockAddressHistoryMappingNS, blockAddressHistoryCountMappingNS,
+			stateHistoryPrefix, account.Address(), diff.Height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneStateDiff deletes the state diff at the height, and the entries of the height in the history of the accounts it
+// has changed
+func (dao *blockDAO) pruneStateDiff(height uint64) error {
+	key := append(stateDiffPrefix, utils.Uint64ToBytes(height)...)
+	value, err := dao.kvstore.Get(blockStateDiffNS, key)
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get state diff at height %d", height)
+	}
+	diff := &state.StateDiff{}
+	if err := diff.Deserialize(value); err != nil {
+		return err
+	}
+	for _, account := range diff.Accounts {
+		if err := dao.pruneAddressEntry(blockAddressHistoryMappingNS, blockAddressHistoryCountMappingNS,
+			stateHistoryPrefix, account.Address(), utils.Uint64ToBytes(height)); err != nil {
+			return err
+		}
+	}
+	if err := dao.kvstore.Delete(blockStateDiffNS, key); err != nil {
+		return errors.Wrapf(err, "failed to delete state diff at height %d", height)
+	}
+	return nil
+}
+
+// pruneBlocks deletes the bodies, the address indices and the state diffs of the blocks below the height, and keeps
+// their headers instead. The pruned height is only moved forward after all the blocks below it are pruned, so that the
+// pruning can be resumed if it is interrupted.
+func (dao *blockDAO) pruneBlocks(keepFrom uint64) error {
+	if keepFrom <= dao.prunedHeight() {
+		return nil
+	}
+	for height := dao.prunedHeight(); height < keepFrom; height++ {
+		hash, err := dao.getBlockHash(height)
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			// the chain started from a state snapshot doesn't have the blocks before it
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		value, err := dao.getSerializedBlock(hash)
+		if errors.Cause(err) == db.ErrNotExist {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get block %d", height)
+		}
+		blk := Block{}
+		if err := blk.Deserialize(value); err != nil {
+			return errors.Wrapf(err, "failed to deserialize block %d", height)
+		}
+		header, err := proto.Marshal(blk.ConvertToBlockHeaderPb())
+		if err != nil {
+			return errors.Wrapf(err, "failed to serialize the header of block %d", height)
+		}
+		if err := dao.kvstore.Put(blockNS, append(headerPrefix, hash[:]...), header); err != nil {
+			return errors.Wrapf(err, "failed to put the header of block %d", height)
+		}
+		if err := pruneTransfers(dao, &blk); err != nil {
+			return err
+		}
+		if err := pruneVotes(dao, &blk); err != nil {
+			return err
+		}
+		if err := dao.pruneStateDiff(height); err != nil {
+			return err
+		}
+		if err := dao.pruneRewardReceipts(height); err != nil {
+			return err
+		}
+		if dao.files != nil {
+			continue
+		}
+		if err := dao.kvstore.Delete(blockNS, hash[:]); err != nil {
+			return errors.Wrapf(err, "failed to delete block %d", height)
+		}
+	}
+	if dao.files != nil {
+		if err := dao.files.prune(dao.prunedHeight(), keepFrom); err != nil {
+			return err
+		}
+	}
+	if err := dao.kvstore.Put(blockNS, prunedToKey, utils.Uint64ToBytes(keepFrom)); err != nil {
+		return errors.Wrap(err, "failed to put pruned height")
+	}
+	logger.Info().Uint64("from", dao.prunedHeight()).Uint64("to", keepFrom).Msg("Pruned blocks")
+	atomic.StoreUint64(&dao.prunedTo, keepFrom)
+	return nil
+}
+
+// getStartIndex returns the index of the first entry kept in the list of the address, as the ones before it are pruned
+func (dao *blockDAO) getStartIndex(countNS string, keyPrefix []byte, address string) uint64 {
+	key := append(append(append([]byte{}, startPrefix...), keyPrefix...), address...)
+	value, err := dao.kvstore.Get(countNS, key)
+	if err != nil || len(value) == 0 {
+		return 0
+	}
+	return common.MachineEndian.Uint64(value)
+}
+
+// getAddressEntryCount returns the number of the entries put in the list of the address, including the pruned ones
+func (dao *blockDAO) getAddressEntryCount(countNS string, keyPrefix []byte, address string) (uint64, error) {
+	value, err := dao.kvstore.Get(countNS, append(append([]byte{}, keyPrefix...), address...))
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get entry count of address %s", address)
+	}
+	if len(value) == 0 {
+		return 0, errors.Errorf("entry count of address %s is broken", address)
+	}
+	return common.MachineEndian.Uint64(value), nil
+}
+
+// getAddressEntryHeight returns the height of the block kept at the index of the list of the address
+func (dao *blockDAO) getAddressEntryHeight(ns string, keyPrefix []byte, address string, index uint64) (uint64, error) {
+	key := append(append(append([]byte{}, keyPrefix...), address...), utils.Uint64ToBytes(index)...)
+	value, err := dao.kvstore.Get(ns, key)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get entry %d of address %s", index, address)
+	}
+	if len(value) == 0 {
+		return 0, errors.Wrapf(db.ErrNotExist, "entry %d of address %s missing", index, address)
+	}
+	return common.MachineEndian.Uint64(value), nil
+}
+
+// appendAddressHeight appends the height of the block to the list of the address, unless the last entry kept is at the
+// height or above it, so that replaying the blocks doesn't list them twice
+func (dao *blockDAO) appendAddressHeight(ns string, countNS string, keyPrefix []byte, address string, height uint64) error {
+	count, err := dao.getAddressEntryCount(countNS, keyPrefix, address)
+	if err != nil {
+		return err
+	}
+	if count > dao.getStartIndex(countNS, keyPrefix, address) {
+		last, err := dao.getAddressEntryHeight(ns, keyPrefix, address, count-1)
+		if err != nil {
+			return err
+		}
+		if last >= height {
+			return nil
+		}
+	}
+	countKey := append(append([]byte{}, keyPrefix...), address...)
+	entryKey := append(append([]byte{}, countKey...), utils.Uint64ToBytes(count)...)
+	if err := dao.kvstore.Put(ns, entryKey, utils.Uint64ToBytes(height)); err != nil {
+		return errors.Wrapf(err, "failed to put entry %d of address %s", count, address)
+	}
+	if err := dao.kvstore.Put(countNS, countKey, utils.Uint64ToBytes(count+1)); err != nil {
+		return errors.Wrapf(err, "failed to bump entry count of address %s", address)
+	}
+	return nil
+}
+
+// pruneAddressEntry deletes the entries kept in the list of the address up to the given one. The entries of an address
+// are put in the order of the blocks, so the ones before the given entry belong to the pruned blocks as well. The entry
+// which isn't kept in the list is pruned already.
+func (dao *blockDAO) pruneAddressEntry(ns string, countNS string, keyPrefix []byte, address string, entry []byte) error {
+	start := dao.getStartIndex(countNS, keyPrefix, address)
+	countKey := append(append([]byte{}, keyPrefix...), address...)
+	value, err := dao.kvstore.Get(countNS, countKey)
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get entry count of address %s", address)
+	}
+	count := common.MachineEndian.Uint64(value)
+	found := start
+	for ; found < count; found++ {
+		key := append(append([]byte{}, countKey...), utils.Uint64ToBytes(found)...)
+		value, err := dao.kvstore.Get(ns, key)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get entry %d of address %s", found, address)
+		}
+		if bytes.Equal(value, entry) {
+			break
+		}
+	}
+	if found == count {
+		return nil
+	}
+	for index := start; index <= found; index++ {
+		key := append(append([]byte{}, countKey...), utils.Uint64ToBytes(index)...)
+		if err := dao.kvstore.Delete(ns, key); err != nil {
+			return errors.Wrapf(err, "failed to delete entry %d of address %s", index, address)
+		}
+	}
+	startKey := append(append(append([]byte{}, startPrefix...), keyPrefix...), address...)
+	if err := dao.kvstore.Put(countNS, startKey, utils.Uint64ToBytes(found+1)); err != nil {
+		return errors.Wrapf(err, "failed to bump start index of address %s", address)
+	}
+	return nil
+}
+
+// migrateRewardReceipts moves the receipts of the voter rewards from the single record of each voter into the record of
+// each height, which the lists of the voters credited refer to. The records of the voters are deleted once all of them
+// are moved, so that the migration can run again if it is interrupted.
+func migrateRewardReceipts(kvstore db.KVStore) error {
+	byHeight := make(map[uint64][]*state.RewardReceipt)
+	if err := kvstore.Iterate(blockAddressRewardLegacyNS, func(key, value []byte) error {
+		receiptsPb := &iproto.RewardReceiptsPb{}
+		if err := proto.Unmarshal(value, receiptsPb); err != nil {
+			return errors.Wrapf(err, "failed to decode reward receipts of %s", key)
+		}
+		for _, pb := range receiptsPb.Receipts {
+			byHeight[pb.Height] = append(byHeight[pb.Height], state.RewardReceiptFromPb(pb))
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "failed to read the reward receipts")
+	}
+	heights := make([]uint64, 0, len(byHeight))
+	for height := range byHeight {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	dao := &blockDAO{kvstore: kvstore}
+	for _, height := range heights {
+		// the receipts of a height are credited in the order of the delegates and then the voters
+		receipts := byHeight[height]
+		sort.SliceStable(receipts, func(i, j int) bool {
+			if receipts[i].Delegate != receipts[j].Delegate {
+				return receipts[i].Delegate < receipts[j].Delegate
+			}
+			return receipts[i].Voter < receipts[j].Voter
+		})
+		if err := dao.putRewardReceipts(receipts); err != nil {
+			return err
+		}
+	}
+	return kvstore.DeleteNamespace(blockAddressRewardLegacyNS)
+}
+
+// migrateSnapshotStates re-encodes the states in the state snapshot which the chain starts from, if there is one
+func migrateSnapshotStates(kvstore db.KVStore) error {
+	value, err := kvstore.Get(blockNS, snapshotKey)
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get state snapshot")
+	}
+	ss := &state.Snapshot{}
+	if err := ss.Deserialize(value); err != nil {
+		return err
+	}
+	if err := state.MigrateSnapshot(ss); err != nil {
+		return err
+	}
+	serialized, err := ss.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := kvstore.Put(blockNS, snapshotKey, serialized); err != nil {
+		return errors.Wrap(err, "failed to put state snapshot")
+	}
+	return nil
+}
+
 // putBlock puts a block
 func (dao *blockDAO) putBlock(blk *Block) error {
 	height := utils.Uint64ToBytes(blk.Height())
@@ -354,7 +1073,24 @@ func (dao *blockDAO) putBlock(blk *Block) error {
 		return errors.Wrap(err, "failed to serialize block")
 	}
 	hash := blk.HashBlock()
-	if err = dao.kvstore.PutIfNotExists(blockNS, hash[:], serialized); err != nil {
+	value, err := dao.kvstore.Get(blockNS, topHeightKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to get top height")
+	}
+	topHeight := common.MachineEndian.Uint64(value)
+	if dao.files != nil {
+		// the block above the top may be left in the block files by an interrupted put, which is discarded to be
+		// put again
+		if blk.Height() > topHeight {
+			err = dao.files.discard(blk.Height())
+		}
+		if err == nil {
+			err = dao.files.put(blk.Height(), serialized)
+		}
+	} else {
+		err = dao.kvstore.PutIfNotExists(blockNS, hash[:], serialized)
+	}
+	if err != nil {
 		return errors.Wrap(err, "failed to put block")
 	}
 	hashKey := append(hashPrefix, hash[:]...)
@@ -365,11 +1101,6 @@ func (dao *blockDAO) putBlock(blk *Block) error {
 	if err = dao.kvstore.Put(blockHashHeightMappingNS, heightKey, hash[:]); err != nil {
 		return errors.Wrap(err, "failed to put height -> hash mapping")
 	}
-	value, err := dao.kvstore.Get(blockNS, topHeightKey)
-	if err != nil {
-		return errors.Wrap(err, "failed to get top height")
-	}
-	topHeight := common.MachineEndian.Uint64(value)
 	if blk.Height() > topHeight {
 		if err = dao.kvstore.Put(blockNS, topHeightKey, height); err != nil {
 			return errors.Wrap(err, "failed to put top height")
@@ -493,12 +1224,6 @@ func putVotes(dao *blockDAO, blk *Block) error {
 		}
 		Sender := SenderAddress.RawAddress
 
-		RecipientAddress, err := iotxaddress.GetAddress(vote.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
-		if err != nil {
-			return errors.Wrapf(err, " to get recipient address for pubkey %x", vote.VotePubkey)
-		}
-		Recipient := RecipientAddress.RawAddress
-
 		// get votes count for sender
 		senderVoteCount, err := dao.getVoteCountBySenderAddress(Sender)
 		if err != nil {
@@ -521,6 +1246,16 @@ func putVotes(dao *blockDAO, blk *Block) error {
 				voteHash, Sender)
 		}
 
+		// the vote withdrawing the vote of the sender has no recipient
+		if vote.IsUnvote() {
+			continue
+		}
+		RecipientAddress, err := iotxaddress.GetAddress(vote.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			return errors.Wrapf(err, " to get recipient address for pubkey %x", vote.VotePubkey)
+		}
+		Recipient := RecipientAddress.RawAddress
+
 		// get votes count for recipient
 		recipientVoteCount, err := dao.getVoteCountByRecipientAddress(Recipient)
 		if err != nil {
@@ -546,3 +1281,46 @@ func putVotes(dao *blockDAO, blk *Block) error {
 
 	return nil
 }
+
+// pruneTransfers deletes the transfers of the pruned block from the lists of their senders and recipients
+func pruneTransfers(dao *blockDAO, blk *Block) error {
+	for _, transfer := range blk.Transfers {
+		transferHash := transfer.Hash()
+		if err := dao.pruneAddressEntry(blockAddressTransferMappingNS, blockAddressTransferCountMappingNS,
+			transferFromPrefix, transfer.Sender, transferHash[:]); err != nil {
+			return err
+		}
+		if err := dao.pruneAddressEntry(blockAddressTransferMappingNS, blockAddressTransferCountMappingNS,
+			transferToPrefix, transfer.Recipient, transferHash[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneVotes deletes the votes of the pruned block from the lists of their senders and recipients
+func pruneVotes(dao *blockDAO, blk *Block) error {
+	for _, vote := range blk.Votes {
+		voteHash := vote.Hash()
+		sender, err := iotxaddress.GetAddress(vote.SelfPubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			return errors.Wrapf(err, " to get sender address for pubkey %x", vote.SelfPubkey)
+		}
+		if err := dao.pruneAddressEntry(blockAddressVoteMappingNS, blockAddressVoteCountMappingNS,
+			voteFromPrefix, sender.RawAddress, voteHash[:]); err != nil {
+			return err
+		}
+		if vote.IsUnvote() {
+			continue
+		}
+		recipient, err := iotxaddress.GetAddress(vote.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			return errors.Wrapf(err, " to get recipient address for pubkey %x", vote.VotePubkey)
+		}
+		if err := dao.pruneAddressEntry(blockAddressVoteMappingNS, blockAddressVoteCountMappingNS,
+			voteToPrefix, recipient.RawAddress, voteHash[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
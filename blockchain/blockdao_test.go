@@ -12,11 +12,15 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/iotexproject/iotex-core/blockchain/action"
 	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
 	"github.com/iotexproject/iotex-core/test/testaddress"
 	"github.com/iotexproject/iotex-core/test/util"
 )
@@ -132,3 +136,256 @@ func TestBlockDAO(t *testing.T) {
 	})
 
 }
+
+func TestBlockDAO_SchemaVersion(t *testing.T) {
+	assert := assert.New(t)
+	path := "/tmp/test-schema-version"
+	util.CleanupPath(t, path)
+	defer util.CleanupPath(t, path)
+
+	dao := newBlockDAO(db.NewBoltDB(path, nil))
+	assert.Nil(dao.Start())
+	version, err := db.SchemaVersion(dao.kvstore)
+	assert.Nil(err)
+	assert.Equal(blockDAOMigrations[len(blockDAOMigrations)-1].Version, version)
+
+	// the chain DB written by a newer node is refused
+	newer := append(blockDAOMigrations, db.Migration{
+		Version:     version + 1,
+		Description: "newer",
+		Migrate:     func(db.KVStore) error { return nil },
+	})
+	assert.Nil(db.Migrate(dao.kvstore, newer))
+	assert.Nil(dao.Stop())
+	dao = newBlockDAO(db.NewBoltDB(path, nil))
+	assert.Equal(db.ErrNewerSchema, errors.Cause(dao.Start()))
+	assert.Nil(dao.kvstore.Stop())
+}
+
+func TestBlockDAO_Unvote(t *testing.T) {
+	assert := assert.New(t)
+
+	voter := testaddress.Addrinfo["alfa"]
+	votee := testaddress.Addrinfo["bravo"]
+	vote := action.NewVote(1, voter.PublicKey, votee.PublicKey)
+	unvote := action.NewUnvote(2, voter.PublicKey)
+	blk := NewBlock(0, 1, common.ZeroHash32B, nil, []*action.Vote{vote, unvote})
+
+	dao := newBlockDAO(db.NewMemKVStore())
+	assert.Nil(dao.Start())
+	defer dao.Stop()
+	assert.Nil(dao.putBlock(blk))
+
+	// the unvote is listed for its sender only
+	votes, err := dao.getVotesBySenderAddress(voter.RawAddress)
+	assert.Nil(err)
+	assert.Equal([]common.Hash32B{vote.Hash(), unvote.Hash()}, votes)
+	votes, err = dao.getVotesByRecipientAddress(votee.RawAddress)
+	assert.Nil(err)
+	assert.Equal([]common.Hash32B{vote.Hash()}, votes)
+	blkHash, err := dao.getBlockHashByVoteHash(unvote.Hash())
+	assert.Nil(err)
+	assert.Equal(blk.HashBlock(), blkHash)
+
+	assert.Nil(dao.pruneBlocks(2))
+	votes, err = dao.getVotesBySenderAddress(voter.RawAddress)
+	assert.Nil(err)
+	assert.Equal(0, len(votes))
+}
+
+func TestBlockDAO_PruneAddressEntry(t *testing.T) {
+	assert := assert.New(t)
+
+	dao := newBlockDAO(db.NewMemKVStore())
+	assert.Nil(dao.Start())
+	defer dao.Stop()
+	recipient := testaddress.Addrinfo["alfa"].RawAddress
+	blks := []*Block{}
+	for i := 1; i <= 3; i++ {
+		tsf := action.NewCoinBaseTransfer(big.NewInt(int64(i)), recipient)
+		blk := NewBlock(0, uint64(i), common.ZeroHash32B, []*action.Transfer{tsf}, nil)
+		assert.Nil(dao.putBlock(blk))
+		blks = append(blks, blk)
+	}
+
+	// the entries before the pruned one are skipped over and pruned as well
+	assert.Nil(pruneTransfers(dao, blks[1]))
+	transfers, err := dao.getTransfersByRecipientAddress(recipient)
+	assert.Nil(err)
+	assert.Equal([]common.Hash32B{blks[2].Transfers[0].Hash()}, transfers)
+
+	// and the entry pruned already is left alone
+	assert.Nil(pruneTransfers(dao, blks[0]))
+	transfers, err = dao.getTransfersByRecipientAddress(recipient)
+	assert.Nil(err)
+	assert.Equal([]common.Hash32B{blks[2].Transfers[0].Hash()}, transfers)
+}
+
+func TestBlockDAO_RewardReceipts(t *testing.T) {
+	assert := assert.New(t)
+
+	delegate := testaddress.Addrinfo["alfa"].RawAddress
+	voter := testaddress.Addrinfo["bravo"].RawAddress
+	first := []*state.RewardReceipt{
+		{Height: 10, Delegate: delegate, Voter: voter, Amount: big.NewInt(3)},
+		{Height: 10, Delegate: delegate, Voter: delegate, Amount: big.NewInt(1)},
+	}
+	second := []*state.RewardReceipt{{Height: 20, Delegate: delegate, Voter: voter, Amount: big.NewInt(5)}}
+
+	dao := newBlockDAO(db.NewMemKVStore())
+	assert.Nil(dao.Start())
+	defer dao.Stop()
+	receipts, err := dao.getRewardReceiptsByAddress(voter)
+	assert.Nil(err)
+	assert.Equal(0, len(receipts))
+
+	assert.Nil(dao.putRewardReceipts(first))
+	assert.Nil(dao.putRewardReceipts(second))
+	// the receipts put again while replaying the blocks are skipped
+	assert.Nil(dao.putRewardReceipts(first))
+	assert.Nil(dao.putRewardReceipts(second))
+
+	receipts, err = dao.getRewardReceiptsByAddress(voter)
+	assert.Nil(err)
+	assert.Equal(2, len(receipts))
+	assert.Equal(uint64(10), receipts[0].Height)
+	assert.Equal("3", receipts[0].Amount.String())
+	assert.Equal(uint64(20), receipts[1].Height)
+	assert.Equal("5", receipts[1].Amount.String())
+	receipts, err = dao.getRewardReceiptsByAddress(delegate)
+	assert.Nil(err)
+	assert.Equal(1, len(receipts))
+	assert.Equal(delegate, receipts[0].Voter)
+
+	// the receipts of the pruned blocks are dropped, and so are their entries in the lists of the voters
+	coinbase := action.NewCoinBaseTransfer(big.NewInt(1), delegate)
+	assert.Nil(dao.putBlock(NewBlock(0, 10, common.ZeroHash32B, []*action.Transfer{coinbase}, nil)))
+	assert.Nil(dao.pruneBlocks(11))
+	receipts, err = dao.getRewardReceiptsByAddress(voter)
+	assert.Nil(err)
+	assert.Equal(1, len(receipts))
+	assert.Equal(uint64(20), receipts[0].Height)
+	receipts, err = dao.getRewardReceiptsByAddress(delegate)
+	assert.Nil(err)
+	assert.Equal(0, len(receipts))
+	assert.Equal(uint64(1), dao.getStartIndex(blockAddressRewardCountMappingNS, rewardToPrefix, voter))
+	assert.Equal(uint64(1), dao.getStartIndex(blockAddressRewardCountMappingNS, rewardToPrefix, delegate))
+	receipts, err = dao.getRewardReceipts(10)
+	assert.Nil(err)
+	assert.Nil(receipts)
+}
+
+func TestBlockDAO_MigrateRewardReceipts(t *testing.T) {
+	assert := assert.New(t)
+
+	delegate := testaddress.Addrinfo["alfa"].RawAddress
+	voter := testaddress.Addrinfo["bravo"].RawAddress
+	legacy := map[string][]*state.RewardReceipt{
+		voter: {
+			{Height: 10, Delegate: delegate, Voter: voter, Amount: big.NewInt(3)},
+			{Height: 20, Delegate: delegate, Voter: voter, Amount: big.NewInt(5)},
+		},
+		delegate: {{Height: 10, Delegate: delegate, Voter: delegate, Amount: big.NewInt(1)}},
+	}
+	kvstore := db.NewMemKVStore()
+	for address, receipts := range legacy {
+		receiptsPb := &iproto.RewardReceiptsPb{}
+		for _, receipt := range receipts {
+			receiptsPb.Receipts = append(receiptsPb.Receipts, state.RewardReceiptToPb(receipt))
+		}
+		value, err := proto.Marshal(receiptsPb)
+		assert.Nil(err)
+		assert.Nil(kvstore.Put(blockAddressRewardLegacyNS, append(rewardToPrefix, address...), value))
+	}
+
+	// the migration can run again if it is interrupted
+	assert.Nil(migrateRewardReceipts(kvstore))
+	assert.Nil(migrateRewardReceipts(kvstore))
+	_, err := kvstore.Get(blockAddressRewardLegacyNS, append(rewardToPrefix, voter...))
+	assert.NotNil(err)
+
+	dao := newBlockDAO(kvstore)
+	receipts, err := dao.getRewardReceipts(10)
+	assert.Nil(err)
+	assert.Equal(2, len(receipts))
+	receipts, err = dao.getRewardReceiptsByAddress(voter)
+	assert.Nil(err)
+	assert.Equal(2, len(receipts))
+	assert.Equal(uint64(10), receipts[0].Height)
+	assert.Equal(uint64(20), receipts[1].Height)
+	assert.Equal("5", receipts[1].Amount.String())
+	receipts, err = dao.getRewardReceiptsByAddress(delegate)
+	assert.Nil(err)
+	assert.Equal(1, len(receipts))
+	assert.Equal("1", receipts[0].Amount.String())
+}
+
+func TestBlockDAO_StateDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	sender := testaddress.Addrinfo["alfa"].RawAddress
+	recipient := testaddress.Addrinfo["bravo"].RawAddress
+	first := &state.StateDiff{Height: 1, Accounts: []*state.AccountDiff{
+		{New: &state.State{Address: sender, Balance: big.NewInt(100), VotingWeight: big.NewInt(0)}},
+	}}
+	second := &state.StateDiff{Height: 2, Accounts: []*state.AccountDiff{
+		{
+			Old: &state.State{Address: sender, Balance: big.NewInt(100), VotingWeight: big.NewInt(0)},
+			New: &state.State{Address: sender, Nonce: 1, Balance: big.NewInt(90), VotingWeight: big.NewInt(0)},
+		},
+		{New: &state.State{Address: recipient, Balance: big.NewInt(10), VotingWeight: big.NewInt(0)}},
+	}}
+
+	dao := newBlockDAO(db.NewMemKVStore())
+	assert.Nil(dao.Start())
+	defer dao.Stop()
+	_, err := dao.getStateDiff(1)
+	assert.Equal(db.ErrNotExist, errors.Cause(err))
+	history, err := dao.getStateHistory(sender)
+	assert.Nil(err)
+	assert.Equal(0, len(history))
+
+	assert.Nil(dao.putStateDiff(nil))
+	assert.Nil(dao.putStateDiff(first))
+	assert.Nil(dao.putStateDiff(second))
+	// the diffs put again while replaying the blocks don't list the heights twice
+	assert.Nil(dao.putStateDiff(first))
+	assert.Nil(dao.putStateDiff(second))
+
+	diff, err := dao.getStateDiff(2)
+	assert.Nil(err)
+	assert.Equal(2, len(diff.Accounts))
+	assert.Equal(sender, diff.Accounts[0].Address())
+	assert.Equal("100", diff.Accounts[0].Old.Balance.String())
+	assert.Equal("90", diff.Accounts[0].New.Balance.String())
+	assert.Nil(diff.Accounts[1].Old)
+	history, err = dao.getStateHistory(sender)
+	assert.Nil(err)
+	assert.Equal(2, len(history))
+	assert.Equal(uint64(1), history[0].Height)
+	assert.Equal(uint64(2), history[1].Height)
+	assert.Equal(uint64(1), history[1].Accounts[0].New.Nonce)
+	history, err = dao.getStateHistory(recipient)
+	assert.Nil(err)
+	assert.Equal(1, len(history))
+	assert.Equal(recipient, history[0].Accounts[0].Address())
+
+	// the diffs of the pruned blocks are dropped from the history
+	coinbase := action.NewCoinBaseTransfer(big.NewInt(1), sender)
+	assert.Nil(dao.putBlock(NewBlock(0, 1, common.ZeroHash32B, []*action.Transfer{coinbase}, nil)))
+	assert.Nil(dao.pruneBlocks(2))
+	_, err = dao.getStateDiff(1)
+	assert.Equal(ErrPruned, errors.Cause(err))
+	history, err = dao.getStateHistory(sender)
+	assert.Nil(err)
+	assert.Equal(1, len(history))
+	assert.Equal(uint64(2), history[0].Height)
+	// and so are their entries in the history
+	assert.Equal(uint64(1), dao.getStartIndex(blockAddressHistoryCountMappingNS, stateHistoryPrefix, sender))
+	_, err = dao.getStateHistoryHeight(sender, 0)
+	assert.Equal(db.ErrNotExist, errors.Cause(err))
+	height, err := dao.getStateHistoryHeight(sender, 1)
+	assert.Nil(err)
+	assert.Equal(uint64(2), height)
+	assert.Equal(uint64(0), dao.getStartIndex(blockAddressHistoryCountMappingNS, stateHistoryPrefix, recipient))
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	blockFileMode        = 0600
+	defaultBlocksPerFile = 10000
+	// recordHeaderSize is the size of the length prefixing each serialized block in the block file
+	recordHeaderSize = 4
+)
+
+var (
+	blockIndexPrefix  = []byte("index.")
+	blockOffsetPrefix = []byte("offset.")
+)
+
+// blockFiles keeps the serialized blocks in the append-only block files. The heights are cut into segments of
+// blocksPerFile consecutive heights, and the blocks of a segment are appended to its own file. The BlockIndex of each
+// segment kept in the KV store records the heights of the blocks stored, and the offset of each block in the file is
+// kept under its height, so that appending a block doesn't rewrite the offsets of the others.
+type blockFiles struct {
+	mu            sync.Mutex
+	kvstore       db.KVStore
+	path          string
+	blocksPerFile uint64
+	indices       map[uint64]*iproto.BlockIndex
+	files         map[uint64]*os.File
+}
+
+// newBlockFiles instantiates the block files under the directory
+func newBlockFiles(kvstore db.KVStore, path string, blocksPerFile uint64) *blockFiles {
+	if blocksPerFile == 0 {
+		blocksPerFile = defaultBlocksPerFile
+	}
+	return &blockFiles{
+		kvstore:       kvstore,
+		path:          path,
+		blocksPerFile: blocksPerFile,
+		indices:       map[uint64]*iproto.BlockIndex{},
+		files:         map[uint64]*os.File{},
+	}
+}
+
+// Init does nothing
+func (f *blockFiles) Init() error { return nil }
+
+// Start creates the directory of the block files if it doesn't exist yet
+func (f *blockFiles) Start() error {
+	return os.MkdirAll(f.path, 0700)
+}
+
+// Stop closes the opened block files
+func (f *blockFiles) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var err error
+	for segment, file := range f.files {
+		if e := file.Close(); e != nil {
+			err = errors.Wrapf(e, "failed to close block file %d", segment)
+		}
+	}
+	f.files = map[uint64]*os.File{}
+	f.indices = map[uint64]*iproto.BlockIndex{}
+	return err
+}
+
+// get returns the serialized block at the height. The lock is held through the read, so that the file isn't closed
+// by prune meanwhile.
+func (f *blockFiles) get(height uint64) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	segment := height / f.blocksPerFile
+	index, err := f.index(segment)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil || height < index.Start || height > index.End {
+		return nil, errors.Wrapf(db.ErrNotExist, "block %d missing in block files", height)
+	}
+	file, err := f.file(segment)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := f.offset(height)
+	if err != nil {
+		return nil, err
+	}
+	return read(file, offset, height)
+}
+
+// put appends the serialized block at the height to the file of its segment. The blocks of a segment are expected to
+// be put in the order of their heights. Putting the same block again is a no-op, while another block at the height put
+// already is rejected with db.ErrAlreadyExist.
+func (f *blockFiles) put(height uint64, value []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	segment := height / f.blocksPerFile
+	index, err := f.index(segment)
+	if err != nil {
+		return err
+	}
+	next := height
+	if index != nil {
+		next = index.End + 1
+	}
+	if height != next && (height > next || height < index.Start) {
+		return errors.Errorf("block %d doesn't follow block %d in block file %d", height, next-1, segment)
+	}
+
+	file, err := f.file(segment)
+	if err != nil {
+		return err
+	}
+	if height < next {
+		offset, err := f.offset(height)
+		if err != nil {
+			return err
+		}
+		stored, err := read(file, offset, height)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(stored, value) {
+			return errors.Wrapf(db.ErrAlreadyExist, "block %d", height)
+		}
+		return nil
+	}
+	// the bytes written after the last indexed block, if any, are left by a crash before updating the index and are
+	// never referred to
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.Wrapf(err, "failed to seek the end of block file %d", segment)
+	}
+	if offset+recordHeaderSize+int64(len(value)) > math.MaxUint32 {
+		return errors.Errorf("block file %d is full", segment)
+	}
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(value))
+	common.MachineEndian.PutUint32(record, uint32(len(value)))
+	record = append(record, value...)
+	if _, err := file.WriteAt(record, offset); err != nil {
+		return errors.Wrapf(err, "failed to write block %d", height)
+	}
+	if err := file.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync block file %d", segment)
+	}
+
+	if err := f.kvstore.Put(blockNS, offsetKey(height), utils.Uint32ToBytes(uint32(offset))); err != nil {
+		return errors.Wrapf(err, "failed to put the offset of block %d", height)
+	}
+	updated := &iproto.BlockIndex{Start: height, End: height}
+	if index != nil {
+		updated.Start = index.Start
+	}
+	return f.putIndex(segment, updated)
+}
+
+// discard forgets the blocks of the segment from the height on, so that they can be put again. The bytes of the
+// discarded blocks are left in the file and never referred to.
+func (f *blockFiles) discard(height uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	segment := height / f.blocksPerFile
+	index, err := f.index(segment)
+	if err != nil {
+		return err
+	}
+	if index == nil || height > index.End {
+		return nil
+	}
+	if height <= index.Start {
+		return f.deleteIndex(segment, index)
+	}
+	// the offsets of the discarded blocks are left to be overwritten when the blocks are put again
+	return f.putIndex(segment, &iproto.BlockIndex{Start: index.Start, End: height - 1})
+}
+
+// prune removes the files of the segments whose blocks are all below the height, starting from the segment holding
+// the given height
+func (f *blockFiles) prune(from uint64, to uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for segment := from / f.blocksPerFile; (segment+1)*f.blocksPerFile <= to; segment++ {
+		if file, ok := f.files[segment]; ok {
+			if err := file.Close(); err != nil {
+				return errors.Wrapf(err, "failed to close block file %d", segment)
+			}
+			delete(f.files, segment)
+		}
+		if err := os.Remove(f.name(segment)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove block file %d", segment)
+		}
+		index, err := f.index(segment)
+		if err != nil {
+			return err
+		}
+		if index == nil {
+			continue
+		}
+		if err := f.deleteIndex(segment, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// index returns the index of the segment, or nil if no block of the segment is stored yet
+func (f *blockFiles) index(segment uint64) (*iproto.BlockIndex, error) {
+	if index, ok := f.indices[segment]; ok {
+		return index, nil
+	}
+	value, err := f.kvstore.Get(blockNS, indexKey(segment))
+	if errors.Cause(err) == db.ErrNotExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the index of block file %d", segment)
+	}
+	index := &iproto.BlockIndex{}
+	if err := proto.Unmarshal(value, index); err != nil {
+		return nil, errors.Wrapf(err, "failed to deserialize the index of block file %d", segment)
+	}
+	if len(index.Offset) > 0 {
+		// the index put by the earlier versions records the offsets of the blocks, which are moved under their heights.
+		// The index is put without them only once they are all moved, so that the move can run again if it's
+		// interrupted.
+		keys := make([][]byte, 0, len(index.Offset))
+		values := make([][]byte, 0, len(index.Offset))
+		for i, offset := range index.Offset {
+			keys = append(keys, offsetKey(index.Start+uint64(i)))
+			values = append(values, utils.Uint32ToBytes(offset))
+		}
+		if err := f.kvstore.BatchPut(blockNS, keys, values); err != nil {
+			return nil, errors.Wrapf(err, "failed to put the offsets of block file %d", segment)
+		}
+		index = &iproto.BlockIndex{Start: index.Start, End: index.End}
+		if err := f.putIndex(segment, index); err != nil {
+			return nil, err
+		}
+	}
+	f.indices[segment] = index
+	return index, nil
+}
+
+// putIndex puts the index of the segment
+func (f *blockFiles) putIndex(segment uint64, index *iproto.BlockIndex) error {
+	serialized, err := proto.Marshal(index)
+	if err != nil {
+		return errors.Wrapf(err, "failed to serialize the index of block file %d", segment)
+	}
+	if err := f.kvstore.Put(blockNS, indexKey(segment), serialized); err != nil {
+		return errors.Wrapf(err, "failed to put the index of block file %d", segment)
+	}
+	f.indices[segment] = index
+	return nil
+}
+
+// deleteIndex deletes the index of the segment and the offsets of its blocks
+func (f *blockFiles) deleteIndex(segment uint64, index *iproto.BlockIndex) error {
+	delete(f.indices, segment)
+	if err := f.kvstore.Delete(blockNS, indexKey(segment)); err != nil {
+		return errors.Wrapf(err, "failed to delete the index of block file %d", segment)
+	}
+	keys := make([][]byte, 0, index.End-index.Start+1)
+	for height := index.Start; height <= index.End; height++ {
+		keys = append(keys, offsetKey(height))
+	}
+	if err := f.kvstore.BatchDelete(blockNS, keys); err != nil {
+		return errors.Wrapf(err, "failed to delete the offsets of block file %d", segment)
+	}
+	return nil
+}
+
+// offset returns the offset of the block at the height in the file of its segment
+func (f *blockFiles) offset(height uint64) (int64, error) {
+	value, err := f.kvstore.Get(blockNS, offsetKey(height))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the offset of block %d", height)
+	}
+	if len(value) != 4 {
+		return 0, errors.Errorf("offset of block %d is broken", height)
+	}
+	return int64(common.MachineEndian.Uint32(value)), nil
+}
+
+// file returns the opened file of the segment
+func (f *blockFiles) file(segment uint64) (*os.File, error) {
+	if file, ok := f.files[segment]; ok {
+		return file, nil
+	}
+	name := f.name(segment)
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, blockFileMode)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open block file %s", name)
+	}
+	f.files[segment] = file
+	return file, nil
+}
+
+// read reads the serialized block recorded at the offset of the file
+func read(file *os.File, offset int64, height uint64) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := file.ReadAt(header, offset); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the size of block %d", height)
+	}
+	size := common.MachineEndian.Uint32(header)
+	// the size is checked against the file before it's allocated, as a broken record may claim any size
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to stat the file of block %d", height)
+	}
+	if offset+recordHeaderSize+int64(size) > info.Size() {
+		return nil, errors.Errorf("block %d of %d bytes overruns its file", height, size)
+	}
+	value := make([]byte, size)
+	if _, err := file.ReadAt(value, offset+recordHeaderSize); err != nil {
+		return nil, errors.Wrapf(err, "failed to read block %d", height)
+	}
+	return value, nil
+}
+
+// name returns the name of the file of the segment
+func (f *blockFiles) name(segment uint64) string {
+	return filepath.Join(f.path, fmt.Sprintf("%08d.blk", segment))
+}
+
+func indexKey(segment uint64) []byte {
+	return append(blockIndexPrefix, utils.Uint64ToBytes(segment)...)
+}
+
+func offsetKey(height uint64) []byte {
+	return append(append([]byte{}, blockOffsetPrefix...), utils.Uint64ToBytes(height)...)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain/action"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/proto"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestBlockDAO_BlockFiles(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "blockfiles")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "chain.db")
+	filePath := filepath.Join(dir, "blocks")
+
+	blks := []*Block{NewGenesisBlock(nil)}
+	for i := uint64(1); i <= 6; i++ {
+		tsf := action.NewCoinBaseTransfer(big.NewInt(int64(i)), ta.Addrinfo["alfa"].RawAddress)
+		blks = append(blks, NewBlock(0, i, blks[i-1].HashBlock(), []*action.Transfer{tsf}, nil))
+	}
+
+	// the chain DB keeps the first blocks
+	dao := newBlockDAO(db.NewBoltDB(dbPath, nil))
+	require.Nil(dao.Start())
+	for _, blk := range blks[:5] {
+		require.Nil(dao.putBlock(blk))
+	}
+	require.Nil(dao.Stop())
+
+	// the blocks are migrated into the block files, 4 heights per file
+	dao = newFileBlockDAO(db.NewBoltDB(dbPath, nil), filePath, 4)
+	require.Nil(dao.Start())
+	for _, blk := range blks[:5] {
+		hash := blk.HashBlock()
+		_, err := dao.kvstore.Get(blockNS, hash[:])
+		require.Equal(db.ErrNotExist, errors.Cause(err))
+		stored, err := dao.getBlock(hash)
+		require.Nil(err)
+		require.Equal(hash, stored.HashBlock())
+	}
+	files, err := ioutil.ReadDir(filePath)
+	require.Nil(err)
+	require.Equal(2, len(files))
+
+	// the migration isn't scanned again once it's done
+	_, err = dao.kvstore.Get(blockNS, migratedKey)
+	require.Nil(err)
+
+	// the blocks are appended in the order of their heights, and putting the same block again is a no-op
+	require.Equal(db.ErrAlreadyExist, errors.Cause(dao.files.put(4, []byte{})))
+	serialized, err := blks[4].Serialize()
+	require.Nil(err)
+	require.Nil(dao.files.put(4, serialized))
+	require.NotNil(dao.files.put(6, []byte{}))
+
+	// the block left in the block files by an interrupted put is put again, even if it's replaced by another one
+	serialized, err = blks[5].Serialize()
+	require.Nil(err)
+	require.Nil(dao.files.put(5, serialized))
+	require.Nil(dao.putBlock(blks[5]))
+	tsf := action.NewCoinBaseTransfer(big.NewInt(100), ta.Addrinfo["bravo"].RawAddress)
+	orphan := NewBlock(0, 6, blks[5].HashBlock(), []*action.Transfer{tsf}, nil)
+	serialized, err = orphan.Serialize()
+	require.Nil(err)
+	require.Nil(dao.files.put(6, serialized))
+	require.Nil(dao.putBlock(blks[6]))
+	stored, err := dao.getBlock(blks[6].HashBlock())
+	require.Nil(err)
+	require.Equal(blks[6].HashBlock(), stored.HashBlock())
+	height, err := dao.getBlockchainHeight()
+	require.Nil(err)
+	require.Equal(uint64(6), height)
+	require.Nil(dao.Stop())
+
+	// the blocks are located through the indices after restarting
+	dao = newFileBlockDAO(db.NewBoltDB(dbPath, nil), filePath, 4)
+	require.Nil(dao.Start())
+	defer dao.Stop()
+	for _, blk := range blks {
+		stored, err := dao.getBlock(blk.HashBlock())
+		require.Nil(err)
+		require.Equal(blk.HashBlock(), stored.HashBlock())
+		require.Equal(len(blk.Transfers), len(stored.Transfers))
+	}
+	files, err = ioutil.ReadDir(filePath)
+	require.Nil(err)
+	require.Equal(2, len(files))
+}
+
+func TestBlockDAO_PruneBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prune")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "blocks")
+
+	blks := []*Block{NewGenesisBlock(nil)}
+	for i := uint64(1); i <= 6; i++ {
+		tsf := action.NewCoinBaseTransfer(big.NewInt(int64(i)), ta.Addrinfo["bravo"].RawAddress)
+		blks = append(blks, NewBlock(0, i, blks[i-1].HashBlock(), []*action.Transfer{tsf}, nil))
+	}
+
+	testPrune := func(t *testing.T, newDAO func() *blockDAO) {
+		require := require.New(t)
+		dao := newDAO()
+		require.Nil(dao.Start())
+		for _, blk := range blks {
+			require.Nil(dao.putBlock(blk))
+		}
+		require.Nil(dao.pruneBlocks(4))
+		require.Nil(dao.Stop())
+
+		// the pruned height is kept after restarting
+		dao = newDAO()
+		require.Nil(dao.Start())
+		defer dao.Stop()
+		require.Equal(uint64(4), dao.prunedTo)
+		require.Nil(dao.pruneBlocks(3))
+		for _, blk := range blks {
+			hash := blk.HashBlock()
+			header, err := dao.getBlockHeader(hash)
+			require.Nil(err)
+			require.Equal(hash, header.HashBlock())
+			stored, err := dao.getBlock(hash)
+			if blk.Height() < 4 {
+				require.Equal(ErrPruned, errors.Cause(err))
+				continue
+			}
+			require.Nil(err)
+			require.Equal(len(blk.Transfers), len(stored.Transfers))
+		}
+
+		// only the transfers of the blocks kept are listed
+		transfers, err := dao.getTransfersByRecipientAddress(ta.Addrinfo["bravo"].RawAddress)
+		require.Nil(err)
+		require.Equal(3, len(transfers))
+		for i, hash := range transfers {
+			require.Equal(blks[i+4].Transfers[0].Hash(), hash)
+		}
+		count, err := dao.getTransferCountByRecipientAddress(ta.Addrinfo["bravo"].RawAddress)
+		require.Nil(err)
+		require.Equal(uint64(6), count)
+		blkHash, err := dao.getBlockHashByTransferHash(blks[1].Transfers[0].Hash())
+		require.Nil(err)
+		_, err = dao.getBlock(blkHash)
+		require.Equal(ErrPruned, errors.Cause(err))
+	}
+
+	t.Run("chain DB", func(t *testing.T) {
+		dbPath := filepath.Join(dir, "chain.db")
+		testPrune(t, func() *blockDAO {
+			return newBlockDAO(db.NewBoltDB(dbPath, nil))
+		})
+	})
+	t.Run("block files", func(t *testing.T) {
+		dbPath := filepath.Join(dir, "files.db")
+		testPrune(t, func() *blockDAO {
+			return newFileBlockDAO(db.NewBoltDB(dbPath, nil), filePath, 3)
+		})
+		// the files holding only the pruned blocks are removed
+		files, err := ioutil.ReadDir(filePath)
+		require.Nil(t, err)
+		require.Equal(t, 2, len(files))
+	})
+}
+
+func TestBlockFiles_Offsets(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "offsets")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	kvstore := db.NewMemKVStore()
+	files := newBlockFiles(kvstore, dir, 4)
+	require.Nil(files.Start())
+	values := [][]byte{[]byte("genesis"), []byte("first"), []byte("second")}
+	for height, value := range values {
+		require.Nil(files.put(uint64(height), value))
+	}
+	require.Nil(files.Stop())
+
+	// the index put by the earlier versions records the offsets, which are moved under the heights once it's read
+	legacy := &iproto.BlockIndex{Start: 0, End: 2}
+	for height := range values {
+		offset, err := files.offset(uint64(height))
+		require.Nil(err)
+		legacy.Offset = append(legacy.Offset, uint32(offset))
+		require.Nil(kvstore.Delete(blockNS, offsetKey(uint64(height))))
+	}
+	serialized, err := proto.Marshal(legacy)
+	require.Nil(err)
+	require.Nil(kvstore.Put(blockNS, indexKey(0), serialized))
+	files = newBlockFiles(kvstore, dir, 4)
+	require.Nil(files.Start())
+	defer files.Stop()
+	for height, value := range values {
+		stored, err := files.get(uint64(height))
+		require.Nil(err)
+		require.Equal(value, stored)
+	}
+	serialized, err = kvstore.Get(blockNS, indexKey(0))
+	require.Nil(err)
+	index := &iproto.BlockIndex{}
+	require.Nil(proto.Unmarshal(serialized, index))
+	require.Equal(uint64(2), index.End)
+	require.Equal(0, len(index.Offset))
+
+	// a record claiming more bytes than its file holds is rejected before they're allocated
+	file, err := files.file(0)
+	require.Nil(err)
+	offset, err := file.Seek(0, io.SeekEnd)
+	require.Nil(err)
+	_, err = file.WriteAt(utils.Uint32ToBytes(math.MaxUint32), offset)
+	require.Nil(err)
+	_, err = read(file, offset, 3)
+	require.NotNil(err)
+}
@@ -80,6 +80,14 @@ func (v *validator) Validate(blk *Block, tipHeight uint64, tipHash common.Hash32
 	}
 
 	if v.sf != nil {
+		// verify new block is built on the current states
+		if blk.Header.height > 0 && blk.Header.stateRoot != v.sf.RootHash() {
+			return errors.Wrapf(
+				ErrInvalidBlock,
+				"Wrong state root %x, expecting %x",
+				blk.Header.stateRoot,
+				v.sf.RootHash())
+		}
 		// Verify the signatures here (balance is checked in CommitStateChanges)
 		for _, tsf := range blk.Transfers {
 			if tsf.IsCoinbase {
@@ -0,0 +1,54 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+var (
+	// ErrCheckpointMismatch means the block conflicts with the trusted checkpoint at its height
+	ErrCheckpointMismatch = errors.New("block conflicts with the checkpoint")
+)
+
+// Checkpoints are the hashes of the trusted blocks, keyed by the block heights
+type Checkpoints map[uint64]common.Hash32B
+
+// NewCheckpoints collects the checkpoints from the genesis and the config
+func NewCheckpoints(cfg *config.Config) (Checkpoints, error) {
+	checkpoints := Checkpoints{}
+	all := Gen.Checkpoints
+	if cfg != nil {
+		all = append(append([]config.Checkpoint{}, all...), cfg.Chain.Checkpoints...)
+	}
+	for _, checkpoint := range all {
+		bytes, err := hex.DecodeString(checkpoint.Hash)
+		if err != nil || len(bytes) != len(common.ZeroHash32B) {
+			return nil, errors.Errorf("invalid checkpoint hash %s at height %d", checkpoint.Hash, checkpoint.Height)
+		}
+		var hash common.Hash32B
+		copy(hash[:], bytes)
+		if existing, ok := checkpoints[checkpoint.Height]; ok && existing != hash {
+			return nil, errors.Errorf("conflicting checkpoints at height %d", checkpoint.Height)
+		}
+		checkpoints[checkpoint.Height] = hash
+	}
+	return checkpoints, nil
+}
+
+// Verify returns ErrCheckpointMismatch if there is a checkpoint at the height, and the hash is different
+func (c Checkpoints) Verify(height uint64, hash common.Hash32B) error {
+	if checkpoint, ok := c[height]; ok && checkpoint != hash {
+		return errors.Wrapf(ErrCheckpointMismatch, "block %d has hash %x instead of %x", height, hash, checkpoint)
+	}
+	return nil
+}
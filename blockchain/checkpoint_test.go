@@ -0,0 +1,51 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+)
+
+func TestCheckpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	hash1 := common.Hash32B{1}
+	hash2 := common.Hash32B{2}
+	defer func(checkpoints []config.Checkpoint) {
+		Gen.Checkpoints = checkpoints
+	}(Gen.Checkpoints)
+	Gen.Checkpoints = []config.Checkpoint{{Height: 10, Hash: hex.EncodeToString(hash1[:])}}
+
+	cfg := &config.Config{}
+	cfg.Chain.Checkpoints = []config.Checkpoint{
+		{Height: 10, Hash: hex.EncodeToString(hash1[:])},
+		{Height: 20, Hash: hex.EncodeToString(hash2[:])},
+	}
+	checkpoints, err := NewCheckpoints(cfg)
+	assert.Nil(err)
+	assert.Equal(Checkpoints{10: hash1, 20: hash2}, checkpoints)
+
+	assert.Nil(checkpoints.Verify(10, hash1))
+	assert.Nil(checkpoints.Verify(11, hash2))
+	assert.Equal(ErrCheckpointMismatch, errors.Cause(checkpoints.Verify(20, hash1)))
+
+	// The configured checkpoint can't conflict with the genesis one
+	cfg.Chain.Checkpoints[0].Hash = hex.EncodeToString(hash2[:])
+	_, err = NewCheckpoints(cfg)
+	assert.NotNil(err)
+
+	cfg.Chain.Checkpoints[0].Hash = "0123"
+	_, err = NewCheckpoints(cfg)
+	assert.NotNil(err)
+}
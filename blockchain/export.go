@@ -0,0 +1,127 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"hash/crc32"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	// blockRecordHeaderSize is the size of the length and the checksum prefixing each exported block
+	blockRecordHeaderSize = 8
+	// maxBlockRecordSize is the max size of an exported block
+	maxBlockRecordSize = 1 << 26
+)
+
+// ErrCorruptedBlockRecord indicates the exported block record is truncated or doesn't match its checksum
+var ErrCorruptedBlockRecord = errors.New("corrupted block record")
+
+// ExportBlocks writes the blocks of the heights in [start, end] to the writer. Each block is written as a BlockPb
+// record, prefixed by its length and its CRC32 checksum.
+func ExportBlocks(bc Blockchain, w io.Writer, start uint64, end uint64) error {
+	for height := start; height <= end; height++ {
+		blk, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get block %d", height)
+		}
+		blkPb := blk.ConvertToBlockPb()
+		if blkPb == nil {
+			// the block without any action still needs its header to be exported
+			blkPb = &iproto.BlockPb{Header: blk.ConvertToBlockHeaderPb()}
+		}
+		data, err := proto.Marshal(blkPb)
+		if err != nil {
+			return errors.Wrapf(err, "failed to serialize block %d", height)
+		}
+		record := make([]byte, blockRecordHeaderSize, blockRecordHeaderSize+len(data))
+		common.MachineEndian.PutUint32(record, uint32(len(data)))
+		common.MachineEndian.PutUint32(record[4:], crc32.ChecksumIEEE(data))
+		if _, err := w.Write(append(record, data...)); err != nil {
+			return errors.Wrapf(err, "failed to write block %d", height)
+		}
+	}
+	return nil
+}
+
+// ImportBlocks reads the exported blocks from the reader, and validates and commits them to the chain one by one. The
+// blocks already in the chain are skipped. It returns the number of the blocks committed.
+func ImportBlocks(bc Blockchain, r io.Reader) (int, error) {
+	imported := 0
+	for {
+		blk, err := readBlockRecord(r)
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, err
+		}
+		tipHeight, err := bc.TipHeight()
+		if err != nil {
+			return imported, err
+		}
+		if blk.Height() <= tipHeight {
+			hash, err := bc.GetHashByHeight(blk.Height())
+			if err != nil {
+				return imported, err
+			}
+			if hash != blk.HashBlock() {
+				return imported, errors.Wrapf(
+					ErrInvalidBlock,
+					"block %d conflicts with the chain, hash %x, expecting %x",
+					blk.Height(),
+					blk.HashBlock(),
+					hash)
+			}
+			continue
+		}
+		if err := bc.ValidateBlock(blk); err != nil {
+			return imported, errors.Wrapf(err, "failed to validate block %d", blk.Height())
+		}
+		if err := bc.CommitBlock(blk); err != nil {
+			return imported, errors.Wrapf(err, "failed to commit block %d", blk.Height())
+		}
+		imported++
+		logger.Debug().Uint64("height", blk.Height()).Msg("Import block")
+	}
+}
+
+// readBlockRecord reads the next exported block, or returns io.EOF if there is no more
+func readBlockRecord(r io.Reader) (*Block, error) {
+	header := make([]byte, blockRecordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.Wrap(ErrCorruptedBlockRecord, err.Error())
+	}
+	size := common.MachineEndian.Uint32(header)
+	if size > maxBlockRecordSize {
+		return nil, errors.Wrapf(ErrCorruptedBlockRecord, "block record of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrap(ErrCorruptedBlockRecord, err.Error())
+	}
+	if crc32.ChecksumIEEE(data) != common.MachineEndian.Uint32(header[4:]) {
+		return nil, errors.Wrap(ErrCorruptedBlockRecord, "checksum mismatch")
+	}
+	blkPb := &iproto.BlockPb{}
+	if err := proto.Unmarshal(data, blkPb); err != nil {
+		return nil, errors.Wrap(ErrCorruptedBlockRecord, err.Error())
+	}
+	blk := &Block{}
+	blk.ConvertFromBlockPb(blkPb)
+	return blk, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestExportImportBlocks(t *testing.T) {
+	require := require.New(t)
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	// disable account-based testing
+	cfg.Chain.TrieDBPath = ""
+	cfg.Chain.InMemTest = true
+
+	src := CreateBlockchain(cfg, nil)
+	require.NotNil(src)
+	defer src.Stop()
+	for i := 0; i < 5; i++ {
+		blk, err := src.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(src.CommitBlock(blk))
+	}
+	var buf bytes.Buffer
+	require.Nil(ExportBlocks(src, &buf, 0, 5))
+	exported := buf.Bytes()
+
+	dst := CreateBlockchain(cfg, nil)
+	require.NotNil(dst)
+	defer dst.Stop()
+	imported, err := ImportBlocks(dst, bytes.NewReader(exported))
+	require.Nil(err)
+	require.Equal(5, imported)
+	srcHash, err := src.TipHash()
+	require.Nil(err)
+	dstHash, err := dst.TipHash()
+	require.Nil(err)
+	require.Equal(srcHash, dstHash)
+
+	// importing again skips the blocks already in the chain
+	imported, err = ImportBlocks(dst, bytes.NewReader(exported))
+	require.Nil(err)
+	require.Equal(0, imported)
+
+	// the corrupted or truncated records are rejected
+	corrupted := append([]byte{}, exported...)
+	corrupted[len(corrupted)-1] ^= 1
+	_, err = ImportBlocks(dst, bytes.NewReader(corrupted))
+	require.Equal(ErrCorruptedBlockRecord, errors.Cause(err))
+	_, err = ImportBlocks(dst, bytes.NewReader(exported[:len(exported)-1]))
+	require.Equal(ErrCorruptedBlockRecord, errors.Cause(err))
+
+	// the blocks not following the tip fail the validation
+	buf.Reset()
+	require.Nil(ExportBlocks(src, &buf, 3, 5))
+	other := CreateBlockchain(cfg, nil)
+	require.NotNil(other)
+	defer other.Stop()
+	imported, err = ImportBlocks(other, &buf)
+	require.Equal(ErrInvalidTipHeight, errors.Cause(err))
+	require.Equal(0, imported)
+}
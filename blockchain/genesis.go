@@ -19,6 +19,7 @@ import (
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/iotxaddress"
 	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/state"
 )
 
 const testnetActionPath = "testnet_actions.yaml"
@@ -34,6 +35,20 @@ type Genesis struct {
 	GenesisCoinbaseData string
 	CreatorAddr         string
 	CreatorPubKey       string
+	// CandidateSize is the size of the candidate pool, and CandidateBufferSize is the size of the candidate buffer pool
+	// backing it. They decide the candidates kept in the state, so they are part of the genesis every node agrees on.
+	CandidateSize       uint
+	CandidateBufferSize uint
+	// VoteUnbondingPeriod is the number of blocks, for which the tokens of a withdrawn vote stay locked. It decides the
+	// balances that can be spent, so it is part of the genesis as well.
+	VoteUnbondingPeriod uint64
+	// VoterRewardPercentage is the percentage of the block reward credited to the voters of the block producer in
+	// proportion to their votes at the end of every reward epoch of RewardEpoch blocks, while 0 pays the whole block
+	// reward to the producer. They decide the balances, so they are part of the genesis as well.
+	VoterRewardPercentage uint
+	RewardEpoch           uint64
+	// Checkpoints are the trusted blocks that every node must go through, in addition to the configured ones
+	Checkpoints []config.Checkpoint
 }
 
 // GenesisAction is the root action struct, each package's action should be put as its sub struct
@@ -71,6 +86,10 @@ var Gen = &Genesis{
 	GenesisCoinbaseData: "Connecting the physical world, block by block",
 	CreatorAddr:         "io1qyqsyqcy222ggazmccgf7dsx9m9vfqtadw82ygwhjnxtmx",
 	CreatorPubKey:       "d01164c3afe47406728d3e17861a3251dcff39e62bdc2b93ccb69a02785a175e195b5605517fd647eb7dd095b3d862dffb087f35eacf10c6859d04a100dbfb7358eeca9d5c37c904",
+	CandidateSize:       state.DefaultCandidateSize,
+	CandidateBufferSize: state.DefaultCandidateBufferSize,
+	VoteUnbondingPeriod: uint64(8640),
+	RewardEpoch:         uint64(8640),
 }
 
 // NewGenesisBlock creates a new genesis block
@@ -0,0 +1,51 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+)
+
+var (
+	// ErrUnknownProducer means the block isn't produced by a trusted producer
+	ErrUnknownProducer = errors.New("block is not produced by a trusted producer")
+)
+
+// Producers are the public keys of the trusted block producers, which are hex encoded
+type Producers map[string]bool
+
+// NewProducers collects the trusted block producers, which are the initial delegates of the genesis and the configured
+// ones
+func NewProducers(cfg *config.Config) (Producers, error) {
+	producers := Producers{}
+	all := Gen.InitDelegatesPubKey
+	if cfg != nil {
+		all = append(append([]string{}, all...), cfg.Chain.TrustedProducers...)
+	}
+	for _, pubKey := range all {
+		if _, err := hex.DecodeString(pubKey); err != nil {
+			return nil, errors.Errorf("invalid trusted producer public key %s", pubKey)
+		}
+		producers[pubKey] = true
+	}
+	return producers, nil
+}
+
+// Verify returns ErrUnknownProducer unless the block is signed by one of the trusted producers
+func (p Producers) Verify(blk *Block) error {
+	if !p[hex.EncodeToString(blk.Header.Pubkey)] {
+		return errors.Wrapf(ErrUnknownProducer, "block %d is produced by %x", blk.Height(), blk.Header.Pubkey)
+	}
+	if !blk.VerifySignature() {
+		return errors.Wrapf(ErrInvalidBlock, "block %d has an invalid signature", blk.Height())
+	}
+	return nil
+}
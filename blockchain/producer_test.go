@@ -0,0 +1,40 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestProducers(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &config.Config{}
+	cfg.Chain.TrustedProducers = []string{hex.EncodeToString(ta.Addrinfo["miner"].PublicKey)}
+	producers, err := NewProducers(cfg)
+	assert.Nil(err)
+	// the initial delegates of the genesis are trusted as well
+	assert.Equal(len(Gen.InitDelegatesPubKey)+1, len(producers))
+
+	blk := NewBlock(0, 1, common.ZeroHash32B, nil, nil)
+	assert.Nil(blk.SignBlock(ta.Addrinfo["miner"]))
+	assert.Nil(producers.Verify(blk))
+	assert.Nil(blk.SignBlock(ta.Addrinfo["alfa"]))
+	assert.Equal(ErrUnknownProducer, errors.Cause(producers.Verify(blk)))
+
+	cfg.Chain.TrustedProducers = []string{"xyz"}
+	_, err = NewProducers(cfg)
+	assert.NotNil(err)
+}
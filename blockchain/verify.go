@@ -0,0 +1,311 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/trie"
+)
+
+// chainDBOpenTimeout is how long to wait for the lock of the chain DB file, which the running node holds
+const chainDBOpenTimeout = time.Second
+
+// chainVerifier walks the blocks in the chain DB and collects the inconsistencies found
+type chainVerifier struct {
+	dao    *blockDAO
+	sf     state.Factory // nil once the replay fails
+	report []string
+	// the transfers and the votes expected in the lists of the addresses, keyed by the list prefix
+	lists map[string]map[string][]common.Hash32B
+}
+
+// VerifyChain checks the chain DB and the trie DB at the paths of the config offline. It walks every height to recheck
+// the hash linkage, the block signatures and the tx roots, cross-checks the indices against the blocks, and replays the
+// actions to compare the resulting state root with the blocks and the trie DB. The DBs are opened read-only, so they are
+// left as they are, and the node is expected to be stopped. It returns every inconsistency found, while the error is
+// only returned if the DBs can't be read.
+func VerifyChain(cfg *config.Config) ([]string, error) {
+	kvStore := db.NewBoltDB(cfg.Chain.ChainDBPath, &bolt.Options{ReadOnly: true, Timeout: chainDBOpenTimeout})
+	dao := newBlockDAO(kvStore)
+	if cfg.Chain.BlockStore == config.FileBlockStore {
+		dao = newFileBlockDAO(kvStore, cfg.Chain.BlockFilePath, cfg.Chain.BlocksPerFile)
+	}
+	if err := dao.startReadOnly(); err != nil {
+		return nil, err
+	}
+	defer dao.Stop()
+
+	tr, err := trie.NewTrie("", true)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := state.NewFactory(
+		tr,
+		state.CandidatePoolOption(Gen.CandidateSize, Gen.CandidateBufferSize),
+		state.UnbondingPeriodOption(Gen.VoteUnbondingPeriod),
+		state.VoterRewardOption(Gen.VoterRewardPercentage, Gen.RewardEpoch),
+	)
+	if err != nil {
+		return nil, err
+	}
+	v := &chainVerifier{
+		dao:   dao,
+		sf:    sf,
+		lists: map[string]map[string][]common.Hash32B{},
+	}
+	if err := createGenesisStates(v.sf); err != nil {
+		return nil, err
+	}
+	if err := v.verifyBlocks(); err != nil {
+		return nil, err
+	}
+	v.verifyAddressIndices()
+	if v.sf != nil && cfg.Chain.TrieDBPath != "" {
+		root := v.sf.RootHash()
+		ok, err := trie.HasRoot(cfg.Chain.TrieDBPath, root)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			v.reportf("trie DB doesn't hold the state root %x replayed from the blocks", root)
+		}
+	}
+	return v.report, nil
+}
+
+func (v *chainVerifier) reportf(format string, args ...interface{}) {
+	v.report = append(v.report, fmt.Sprintf(format, args...))
+}
+
+// verifyBlocks walks the blocks from the genesis block, or the state snapshot which the chain starts from, to the top
+func (v *chainVerifier) verifyBlocks() error {
+	top, err := v.dao.getBlockchainHeight()
+	if err != nil {
+		return err
+	}
+	start := uint64(0)
+	ss, err := v.dao.getSnapshot()
+	if err != nil {
+		return err
+	}
+	if ss != nil {
+		if err := v.sf.LoadSnapshot(ss); err != nil {
+			return err
+		}
+		start = ss.Height + 1
+	}
+
+	prevHash := common.ZeroHash32B
+	for height := uint64(0); height <= top; height++ {
+		hash, err := v.dao.getBlockHash(height)
+		if err != nil {
+			// the chain started from a state snapshot doesn't have the blocks before it
+			if height+1 < start {
+				continue
+			}
+			v.reportf("block %d: hash missing, %v", height, err)
+			v.sf = nil
+			prevHash = common.ZeroHash32B
+			continue
+		}
+		if h, err := v.dao.getBlockHeight(hash); err != nil {
+			v.reportf("block %d: height of hash %x missing, %v", height, hash, err)
+		} else if h != height {
+			v.reportf("block %d: height of hash %x is %d", height, hash, h)
+		}
+		// the blocks before the state snapshot have their headers at most
+		pruned := height < start || height < v.dao.prunedHeight()
+		if pruned && height >= start {
+			// the state can't be replayed without the pruned blocks
+			v.sf = nil
+		}
+		var blk *Block
+		if pruned {
+			blk, err = v.dao.getBlockHeader(hash)
+		} else {
+			blk, err = v.dao.getBlock(hash)
+		}
+		if err != nil {
+			if height >= start {
+				v.reportf("block %d: block %x missing, %v", height, hash, err)
+				v.sf = nil
+			}
+			prevHash = hash
+			continue
+		}
+		v.verifyBlock(height, hash, prevHash, blk, !pruned)
+		prevHash = hash
+		if height >= start {
+			v.replay(height, blk)
+		}
+	}
+	return nil
+}
+
+// verifyBlock verifies the block at the height, and its indices if it isn't pruned
+func (v *chainVerifier) verifyBlock(height uint64, hash common.Hash32B, prevHash common.Hash32B, blk *Block, body bool) {
+	if blk.Height() != height {
+		v.reportf("block %d: height %d in the header", height, blk.Height())
+	}
+	if blk.HashBlock() != hash {
+		v.reportf("block %d: hash %x, expecting %x", height, blk.HashBlock(), hash)
+	}
+	if height > 0 && prevHash != common.ZeroHash32B && blk.PrevHash() != prevHash {
+		v.reportf("block %d: prev hash %x, expecting %x", height, blk.PrevHash(), prevHash)
+	}
+	if height > 0 && !blk.VerifySignature() {
+		v.reportf("block %d: invalid signature", height)
+	}
+	if !body {
+		return
+	}
+	if !blk.VerifyTxRoot() {
+		v.reportf("block %d: tx root %x, expecting %x", height, blk.Header.txRoot, blk.TxRoot())
+	}
+	for _, transfer := range blk.Transfers {
+		transferHash := transfer.Hash()
+		if blkHash, err := v.dao.getBlockHashByTransferHash(transferHash); err != nil {
+			v.reportf("block %d: block of transfer %x missing, %v", height, transferHash, err)
+		} else if blkHash != hash && !v.hasTransfer(blkHash, transferHash) {
+			v.reportf("block %d: transfer %x is mapped to block %x", height, transferHash, blkHash)
+		}
+		v.expect(transferFromPrefix, transfer.Sender, transferHash)
+		v.expect(transferToPrefix, transfer.Recipient, transferHash)
+	}
+	for _, vote := range blk.Votes {
+		voteHash := vote.Hash()
+		if blkHash, err := v.dao.getBlockHashByVoteHash(voteHash); err != nil {
+			v.reportf("block %d: block of vote %x missing, %v", height, voteHash, err)
+		} else if blkHash != hash && !v.hasVote(blkHash, voteHash) {
+			v.reportf("block %d: vote %x is mapped to block %x", height, voteHash, blkHash)
+		}
+		sender, err := iotxaddress.GetAddress(vote.SelfPubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			v.reportf("block %d: vote %x has invalid sender public key", height, voteHash)
+			continue
+		}
+		v.expect(voteFromPrefix, sender.RawAddress, voteHash)
+		if vote.IsUnvote() {
+			continue
+		}
+		recipient, err := iotxaddress.GetAddress(vote.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			v.reportf("block %d: vote %x has invalid recipient public key", height, voteHash)
+			continue
+		}
+		v.expect(voteToPrefix, recipient.RawAddress, voteHash)
+	}
+}
+
+// hasTransfer checks if the block has the transfer. The same transfer, such as the coinbase transfer, may be in several
+// blocks, and is mapped to the last one of them.
+func (v *chainVerifier) hasTransfer(blkHash common.Hash32B, transferHash common.Hash32B) bool {
+	blk, err := v.dao.getBlock(blkHash)
+	if err != nil {
+		return false
+	}
+	for _, transfer := range blk.Transfers {
+		if transfer.Hash() == transferHash {
+			return true
+		}
+	}
+	return false
+}
+
+// hasVote checks if the block has the vote
+func (v *chainVerifier) hasVote(blkHash common.Hash32B, voteHash common.Hash32B) bool {
+	blk, err := v.dao.getBlock(blkHash)
+	if err != nil {
+		return false
+	}
+	for _, vote := range blk.Votes {
+		if vote.Hash() == voteHash {
+			return true
+		}
+	}
+	return false
+}
+
+// replay commits the actions of the block to the state factory, after checking the state root the block is built on
+func (v *chainVerifier) replay(height uint64, blk *Block) {
+	if v.sf == nil {
+		return
+	}
+	if blk.StateRoot() != common.ZeroHash32B && blk.StateRoot() != v.sf.RootHash() {
+		v.reportf("block %d: state root %x, replayed %x", height, blk.StateRoot(), v.sf.RootHash())
+	}
+	if blk.Transfers == nil && blk.Votes == nil {
+		return
+	}
+	if err := v.sf.CommitStateChanges(height, blk.Transfers, blk.Votes); err != nil {
+		v.reportf("block %d: failed to replay the actions, %v", height, err)
+		// the state roots after it are meaningless
+		v.sf = nil
+	}
+}
+
+func (v *chainVerifier) expect(keyPrefix []byte, address string, hash common.Hash32B) {
+	lists, ok := v.lists[string(keyPrefix)]
+	if !ok {
+		lists = map[string][]common.Hash32B{}
+		v.lists[string(keyPrefix)] = lists
+	}
+	lists[address] = append(lists[address], hash)
+}
+
+// verifyAddressIndices compares the transfers and the votes listed for each address with the ones in the blocks
+func (v *chainVerifier) verifyAddressIndices() {
+	getters := []struct {
+		prefix []byte
+		get    func(string) ([]common.Hash32B, error)
+	}{
+		{transferFromPrefix, v.dao.getTransfersBySenderAddress},
+		{transferToPrefix, v.dao.getTransfersByRecipientAddress},
+		{voteFromPrefix, v.dao.getVotesBySenderAddress},
+		{voteToPrefix, v.dao.getVotesByRecipientAddress},
+	}
+	for _, getter := range getters {
+		lists := v.lists[string(getter.prefix)]
+		addresses := make([]string, 0, len(lists))
+		for address := range lists {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		for _, address := range addresses {
+			listed, err := getter.get(address)
+			if err == nil {
+				err = compareHashes(lists[address], listed)
+			}
+			if err != nil {
+				v.reportf("list %s%s: %v", getter.prefix, address, err)
+			}
+		}
+	}
+}
+
+func compareHashes(expected []common.Hash32B, listed []common.Hash32B) error {
+	if len(listed) != len(expected) {
+		return errors.Errorf("%d listed, expecting %d", len(listed), len(expected))
+	}
+	for i := range expected {
+		if listed[i] != expected[i] {
+			return errors.Errorf("%x listed at %d, expecting %x", listed[i], i, expected[i])
+		}
+	}
+	return nil
+}
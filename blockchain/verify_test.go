@@ -0,0 +1,97 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/db"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+)
+
+func TestVerifyChain(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "verify")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	cfg, err := config.LoadConfigWithPathWithoutValidation(testingConfigPath)
+	require.Nil(err)
+	cfg.Chain.ChainDBPath = filepath.Join(dir, "chain.db")
+	cfg.Chain.TrieDBPath = ""
+	cfg.Chain.InMemTest = false
+
+	bc := CreateBlockchain(cfg, nil)
+	require.NotNil(bc)
+	for i := 0; i < 3; i++ {
+		blk, err := bc.MintNewBlock(nil, nil, ta.Addrinfo["miner"], "")
+		require.Nil(err)
+		require.Nil(bc.CommitBlock(blk))
+	}
+	genesis, err := bc.GetBlockByHeight(0)
+	require.Nil(err)
+	blk, err := bc.GetBlockByHeight(2)
+	require.Nil(err)
+	require.Nil(bc.Stop())
+
+	// the DBs are left as they are
+	before, err := ioutil.ReadFile(cfg.Chain.ChainDBPath)
+	require.Nil(err)
+	report, err := VerifyChain(cfg)
+	require.Nil(err)
+	require.Equal(0, len(report), strings.Join(report, "\n"))
+	after, err := ioutil.ReadFile(cfg.Chain.ChainDBPath)
+	require.Nil(err)
+	require.Equal(before, after)
+
+	// break the indices of the blocks
+	kvStore := db.NewBoltDB(cfg.Chain.ChainDBPath, nil)
+	require.Nil(kvStore.Start())
+	hash := blk.HashBlock()
+	require.Nil(kvStore.Put(blockHashHeightMappingNS, append(hashPrefix, hash[:]...), utils.Uint64ToBytes(3)))
+	transferHash := genesis.Transfers[0].Hash()
+	require.Nil(kvStore.Delete(blockTransferBlockMappingNS, append(transferPrefix, transferHash[:]...)))
+	count, err := kvStore.Get(blockAddressTransferCountMappingNS, append(transferToPrefix, ta.Addrinfo["miner"].RawAddress...))
+	require.Nil(err)
+	require.Nil(kvStore.Put(
+		blockAddressTransferCountMappingNS,
+		append(transferToPrefix, ta.Addrinfo["miner"].RawAddress...),
+		utils.Uint64ToBytes(common.MachineEndian.Uint64(count)-1),
+	))
+	require.Nil(kvStore.Stop())
+
+	report, err = VerifyChain(cfg)
+	require.Nil(err)
+	require.Equal(3, len(report), strings.Join(report, "\n"))
+	require.True(strings.HasPrefix(report[0], "block 0: block of transfer"))
+	require.True(strings.HasPrefix(report[1], "block 2: height of hash"))
+	require.True(strings.HasPrefix(report[2], "list transfer-to."))
+
+	// the chain DB not migrated yet isn't migrated by the verification
+	cfg.Chain.ChainDBPath = filepath.Join(dir, "old.db")
+	kvStore = db.NewBoltDB(cfg.Chain.ChainDBPath, nil)
+	require.Nil(kvStore.Start())
+	require.Nil(kvStore.Put(blockNS, topHeightKey, make([]byte, 8)))
+	require.Nil(kvStore.Stop())
+	_, err = VerifyChain(cfg)
+	require.NotNil(err)
+	kvStore = db.NewBoltDB(cfg.Chain.ChainDBPath, nil)
+	require.Nil(kvStore.Start())
+	version, err := db.SchemaVersion(kvStore)
+	require.Nil(err)
+	require.Equal(uint64(0), version)
+	require.Nil(kvStore.Stop())
+}
@@ -30,6 +30,9 @@ const (
 	Init = Idle + 1
 	// Active indicates the state after first block has been processed
 	Active = Init + 1
+
+	// rateWindow is the period over which the sync speed is measured
+	rateWindow = time.Minute
 )
 
 // BlockSync defines the interface of blocksyncer
@@ -40,6 +43,11 @@ type BlockSync interface {
 	ProcessSyncRequest(sender string, sync *pb.BlockSync) error
 	ProcessBlock(blk *bc.Block) error
 	ProcessBlockSync(blk *bc.Block) error
+	ProcessHeaderSyncRequest(sender string, sync *pb.BlockHeaderSync) error
+	ProcessBlockHeaders(sender string, headers *pb.BlockHeaderContainer) error
+	ProcessSnapshotSyncRequest(sender string, sync *pb.StateSnapshotSync) error
+	ProcessSnapshotChunk(sender string, chunk *pb.StateSnapshotChunk) error
+	SyncStatus() (*pb.SyncStatus, error)
 }
 
 // blockSyncer implements BlockSync interface
@@ -55,7 +63,13 @@ type blockSyncer struct {
 	lastRcvdHeight uint64               // height of last incoming block
 	rcvdBlocks     map[uint64]*bc.Block // buffer of received blocks
 	actionTime     time.Time
+	commitTimes    []time.Time // times of committing the blocks within the rate window
 	sw             *SlidingWindow
+	dl             *downloader
+	snapshotSync   bool // starts an empty chain from the state snapshot of a peer
+	snap           *snapshotDownloader
+	snapServer     *snapshotServer
+	checkpoints    bc.Checkpoints
 	bc             bc.Blockchain
 	ap             actpool.ActPool
 	p2p            *network.Overlay
@@ -120,6 +134,15 @@ func NewBlockSyncer(cfg *config.Config, chain bc.Blockchain, ap actpool.ActPool,
 	default:
 		return nil, errors.New("Unexpected node type: " + cfg.NodeType)
 	}
+	checkpoints, err := bc.NewCheckpoints(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bs.checkpoints = checkpoints
+	bs.dl = newDownloader(&cfg.BlockSync, chain, p2p, dp, bs.fnd, checkpoints)
+	bs.snapshotSync = cfg.BlockSync.SnapshotSync
+	bs.snap = newSnapshotDownloader(&cfg.BlockSync, p2p, bs.fnd)
+	bs.snapServer = newSnapshotServer(&cfg.BlockSync, chain)
 	return bs, nil
 }
 
@@ -152,6 +175,9 @@ func (bs *blockSyncer) Do() {
 	bs.mu.RLock()
 	defer bs.mu.RUnlock()
 
+	// retry the timed out requests of the ongoing sync
+	bs.dl.Do()
+	bs.snap.Do()
 	if bs.state == Idle {
 		// simple exit if we haven't received any blocks
 		return
@@ -164,13 +190,14 @@ func (bs *blockSyncer) Do() {
 
 	// This handles the case where a sync takes long time. By the time the window is closing, enough new
 	// blocks are being dropped, so we check the window range and issue a new sync request
-	if bs.state == Active && bs.sw.State != Open && bs.syncHeight < bs.dropHeight {
-		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockSync{bs.syncHeight + 1, bs.dropHeight})
+	if bs.state == Active && bs.sw.State != Open && bs.syncHeight < bs.dropHeight && !bs.snap.Active() {
+		if err := bs.dl.Sync(bs.dropHeight); err != nil {
+			logger.Error().Err(err).Msg("Error when syncing the dropped blocks")
+		}
 		logger.Warn().
 			Str("addr", bs.p2p.PRC.Addr).
 			Uint64("start", bs.syncHeight+1).
 			Uint64("end", bs.dropHeight).
-			Str("to", bs.fnd).
 			Msg("+++++++++")
 		if bs.dropHeight-bs.syncHeight > WindowSize {
 			// trigger ProcessBlock() to drop incoming blocks, preventing too many blocks piling up in the buffer
@@ -206,28 +233,150 @@ func (bs *blockSyncer) ProcessSyncRequest(sender string, sync *pb.BlockSync) err
 		if err != nil {
 			return err
 		}
+		blkPb := blk.ConvertToBlockPb()
+		if blkPb == nil {
+			// the block without any action still needs its header to be synced
+			blkPb = &pb.BlockPb{Header: blk.ConvertToBlockHeaderPb()}
+		}
 		// TODO: send back multiple blocks in one shot
-		bs.p2p.Tell(cm.NewTCPNode(sender), &pb.BlockContainer{blk.ConvertToBlockPb()})
+		bs.p2p.Tell(cm.NewTCPNode(sender), &pb.BlockContainer{Block: blkPb})
 		//time.Sleep(time.Millisecond << 8)
 	}
 	return nil
 }
 
+// ProcessHeaderSyncRequest processes a block header sync request
+func (bs *blockSyncer) ProcessHeaderSyncRequest(sender string, sync *pb.BlockHeaderSync) error {
+	if !bs.ackSyncReq {
+		// node is not meant to handle sync request, simply exit
+		return nil
+	}
+	height, err := bs.bc.TipHeight()
+	if err != nil {
+		return err
+	}
+	end := sync.End
+	if end > height {
+		end = height
+	}
+	if sync.Start+maxHeadersPerRequest <= end {
+		end = sync.Start + maxHeadersPerRequest - 1
+	}
+	headers := &pb.BlockHeaderContainer{}
+	for i := sync.Start; i <= end; i++ {
+		blk, err := bs.bc.GetBlockHeaderByHeight(i)
+		if err != nil {
+			return err
+		}
+		headers.Headers = append(headers.Headers, blk.ConvertToBlockHeaderPb())
+	}
+	return bs.p2p.Tell(cm.NewTCPNode(sender), headers)
+}
+
+// ProcessBlockHeaders processes the headers answering the block header sync request
+func (bs *blockSyncer) ProcessBlockHeaders(sender string, headers *pb.BlockHeaderContainer) error {
+	if !bs.ackBlockSync {
+		// node is not meant to handle sync block, simply exit
+		return nil
+	}
+	return bs.dl.OnHeaders(sender, headers.Headers)
+}
+
+// ProcessSnapshotSyncRequest processes a state snapshot sync request
+func (bs *blockSyncer) ProcessSnapshotSyncRequest(sender string, sync *pb.StateSnapshotSync) error {
+	if !bs.ackSyncReq {
+		// node is not meant to handle sync request, simply exit
+		return nil
+	}
+	chunk, err := bs.snapServer.Chunk(sync.Height, sync.Chunk)
+	if err != nil {
+		return err
+	}
+	return bs.p2p.Tell(cm.NewTCPNode(sender), chunk)
+}
+
+// ProcessSnapshotChunk processes the chunk answering the state snapshot sync request
+func (bs *blockSyncer) ProcessSnapshotChunk(sender string, chunk *pb.StateSnapshotChunk) error {
+	if !bs.ackBlockSync {
+		// node is not meant to handle sync block, simply exit
+		return nil
+	}
+	return bs.snap.OnChunk(sender, chunk)
+}
+
+// SyncStatus returns the progress of block sync
+func (bs *blockSyncer) SyncStatus() (*pb.SyncStatus, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	height, err := bs.bc.TipHeight()
+	if err != nil {
+		return nil, err
+	}
+	status := &pb.SyncStatus{
+		State:       stateName(bs.state),
+		Syncing:     bs.dl.Active() || bs.snap.Active(),
+		LocalHeight: height,
+		BestHeight:  height,
+		ActivePeers: bs.dl.ActivePeers(),
+	}
+	for _, h := range []uint64{bs.currRcvdHeight, bs.dropHeight, bs.dl.Target()} {
+		if h > status.BestHeight {
+			status.BestHeight = h
+		}
+	}
+
+	// measure the speed over the commits within the rate window
+	bs.trimCommitTimes(time.Now())
+	status.BlocksPerSecond = float64(len(bs.commitTimes)) / rateWindow.Seconds()
+	if status.BlocksPerSecond > 0 && status.BestHeight > height {
+		status.Eta = int64(float64(status.BestHeight-height) / status.BlocksPerSecond)
+	}
+	return status, nil
+}
+
+// trimCommitTimes drops the commit times out of the rate window
+func (bs *blockSyncer) trimCommitTimes(now time.Time) {
+	i := 0
+	for i < len(bs.commitTimes) && now.Sub(bs.commitTimes[i]) > rateWindow {
+		i++
+	}
+	bs.commitTimes = bs.commitTimes[i:]
+}
+
+// stateName returns the name of the block syncer state
+func stateName(state int) string {
+	switch state {
+	case Idle:
+		return "Idle"
+	case Init:
+		return "Init"
+	case Active:
+		return "Active"
+	default:
+		return "Unknown"
+	}
+}
+
 // processFirstBlock processes an incoming latest committed block
 func (bs *blockSyncer) processFirstBlock() error {
 	height, err := bs.bc.TipHeight()
 	if err != nil {
 		return err
 	}
-	if bs.syncHeight = height; bs.currRcvdHeight > bs.syncHeight+1 {
+	if bs.syncHeight = height; bs.snapshotSync && height == 0 && bs.currRcvdHeight > 1 {
+		// start from the state snapshot of a peer instead of replaying the blocks from the genesis block
+		bs.snap.Start()
+	} else if bs.currRcvdHeight > bs.syncHeight+1 {
 		//TODO make it structured logging
 		logger.Warn().Msgf(
-			"++++++ [%s] Send first start = %d end = %d to %s",
+			"++++++ [%s] Sync first start = %d end = %d",
 			bs.p2p.PRC.Addr,
 			bs.syncHeight+1,
-			bs.currRcvdHeight,
-			bs.fnd)
-		bs.p2p.Tell(cm.NewTCPNode(bs.fnd), &pb.BlockSync{bs.syncHeight + 1, bs.currRcvdHeight})
+			bs.currRcvdHeight)
+		if err := bs.dl.Sync(bs.currRcvdHeight); err != nil {
+			return err
+		}
 	}
 	if err := bs.sw.SetRange(bs.syncHeight, bs.currRcvdHeight); err != nil {
 		return err
@@ -250,6 +399,10 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 	if err != nil {
 		return err
 	}
+	// reject the branch conflicting with the trusted checkpoints
+	if err := bs.checkpoints.Verify(blk.Height(), blk.HashBlock()); err != nil {
+		return err
+	}
 	if bs.currRcvdHeight = blk.Height(); bs.currRcvdHeight <= height {
 		err := fmt.Errorf(
 			"****** [%s] Received block height %d <= Blockchain tip height %d",
@@ -271,6 +424,19 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 		//TODO make it structured logging
 		logger.Warn().Msgf("====== receive tip block %d", bs.currRcvdHeight)
 	}
+	if bs.state == Idle {
+		// the first incoming block ahead of the tip starts syncing the blocks in between right away, or the state
+		// snapshot of a peer for a new node, as the synced blocks are only taken for the requested heights
+		if err := bs.processFirstBlock(); err != nil {
+			return err
+		}
+		bs.state = Active
+	}
+	if bs.snap.Active() {
+		// the block on top of the downloaded state snapshot may come as the latest committed block
+		bs.dropHeight = bs.currRcvdHeight
+		return bs.importSnapshot(blk)
+	}
 
 	// TODO  Refancor the sync part to make logic clear and thorough
 	// Just simply check the incoming blocks into the buffer
@@ -289,7 +455,7 @@ func (bs *blockSyncer) ProcessBlock(blk *bc.Block) error {
 	}
 
 	// check-in incoming block to the buffer
-	if err := bs.checkBlockIntoBuffer(blk); err != nil {
+	if err := bs.checkBlockIntoBuffer(blk, height); err != nil {
 		logger.Error().Err(err).Msg("")
 		return nil
 	}
@@ -321,17 +487,71 @@ func (bs *blockSyncer) ProcessBlockSync(blk *bc.Block) error {
 			height)
 		return nil
 	}
+	if bs.snap.Active() {
+		return bs.importSnapshot(blk)
+	}
+
+	// check the block against the synced headers, so that the one from a lying peer is requested again elsewhere
+	if err := bs.dl.Accept(blk); err != nil {
+		logger.Error().Err(err).Msg("Drop the synced block")
+		return nil
+	}
 
 	// check-in incoming block to the buffer
-	bs.checkBlockIntoBuffer(blk)
+	bs.checkBlockIntoBuffer(blk, height)
 
 	// commit all blocks in buffer that can be added to Blockchain
 	return bs.commitBlocksInBuffer()
 }
 
-// checkBlockIntoBuffer adds a received blocks into the buffer
-func (bs *blockSyncer) checkBlockIntoBuffer(blk *bc.Block) error {
+// importSnapshot imports the downloaded state snapshot along with the block on top of it, and then syncs the rest of
+// the blocks as usual
+func (bs *blockSyncer) importSnapshot(blk *bc.Block) error {
+	ss := bs.snap.Snapshot()
+	if ss == nil || blk.Height() != ss.Height+1 {
+		// drop the blocks until the snapshot is imported
+		return nil
+	}
+	if err := bs.checkpoints.Verify(blk.Height(), blk.HashBlock()); err != nil {
+		bs.snap.Fail()
+		return err
+	}
+	if err := bs.bc.ImportSnapshot(ss, blk); err != nil {
+		bs.snap.Fail()
+		return err
+	}
+	bs.snap.Finish()
+
+	target := bs.syncHeight
+	if bs.dropHeight > target {
+		target = bs.dropHeight
+	}
+	if target <= blk.Height() {
+		target = blk.Height()
+	}
+	if err := bs.sw.SetRange(ss.Height, target); err != nil {
+		return err
+	}
+	bs.syncHeight = target
+	bs.checkBlockIntoBuffer(blk, ss.Height)
+	if err := bs.commitBlocksInBuffer(); err != nil {
+		return err
+	}
+	return bs.dl.Sync(target)
+}
+
+// checkBlockIntoBuffer adds a received block into the buffer. The buffer only keeps the blocks within
+// maxBufferedBlocks ahead of the tip, so that it stays bounded.
+func (bs *blockSyncer) checkBlockIntoBuffer(blk *bc.Block, tip uint64) error {
 	height := blk.Height()
+	if height > tip+maxBufferedBlocks {
+		return fmt.Errorf("|||||| [%s] discard block %d too far ahead of tip %d", bs.p2p.PRC.Addr, height, tip)
+	}
+	for h := range bs.rcvdBlocks {
+		if h <= tip {
+			delete(bs.rcvdBlocks, h)
+		}
+	}
 	if bs.rcvdBlocks[height] != nil {
 		return fmt.Errorf("|||||| [%s] discard existing block %d", bs.p2p.PRC.Addr, height)
 	}
@@ -358,6 +578,7 @@ func (bs *blockSyncer) commitBlocksInBuffer() error {
 			return err
 		}
 		delete(bs.rcvdBlocks, next)
+		bs.dl.Committed(next)
 
 		// remove transfers in this block from ActPool and reset ActPool state
 		bs.ap.Reset()
@@ -368,6 +589,8 @@ func (bs *blockSyncer) commitBlocksInBuffer() error {
 			Uint64("height", blk.Height()).
 			Msg("commit a block")
 		bs.actionTime = time.Now()
+		bs.trimCommitTimes(bs.actionTime)
+		bs.commitTimes = append(bs.commitTimes, bs.actionTime)
 
 		// update sliding window
 		bs.sw.Update(next)
@@ -59,9 +59,9 @@ func TestSyncTaskInterval(t *testing.T) {
 	assert.Equal(interval, fullNode)
 }
 
-func generateP2P() *network.Overlay {
-	c := &config.Network{
-		Addr: "127.0.0.1:10001",
+func generateP2PConfig() *config.Network {
+	return &config.Network{
+		Addr:                    "127.0.0.1:10001",
 		MsgLogsCleaningInterval: 2 * time.Second,
 		MsgLogRetention:         10 * time.Second,
 		HealthCheckInterval:     time.Second,
@@ -76,7 +76,10 @@ func generateP2P() *network.Overlay {
 		MaxMsgSize:              1024 * 1024 * 10,
 		PeerDiscovery:           true,
 	}
-	return network.NewOverlay(c)
+}
+
+func generateP2P() *network.Overlay {
+	return network.NewOverlay(generateP2PConfig())
 }
 
 func TestNewBlockSyncer(t *testing.T) {
@@ -235,7 +238,7 @@ func TestBlockSyncer_ProcessBlock_TipHeight(t *testing.T) {
 	mBc.EXPECT().CommitBlock(gomock.Any()).AnyTimes()
 
 	tr, _ := trie.NewTrie("", true)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	ap := actpool.NewActPool(sf)
 
@@ -283,7 +286,7 @@ func TestBlockSyncer_ProcessBlockSync(t *testing.T) {
 	mBc.EXPECT().TipHeight().Times(1).Return(uint64(6), nil)
 
 	tr, _ := trie.NewTrie("", true)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	assert.NotNil(sf)
 	ap := actpool.NewActPool(sf)
 
@@ -304,3 +307,88 @@ func TestBlockSyncer_ProcessBlockSync(t *testing.T) {
 	assert.Nil(bs.ProcessBlockSync(blk))
 	assert.Nil(bs.ProcessBlockSync(blk))
 }
+
+func TestBlockSyncer_BufferCap(t *testing.T) {
+	assert := assert.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mPool := mock_delegate.NewMockPool(ctrl)
+	mPool.EXPECT().AllDelegates().Times(1).Return([]net.Addr{common.NewNode("", "123")}, nil)
+	mPool.EXPECT().AnotherDelegate(gomock.Any()).Times(1).Return(common.NewNode("", "123"))
+
+	cfgFullNode := &config.Config{
+		NodeType: config.FullNodeType,
+	}
+	bs, err := NewBlockSyncer(cfgFullNode, nil, nil, generateP2P(), mPool)
+	assert.Nil(err)
+	syncer := bs.(*blockSyncer)
+
+	// The block too far ahead of the tip is discarded
+	far := bc.NewBlock(uint32(123), uint64(5+maxBufferedBlocks+1), common.Hash32B{}, nil, nil)
+	assert.Error(syncer.checkBlockIntoBuffer(far, 5))
+	assert.Equal(0, len(syncer.rcvdBlocks))
+
+	// The blocks within the cap are buffered, until the tip passes them
+	last := bc.NewBlock(uint32(123), uint64(5+maxBufferedBlocks), common.Hash32B{}, nil, nil)
+	assert.Nil(syncer.checkBlockIntoBuffer(bc.NewBlock(uint32(123), uint64(6), common.Hash32B{}, nil, nil), 5))
+	assert.Nil(syncer.checkBlockIntoBuffer(last, 5))
+	assert.Equal(2, len(syncer.rcvdBlocks))
+	assert.Nil(syncer.checkBlockIntoBuffer(bc.NewBlock(uint32(123), uint64(8), common.Hash32B{}, nil, nil), 6))
+	assert.Equal(2, len(syncer.rcvdBlocks))
+	assert.Nil(syncer.rcvdBlocks[6])
+}
+
+func TestBlockSyncer_SyncStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mPool := mock_delegate.NewMockPool(ctrl)
+	mPool.EXPECT().AllDelegates().Times(1).Return([]net.Addr{common.NewNode("", "123")}, nil)
+	mPool.EXPECT().AnotherDelegate(gomock.Any()).Times(1).Return(common.NewNode("", "123"))
+
+	mBc := mock_blockchain.NewMockBlockchain(ctrl)
+	mBc.EXPECT().TipHeight().Times(1).Return(uint64(0), errors.New("Error"))
+	mBc.EXPECT().TipHeight().Times(2).Return(uint64(5), nil)
+
+	cfgFullNode := &config.Config{
+		NodeType: config.FullNodeType,
+	}
+	bs, err := NewBlockSyncer(cfgFullNode, mBc, nil, generateP2P(), mPool)
+	assert.Nil(err)
+	_, err = bs.SyncStatus()
+	assert.Error(err)
+
+	status, err := bs.SyncStatus()
+	assert.Nil(err)
+	assert.Equal("Idle", status.State)
+	assert.False(status.Syncing)
+	assert.Equal(uint64(5), status.LocalHeight)
+	assert.Equal(uint64(5), status.BestHeight)
+	assert.Equal(float64(0), status.BlocksPerSecond)
+	assert.Equal(int64(0), status.Eta)
+
+	// 30 blocks are committed within the last minute, and the one before is out of the rate window
+	syncer := bs.(*blockSyncer)
+	syncer.state = Active
+	syncer.currRcvdHeight = 20
+	now := time.Now()
+	syncer.commitTimes = []time.Time{now.Add(-2 * rateWindow)}
+	for i := 0; i < 30; i++ {
+		syncer.commitTimes = append(syncer.commitTimes, now)
+	}
+	syncer.dl.target = 20
+	syncer.dl.headerReq = &request{peer: "127.0.0.1:10002"}
+	syncer.dl.chunks = []*request{{peer: "127.0.0.1:10003"}, {peer: "127.0.0.1:10002"}}
+	status, err = bs.SyncStatus()
+	assert.Nil(err)
+	assert.Equal("Active", status.State)
+	assert.True(status.Syncing)
+	assert.Equal(uint64(20), status.BestHeight)
+	assert.Equal(0.5, status.BlocksPerSecond)
+	assert.Equal(int64(30), status.Eta)
+	assert.Equal([]string{"127.0.0.1:10002", "127.0.0.1:10003"}, status.ActivePeers)
+}
@@ -0,0 +1,465 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+)
+
+const (
+	defaultHeaderBatchSize = 256
+	defaultChunkSize       = 16
+	defaultDownloadPeers   = 4
+	defaultRequestTimeout  = 10 * time.Second
+	// maxHeadersPerRequest caps the number of headers served for one request
+	maxHeadersPerRequest = 1024
+	// maxBufferedBlocks caps how far ahead of the tip the blocks are downloaded and buffered to be committed
+	maxBufferedBlocks = 256
+)
+
+var (
+	// ErrInvalidHeader means the header doesn't chain up to the validated headers, its signature is invalid, or it's
+	// not produced by a delegate
+	ErrInvalidHeader = errors.New("invalid block header")
+	// ErrUnexpectedBlock means the block isn't requested, or it doesn't match the validated header at its height
+	ErrUnexpectedBlock = errors.New("block doesn't match the header")
+)
+
+// request is an outstanding request to a peer for the headers or the blocks within [start, end]
+type request struct {
+	peer     string
+	start    uint64
+	end      uint64
+	deadline time.Time
+}
+
+// downloader syncs the blocks in the header-first way. It first fetches the headers in batches and validates that they
+// chain up to the local tip and are produced by the delegates, and then downloads the blocks in chunks from several peers in parallel, checking them
+// against the validated headers. A request which times out or is answered with invalid data is retried on another
+// peer, and the peer is not asked again until the sync finishes.
+type downloader struct {
+	mu              sync.Mutex
+	bc              bc.Blockchain
+	p2p             *network.Overlay
+	dp              delegate.Pool
+	fallback        string
+	checkpoints     bc.Checkpoints
+	headerBatchSize uint64
+	chunkSize       uint64
+	downloadPeers   int
+	timeout         time.Duration
+
+	target        uint64
+	tip           uint64
+	headers       map[uint64]cm.Hash32B
+	headerTip     uint64
+	headerTipHash cm.Hash32B
+	headerReq     *request
+	chunks        []*request
+	next          uint64
+	received      map[uint64]bool
+	banned        map[string]bool
+}
+
+// newDownloader creates an instance of downloader. The fallback is the node to ask if no peer is connected.
+func newDownloader(
+	cfg *config.BlockSync,
+	chain bc.Blockchain,
+	p2p *network.Overlay,
+	dp delegate.Pool,
+	fallback string,
+	checkpoints bc.Checkpoints,
+) *downloader {
+	d := &downloader{
+		bc:              chain,
+		p2p:             p2p,
+		dp:              dp,
+		fallback:        fallback,
+		checkpoints:     checkpoints,
+		headerBatchSize: cfg.HeaderBatchSize,
+		chunkSize:       cfg.ChunkSize,
+		downloadPeers:   int(cfg.DownloadPeers),
+		timeout:         cfg.RequestTimeout,
+	}
+	if d.headerBatchSize == 0 {
+		d.headerBatchSize = defaultHeaderBatchSize
+	}
+	if d.chunkSize == 0 {
+		d.chunkSize = defaultChunkSize
+	}
+	if d.downloadPeers == 0 {
+		d.downloadPeers = defaultDownloadPeers
+	}
+	if d.timeout == 0 {
+		d.timeout = defaultRequestTimeout
+	}
+	d.reset()
+	return d
+}
+
+// Active returns true if a sync is in progress
+func (d *downloader) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.target > 0
+}
+
+// Target returns the height that the ongoing sync is up to, or 0 if no sync is in progress
+func (d *downloader) Target() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.target
+}
+
+// ActivePeers returns the peers which the outstanding requests are sent to
+func (d *downloader) ActivePeers() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := map[string]bool{}
+	peers := []string{}
+	reqs := d.chunks
+	if d.headerReq != nil {
+		reqs = append([]*request{d.headerReq}, reqs...)
+	}
+	for _, req := range reqs {
+		if !seen[req.peer] {
+			seen[req.peer] = true
+			peers = append(peers, req.peer)
+		}
+	}
+	return peers
+}
+
+// Sync starts syncing up to the target height, or extends the target of the ongoing sync
+func (d *downloader) Sync(target uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if target <= d.target {
+		return nil
+	}
+	if d.target == 0 {
+		height, err := d.bc.TipHeight()
+		if err != nil {
+			return err
+		}
+		if target <= height {
+			return nil
+		}
+		hash, err := d.bc.TipHash()
+		if err != nil {
+			return err
+		}
+		d.tip = height
+		d.headerTip = height
+		d.headerTipHash = hash
+		d.next = height + 1
+		logger.Info().
+			Uint64("tip", height).
+			Uint64("target", target).
+			Msg("Start header-first block sync")
+	}
+	d.target = target
+	d.schedule()
+	return nil
+}
+
+// OnHeaders validates the headers that the peer answers with, and then schedules the downloads of their blocks
+func (d *downloader) OnHeaders(sender string, headers []*pb.BlockHeaderPb) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.headerReq == nil || d.headerReq.peer != sender {
+		// Ignore the unsolicited or outdated headers
+		return nil
+	}
+	req := d.headerReq
+	d.headerReq = nil
+	err := d.appendHeaders(req, headers)
+	if errors.Cause(err) == bc.ErrCheckpointMismatch {
+		d.p2p.PM.BanPeer(sender)
+	}
+	if err != nil {
+		d.ban(sender)
+	}
+	d.schedule()
+	return err
+}
+
+// Accept checks the block against the checkpoint and the validated header at its height. If it doesn't match, the
+// chunk which the block belongs to is retried on another peer, and the peer serving the block conflicting with the
+// checkpoint is banned for good. The blocks which aren't requested by the outstanding chunks are rejected.
+func (d *downloader) Accept(blk *bc.Block) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	height := blk.Height()
+	chunk := d.chunkOf(height)
+	hash, ok := d.headers[height]
+	if chunk == nil || !ok {
+		return errors.Wrapf(ErrUnexpectedBlock, "block %d isn't requested", height)
+	}
+	if err := d.checkpoints.Verify(height, blk.HashBlock()); err != nil {
+		d.p2p.PM.BanPeer(chunk.peer)
+		d.ban(chunk.peer)
+		d.retry(chunk)
+		return err
+	}
+	if blk.HashBlock() == hash && blk.VerifyTxRoot() {
+		d.received[height] = true
+		return nil
+	}
+	d.ban(chunk.peer)
+	d.retry(chunk)
+	return errors.Wrapf(ErrUnexpectedBlock, "block %d", height)
+}
+
+// Committed moves the sync forward after the block at the height is committed
+func (d *downloader) Committed(height uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.target == 0 {
+		return
+	}
+	delete(d.headers, height)
+	delete(d.received, height)
+	if d.tip < height {
+		d.tip = height
+	}
+	if d.next <= height {
+		d.next = height + 1
+	}
+	if height >= d.target {
+		logger.Info().Uint64("height", height).Msg("Finish header-first block sync")
+		d.reset()
+		return
+	}
+	d.schedule()
+}
+
+// Do retries the requests which time out on other peers
+func (d *downloader) Do() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.target == 0 {
+		return
+	}
+	now := time.Now()
+	if d.headerReq != nil && now.After(d.headerReq.deadline) {
+		logger.Warn().
+			Str("peer", d.headerReq.peer).
+			Uint64("start", d.headerReq.start).
+			Msg("Header request timed out")
+		d.ban(d.headerReq.peer)
+		d.headerReq = nil
+	}
+	for _, chunk := range d.chunks {
+		if now.After(chunk.deadline) {
+			logger.Warn().
+				Str("peer", chunk.peer).
+				Uint64("start", chunk.start).
+				Uint64("end", chunk.end).
+				Msg("Block request timed out")
+			d.ban(chunk.peer)
+			d.retry(chunk)
+		}
+	}
+	d.schedule()
+}
+
+// appendHeaders validates the headers answering the request, and appends the valid ones to the header chain
+func (d *downloader) appendHeaders(req *request, headers []*pb.BlockHeaderPb) error {
+	if len(headers) == 0 {
+		return errors.Wrapf(ErrInvalidHeader, "no header answering %d to %d", req.start, req.end)
+	}
+	producers, err := d.producers()
+	if err != nil {
+		return err
+	}
+	for _, header := range headers {
+		blk := &bc.Block{}
+		blk.ConvertFromBlockHeaderPb(&pb.BlockPb{Header: header})
+		if blk.Height() != d.headerTip+1 || blk.Height() > req.end {
+			return errors.Wrapf(ErrInvalidHeader, "unexpected height %d", blk.Height())
+		}
+		if blk.PrevHash() != d.headerTipHash {
+			return errors.Wrapf(ErrInvalidHeader, "header %d doesn't link to the previous one", blk.Height())
+		}
+		if !blk.VerifySignature() {
+			return errors.Wrapf(ErrInvalidHeader, "header %d has an invalid signature", blk.Height())
+		}
+		if !producers[string(blk.Header.Pubkey)] {
+			return errors.Wrapf(ErrInvalidHeader, "header %d isn't produced by a delegate", blk.Height())
+		}
+		if err := d.checkpoints.Verify(blk.Height(), blk.HashBlock()); err != nil {
+			return err
+		}
+		d.headerTip = blk.Height()
+		d.headerTipHash = blk.HashBlock()
+		d.headers[d.headerTip] = d.headerTipHash
+	}
+	return nil
+}
+
+// producers returns the public keys of the delegates which are known from the handshakes with them
+func (d *downloader) producers() (map[string]bool, error) {
+	delegates, err := d.dp.AllDelegates()
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for _, dlg := range delegates {
+		key, err := d.dp.PublicKey(dlg)
+		if err != nil {
+			logger.Debug().Err(err).Str("delegate", dlg.String()).Msg("Key of the delegate is unknown")
+			continue
+		}
+		keys[string(key)] = true
+	}
+	return keys, nil
+}
+
+// schedule sends out the header and block requests as many as allowed
+func (d *downloader) schedule() {
+	if d.headerReq == nil && d.headerTip < d.target {
+		start := d.headerTip + 1
+		end := minUint64(d.target, start+d.headerBatchSize-1)
+		if peer := d.pickPeer(nil); peer != "" {
+			d.headerReq = d.send(peer, start, end, &pb.BlockHeaderSync{Start: start, End: end})
+		}
+	}
+	chunks := []*request{}
+	busy := map[string]bool{}
+	for _, chunk := range d.chunks {
+		if !d.done(chunk) {
+			chunks = append(chunks, chunk)
+			busy[chunk.peer] = true
+		}
+	}
+	d.chunks = chunks
+	for len(d.chunks) < d.downloadPeers && d.next <= d.headerTip && d.next <= d.tip+maxBufferedBlocks {
+		start := d.next
+		end := minUint64(minUint64(d.headerTip, d.tip+maxBufferedBlocks), start+d.chunkSize-1)
+		peer := d.pickPeer(busy)
+		if peer == "" {
+			return
+		}
+		busy[peer] = true
+		d.chunks = append(d.chunks, d.send(peer, start, end, &pb.BlockSync{Start: start, End: end}))
+		d.next = end + 1
+	}
+}
+
+// retry sends the request for the blocks of the chunk to another peer
+func (d *downloader) retry(chunk *request) {
+	peer := d.pickPeer(map[string]bool{chunk.peer: true})
+	if peer == "" {
+		return
+	}
+	*chunk = *d.send(peer, chunk.start, chunk.end, &pb.BlockSync{Start: chunk.start, End: chunk.end})
+}
+
+// chunkOf returns the outstanding chunk which the height belongs to
+func (d *downloader) chunkOf(height uint64) *request {
+	for _, chunk := range d.chunks {
+		if chunk.start <= height && height <= chunk.end {
+			return chunk
+		}
+	}
+	return nil
+}
+
+// done returns true if all the blocks of the chunk are received
+func (d *downloader) done(chunk *request) bool {
+	for height := chunk.start; height <= chunk.end; height++ {
+		if _, ok := d.headers[height]; ok && !d.received[height] {
+			return false
+		}
+	}
+	return true
+}
+
+// send sends the request to the peer, and returns it to be tracked
+func (d *downloader) send(peer string, start uint64, end uint64, msg proto.Message) *request {
+	if err := d.p2p.Tell(cm.NewTCPNode(peer), msg); err != nil {
+		logger.Error().Err(err).Str("peer", peer).Msg("Error when sending block sync request")
+	}
+	logger.Debug().
+		Str("peer", peer).
+		Uint64("start", start).
+		Uint64("end", end).
+		Msg("Send block sync request")
+	return &request{peer: peer, start: start, end: end, deadline: time.Now().Add(d.timeout)}
+}
+
+// pickPeer picks a random peer which is neither banned nor excluded. The excluded peers are picked if no other peer is
+// available, and the banned peers get another chance once all peers are banned.
+func (d *downloader) pickPeer(excluded map[string]bool) string {
+	peers := []string{}
+	for _, addr := range d.p2p.GetPeers() {
+		peers = append(peers, addr.String())
+	}
+	if len(peers) == 0 && d.fallback != "" {
+		peers = append(peers, d.fallback)
+	}
+	candidates := []string{}
+	for _, peer := range peers {
+		if !d.banned[peer] && !excluded[peer] {
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, peer := range peers {
+			if !d.banned[peer] {
+				candidates = append(candidates, peer)
+			}
+		}
+	}
+	if len(candidates) == 0 && len(d.banned) > 0 {
+		d.banned = map[string]bool{}
+		candidates = peers
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+func (d *downloader) ban(peer string) {
+	logger.Warn().Str("peer", peer).Msg("Stop syncing blocks from the peer")
+	d.banned[peer] = true
+}
+
+func (d *downloader) reset() {
+	d.target = 0
+	d.tip = 0
+	d.headers = map[uint64]cm.Hash32B{}
+	d.headerTip = 0
+	d.headerTipHash = cm.ZeroHash32B
+	d.headerReq = nil
+	d.chunks = nil
+	d.next = 0
+	d.received = map[uint64]bool{}
+	d.banned = map[string]bool{}
+}
+
+func minUint64(a uint64, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
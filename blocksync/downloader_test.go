@@ -0,0 +1,324 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_delegate"
+	ta "github.com/iotexproject/iotex-core/test/testaddress"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+// syncRecorder records the sync requests that a peer receives
+type syncRecorder struct {
+	mu   sync.Mutex
+	reqs []proto.Message
+}
+
+func (r *syncRecorder) Start() error { return nil }
+
+func (r *syncRecorder) Stop() error { return nil }
+
+func (r *syncRecorder) HandleBroadcast(proto.Message, chan bool) {}
+
+func (r *syncRecorder) HandleTell(_ net.Addr, msg proto.Message, _ chan bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reqs = append(r.reqs, msg)
+}
+
+func (r *syncRecorder) Requests() []proto.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]proto.Message{}, r.reqs...)
+}
+
+// startSyncPeers starts the node to sync and its peers on the in-memory network
+func startSyncPeers(t *testing.T, size int) (*network.Overlay, map[string]*syncRecorder, func()) {
+	n := network.NewMemNetwork(0)
+	start := func() *network.Overlay {
+		cfg := generateP2PConfig()
+		cfg.Addr = "127.0.0.1:0"
+		o := network.NewOverlay(cfg)
+		o.AttachTransport(n.NewTransport())
+		return o
+	}
+	p2p := start()
+	p2p.AttachDispatcher(&syncRecorder{})
+	p2p.PRC.Start()
+	nodes := []*network.Overlay{p2p}
+	recorders := map[string]*syncRecorder{}
+	for i := 0; i < size; i++ {
+		o := start()
+		r := &syncRecorder{}
+		o.AttachDispatcher(r)
+		o.PRC.Start()
+		nodes = append(nodes, o)
+		recorders[o.PRC.String()] = r
+		p2p.PM.AddPeer(o.PRC.String())
+	}
+	assert.Equal(t, size, len(p2p.GetPeers()))
+	return p2p, recorders, func() {
+		for _, o := range nodes {
+			o.PRC.Stop()
+		}
+	}
+}
+
+// generateChain creates the genesis block and the signed blocks on top of it
+func generateChain(t *testing.T, height uint64) []*bc.Block {
+	blks := []*bc.Block{bc.NewBlock(0, 0, common.ZeroHash32B, nil, nil)}
+	for i := uint64(1); i <= height; i++ {
+		blk := bc.NewBlock(0, i, blks[i-1].HashBlock(), nil, nil)
+		assert.Nil(t, blk.SignBlock(ta.Addrinfo["miner"]))
+		blks = append(blks, blk)
+	}
+	return blks
+}
+
+func headersOf(blks []*bc.Block) []*pb.BlockHeaderPb {
+	headers := []*pb.BlockHeaderPb{}
+	for _, blk := range blks {
+		headers = append(headers, blk.ConvertToBlockHeaderPb())
+	}
+	return headers
+}
+
+func newTestDownloader(t *testing.T, ctrl *gomock.Controller, p2p *network.Overlay, genesis *bc.Block, timeout time.Duration) *downloader {
+	chain := mock_blockchain.NewMockBlockchain(ctrl)
+	chain.EXPECT().TipHeight().Return(uint64(0), nil).AnyTimes()
+	chain.EXPECT().TipHash().Return(genesis.HashBlock(), nil).AnyTimes()
+	cfg := &config.BlockSync{
+		HeaderBatchSize: 32,
+		ChunkSize:       8,
+		DownloadPeers:   3,
+		RequestTimeout:  timeout,
+	}
+	// the miner is the only delegate whose key is known
+	miner := common.NewTCPNode("127.0.0.1:10000")
+	stranger := common.NewTCPNode("127.0.0.1:10001")
+	pool := mock_delegate.NewMockPool(ctrl)
+	pool.EXPECT().AllDelegates().Return([]net.Addr{miner, stranger}, nil).AnyTimes()
+	pool.EXPECT().PublicKey(miner).Return(ta.Addrinfo["miner"].PublicKey, nil).AnyTimes()
+	pool.EXPECT().PublicKey(stranger).Return(nil, errors.New("unknown key")).AnyTimes()
+	return newDownloader(cfg, chain, p2p, pool, "", bc.Checkpoints{})
+}
+
+func TestDownloader_ParallelSync(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p2p, recorders, stop := startSyncPeers(t, 3)
+	defer stop()
+	blks := generateChain(t, 40)
+	d := newTestDownloader(t, ctrl, p2p, blks[0], time.Hour)
+
+	assert.Nil(d.Sync(40))
+	assert.True(d.Active())
+	assert.NotNil(d.headerReq)
+	assert.Equal(uint64(1), d.headerReq.start)
+	assert.Equal(uint64(32), d.headerReq.end)
+	headerPeer := d.headerReq.peer
+	assert.Nil(util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return len(recorders[headerPeer].Requests()) == 1, nil
+	}))
+	assert.True(proto.Equal(&pb.BlockHeaderSync{Start: 1, End: 32}, recorders[headerPeer].Requests()[0]))
+
+	// The headers not asked for are ignored
+	for peer := range recorders {
+		if peer != headerPeer {
+			assert.Nil(d.OnHeaders(peer, headersOf(blks[1:33])))
+		}
+	}
+	assert.Empty(d.chunks)
+
+	// The blocks are downloaded in chunks from all the peers, while the rest of the headers are fetched
+	assert.Nil(d.OnHeaders(headerPeer, headersOf(blks[1:33])))
+	assert.Equal(uint64(32), d.headerTip)
+	assert.NotNil(d.headerReq)
+	assert.Equal(uint64(33), d.headerReq.start)
+	assert.Equal(uint64(40), d.headerReq.end)
+	assert.Equal(3, len(d.chunks))
+	peers := map[string]bool{}
+	for i, chunk := range d.chunks {
+		assert.Equal(uint64(i*8+1), chunk.start)
+		assert.Equal(uint64(i*8+8), chunk.end)
+		peers[chunk.peer] = true
+	}
+	assert.Equal(3, len(peers))
+	assert.Nil(util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		count := 0
+		for _, r := range recorders {
+			for _, req := range r.Requests() {
+				if _, ok := req.(*pb.BlockSync); ok {
+					count++
+				}
+			}
+		}
+		return count == 3, nil
+	}))
+
+	// The block which doesn't match the header is rejected, and the chunk is retried on another peer
+	liar := d.chunks[0].peer
+	forged := bc.NewBlock(0, 1, blks[0].HashBlock(), nil, nil)
+	forged.Header.Pubkey = []byte{1}
+	err := d.Accept(forged)
+	assert.Equal(ErrUnexpectedBlock, errors.Cause(err))
+	assert.True(d.banned[liar])
+	assert.NotEqual(liar, d.chunks[0].peer)
+	assert.Equal(uint64(1), d.chunks[0].start)
+
+	// The block beyond the requested chunks is rejected, even if it matches the header
+	err = d.Accept(blks[25])
+	assert.Equal(ErrUnexpectedBlock, errors.Cause(err))
+	assert.False(d.received[25])
+
+	// Once the chunk is committed, the next one is scheduled
+	for i := 1; i <= 8; i++ {
+		assert.Nil(d.Accept(blks[i]))
+		d.Committed(uint64(i))
+	}
+	assert.Equal(3, len(d.chunks))
+	assert.Equal(uint64(25), d.chunks[2].start)
+	assert.Equal(uint64(32), d.chunks[2].end)
+
+	// The sync finishes at the target
+	assert.Nil(d.OnHeaders(d.headerReq.peer, headersOf(blks[33:])))
+	assert.Nil(d.headerReq)
+	for i := 9; i <= 40; i++ {
+		assert.Nil(d.Accept(blks[i]))
+		d.Committed(uint64(i))
+	}
+	assert.False(d.Active())
+}
+
+func TestDownloader_InvalidHeaders(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p2p, _, stop := startSyncPeers(t, 3)
+	defer stop()
+	blks := generateChain(t, 8)
+	d := newTestDownloader(t, ctrl, p2p, blks[0], time.Hour)
+	assert.Nil(d.Sync(8))
+
+	// The headers not linking to the validated ones
+	peer := d.headerReq.peer
+	err := d.OnHeaders(peer, headersOf(append([]*bc.Block{blks[1]}, blks[3:]...)))
+	assert.Equal(ErrInvalidHeader, errors.Cause(err))
+	assert.True(d.banned[peer])
+	assert.NotEqual(peer, d.headerReq.peer)
+	assert.Equal(uint64(2), d.headerReq.start)
+	assert.Equal(uint64(1), d.headerTip)
+
+	// The header with an invalid signature
+	peer = d.headerReq.peer
+	unsigned := bc.NewBlock(0, 2, blks[1].HashBlock(), nil, nil)
+	err = d.OnHeaders(peer, headersOf([]*bc.Block{unsigned}))
+	assert.Equal(ErrInvalidHeader, errors.Cause(err))
+	assert.True(d.banned[peer])
+
+	// The header produced by someone other than the delegates
+	peer = d.headerReq.peer
+	outsider := bc.NewBlock(0, 2, blks[1].HashBlock(), nil, nil)
+	assert.Nil(outsider.SignBlock(ta.Addrinfo["alfa"]))
+	err = d.OnHeaders(peer, headersOf([]*bc.Block{outsider}))
+	assert.Equal(ErrInvalidHeader, errors.Cause(err))
+	assert.Equal(uint64(1), d.headerTip)
+
+	// No header at all
+	peer = d.headerReq.peer
+	err = d.OnHeaders(peer, nil)
+	assert.Equal(ErrInvalidHeader, errors.Cause(err))
+
+	// All peers are banned, so that they get another chance
+	assert.NotNil(d.headerReq)
+	assert.Nil(d.OnHeaders(d.headerReq.peer, headersOf(blks[2:])))
+	assert.Equal(uint64(8), d.headerTip)
+}
+
+func TestDownloader_Timeout(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p2p, _, stop := startSyncPeers(t, 2)
+	defer stop()
+	blks := generateChain(t, 8)
+	d := newTestDownloader(t, ctrl, p2p, blks[0], 50*time.Millisecond)
+	assert.Nil(d.Sync(8))
+	assert.Nil(d.OnHeaders(d.headerReq.peer, headersOf(blks[1:])))
+	assert.Equal(1, len(d.chunks))
+	slow := d.chunks[0].peer
+
+	// The request is not retried before the deadline
+	d.Do()
+	assert.Equal(slow, d.chunks[0].peer)
+
+	time.Sleep(100 * time.Millisecond)
+	d.Do()
+	assert.True(d.banned[slow])
+	assert.NotEqual(slow, d.chunks[0].peer)
+	assert.Equal(uint64(1), d.chunks[0].start)
+	assert.Equal(uint64(8), d.chunks[0].end)
+}
+
+func TestDownloader_Checkpoints(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p2p, _, stop := startSyncPeers(t, 3)
+	defer stop()
+	blks := generateChain(t, 8)
+	d := newTestDownloader(t, ctrl, p2p, blks[0], time.Hour)
+	d.checkpoints = bc.Checkpoints{4: blks[4].HashBlock()}
+	assert.Nil(d.Sync(8))
+
+	// The branch forking before the checkpoint is rejected, and the peer serving it is banned for good
+	fork := []*bc.Block{blks[1], blks[2], blks[3]}
+	for i := uint64(4); i <= 8; i++ {
+		blk := bc.NewBlock(1, i, fork[i-2].HashBlock(), nil, nil)
+		assert.Nil(blk.SignBlock(ta.Addrinfo["miner"]))
+		fork = append(fork, blk)
+	}
+	liar := d.headerReq.peer
+	err := d.OnHeaders(liar, headersOf(fork))
+	assert.Equal(bc.ErrCheckpointMismatch, errors.Cause(err))
+	assert.True(p2p.PM.IsBanned(liar))
+	assert.Equal(2, len(p2p.GetPeers()))
+	assert.Equal(uint64(3), d.headerTip)
+
+	// So is the peer serving the block conflicting with the checkpoint
+	assert.Nil(d.OnHeaders(d.headerReq.peer, headersOf(blks[4:])))
+	assert.Equal(2, len(d.chunks))
+	chunk := d.chunkOf(4)
+	liar = chunk.peer
+	err = d.Accept(fork[3])
+	assert.Equal(bc.ErrCheckpointMismatch, errors.Cause(err))
+	assert.True(p2p.PM.IsBanned(liar))
+	assert.Equal(1, len(p2p.GetPeers()))
+	assert.NotEqual(liar, chunk.peer)
+	assert.Equal(uint64(4), chunk.start)
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/network"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+const defaultSnapshotChunkSize = 64 * 1024
+
+var (
+	// ErrSnapshotNotAvailable means the state snapshot at the requested height is no longer served
+	ErrSnapshotNotAvailable = errors.New("state snapshot not available")
+	// ErrInvalidSnapshotChunk means the chunk doesn't belong to the state snapshot being downloaded
+	ErrInvalidSnapshotChunk = errors.New("invalid state snapshot chunk")
+)
+
+// servedSnapshot is a state snapshot serialized and cut into chunks
+type servedSnapshot struct {
+	height uint64
+	root   cm.Hash32B
+	chunks [][]byte
+}
+
+// snapshotServer serves the chunks of the state snapshot at the tip. The snapshot is exported again only when a peer
+// asks for the latest one after the tip moves, and the previous one is kept so that the ongoing downloads can finish.
+type snapshotServer struct {
+	mu        sync.Mutex
+	bc        bc.Blockchain
+	chunkSize uint64
+	latest    *servedSnapshot
+	previous  *servedSnapshot
+}
+
+// newSnapshotServer creates an instance of snapshotServer
+func newSnapshotServer(cfg *config.BlockSync, chain bc.Blockchain) *snapshotServer {
+	s := &snapshotServer{bc: chain, chunkSize: cfg.SnapshotChunkSize}
+	if s.chunkSize == 0 {
+		s.chunkSize = defaultSnapshotChunkSize
+	}
+	return s
+}
+
+// Chunk returns the chunk of the state snapshot at the height, where height 0 means the latest snapshot
+func (s *snapshotServer) Chunk(height uint64, chunk uint32) (*pb.StateSnapshotChunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if height == 0 {
+		if err := s.refresh(); err != nil {
+			return nil, err
+		}
+		height = s.latest.height
+	}
+	var ss *servedSnapshot
+	for _, served := range []*servedSnapshot{s.latest, s.previous} {
+		if served != nil && served.height == height {
+			ss = served
+		}
+	}
+	if ss == nil {
+		return nil, errors.Wrapf(ErrSnapshotNotAvailable, "height %d", height)
+	}
+	if int(chunk) >= len(ss.chunks) {
+		return nil, errors.Wrapf(ErrSnapshotNotAvailable, "chunk %d out of %d at height %d", chunk, len(ss.chunks), height)
+	}
+	return &pb.StateSnapshotChunk{
+		Height: ss.height,
+		Root:   ss.root[:],
+		Chunk:  chunk,
+		Total:  uint32(len(ss.chunks)),
+		Data:   ss.chunks[chunk],
+	}, nil
+}
+
+// refresh exports the state snapshot again if the tip has moved since the latest one
+func (s *snapshotServer) refresh() error {
+	height, err := s.bc.TipHeight()
+	if err != nil {
+		return err
+	}
+	if s.latest != nil && s.latest.height == height {
+		return nil
+	}
+	ss, err := s.bc.StateSnapshot()
+	if err != nil {
+		return err
+	}
+	if ss.Height == 0 {
+		return errors.Wrap(ErrSnapshotNotAvailable, "no block on top of the genesis block")
+	}
+	data, err := ss.Serialize()
+	if err != nil {
+		return err
+	}
+	served := &servedSnapshot{height: ss.Height, root: ss.Root}
+	for len(data) > 0 {
+		size := minUint64(s.chunkSize, uint64(len(data)))
+		served.chunks = append(served.chunks, data[:size])
+		data = data[size:]
+	}
+	s.previous = s.latest
+	s.latest = served
+	logger.Info().
+		Uint64("height", served.height).
+		Int("chunks", len(served.chunks)).
+		Msg("Export state snapshot")
+	return nil
+}
+
+// snapshotDownloader fetches the state snapshot of a peer chunk by chunk, so that a new node can start from it instead
+// of replaying all the blocks. Once all the chunks are received, it asks the peer for the block on top of the snapshot,
+// whose state root vouches for the snapshot. The download starts over on another peer if the peer times out or serves
+// an invalid snapshot.
+type snapshotDownloader struct {
+	mu       sync.Mutex
+	p2p      *network.Overlay
+	fallback string
+	timeout  time.Duration
+
+	active   bool
+	peer     string
+	height   uint64
+	root     cm.Hash32B
+	total    uint32
+	chunks   [][]byte
+	snapshot *state.Snapshot
+	deadline time.Time
+	banned   map[string]bool
+}
+
+// newSnapshotDownloader creates an instance of snapshotDownloader. The fallback is the node to ask if no peer is
+// connected.
+func newSnapshotDownloader(cfg *config.BlockSync, p2p *network.Overlay, fallback string) *snapshotDownloader {
+	d := &snapshotDownloader{
+		p2p:      p2p,
+		fallback: fallback,
+		timeout:  cfg.RequestTimeout,
+		banned:   map[string]bool{},
+	}
+	if d.timeout == 0 {
+		d.timeout = defaultRequestTimeout
+	}
+	return d
+}
+
+// Active returns true if a snapshot download is in progress
+func (d *snapshotDownloader) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active
+}
+
+// Snapshot returns the downloaded snapshot which awaits the block on top of it, or nil if it is not complete yet
+func (d *snapshotDownloader) Snapshot() *state.Snapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.snapshot
+}
+
+// Start starts downloading the latest state snapshot of a peer
+func (d *snapshotDownloader) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active {
+		return
+	}
+	d.active = true
+	logger.Info().Msg("Start state snapshot sync")
+	d.restart()
+}
+
+// Finish ends the download after the snapshot is imported
+func (d *snapshotDownloader) Finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	logger.Info().Uint64("height", d.height).Msg("Finish state snapshot sync")
+	d.active = false
+	d.banned = map[string]bool{}
+	d.reset("")
+}
+
+// Fail drops the snapshot which turns out to be invalid, and starts over on another peer
+func (d *snapshotDownloader) Fail() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active {
+		return
+	}
+	d.ban(d.peer)
+	d.restart()
+}
+
+// OnChunk appends the chunk that the peer answers with, and then asks for the next one. After the last chunk, the
+// snapshot is assembled and the block on top of it is requested.
+func (d *snapshotDownloader) OnChunk(sender string, chunk *pb.StateSnapshotChunk) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active || d.snapshot != nil || d.peer != sender || chunk.Chunk != uint32(len(d.chunks)) {
+		// Ignore the unsolicited or outdated chunk
+		return nil
+	}
+	if err := d.appendChunk(chunk); err != nil {
+		d.ban(sender)
+		d.restart()
+		return err
+	}
+	if uint32(len(d.chunks)) < d.total {
+		d.send(&pb.StateSnapshotSync{Height: d.height, Chunk: uint32(len(d.chunks))})
+		return nil
+	}
+
+	ss := &state.Snapshot{}
+	if err := ss.Deserialize(bytes.Join(d.chunks, nil)); err != nil {
+		d.ban(sender)
+		d.restart()
+		return err
+	}
+	if ss.Height != d.height || ss.Root != d.root {
+		d.ban(sender)
+		d.restart()
+		return errors.Wrapf(ErrInvalidSnapshotChunk, "snapshot at height %d doesn't match the chunks", ss.Height)
+	}
+	d.snapshot = ss
+	d.send(&pb.BlockSync{Start: d.height + 1, End: d.height + 1})
+	return nil
+}
+
+// Do starts over on another peer if the peer doesn't answer in time
+func (d *snapshotDownloader) Do() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active || time.Now().Before(d.deadline) {
+		return
+	}
+	if d.peer != "" {
+		logger.Warn().
+			Str("peer", d.peer).
+			Int("chunk", len(d.chunks)).
+			Msg("State snapshot request timed out")
+		d.ban(d.peer)
+	}
+	d.restart()
+}
+
+// appendChunk checks the chunk against the ones received before, and appends it
+func (d *snapshotDownloader) appendChunk(chunk *pb.StateSnapshotChunk) error {
+	if len(chunk.Root) != len(d.root) {
+		return errors.Wrapf(ErrInvalidSnapshotChunk, "root of %d bytes", len(chunk.Root))
+	}
+	var root cm.Hash32B
+	copy(root[:], chunk.Root)
+	if len(d.chunks) == 0 {
+		if chunk.Height == 0 || chunk.Total == 0 {
+			return errors.Wrapf(ErrInvalidSnapshotChunk, "height %d with %d chunks", chunk.Height, chunk.Total)
+		}
+		d.height = chunk.Height
+		d.root = root
+		d.total = chunk.Total
+	}
+	if chunk.Height != d.height || root != d.root || chunk.Total != d.total {
+		return errors.Wrapf(ErrInvalidSnapshotChunk, "chunk %d doesn't belong to snapshot at height %d", chunk.Chunk, d.height)
+	}
+	d.chunks = append(d.chunks, chunk.Data)
+	return nil
+}
+
+// restart drops what is downloaded so far, and asks another peer for its latest snapshot
+func (d *snapshotDownloader) restart() {
+	d.reset(d.pickPeer())
+	if d.peer == "" {
+		return
+	}
+	d.send(&pb.StateSnapshotSync{})
+}
+
+func (d *snapshotDownloader) reset(peer string) {
+	d.peer = peer
+	d.height = 0
+	d.root = cm.ZeroHash32B
+	d.total = 0
+	d.chunks = nil
+	d.snapshot = nil
+	d.deadline = time.Now().Add(d.timeout)
+}
+
+// send sends the request to the peer, and extends the deadline for its answer
+func (d *snapshotDownloader) send(msg proto.Message) {
+	if err := d.p2p.Tell(cm.NewTCPNode(d.peer), msg); err != nil {
+		logger.Error().Err(err).Str("peer", d.peer).Msg("Error when sending state snapshot sync request")
+	}
+	d.deadline = time.Now().Add(d.timeout)
+}
+
+// pickPeer picks a random peer which is not banned. The banned peers get another chance once all peers are banned.
+func (d *snapshotDownloader) pickPeer() string {
+	peers := []string{}
+	for _, addr := range d.p2p.GetPeers() {
+		peers = append(peers, addr.String())
+	}
+	if len(peers) == 0 && d.fallback != "" {
+		peers = append(peers, d.fallback)
+	}
+	candidates := []string{}
+	for _, peer := range peers {
+		if !d.banned[peer] {
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		d.banned = map[string]bool{}
+		candidates = peers
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+func (d *snapshotDownloader) ban(peer string) {
+	logger.Warn().Str("peer", peer).Msg("Stop syncing state snapshot from the peer")
+	d.banned[peer] = true
+}
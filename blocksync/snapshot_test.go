@@ -0,0 +1,206 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package blocksync
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/actpool"
+	bc "github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_delegate"
+	"github.com/iotexproject/iotex-core/test/util"
+	"github.com/iotexproject/iotex-core/trie"
+)
+
+// generateSnapshot creates the state snapshot holding the given number of accounts at the height
+func generateSnapshot(t *testing.T, height uint64, accounts int) *state.Snapshot {
+	tr, err := trie.NewTrie("", true)
+	assert.Nil(t, err)
+	sf, err := state.NewFactory(tr)
+	assert.Nil(t, err)
+	for i := 0; i < accounts; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		assert.Nil(t, err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(i+1))
+		assert.Nil(t, err)
+	}
+	ss, err := sf.Snapshot()
+	assert.Nil(t, err)
+	ss.Height = height
+	return ss
+}
+
+// lastRequest waits for the peer to receive the given number of requests, and returns the last one
+func lastRequest(t *testing.T, recorder *syncRecorder, count int) proto.Message {
+	assert.Nil(t, util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return len(recorder.Requests()) == count, nil
+	}))
+	reqs := recorder.Requests()
+	return reqs[len(reqs)-1]
+}
+
+func TestSnapshotSync(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ss := generateSnapshot(t, 5, 50)
+	chain := mock_blockchain.NewMockBlockchain(ctrl)
+	chain.EXPECT().TipHeight().Return(uint64(5), nil).AnyTimes()
+	// the snapshot is exported once for all the requests at the same tip
+	chain.EXPECT().StateSnapshot().Return(ss, nil).Times(1)
+	server := newSnapshotServer(&config.BlockSync{SnapshotChunkSize: 512}, chain)
+
+	p2p, recorders, stop := startSyncPeers(t, 2)
+	defer stop()
+	d := newSnapshotDownloader(&config.BlockSync{RequestTimeout: time.Hour}, p2p, "")
+	assert.False(d.Active())
+	d.Start()
+	assert.True(d.Active())
+	peer := d.peer
+	var other string
+	for addr := range recorders {
+		if addr != peer {
+			other = addr
+		}
+	}
+
+	count := 1
+	for d.Snapshot() == nil {
+		req, ok := lastRequest(t, recorders[peer], count).(*pb.StateSnapshotSync)
+		assert.True(ok)
+		if count == 1 {
+			assert.True(proto.Equal(&pb.StateSnapshotSync{}, req))
+		} else {
+			assert.Equal(uint64(5), req.Height)
+		}
+		chunk, err := server.Chunk(req.Height, req.Chunk)
+		assert.Nil(err)
+		assert.True(chunk.Total > 1)
+		// The chunks not asked for are ignored
+		assert.Nil(d.OnChunk(other, chunk))
+		assert.Nil(d.OnChunk(peer, chunk))
+		count++
+	}
+	assert.True(proto.Equal(&pb.BlockSync{Start: 6, End: 6}, lastRequest(t, recorders[peer], count)))
+	assert.Equal(0, len(recorders[other].Requests()))
+	assert.Equal(ss.Height, d.Snapshot().Height)
+	assert.Equal(ss.Root, d.Snapshot().Root)
+	assert.Equal(ss.Keys, d.Snapshot().Keys)
+	assert.Equal(ss.Values, d.Snapshot().Values)
+
+	// the snapshot at another height is not served
+	_, err := server.Chunk(4, 0)
+	assert.Equal(ErrSnapshotNotAvailable, errors.Cause(err))
+
+	// the snapshot failing to import is downloaded again from the other peer
+	d.Fail()
+	assert.True(d.Active())
+	assert.Nil(d.Snapshot())
+	assert.Equal(other, d.peer)
+	d.Finish()
+	assert.False(d.Active())
+}
+
+func TestSnapshotSync_InvalidChunk(t *testing.T) {
+	assert := assert.New(t)
+
+	p2p, recorders, stop := startSyncPeers(t, 2)
+	defer stop()
+	d := newSnapshotDownloader(&config.BlockSync{RequestTimeout: time.Hour}, p2p, "")
+	d.Start()
+	peer := d.peer
+	lastRequest(t, recorders[peer], 1)
+	err := d.OnChunk(peer, &pb.StateSnapshotChunk{Height: 5, Root: common.ZeroHash32B[:], Chunk: 0, Total: 0})
+	assert.Equal(ErrInvalidSnapshotChunk, errors.Cause(err))
+	assert.True(d.banned[peer])
+	assert.NotEqual(peer, d.peer)
+	peer = d.peer
+	assert.True(proto.Equal(&pb.StateSnapshotSync{}, lastRequest(t, recorders[peer], 1)))
+
+	// the chunks which don't assemble into the snapshot are dropped
+	err = d.OnChunk(peer, &pb.StateSnapshotChunk{Height: 5, Root: common.ZeroHash32B[:], Chunk: 0, Total: 1, Data: []byte{1}})
+	assert.NotNil(err)
+	assert.Nil(d.Snapshot())
+	assert.True(d.Active())
+}
+
+func TestSnapshotSync_Timeout(t *testing.T) {
+	assert := assert.New(t)
+
+	p2p, recorders, stop := startSyncPeers(t, 2)
+	defer stop()
+	d := newSnapshotDownloader(&config.BlockSync{RequestTimeout: 10 * time.Millisecond}, p2p, "")
+	d.Start()
+	peer := d.peer
+	lastRequest(t, recorders[peer], 1)
+	time.Sleep(20 * time.Millisecond)
+	d.Do()
+	assert.True(d.banned[peer])
+	assert.NotEqual(peer, d.peer)
+	assert.True(proto.Equal(&pb.StateSnapshotSync{}, lastRequest(t, recorders[d.peer], 1)))
+}
+
+func TestBlockSyncer_ImportSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mPool := mock_delegate.NewMockPool(ctrl)
+	mPool.EXPECT().AllDelegates().Times(1).Return([]net.Addr{common.NewNode("", "123")}, nil)
+	mPool.EXPECT().AnotherDelegate(gomock.Any()).Times(1).Return(common.NewNode("", "123"))
+
+	ss := generateSnapshot(t, 5, 10)
+	blk := bc.NewBlock(uint32(123), uint64(6), common.Hash32B{}, nil, nil)
+	mBc := mock_blockchain.NewMockBlockchain(ctrl)
+	gomock.InOrder(
+		mBc.EXPECT().TipHeight().Times(1).Return(uint64(0), nil),
+		mBc.EXPECT().ImportSnapshot(ss, blk).Times(1).Return(state.ErrSnapshotMismatch),
+		mBc.EXPECT().TipHeight().Times(1).Return(uint64(0), nil),
+		mBc.EXPECT().ImportSnapshot(ss, blk).Times(1).Return(nil),
+		mBc.EXPECT().TipHeight().Times(1).Return(uint64(5), nil),
+		mBc.EXPECT().CommitBlock(blk).Times(1).Return(nil),
+		mBc.EXPECT().TipHeight().AnyTimes().Return(uint64(6), nil),
+	)
+
+	tr, _ := trie.NewTrie("", true)
+	sf, _ := state.NewFactory(tr)
+	ap := actpool.NewActPool(sf)
+	cfg := &config.Config{
+		NodeType:  config.FullNodeType,
+		BlockSync: config.BlockSync{SnapshotSync: true},
+	}
+	bs, err := NewBlockSyncer(cfg, mBc, ap, generateP2P(), mPool)
+	assert.Nil(err)
+	syncer := bs.(*blockSyncer)
+	syncer.snap.active = true
+	syncer.snap.snapshot = ss
+
+	// the snapshot not vouched by the block is dropped
+	assert.Equal(state.ErrSnapshotMismatch, errors.Cause(bs.ProcessBlockSync(blk)))
+	assert.Nil(syncer.snap.Snapshot())
+
+	// the block sync goes on from the imported snapshot
+	syncer.snap.snapshot = ss
+	assert.Nil(bs.ProcessBlockSync(blk))
+	assert.False(syncer.snap.Active())
+	assert.Equal(0, len(syncer.rcvdBlocks))
+	assert.False(syncer.dl.Active())
+}
@@ -17,7 +17,7 @@ func Test_All(t *testing.T) {
 	cfg, err := config.LoadConfigWithPath(configFile)
 	require.Nil(t, err)
 	httpPort := cfg.Explorer.Addr
-	explorer.StartJSONServer(nil, nil, true, httpPort, 0)
+	explorer.StartJSONServer(nil, nil, nil, true, httpPort, 0)
 
 	s := strings.Split(self(), " ")
 	addr := s[len(s)-1]
@@ -32,4 +32,8 @@ func Test_All(t *testing.T) {
 	det := details([]string{addr})
 	assert.Equal(t, 1, strings.Count(det, "\n"))
 	assert.NotEqual(t, "", balance([]string{addr})) // no real way to test this because balance returned is random
+
+	sync := syncStatus()
+	assert.Equal(t, 3, strings.Count(sync, "\n"))
+	assert.True(t, strings.HasPrefix(sync, "state: Active, syncing: true"))
 }
@@ -0,0 +1,45 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Returns the progress of block sync",
+	Long:  `Returns the progress of block sync, namely the heights, the speed, the ETA and the peers being synced from.`,
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(syncStatus())
+	},
+}
+
+func syncStatus() string {
+	client, _ := getClientAndCfg()
+	status, err := client.GetSyncStatus()
+	if err != nil {
+		logger.Error().Err(err).Msg("cannot get sync status")
+		return ""
+	}
+	return fmt.Sprintf("state: %s, syncing: %t\n", status.State, status.Syncing) +
+		fmt.Sprintf("height: %d/%d\n", status.LocalHeight, status.BestHeight) +
+		fmt.Sprintf("speed: %.2f blocks/s, ETA: %s\n", status.BlocksPerSecond, time.Duration(status.Eta)*time.Second) +
+		fmt.Sprintf("peers: %s", strings.Join(status.ActivePeers, ", "))
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
@@ -61,12 +61,62 @@ type Network struct {
 	PeerDiscovery           bool                        `yaml:"peerDiscovery"`
 	TopologyPath            string                      `yaml:"topologyPath"`
 	TTL                     uint32                      `yaml:"ttl"`
+	// PeerDiscoveryScheme decides how to find new peers when peer discovery is enabled
+	PeerDiscoveryScheme string `yaml:"peerDiscoveryScheme"`
+	// KadBucketSize is the max number of nodes in a k-bucket of the Kademlia routing table
+	KadBucketSize uint `yaml:"kadBucketSize"`
+	// KadAlpha is the number of concurrent FIND_NODE requests in a Kademlia lookup
+	KadAlpha uint `yaml:"kadAlpha"`
+	// ChainID identifies the chain that the node is on. Peers on a different chain are rejected in the handshake
+	ChainID uint32 `yaml:"chainID"`
+	// Topics are the gossip topics that the node subscribes to. Empty means subscribing to all topics
+	Topics []string `yaml:"topics"`
+	// GossipFanout is the max number of peers to relay a broadcast message to. 0 means relaying to all peers
+	GossipFanout uint `yaml:"gossipFanout"`
+	// BloomFilterSize is the number of bits of the bloom filters which dedup the broadcast messages
+	BloomFilterSize uint `yaml:"bloomFilterSize"`
+	// BloomFilterHashes is the number of hash functions of the bloom filters which dedup the broadcast messages
+	BloomFilterHashes uint `yaml:"bloomFilterHashes"`
+	// EnableCompression decides whether to negotiate the message compression with peers in the handshake
+	EnableCompression bool `yaml:"enableCompression"`
+	// CompressionThreshold is the size in bytes, above which the message bodies are compressed
+	CompressionThreshold int `yaml:"compressionThreshold"`
+	// PeerStorePath is the path of the DB file which persists the known peers across restarts. Empty means keeping
+	// them in memory only
+	PeerStorePath string `yaml:"peerStorePath"`
+	// IdentityKeyPath is the path of the file which keeps the identity key pair of the node across restarts, unless a
+	// long-lived one is attached. The file is only readable by its owner. Empty means using a new identity every start
+	IdentityKeyPath string `yaml:"identityKeyPath"`
+	// PeerStoreTTL is how long a known peer is kept since it was last seen. 0 means never aging out
+	PeerStoreTTL time.Duration `yaml:"peerStoreTTL"`
+	// PeerStoreSize is the max number of known peers to keep. 0 means no limit
+	PeerStoreSize uint `yaml:"peerStoreSize"`
 }
 
+const (
+	// RandomPeerDiscovery means that the node asks a random peer for its neighbors when it lacks of peers
+	RandomPeerDiscovery = "RANDOM"
+	// KademliaPeerDiscovery means that the node finds peers by the iterative lookups on a Kademlia DHT
+	KademliaPeerDiscovery = "KADEMLIA"
+)
+
+const (
+	// ActionsTopic is the gossip topic of the actions (transfers, votes and etc.)
+	ActionsTopic = "actions"
+	// BlocksTopic is the gossip topic of the blocks
+	BlocksTopic = "blocks"
+	// ConsensusTopic is the gossip topic of the consensus messages
+	ConsensusTopic = "consensus"
+)
+
 // Chain is the config struct for blockchain package
 type Chain struct {
 	ChainDBPath string `yaml:"chainDBPath"`
 	TrieDBPath  string `yaml:"trieDBPath"`
+	// TrieRetainedRoots is the number of the recent trie roots whose nodes are kept, and TrieGCInterval is the number
+	// of the roots falling out of them which kick off a collection of the stale nodes. 0 leaves the default
+	TrieRetainedRoots uint64 `yaml:"trieRetainedRoots"`
+	TrieGCInterval    uint64 `yaml:"trieGCInterval"`
 
 	ProducerPubKey  string `yaml:"producerPubKey"`
 	ProducerPrivKey string `yaml:"producerPrivKey"`
@@ -76,6 +126,37 @@ type Chain struct {
 	// InMemTest creates in-memory DB file for local testing
 	InMemTest          bool   `yaml:"inMemTest"`
 	GenesisActionsPath string `yaml:"genesisActionsPath"`
+
+	// Checkpoints are the trusted blocks that the synced chain must go through
+	Checkpoints []Checkpoint `yaml:"checkpoints"`
+	// TrustedProducers are the hex encoded public keys of the block producers trusted in addition to the initial
+	// delegates of the genesis, such as to vouch for the state snapshot which a new node starts from
+	TrustedProducers []string `yaml:"trustedProducers"`
+
+	// BlockStore decides where to keep the blocks, either in the chain DB or in the segmented block files. The
+	// in-memory chain for testing always keeps them in the in-memory DB
+	BlockStore string `yaml:"blockStore"`
+	// BlockFilePath is the directory of the block files
+	BlockFilePath string `yaml:"blockFilePath"`
+	// BlocksPerFile is the number of consecutive heights whose blocks are grouped into one block file
+	BlocksPerFile uint64 `yaml:"blocksPerFile"`
+	// PruneRetention is the number of latest blocks whose bodies and address indices are kept. The headers of all the
+	// blocks are kept anyway. 0 keeps everything as an archive node
+	PruneRetention uint64 `yaml:"pruneRetention"`
+}
+
+const (
+	// BoltBlockStore means keeping the blocks in the chain DB
+	BoltBlockStore = "BOLT"
+	// FileBlockStore means keeping the blocks in the append-only block files, which are located through the block
+	// indices in the chain DB
+	FileBlockStore = "FILE"
+)
+
+// Checkpoint is the config struct for a trusted block, whose hash is hex encoded
+type Checkpoint struct {
+	Height uint64 `yaml:"height"`
+	Hash   string `yaml:"hash"`
 }
 
 const (
@@ -98,6 +179,18 @@ type Consensus struct {
 // BlockSync is the config struct for the BlockSync
 type BlockSync struct {
 	Interval time.Duration `yaml:"interval"` // update duration
+	// HeaderBatchSize is the max number of headers to fetch from a peer in one request
+	HeaderBatchSize uint64 `yaml:"headerBatchSize"`
+	// ChunkSize is the max number of blocks to download from a peer in one request
+	ChunkSize uint64 `yaml:"chunkSize"`
+	// DownloadPeers is the max number of peers to download the blocks from in parallel
+	DownloadPeers uint `yaml:"downloadPeers"`
+	// RequestTimeout is how long to wait for a peer to answer a request before retrying it on another peer
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+	// SnapshotSync makes a new node start from the state snapshot of a peer instead of replaying all the blocks
+	SnapshotSync bool `yaml:"snapshotSync"`
+	// SnapshotChunkSize is the max number of bytes of the state snapshot to send to a peer in one message
+	SnapshotChunkSize uint64 `yaml:"snapshotChunkSize"`
 }
 
 // RollDPoS is the config struct for RollDPoS consensus package
@@ -233,6 +326,28 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("producer has unmatched pubkey and prikey")
 	}
 
+	for _, checkpoint := range cfg.Chain.Checkpoints {
+		if hash, err := hex.DecodeString(checkpoint.Hash); err != nil || len(hash) != 32 {
+			return fmt.Errorf("invalid checkpoint hash at height %d", checkpoint.Height)
+		}
+	}
+	for _, pubKey := range cfg.Chain.TrustedProducers {
+		if _, err := hex.DecodeString(pubKey); err != nil {
+			return fmt.Errorf("invalid trusted producer public key %s", pubKey)
+		}
+	}
+
+	switch cfg.Chain.BlockStore {
+	case "", BoltBlockStore:
+		break
+	case FileBlockStore:
+		if cfg.Chain.BlockFilePath == "" || cfg.Chain.BlocksPerFile == 0 {
+			return fmt.Errorf("block file path and blocks per file should be given for the file block store")
+		}
+	default:
+		return fmt.Errorf("unknown block store %s", cfg.Chain.BlockStore)
+	}
+
 	// Validate node type
 	switch cfg.NodeType {
 	case DelegateType:
@@ -255,6 +370,26 @@ func validateConfig(cfg *Config) error {
 	if !cfg.Network.PeerDiscovery && cfg.Network.TopologyPath == "" {
 		return fmt.Errorf("either peer discover should be enabled or a topology should be given")
 	}
+	if cfg.Network.PeerDiscovery {
+		switch cfg.Network.PeerDiscoveryScheme {
+		case "", RandomPeerDiscovery:
+			break
+		case KademliaPeerDiscovery:
+			if cfg.Network.KadBucketSize == 0 || cfg.Network.KadAlpha == 0 {
+				return fmt.Errorf("kademlia bucket size and alpha should be greater than 0")
+			}
+		default:
+			return fmt.Errorf("unknown peer discovery scheme %s", cfg.Network.PeerDiscoveryScheme)
+		}
+	}
+	for _, topic := range cfg.Network.Topics {
+		switch topic {
+		case ActionsTopic, BlocksTopic, ConsensusTopic:
+			break
+		default:
+			return fmt.Errorf("unknown gossip topic %s", topic)
+		}
+	}
 	if cfg.Dispatcher.EventChanSize <= 0 {
 		return fmt.Errorf("dispatcher event chan size should be greater than 0")
 	}
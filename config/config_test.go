@@ -60,6 +60,31 @@ func TestValidateConfig(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "producer has unmatched pubkey and prikey", err.Error())
 
+	cfg = LoadTestConfig()
+	cfg.Chain.Checkpoints = []Checkpoint{{Height: 10, Hash: "0123"}}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid checkpoint hash at height 10", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.TrustedProducers = []string{"xyz"}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid trusted producer public key xyz", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.BlockStore = "UNKNOWN"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown block store UNKNOWN", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Chain.BlockStore = FileBlockStore
+	cfg.Chain.BlocksPerFile = 0
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "block file path and blocks per file should be given for the file block store", err.Error())
+
 	cfg = LoadTestConfig()
 	cfg.Explorer.Enabled = true
 	err = validateConfig(cfg)
@@ -72,6 +97,25 @@ func TestValidateConfig(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "either peer discover should be enabled or a topology should be given", err.Error())
 
+	cfg = LoadTestConfig()
+	cfg.Network.PeerDiscoveryScheme = "UNKNOWN"
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown peer discovery scheme UNKNOWN", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Network.PeerDiscoveryScheme = KademliaPeerDiscovery
+	cfg.Network.KadBucketSize = 0
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "kademlia bucket size and alpha should be greater than 0", err.Error())
+
+	cfg = LoadTestConfig()
+	cfg.Network.Topics = []string{ActionsTopic, "unknown"}
+	err = validateConfig(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown gossip topic unknown", err.Error())
+
 	cfg = LoadTestConfig()
 	cfg.NodeType = FullNodeType
 	cfg.Consensus.Scheme = RollDPoSScheme
@@ -129,11 +173,29 @@ func LoadTestConfig() *Config {
 			PeerDiscovery:           true,
 			TTL:                     3,
 			TopologyPath:            "",
+			PeerDiscoveryScheme:     RandomPeerDiscovery,
+			KadBucketSize:           16,
+			KadAlpha:                3,
+			ChainID:                 1,
+			Topics:                  []string{},
+			GossipFanout:            4,
+			BloomFilterSize:         1 << 20,
+			BloomFilterHashes:       7,
+			EnableCompression:       true,
+			CompressionThreshold:    1024,
+			PeerStorePath:           "",
+			PeerStoreTTL:            72 * time.Hour,
+			PeerStoreSize:           1000,
 		},
 		Chain: Chain{
-			ChainDBPath:     "./a/fake/path",
-			ProducerPrivKey: "925f0c9e4b6f6d92f2961d01aff6204c44d73c0b9d0da188582932d4fcad0d8ee8c66600",
-			ProducerPubKey:  "336eb60a5741f585a8e81de64e071327a3b96c15af4af5723598a07b6121e8e813bbd0056ba71ae29c0d64252e913f60afaeb11059908b81ff27cbfa327fd371d35f5ec0cbc01705",
+			ChainDBPath:      "./a/fake/path",
+			ProducerPrivKey:  "925f0c9e4b6f6d92f2961d01aff6204c44d73c0b9d0da188582932d4fcad0d8ee8c66600",
+			ProducerPubKey:   "336eb60a5741f585a8e81de64e071327a3b96c15af4af5723598a07b6121e8e813bbd0056ba71ae29c0d64252e913f60afaeb11059908b81ff27cbfa327fd371d35f5ec0cbc01705",
+			Checkpoints:      []Checkpoint{},
+			TrustedProducers: []string{},
+			BlockStore:       BoltBlockStore,
+			BlockFilePath:    "./a/fake/blocks",
+			BlocksPerFile:    10000,
 		},
 		Consensus: Consensus{
 			Scheme: NOOPScheme,
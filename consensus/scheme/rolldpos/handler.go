@@ -7,9 +7,12 @@
 package rolldpos
 
 import (
+	"bytes"
 	"net"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/consensus/fsm"
 )
@@ -104,9 +107,29 @@ func (h *acceptPropose) TimeoutDuration() *time.Duration {
 
 func (h *acceptPropose) Handle(event *fsm.Event) {
 	h.roundCtx.prevotes[event.SenderAddr] = event.BlockHash
+	if err := h.validateProposer(event); err != nil {
+		event.Err = err
+		return
+	}
 	event.Err = h.bc.ValidateBlock(event.Block)
 }
 
+// validateProposer checks if the proposed block is produced by the key of the delegate who sends it
+func (h *acceptPropose) validateProposer(event *fsm.Event) error {
+	if event.Block == nil || event.SenderAddr == nil {
+		return nil
+	}
+	// the block is rejected if the key of the proposer is not known yet, as its producer can't be told then
+	key, err := h.pool.PublicKey(event.SenderAddr)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(key, event.Block.Header.Pubkey) {
+		return errors.Wrapf(ErrInvalidProposer, "proposer %s", event.SenderAddr.String())
+	}
+	return nil
+}
+
 // acceptVote waits for 2k vote messages from others or timeout.
 type acceptVote struct {
 	*RollDPoS
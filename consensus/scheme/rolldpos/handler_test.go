@@ -7,13 +7,19 @@
 package rolldpos
 
 import (
+	"net"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/consensus/fsm"
+	"github.com/iotexproject/iotex-core/delegate"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_delegate"
 )
 
 func TestInitProposeInjectError(t *testing.T) {
@@ -37,3 +43,49 @@ func TestInitProposeInjectError(t *testing.T) {
 
 	assert.Equal(t, evt.Err, err)
 }
+
+func TestAcceptProposeValidateProposer(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	proposer := common.NewTCPNode("127.0.0.1:10000")
+	stranger := common.NewTCPNode("127.0.0.1:10001")
+	newcomer := common.NewTCPNode("127.0.0.1:10002")
+	pool := mock_delegate.NewMockPool(ctrl)
+	pool.EXPECT().PublicKey(proposer).Return([]byte{1, 2, 3}, nil).AnyTimes()
+	pool.EXPECT().PublicKey(stranger).Return(nil, delegate.ErrNotDelegate).AnyTimes()
+	pool.EXPECT().PublicKey(newcomer).Return(nil, delegate.ErrDelegateKeyNotFound).AnyTimes()
+	bc := mock_blockchain.NewMockBlockchain(ctrl)
+	bc.EXPECT().ValidateBlock(gomock.Any()).Return(nil).AnyTimes()
+
+	h := acceptPropose{
+		RollDPoS: &RollDPoS{
+			bc:   bc,
+			pool: pool,
+		},
+	}
+	newEvent := func(sender net.Addr, pubkey []byte) *fsm.Event {
+		h.roundCtx = &roundCtx{prevotes: make(map[net.Addr]*common.Hash32B)}
+		blk := &blockchain.Block{Header: &blockchain.BlockHeader{Pubkey: pubkey}}
+		return &fsm.Event{SenderAddr: sender, Block: blk}
+	}
+
+	evt := newEvent(proposer, []byte{1, 2, 3})
+	h.Handle(evt)
+	assert.Nil(t, evt.Err)
+
+	evt = newEvent(proposer, []byte{4, 5, 6})
+	h.Handle(evt)
+	assert.Equal(t, ErrInvalidProposer, errors.Cause(evt.Err))
+
+	evt = newEvent(stranger, []byte{1, 2, 3})
+	h.Handle(evt)
+	assert.Equal(t, delegate.ErrNotDelegate, errors.Cause(evt.Err))
+
+	// The block is rejected if the key of the proposer is not known yet
+	evt = newEvent(newcomer, []byte{4, 5, 6})
+	h.Handle(evt)
+	assert.Equal(t, delegate.ErrDelegateKeyNotFound, errors.Cause(evt.Err))
+}
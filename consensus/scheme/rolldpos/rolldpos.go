@@ -29,6 +29,8 @@ import (
 var (
 	// ErrInvalidViewChangeMsg is the error that ViewChangeMsg is invalid
 	ErrInvalidViewChangeMsg = errors.New("ViewChangeMsg is invalid")
+	// ErrInvalidProposer is the error that the proposed block is not produced by the sender delegate
+	ErrInvalidProposer = errors.New("block is not produced by the proposer")
 )
 
 // roundCtx keeps the context data for the current round and block.
@@ -81,6 +81,8 @@ func createTestRollDPoS(
 	dp.EXPECT().AllDelegates().Return(delegates, nil).AnyTimes()
 	dp.EXPECT().RollDelegates(gomock.Any()).Return(delegates, nil).AnyTimes()
 	dp.EXPECT().NumDelegatesPerEpoch().Return(uint(len(delegates)), nil).AnyTimes()
+	// the delegates propose the genesis block in the tests
+	dp.EXPECT().PublicKey(gomock.Any()).Return(blockchain.NewGenesisBlock(nil).Header.Pubkey, nil).AnyTimes()
 	dNet := mock_rolldpos.NewMockDNet(ctrl)
 	dNet.EXPECT().Self().Return(self)
 	tellblockCB := func(msg proto.Message) error {
@@ -7,6 +7,7 @@
 package db
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/boltdb/bolt"
@@ -37,6 +38,13 @@ type KVStore interface {
 	Get(string, []byte) ([]byte, error)
 	// Delete deletes a record by (namespace, key)
 	Delete(string, []byte) error
+	// BatchDelete deletes a slice of records identified by (namespace, key)
+	BatchDelete(string, [][]byte) error
+	// DeleteNamespace deletes all the records in the namespace
+	DeleteNamespace(string) error
+	// Iterate calls the function on every record in the namespace, and stops at the first error. The function must not
+	// write to the KV store.
+	Iterate(string, func([]byte, []byte) error) error
 }
 
 const (
@@ -96,6 +104,14 @@ func (m *memKVStore) Delete(namespace string, key []byte) error {
 	return nil
 }
 
+// BatchDelete deletes a slice of records key[]
+func (m *memKVStore) BatchDelete(namespace string, key [][]byte) error {
+	for i := 0; i < len(key); i++ {
+		m.data.Delete(namespace + keyDelimiter + string(key[i]))
+	}
+	return nil
+}
+
 const (
 	fileMode = 0600
 )
@@ -192,6 +208,32 @@ func (b *boltDB) Get(namespace string, key []byte) ([]byte, error) {
 	return value, err
 }
 
+// DeleteNamespace deletes all the records in the namespace
+func (m *memKVStore) DeleteNamespace(namespace string) error {
+	prefix := namespace + keyDelimiter
+	m.data.Range(func(key, _ interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			m.data.Delete(key)
+		}
+		return true
+	})
+	return nil
+}
+
+// Iterate calls the function on every record in the namespace, and stops at the first error
+func (m *memKVStore) Iterate(namespace string, fn func(key, value []byte) error) error {
+	prefix := namespace + keyDelimiter
+	var err error
+	m.data.Range(func(key, value interface{}) bool {
+		if !strings.HasPrefix(key.(string), prefix) {
+			return true
+		}
+		err = fn([]byte(strings.TrimPrefix(key.(string), prefix)), value.([]byte))
+		return err == nil
+	})
+	return err
+}
+
 // Delete deletes a record
 func (b *boltDB) Delete(namespace string, key []byte) error {
 	return b.db.Update(func(tx *bolt.Tx) error {
@@ -203,6 +245,46 @@ func (b *boltDB) Delete(namespace string, key []byte) error {
 	})
 }
 
+// BatchDelete deletes a slice of records key[]
+func (b *boltDB) BatchDelete(namespace string, key [][]byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return errors.Wrapf(bolt.ErrBucketNotFound, "bucket = %s", namespace)
+		}
+		for i := 0; i < len(key); i++ {
+			if err := bucket.Delete(key[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteNamespace deletes all the records in the namespace
+func (b *boltDB) DeleteNamespace(namespace string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(namespace)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// Iterate calls the function on every record in the namespace, in the order of the keys, and stops at the first error.
+// The function is given the copies of the records, which stay valid after it returns.
+func (b *boltDB) Iterate(namespace string, fn func(key, value []byte) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			return fn(append([]byte{}, key...), append([]byte{}, value...))
+		})
+	})
+}
+
 //======================================
 // private functions
 //======================================
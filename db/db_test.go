@@ -10,6 +10,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/iotexproject/iotex-core/test/util"
@@ -57,6 +58,30 @@ func TestKVStorePutGet(t *testing.T) {
 		value, err = kvStore.Get(bucket, testK[0])
 		assert.Nil(err)
 		assert.Equal(testV[0], value)
+
+		err = kvStore.BatchPut(bucket, [][]byte{testK[1], testK[2]}, [][]byte{testV[1], testV[2]})
+		assert.Nil(err)
+		err = kvStore.BatchDelete(bucket, [][]byte{testK[0], testK[1]})
+		assert.Nil(err)
+		value, err = kvStore.Get(bucket, testK[0])
+		assert.NotNil(err)
+		assert.Nil(value)
+		value, err = kvStore.Get(bucket, testK[1])
+		assert.NotNil(err)
+		assert.Nil(value)
+		value, err = kvStore.Get(bucket, testK[2])
+		assert.Nil(err)
+		assert.Equal(testV[2], value)
+
+		records := map[string]string{}
+		assert.Nil(kvStore.Iterate(bucket, func(key, value []byte) error {
+			records[string(key)] = string(value)
+			return nil
+		}))
+		assert.Equal(map[string]string{"key": "value", "key_3": "value_3"}, records)
+		assert.Nil(kvStore.Iterate("test_ns_1", func([]byte, []byte) error {
+			return errors.New("no record expected")
+		}))
 	}
 
 	t.Run("In-memory KV Store", func(t *testing.T) {
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package db
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+const schemaNS = "schema"
+
+var schemaVersionKey = []byte("version")
+
+// ErrNewerSchema indicates the DB is written in a schema newer than the ones known to this node
+var ErrNewerSchema = errors.New("DB schema is newer than supported")
+
+// Migration upgrades the data in the KV store from the previous schema version to its version. The migration may be
+// interrupted, so it is expected to be able to run again on the data it has partly upgraded.
+type Migration struct {
+	Version     uint64
+	Description string
+	Migrate     func(KVStore) error
+}
+
+// SchemaVersion returns the schema version of the data in the KV store. The KV store without any version is of version
+// 0, which is either empty or written before the schema is versioned.
+func SchemaVersion(kvStore KVStore) (uint64, error) {
+	value, err := kvStore.Get(schemaNS, schemaVersionKey)
+	if cause := errors.Cause(err); cause == ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get schema version")
+	}
+	if len(value) != 8 {
+		return 0, errors.Wrap(ErrInvalidDB, "schema version is broken")
+	}
+	return common.MachineEndian.Uint64(value), nil
+}
+
+// Migrate upgrades the data in the KV store to the latest schema version, by running the migrations above its current
+// version in order. The version is bumped after each migration completes, so that the migrations can be resumed from
+// the one interrupted. It returns ErrNewerSchema if the data is of a version beyond the migrations.
+func Migrate(kvStore KVStore, migrations []Migration) error {
+	version, err := SchemaVersion(kvStore)
+	if err != nil {
+		return err
+	}
+	latest := uint64(0)
+	for _, m := range migrations {
+		if m.Version <= latest {
+			return errors.Errorf("migration to version %d is out of order", m.Version)
+		}
+		latest = m.Version
+	}
+	if version > latest {
+		return errors.Wrapf(ErrNewerSchema, "DB schema version %d, supporting up to version %d", version, latest)
+	}
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		logger.Info().
+			Uint64("from", version).
+			Uint64("to", m.Version).
+			Str("migration", m.Description).
+			Msg("Migrate DB schema")
+		if err := m.Migrate(kvStore); err != nil {
+			return errors.Wrapf(err, "failed to migrate DB schema to version %d", m.Version)
+		}
+		value := make([]byte, 8)
+		common.MachineEndian.PutUint64(value, m.Version)
+		if err := kvStore.Put(schemaNS, schemaVersionKey, value); err != nil {
+			return errors.Wrapf(err, "failed to put schema version %d", m.Version)
+		}
+		version = m.Version
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+func TestMigrate(t *testing.T) {
+	testMigrate := func(kvStore KVStore, t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Nil(kvStore.Start())
+		defer func() {
+			assert.Nil(kvStore.Stop())
+		}()
+		version, err := SchemaVersion(kvStore)
+		assert.Nil(err)
+		assert.Equal(uint64(0), version)
+
+		var ran []uint64
+		failing := true
+		migrations := []Migration{
+			{1, "first", func(KVStore) error {
+				ran = append(ran, 1)
+				return nil
+			}},
+			{3, "second", func(KVStore) error {
+				ran = append(ran, 3)
+				if failing {
+					return errors.New("interrupted")
+				}
+				return nil
+			}},
+		}
+
+		// the migrations are resumed from the one interrupted
+		assert.NotNil(Migrate(kvStore, migrations))
+		version, err = SchemaVersion(kvStore)
+		assert.Nil(err)
+		assert.Equal(uint64(1), version)
+		failing = false
+		assert.Nil(Migrate(kvStore, migrations))
+		assert.Equal([]uint64{1, 3, 3}, ran)
+		version, err = SchemaVersion(kvStore)
+		assert.Nil(err)
+		assert.Equal(uint64(3), version)
+		assert.Nil(Migrate(kvStore, migrations))
+		assert.Equal(3, len(ran))
+
+		// the DB of a newer version is refused
+		assert.Equal(ErrNewerSchema, errors.Cause(Migrate(kvStore, migrations[:1])))
+		// the migrations out of order are refused
+		assert.NotNil(Migrate(kvStore, []Migration{migrations[1], migrations[0]}))
+	}
+
+	t.Run("In-memory KV Store", func(t *testing.T) {
+		testMigrate(NewMemKVStore(), t)
+	})
+
+	path := "/tmp/test-migrate"
+	t.Run("Bolt DB", func(t *testing.T) {
+		util.CleanupPath(t, path)
+		defer util.CleanupPath(t, path)
+		testMigrate(NewBoltDB(path, nil), t)
+	})
+}
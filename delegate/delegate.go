@@ -19,6 +19,10 @@ import (
 var (
 	// ErrZeroDelegate indicates seeing 0 delegates in the network
 	ErrZeroDelegate = errors.New("zero delegates in the network")
+	// ErrNotDelegate indicates the address is not a delegate's
+	ErrNotDelegate = errors.New("not a delegate")
+	// ErrDelegateKeyNotFound indicates the public key of the delegate is not known yet
+	ErrDelegateKeyNotFound = errors.New("delegate's public key is not found")
 )
 
 // Pool is the interface
@@ -34,6 +38,14 @@ type Pool interface {
 
 	// NumDelegatesPerEpoch returns number of delegates per epoch
 	NumDelegatesPerEpoch() (uint, error)
+
+	// PublicKey returns the public key of the delegate at the address
+	PublicKey(addr net.Addr) ([]byte, error)
+}
+
+// KeyResolver resolves the public key of the node at the address
+type KeyResolver interface {
+	PublicKey(addr string) ([]byte, bool)
 }
 
 // ConfigBasedPool is the simple delegate pool implementing Pool interface
@@ -41,6 +53,7 @@ type ConfigBasedPool struct {
 	service.AbstractService
 	cfg       *config.Delegate
 	delegates []net.Addr
+	resolver  KeyResolver
 }
 
 // NewConfigBasedPool creates an instance of config-based delegate pool
@@ -104,3 +117,30 @@ func (cbdp *ConfigBasedPool) NumDelegatesPerEpoch() (uint, error) {
 	}
 	return cbdp.cfg.RollNum, nil
 }
+
+// AttachKeyResolver attaches the resolver to look up the delegates' public keys
+func (cbdp *ConfigBasedPool) AttachKeyResolver(resolver KeyResolver) {
+	cbdp.resolver = resolver
+}
+
+// PublicKey returns the public key of the delegate at the address, which is resolved by the attached resolver
+func (cbdp *ConfigBasedPool) PublicKey(addr net.Addr) ([]byte, error) {
+	found := false
+	for _, d := range cbdp.delegates {
+		if d.String() == addr.String() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNotDelegate
+	}
+	if cbdp.resolver == nil {
+		return nil, ErrDelegateKeyNotFound
+	}
+	key, ok := cbdp.resolver.PublicKey(addr.String())
+	if !ok {
+		return nil, ErrDelegateKeyNotFound
+	}
+	return key, nil
+}
@@ -10,9 +10,12 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/test/mock/mock_delegate"
 )
 
 func TestConfigBasedPool_AllDelegates(t *testing.T) {
@@ -127,3 +130,28 @@ func TestConfigBasedPool_NumDelegates(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, uint(4), num)
 }
+
+func TestConfigBasedPool_PublicKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := config.Config{}
+	cfg.Delegate.Addrs = []string{"127.0.0.1:10000", "127.0.0.1:10001"}
+	cbdp := NewConfigBasedPool(&cfg.Delegate)
+
+	_, err := cbdp.PublicKey(common.NewTCPNode("127.0.0.1:10000"))
+	require.Equal(t, ErrDelegateKeyNotFound, err)
+
+	resolver := mock_delegate.NewMockKeyResolver(ctrl)
+	resolver.EXPECT().PublicKey("127.0.0.1:10000").Return([]byte{1, 2, 3}, true).Times(1)
+	resolver.EXPECT().PublicKey("127.0.0.1:10001").Return(nil, false).Times(1)
+	cbdp.AttachKeyResolver(resolver)
+
+	key, err := cbdp.PublicKey(common.NewTCPNode("127.0.0.1:10000"))
+	require.Nil(t, err)
+	require.Equal(t, []byte{1, 2, 3}, key)
+	_, err = cbdp.PublicKey(common.NewTCPNode("127.0.0.1:10001"))
+	require.Equal(t, ErrDelegateKeyNotFound, err)
+	_, err = cbdp.PublicKey(common.NewTCPNode("127.0.0.1:10002"))
+	require.Equal(t, ErrNotDelegate, err)
+}
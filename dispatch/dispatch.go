@@ -41,6 +41,34 @@ type blockSyncMsg struct {
 	done   chan bool
 }
 
+// blockHeaderSyncMsg packages a proto block header sync message.
+type blockHeaderSyncMsg struct {
+	sender string
+	sync   *pb.BlockHeaderSync
+	done   chan bool
+}
+
+// blockHeadersMsg packages a proto block header container message.
+type blockHeadersMsg struct {
+	sender  string
+	headers *pb.BlockHeaderContainer
+	done    chan bool
+}
+
+// snapshotSyncMsg packages a proto state snapshot sync message.
+type snapshotSyncMsg struct {
+	sender string
+	sync   *pb.StateSnapshotSync
+	done   chan bool
+}
+
+// snapshotChunkMsg packages a proto state snapshot chunk message.
+type snapshotChunkMsg struct {
+	sender string
+	chunk  *pb.StateSnapshotChunk
+	done   chan bool
+}
+
 // actionMsg packages a proto action message.
 type actionMsg struct {
 	action *pb.ActionPb
@@ -149,6 +177,18 @@ loop:
 			case *blockSyncMsg:
 				d.handleBlockSyncMsg(msg)
 
+			case *blockHeaderSyncMsg:
+				d.handleBlockHeaderSyncMsg(msg)
+
+			case *blockHeadersMsg:
+				d.handleBlockHeadersMsg(msg)
+
+			case *snapshotSyncMsg:
+				d.handleSnapshotSyncMsg(msg)
+
+			case *snapshotChunkMsg:
+				d.handleSnapshotChunkMsg(msg)
+
 			default:
 				logger.Warn().
 					Str("msg", msg.(string)).
@@ -223,6 +263,66 @@ func (d *IotxDispatcher) handleBlockSyncMsg(m *blockSyncMsg) {
 	}
 }
 
+// handleBlockHeaderSyncMsg handles block header sync requests from peers.
+func (d *IotxDispatcher) handleBlockHeaderSyncMsg(m *blockHeaderSyncMsg) {
+	logger.Info().
+		Str("addr", m.sender).Uint64("start", m.sync.Start).Uint64("end", m.sync.End).
+		Msg("receive blockHeaderSyncMsg")
+	// dispatch to block sync
+	if err := d.bs.ProcessHeaderSyncRequest(m.sender, m.sync); err != nil {
+		logger.Error().Err(err).Msg("Fail to process the block header sync request")
+	}
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+}
+
+// handleBlockHeadersMsg handles block headers from peers.
+func (d *IotxDispatcher) handleBlockHeadersMsg(m *blockHeadersMsg) {
+	logger.Info().
+		Str("addr", m.sender).Int("headers", len(m.headers.Headers)).
+		Msg("receive blockHeadersMsg")
+	// dispatch to block sync
+	if err := d.bs.ProcessBlockHeaders(m.sender, m.headers); err != nil {
+		logger.Error().Err(err).Msg("Fail to sync the block headers")
+	}
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+}
+
+// handleSnapshotSyncMsg handles state snapshot sync requests from peers.
+func (d *IotxDispatcher) handleSnapshotSyncMsg(m *snapshotSyncMsg) {
+	logger.Info().
+		Str("addr", m.sender).Uint64("height", m.sync.Height).Uint32("chunk", m.sync.Chunk).
+		Msg("receive snapshotSyncMsg")
+	// dispatch to block sync
+	if err := d.bs.ProcessSnapshotSyncRequest(m.sender, m.sync); err != nil {
+		logger.Error().Err(err).Msg("Fail to process the state snapshot sync request")
+	}
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+}
+
+// handleSnapshotChunkMsg handles state snapshot chunks from peers.
+func (d *IotxDispatcher) handleSnapshotChunkMsg(m *snapshotChunkMsg) {
+	logger.Info().
+		Str("addr", m.sender).Uint64("height", m.chunk.Height).Uint32("chunk", m.chunk.Chunk).
+		Msg("receive snapshotChunkMsg")
+	// dispatch to block sync
+	if err := d.bs.ProcessSnapshotChunk(m.sender, m.chunk); err != nil {
+		logger.Error().Err(err).Msg("Fail to sync the state snapshot chunk")
+	}
+	// signal to let caller know we are done
+	if m.done != nil {
+		m.done <- true
+	}
+}
+
 // dispatchAction adds the passed action message to the news handling queue.
 func (d *IotxDispatcher) dispatchAction(msg proto.Message, done chan bool) {
 	if atomic.LoadInt32(&d.shutdown) != 0 {
@@ -268,6 +368,50 @@ func (d *IotxDispatcher) dispatchBlockSyncData(msg proto.Message, done chan bool
 	d.enqueueEvent(&blockMsg{data.Block, pb.MsgBlockSyncDataType, done})
 }
 
+// dispatchBlockHeaderSyncReq adds the passed block header sync request to the news handling queue.
+func (d *IotxDispatcher) dispatchBlockHeaderSyncReq(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+	d.enqueueEvent(&blockHeaderSyncMsg{sender, (msg).(*pb.BlockHeaderSync), done})
+}
+
+// dispatchBlockHeaderSyncData adds the passed block headers to the news handling queue.
+func (d *IotxDispatcher) dispatchBlockHeaderSyncData(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+	d.enqueueEvent(&blockHeadersMsg{sender, (msg).(*pb.BlockHeaderContainer), done})
+}
+
+// dispatchSnapshotSyncReq adds the passed state snapshot sync request to the news handling queue.
+func (d *IotxDispatcher) dispatchSnapshotSyncReq(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+	d.enqueueEvent(&snapshotSyncMsg{sender, (msg).(*pb.StateSnapshotSync), done})
+}
+
+// dispatchSnapshotSyncData adds the passed state snapshot chunk to the news handling queue.
+func (d *IotxDispatcher) dispatchSnapshotSyncData(sender string, msg proto.Message, done chan bool) {
+	if atomic.LoadInt32(&d.shutdown) != 0 {
+		if done != nil {
+			close(done)
+		}
+		return
+	}
+	d.enqueueEvent(&snapshotChunkMsg{sender, (msg).(*pb.StateSnapshotChunk), done})
+}
+
 // HandleBroadcast handles incoming broadcast message
 func (d *IotxDispatcher) HandleBroadcast(message proto.Message, done chan bool) {
 	msgType, err := pb.GetTypeFromProtoMsg(message)
@@ -309,6 +453,14 @@ func (d *IotxDispatcher) HandleTell(sender net.Addr, message proto.Message, done
 		d.dispatchBlockSyncReq(sender.String(), message, done)
 	case pb.MsgBlockSyncDataType:
 		d.dispatchBlockSyncData(message, done)
+	case pb.MsgBlockHeaderSyncReqType:
+		d.dispatchBlockHeaderSyncReq(sender.String(), message, done)
+	case pb.MsgBlockHeaderSyncDataType:
+		d.dispatchBlockHeaderSyncData(sender.String(), message, done)
+	case pb.MsgStateSnapshotSyncReqType:
+		d.dispatchSnapshotSyncReq(sender.String(), message, done)
+	case pb.MsgStateSnapshotSyncDataType:
+		d.dispatchSnapshotSyncData(sender.String(), message, done)
 	case pb.MsgBlockProtoMsgType:
 		d.cs.HandleBlockPropose(message, done)
 	default:
@@ -16,6 +16,7 @@ import (
 
 	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/network"
 	"github.com/iotexproject/iotex-core/server/itx"
 )
 
@@ -66,11 +67,14 @@ func testLocalRollDPoS(prCb string, epochCb string, numBlocks uint64, t *testing
 	require.Nil(err)
 
 	var svrs []*itx.Server
+	// All the nodes run on an in-memory network instead of binding the real ports
+	n := network.NewMemNetwork(0)
 
 	for i := 0; i < 3; i++ {
 		cfg.NodeType = config.FullNodeType
 		cfg.Network.Addr = "127.0.0.1:5000" + strconv.Itoa(i)
 		svr := itx.NewServer(*cfg)
+		svr.P2p().AttachTransport(n.NewTransport())
 		err = svr.Init()
 		require.Nil(err)
 		err = svr.Start()
@@ -84,6 +88,7 @@ func testLocalRollDPoS(prCb string, epochCb string, numBlocks uint64, t *testing
 		cfg.Network.Addr = "127.0.0.1:4000" + strconv.Itoa(i)
 		cfg.Consensus.Scheme = config.RollDPoSScheme
 		svr := itx.NewServer(*cfg)
+		svr.P2p().AttachTransport(n.NewTransport())
 		err = svr.Init()
 		require.Nil(err)
 		err = svr.Start()
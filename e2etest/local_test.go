@@ -18,7 +18,6 @@ import (
 
 	"github.com/iotexproject/iotex-core/blockchain"
 	"github.com/iotexproject/iotex-core/blockchain/action"
-	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/iotxaddress"
 	"github.com/iotexproject/iotex-core/logger"
@@ -57,6 +56,11 @@ func TestLocalCommit(t *testing.T) {
 
 	blockchain.Gen.TotalSupply = uint64(50 << 22)
 	blockchain.Gen.BlockReward = uint64(0)
+	// keep the two top candidates only
+	defer func(size, bufferSize uint) {
+		blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize = size, bufferSize
+	}(blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize)
+	blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize = 2, 10
 
 	// create node
 	svr := itx.NewServer(*cfg)
@@ -143,6 +147,18 @@ func TestLocalCommit(t *testing.T) {
 	require.Nil(err)
 	require.True(height == 4)
 
+	// build the blocks on a replica of the chain, so that each of them carries the states left by the previous one
+	replicaCfg := *cfg
+	replicaCfg.Chain.InMemTest = true
+	replica := blockchain.CreateBlockchain(&replicaCfg, nil)
+	require.NotNil(replica)
+	defer replica.Stop()
+	for h := uint64(1); h <= height; h++ {
+		blk, err := bc.GetBlockByHeight(h)
+		require.Nil(err)
+		require.Nil(replica.CommitBlock(blk))
+	}
+
 	// transfer 1
 	// C --> A
 	s, err = bc.StateByAddr(ta.Addrinfo["charlie"].RawAddress)
@@ -159,19 +175,18 @@ func TestLocalCommit(t *testing.T) {
 	require.Nil(err)
 
 	tsf, _ := ap.PickActs()
-	blk1, err := bc.MintNewBlock(tsf, nil, ta.Addrinfo["miner"], "")
-	hash1 := blk1.HashBlock()
+	blk1, err := replica.MintNewBlock(tsf, nil, ta.Addrinfo["miner"], "")
 	require.Nil(err)
+	require.Nil(replica.CommitBlock(blk1))
 
 	// transfer 2
 	// F --> D
 	s, err = bc.StateByAddr(ta.Addrinfo["foxtrot"].RawAddress)
 	tsf2 := action.NewTransfer(s.Nonce+1, big.NewInt(1), ta.Addrinfo["foxtrot"].RawAddress, ta.Addrinfo["delta"].RawAddress)
 	tsf2, err = tsf2.Sign(ta.Addrinfo["foxtrot"])
-	blk2 := blockchain.NewBlock(0, height+2, hash1, []*action.Transfer{tsf2}, nil)
-	err = blk2.SignBlock(ta.Addrinfo["miner"])
+	blk2, err := replica.MintNewBlock([]*action.Transfer{tsf2}, nil, ta.Addrinfo["miner"], "")
 	require.Nil(err)
-	hash2 := blk2.HashBlock()
+	require.Nil(replica.CommitBlock(blk2))
 	act2 := &pb.ActionPb{&pb.ActionPb_Transfer{tsf2.ConvertToTransferPb()}}
 	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
 		if err := p1.Broadcast(act2); err != nil {
@@ -187,10 +202,9 @@ func TestLocalCommit(t *testing.T) {
 	s, err = bc.StateByAddr(ta.Addrinfo["bravo"].RawAddress)
 	tsf3 := action.NewTransfer(s.Nonce+1, big.NewInt(1), ta.Addrinfo["bravo"].RawAddress, ta.Addrinfo["bravo"].RawAddress)
 	tsf3, err = tsf3.Sign(ta.Addrinfo["bravo"])
-	blk3 := blockchain.NewBlock(0, height+3, hash2, []*action.Transfer{tsf3}, nil)
-	err = blk3.SignBlock(ta.Addrinfo["miner"])
+	blk3, err := replica.MintNewBlock([]*action.Transfer{tsf3}, nil, ta.Addrinfo["miner"], "")
 	require.Nil(err)
-	hash3 := blk3.HashBlock()
+	require.Nil(replica.CommitBlock(blk3))
 	act3 := &pb.ActionPb{&pb.ActionPb_Transfer{tsf3.ConvertToTransferPb()}}
 	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
 		if err := p1.Broadcast(act3); err != nil {
@@ -206,9 +220,9 @@ func TestLocalCommit(t *testing.T) {
 	s, err = bc.StateByAddr(ta.Addrinfo["miner"].RawAddress)
 	tsf4 := action.NewTransfer(s.Nonce+1, big.NewInt(1), ta.Addrinfo["miner"].RawAddress, ta.Addrinfo["echo"].RawAddress)
 	tsf4, err = tsf4.Sign(ta.Addrinfo["miner"])
-	blk4 := blockchain.NewBlock(0, height+4, hash3, []*action.Transfer{tsf4}, nil)
-	err = blk4.SignBlock(ta.Addrinfo["miner"])
+	blk4, err := replica.MintNewBlock([]*action.Transfer{tsf4}, nil, ta.Addrinfo["miner"], "")
 	require.Nil(err)
+	require.Nil(replica.CommitBlock(blk4))
 	act4 := &pb.ActionPb{&pb.ActionPb_Transfer{tsf4.ConvertToTransferPb()}}
 	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
 		if err := p1.Broadcast(act4); err != nil {
@@ -358,8 +372,10 @@ func TestLocalSync(t *testing.T) {
 	p1 := cli.P2p()
 	assert.NotNil(p1)
 
-	// P1 download 4 blocks from P2
-	p1.Tell(cm.NewTCPNode(p2.PRC.Addr), &pb.BlockSync{1, 4})
+	// P1 download 4 blocks from P2, once it's told of the tip block
+	blk, err = bc.GetBlockByHeight(4)
+	assert.Nil(err)
+	assert.Nil(cli.Bs().ProcessBlock(blk))
 	check := util.CheckCondition(func() (bool, error) {
 		blk1, err := bc1.GetBlockByHeight(1)
 		if err != nil {
@@ -418,6 +434,11 @@ func TestVoteLocalCommit(t *testing.T) {
 
 	blockchain.Gen.TotalSupply = uint64(50 << 22)
 	blockchain.Gen.BlockReward = uint64(0)
+	// keep the two top candidates only
+	defer func(size, bufferSize uint) {
+		blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize = size, bufferSize
+	}(blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize)
+	blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize = 2, 10
 
 	// create node
 	svr := itx.NewServer(*cfg)
@@ -478,7 +499,6 @@ func TestVoteLocalCommit(t *testing.T) {
 
 	_, votes := ap.PickActs()
 	blk1, err := bc.MintNewBlock(nil, votes, ta.Addrinfo["miner"], "")
-	hash1 := blk1.HashBlock()
 	require.Nil(err)
 
 	// Add block 2
@@ -487,9 +507,6 @@ func TestVoteLocalCommit(t *testing.T) {
 	require.Nil(err)
 	vote5, err := newSignedVote(3, ta.Addrinfo["charlie"], ta.Addrinfo["alfa"])
 	require.Nil(err)
-	blk2 := blockchain.NewBlock(0, height+2, hash1, nil, []*action.Vote{vote4, vote5})
-	err = blk2.SignBlock(ta.Addrinfo["miner"])
-	require.Nil(err)
 	act4 := &pb.ActionPb{&pb.ActionPb_Vote{vote4.ConvertToVotePb()}}
 	act5 := &pb.ActionPb{&pb.ActionPb_Vote{vote5.ConvertToVotePb()}}
 	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
@@ -505,6 +522,18 @@ func TestVoteLocalCommit(t *testing.T) {
 	require.Nil(err)
 
 	p1.Broadcast(blk1.ConvertToBlockPb())
+	err = util.WaitUntil(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		height, err := bc.TipHeight()
+		if err != nil {
+			return false, err
+		}
+		return int(height) == 5, nil
+	})
+	require.Nil(err)
+
+	// block 2 is built on the states committed by block 1
+	blk2, err := bc.MintNewBlock(nil, []*action.Vote{vote4, vote5}, ta.Addrinfo["miner"], "")
+	require.Nil(err)
 	p1.Broadcast(blk2.ConvertToBlockPb())
 
 	err = util.WaitUntil(10*time.Millisecond, 5*time.Second, func() (bool, error) {
@@ -12,10 +12,13 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blockchain/action"
+	"github.com/iotexproject/iotex-core/blocksync"
 	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/consensus"
 	"github.com/iotexproject/iotex-core/explorer/idl/explorer"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/state"
 )
 
 // ErrInternalServer indicates the internal server error
@@ -25,6 +28,7 @@ var ErrInternalServer = errors.New("internal server error")
 type Service struct {
 	bc        blockchain.Blockchain
 	c         consensus.Consensus
+	bs        blocksync.BlockSync
 	tpsWindow int
 }
 
@@ -239,7 +243,7 @@ ChainLoop:
 				return res, err
 			}
 
-			votee, err := getAddrFromPubKey(blk.Votes[i].VotePubkey)
+			votee, err := getVoteeAddr(blk.Votes[i])
 			if err != nil {
 				return res, err
 			}
@@ -340,7 +344,7 @@ func (exp *Service) GetVotesByBlockID(blkID string, offset int64, limit int64) (
 			return res, err
 		}
 
-		votee, err := getAddrFromPubKey(vote.VotePubkey)
+		votee, err := getVoteeAddr(vote)
 		if err != nil {
 			return res, err
 		}
@@ -526,6 +530,127 @@ func (exp *Service) GetConsensusMetrics() (explorer.ConsensusMetrics, error) {
 	}, nil
 }
 
+// GetSyncStatus returns the progress of block sync
+func (exp *Service) GetSyncStatus() (explorer.SyncStatus, error) {
+	status, err := exp.bs.SyncStatus()
+	if err != nil {
+		return explorer.SyncStatus{}, err
+	}
+	return explorer.SyncStatus{
+		State:           status.State,
+		Syncing:         status.Syncing,
+		LocalHeight:     int64(status.LocalHeight),
+		BestHeight:      int64(status.BestHeight),
+		BlocksPerSecond: status.BlocksPerSecond,
+		Eta:             status.Eta,
+		ActivePeers:     status.ActivePeers,
+	}, nil
+}
+
+// GetRewardsByAddress returns the voter rewards credited to an address
+func (exp *Service) GetRewardsByAddress(address string, offset int64, limit int64) ([]explorer.Reward, error) {
+	receipts, err := exp.bc.GetRewardReceiptsByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	var res []explorer.Reward
+	for i, receipt := range receipts {
+		if int64(i) < offset {
+			continue
+		}
+		if int64(len(res)) >= limit {
+			break
+		}
+		res = append(res, explorer.Reward{
+			Height:   int64(receipt.Height),
+			Delegate: receipt.Delegate,
+			Voter:    receipt.Voter,
+			Amount:   receipt.Amount.Int64(),
+		})
+	}
+	return res, nil
+}
+
+// SimulateActions previews the accounts which the hex encoded serialized transfers and votes would lead to in the next
+// block. The actions which would fail are answered with the error instead of the accounts.
+func (exp *Service) SimulateActions(transfers []string, votes []string) (explorer.SimulationResult, error) {
+	var tsfs []*action.Transfer
+	for _, transfer := range transfers {
+		serialized, err := hex.DecodeString(transfer)
+		if err != nil {
+			return explorer.SimulationResult{}, err
+		}
+		tsf := &action.Transfer{}
+		if err := tsf.Deserialize(serialized); err != nil {
+			return explorer.SimulationResult{}, err
+		}
+		tsfs = append(tsfs, tsf)
+	}
+	var vs []*action.Vote
+	for _, vote := range votes {
+		serialized, err := hex.DecodeString(vote)
+		if err != nil {
+			return explorer.SimulationResult{}, err
+		}
+		v := &action.Vote{}
+		if err := v.Deserialize(serialized); err != nil {
+			return explorer.SimulationResult{}, err
+		}
+		vs = append(vs, v)
+	}
+	states, err := exp.bc.SimulateActions(tsfs, vs)
+	if err != nil {
+		return explorer.SimulationResult{Error: err.Error()}, nil
+	}
+	res := explorer.SimulationResult{}
+	for _, st := range states {
+		res.Accounts = append(res.Accounts, explorer.AddressDetails{
+			Address:      st.Address,
+			TotalBalance: st.Balance.Int64(),
+			Nonce:        int64(st.Nonce),
+		})
+	}
+	return res, nil
+}
+
+// GetStateDiff returns the changes of the accounts made by the block at the height
+func (exp *Service) GetStateDiff(height int64) ([]explorer.AccountChange, error) {
+	if height < 0 {
+		return nil, errors.New("block height should be non-negative")
+	}
+	diff, err := exp.bc.GetStateDiff(uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	var res []explorer.AccountChange
+	for _, account := range diff.Accounts {
+		res = append(res, getAccountChange(diff.Height, account))
+	}
+	return res, nil
+}
+
+// GetStateHistoryByAddress returns the changes of an address made by the blocks, in the order of the heights
+func (exp *Service) GetStateHistoryByAddress(address string, offset int64, limit int64) (
+	[]explorer.AccountChange, error) {
+	diffs, err := exp.bc.GetStateHistoryByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	var res []explorer.AccountChange
+	for i, diff := range diffs {
+		if int64(i) < offset {
+			continue
+		}
+		if int64(len(res)) >= limit {
+			break
+		}
+		for _, account := range diff.Accounts {
+			res = append(res, getAccountChange(diff.Height, account))
+		}
+	}
+	return res, nil
+}
+
 // getTransfer takes in a blockchain and transferHash and returns a Explorer Transfer
 func getTransfer(bc blockchain.Blockchain, transferHash common.Hash32B) (explorer.Transfer, error) {
 	explorerTransfer := explorer.Transfer{}
@@ -584,7 +709,7 @@ func getVote(bc blockchain.Blockchain, voteHash common.Hash32B) (explorer.Vote,
 		return explorerVote, err
 	}
 
-	votee, err := getAddrFromPubKey(vote.VotePubkey)
+	votee, err := getVoteeAddr(vote)
 	if err != nil {
 		return explorerVote, err
 	}
@@ -609,3 +734,31 @@ func getAddrFromPubKey(pubKey []byte) (string, error) {
 	}
 	return Address.RawAddress, nil
 }
+
+// getVoteeAddr returns the address of the votee, or an empty string if the vote withdraws the vote of the voter
+func getVoteeAddr(vote *action.Vote) (string, error) {
+	if vote.IsUnvote() {
+		return "", nil
+	}
+	return getAddrFromPubKey(vote.VotePubkey)
+}
+
+// getAccountChange converts the change of an account into an Explorer AccountChange, whose old fields are left zero if
+// the account is created by the block
+func getAccountChange(height uint64, account *state.AccountDiff) explorer.AccountChange {
+	change := explorer.AccountChange{
+		Height:          int64(height),
+		Address:         account.Address(),
+		NewBalance:      account.New.Balance.Int64(),
+		NewNonce:        int64(account.New.Nonce),
+		NewVotee:        account.New.Votee,
+		NewVotingWeight: account.New.VotingWeight.Int64(),
+	}
+	if account.Old != nil {
+		change.OldBalance = account.Old.Balance.Int64()
+		change.OldNonce = int64(account.Old.Nonce)
+		change.OldVotee = account.Old.Votee
+		change.OldVotingWeight = account.Old.VotingWeight.Int64()
+	}
+	return change
+}
@@ -7,6 +7,7 @@
 package explorer
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"net"
@@ -20,8 +21,11 @@ import (
 	"github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/consensus/scheme"
+	"github.com/iotexproject/iotex-core/explorer/idl/explorer"
+	pb "github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/state"
 	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blocksync"
 	"github.com/iotexproject/iotex-core/test/mock/mock_consensus"
 	ta "github.com/iotexproject/iotex-core/test/testaddress"
 	"github.com/iotexproject/iotex-core/test/util"
@@ -106,7 +110,7 @@ func TestExplorerApi(t *testing.T) {
 	config.Chain.ChainDBPath = testDBPath
 
 	tr, _ := trie.NewTrie(testTriePath, true)
-	sf := state.NewFactory(tr)
+	sf, _ := state.NewFactory(tr)
 	sf.CreateState(ta.Addrinfo["miner"].RawAddress, blockchain.Gen.TotalSupply)
 	// Disable block reward to make bookkeeping easier
 	blockchain.Gen.BlockReward = uint64(0)
@@ -307,3 +311,136 @@ func TestService_GetConsensusMetrics(t *testing.T) {
 		m.Candidates,
 	)
 }
+
+func TestService_GetSyncStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bs := mock_blocksync.NewMockBlockSync(ctrl)
+	bs.EXPECT().SyncStatus().Return(&pb.SyncStatus{
+		State:           "Active",
+		Syncing:         true,
+		LocalHeight:     100,
+		BestHeight:      160,
+		BlocksPerSecond: 2,
+		Eta:             30,
+		ActivePeers:     []string{"127.0.0.1:40000", "127.0.0.1:40001"},
+	}, nil)
+
+	svc := Service{bs: bs}
+
+	s, err := svc.GetSyncStatus()
+	require.Nil(t, err)
+	require.Equal(t, "Active", s.State)
+	require.True(t, s.Syncing)
+	require.Equal(t, int64(100), s.LocalHeight)
+	require.Equal(t, int64(160), s.BestHeight)
+	require.Equal(t, float64(2), s.BlocksPerSecond)
+	require.Equal(t, int64(30), s.Eta)
+	require.Equal(t, []string{"127.0.0.1:40000", "127.0.0.1:40001"}, s.ActivePeers)
+}
+
+func TestService_GetRewardsByAddress(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	voter := ta.Addrinfo["bravo"].RawAddress
+	delegate := ta.Addrinfo["alfa"].RawAddress
+	mBc := mock_blockchain.NewMockBlockchain(ctrl)
+	mBc.EXPECT().GetRewardReceiptsByAddress(voter).Times(2).Return([]*state.RewardReceipt{
+		{Height: 10, Delegate: delegate, Voter: voter, Amount: big.NewInt(3)},
+		{Height: 20, Delegate: delegate, Voter: voter, Amount: big.NewInt(5)},
+		{Height: 30, Delegate: delegate, Voter: voter, Amount: big.NewInt(7)},
+	}, nil)
+
+	svc := Service{bc: mBc}
+
+	rewards, err := svc.GetRewardsByAddress(voter, 0, 10)
+	require.Nil(err)
+	require.Equal(3, len(rewards))
+	rewards, err = svc.GetRewardsByAddress(voter, 1, 1)
+	require.Nil(err)
+	require.Equal([]explorer.Reward{{Height: 20, Delegate: delegate, Voter: voter, Amount: 5}}, rewards)
+}
+
+func TestService_SimulateActions(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sender := ta.Addrinfo["alfa"].RawAddress
+	recipient := ta.Addrinfo["bravo"].RawAddress
+	tsf := action.NewTransfer(1, big.NewInt(10), sender, recipient)
+	serialized, err := tsf.Serialize()
+	require.Nil(err)
+
+	mBc := mock_blockchain.NewMockBlockchain(ctrl)
+	mBc.EXPECT().SimulateActions(gomock.Any(), gomock.Any()).Times(1).Return([]*state.State{
+		{Address: sender, Nonce: 1, Balance: big.NewInt(90), VotingWeight: big.NewInt(0)},
+		{Address: recipient, Balance: big.NewInt(10), VotingWeight: big.NewInt(0)},
+	}, nil)
+	mBc.EXPECT().SimulateActions(gomock.Any(), gomock.Any()).Times(1).Return(nil, state.ErrNotEnoughBalance)
+
+	svc := Service{bc: mBc}
+
+	res, err := svc.SimulateActions([]string{hex.EncodeToString(serialized)}, nil)
+	require.Nil(err)
+	require.Equal("", res.Error)
+	require.Equal([]explorer.AddressDetails{
+		{Address: sender, TotalBalance: 90, Nonce: 1},
+		{Address: recipient, TotalBalance: 10},
+	}, res.Accounts)
+
+	res, err = svc.SimulateActions([]string{hex.EncodeToString(serialized)}, nil)
+	require.Nil(err)
+	require.Equal(state.ErrNotEnoughBalance.Error(), res.Error)
+	require.Equal(0, len(res.Accounts))
+
+	_, err = svc.SimulateActions([]string{"not hex"}, nil)
+	require.NotNil(err)
+}
+
+func TestService_GetStateDiff(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sender := ta.Addrinfo["alfa"].RawAddress
+	recipient := ta.Addrinfo["bravo"].RawAddress
+	senderDiff := &state.AccountDiff{
+		Old: &state.State{Address: sender, Balance: big.NewInt(100), VotingWeight: big.NewInt(0)},
+		New: &state.State{Address: sender, Nonce: 1, Balance: big.NewInt(90), VotingWeight: big.NewInt(0)},
+	}
+	recipientDiff := &state.AccountDiff{
+		New: &state.State{Address: recipient, Balance: big.NewInt(10), VotingWeight: big.NewInt(0)},
+	}
+	mBc := mock_blockchain.NewMockBlockchain(ctrl)
+	mBc.EXPECT().GetStateDiff(uint64(5)).Times(1).Return(&state.StateDiff{
+		Height:   5,
+		Accounts: []*state.AccountDiff{senderDiff, recipientDiff},
+	}, nil)
+	mBc.EXPECT().GetStateHistoryByAddress(sender).Times(2).Return([]*state.StateDiff{
+		{Height: 5, Accounts: []*state.AccountDiff{senderDiff}},
+		{Height: 8, Accounts: []*state.AccountDiff{senderDiff}},
+	}, nil)
+
+	svc := Service{bc: mBc}
+
+	changes, err := svc.GetStateDiff(5)
+	require.Nil(err)
+	require.Equal([]explorer.AccountChange{
+		{Height: 5, Address: sender, OldBalance: 100, NewBalance: 90, NewNonce: 1},
+		{Height: 5, Address: recipient, NewBalance: 10},
+	}, changes)
+	_, err = svc.GetStateDiff(-1)
+	require.NotNil(err)
+
+	changes, err = svc.GetStateHistoryByAddress(sender, 0, 10)
+	require.Nil(err)
+	require.Equal(2, len(changes))
+	changes, err = svc.GetStateHistoryByAddress(sender, 1, 1)
+	require.Nil(err)
+	require.Equal(1, len(changes))
+	require.Equal(int64(8), changes[0].Height)
+}
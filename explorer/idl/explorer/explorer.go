@@ -69,6 +69,41 @@ type ConsensusMetrics struct {
 	Candidates          []string `json:"candidates"`
 }
 
+type SyncStatus struct {
+	State           string   `json:"state"`
+	Syncing         bool     `json:"syncing"`
+	LocalHeight     int64    `json:"localHeight"`
+	BestHeight      int64    `json:"bestHeight"`
+	BlocksPerSecond float64  `json:"blocksPerSecond"`
+	Eta             int64    `json:"eta"`
+	ActivePeers     []string `json:"activePeers"`
+}
+
+type Reward struct {
+	Height   int64  `json:"height"`
+	Delegate string `json:"delegate"`
+	Voter    string `json:"voter"`
+	Amount   int64  `json:"amount"`
+}
+
+type SimulationResult struct {
+	Error    string           `json:"error"`
+	Accounts []AddressDetails `json:"accounts"`
+}
+
+type AccountChange struct {
+	Height          int64  `json:"height"`
+	Address         string `json:"address"`
+	OldBalance      int64  `json:"oldBalance"`
+	NewBalance      int64  `json:"newBalance"`
+	OldNonce        int64  `json:"oldNonce"`
+	NewNonce        int64  `json:"newNonce"`
+	OldVotee        string `json:"oldVotee"`
+	NewVotee        string `json:"newVotee"`
+	OldVotingWeight int64  `json:"oldVotingWeight"`
+	NewVotingWeight int64  `json:"newVotingWeight"`
+}
+
 type Explorer interface {
 	GetBlockchainHeight() (int64, error)
 	GetAddressBalance(address string) (int64, error)
@@ -85,6 +120,11 @@ type Explorer interface {
 	GetBlockByID(blkID string) (Block, error)
 	GetCoinStatistic() (CoinStatistic, error)
 	GetConsensusMetrics() (ConsensusMetrics, error)
+	GetSyncStatus() (SyncStatus, error)
+	GetRewardsByAddress(address string, offset int64, limit int64) ([]Reward, error)
+	SimulateActions(transfers []string, votes []string) (SimulationResult, error)
+	GetStateDiff(height int64) ([]AccountChange, error)
+	GetStateHistoryByAddress(address string, offset int64, limit int64) ([]AccountChange, error)
 }
 
 func NewExplorerProxy(c barrister.Client) Explorer {
@@ -366,6 +406,96 @@ func (_p ExplorerProxy) GetConsensusMetrics() (ConsensusMetrics, error) {
 	return ConsensusMetrics{}, _err
 }
 
+func (_p ExplorerProxy) GetSyncStatus() (SyncStatus, error) {
+	_res, _err := _p.client.Call("Explorer.getSyncStatus")
+	if _err == nil {
+		_retType := _p.idl.Method("Explorer.getSyncStatus").Returns
+		_res, _err = barrister.Convert(_p.idl, &_retType, reflect.TypeOf(SyncStatus{}), _res, "")
+	}
+	if _err == nil {
+		_cast, _ok := _res.(SyncStatus)
+		if !_ok {
+			_t := reflect.TypeOf(_res)
+			_msg := fmt.Sprintf("Explorer.getSyncStatus returned invalid type: %v", _t)
+			return SyncStatus{}, &barrister.JsonRpcError{Code: -32000, Message: _msg}
+		}
+		return _cast, nil
+	}
+	return SyncStatus{}, _err
+}
+
+func (_p ExplorerProxy) GetRewardsByAddress(address string, offset int64, limit int64) ([]Reward, error) {
+	_res, _err := _p.client.Call("Explorer.getRewardsByAddress", address, offset, limit)
+	if _err == nil {
+		_retType := _p.idl.Method("Explorer.getRewardsByAddress").Returns
+		_res, _err = barrister.Convert(_p.idl, &_retType, reflect.TypeOf([]Reward{}), _res, "")
+	}
+	if _err == nil {
+		_cast, _ok := _res.([]Reward)
+		if !_ok {
+			_t := reflect.TypeOf(_res)
+			_msg := fmt.Sprintf("Explorer.getRewardsByAddress returned invalid type: %v", _t)
+			return []Reward{}, &barrister.JsonRpcError{Code: -32000, Message: _msg}
+		}
+		return _cast, nil
+	}
+	return []Reward{}, _err
+}
+
+func (_p ExplorerProxy) SimulateActions(transfers []string, votes []string) (SimulationResult, error) {
+	_res, _err := _p.client.Call("Explorer.simulateActions", transfers, votes)
+	if _err == nil {
+		_retType := _p.idl.Method("Explorer.simulateActions").Returns
+		_res, _err = barrister.Convert(_p.idl, &_retType, reflect.TypeOf(SimulationResult{}), _res, "")
+	}
+	if _err == nil {
+		_cast, _ok := _res.(SimulationResult)
+		if !_ok {
+			_t := reflect.TypeOf(_res)
+			_msg := fmt.Sprintf("Explorer.simulateActions returned invalid type: %v", _t)
+			return SimulationResult{}, &barrister.JsonRpcError{Code: -32000, Message: _msg}
+		}
+		return _cast, nil
+	}
+	return SimulationResult{}, _err
+}
+
+func (_p ExplorerProxy) GetStateDiff(height int64) ([]AccountChange, error) {
+	_res, _err := _p.client.Call("Explorer.getStateDiff", height)
+	if _err == nil {
+		_retType := _p.idl.Method("Explorer.getStateDiff").Returns
+		_res, _err = barrister.Convert(_p.idl, &_retType, reflect.TypeOf([]AccountChange{}), _res, "")
+	}
+	if _err == nil {
+		_cast, _ok := _res.([]AccountChange)
+		if !_ok {
+			_t := reflect.TypeOf(_res)
+			_msg := fmt.Sprintf("Explorer.getStateDiff returned invalid type: %v", _t)
+			return []AccountChange{}, &barrister.JsonRpcError{Code: -32000, Message: _msg}
+		}
+		return _cast, nil
+	}
+	return []AccountChange{}, _err
+}
+
+func (_p ExplorerProxy) GetStateHistoryByAddress(address string, offset int64, limit int64) ([]AccountChange, error) {
+	_res, _err := _p.client.Call("Explorer.getStateHistoryByAddress", address, offset, limit)
+	if _err == nil {
+		_retType := _p.idl.Method("Explorer.getStateHistoryByAddress").Returns
+		_res, _err = barrister.Convert(_p.idl, &_retType, reflect.TypeOf([]AccountChange{}), _res, "")
+	}
+	if _err == nil {
+		_cast, _ok := _res.([]AccountChange)
+		if !_ok {
+			_t := reflect.TypeOf(_res)
+			_msg := fmt.Sprintf("Explorer.getStateHistoryByAddress returned invalid type: %v", _t)
+			return []AccountChange{}, &barrister.JsonRpcError{Code: -32000, Message: _msg}
+		}
+		return _cast, nil
+	}
+	return []AccountChange{}, _err
+}
+
 func NewJSONServer(idl *barrister.Idl, forceASCII bool, explorer Explorer) barrister.Server {
 	return NewServer(idl, &barrister.JsonSerializer{forceASCII}, explorer)
 }
@@ -786,6 +916,223 @@ var IdlJsonRaw = `[
         "date_generated": 0,
         "checksum": ""
     },
+    {
+        "type": "struct",
+        "name": "SyncStatus",
+        "comment": "",
+        "value": "",
+        "extends": "",
+        "fields": [
+            {
+                "name": "state",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "syncing",
+                "type": "bool",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "localHeight",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "bestHeight",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "blocksPerSecond",
+                "type": "float",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "eta",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "activePeers",
+                "type": "string",
+                "optional": false,
+                "is_array": true,
+                "comment": ""
+            }
+        ],
+        "values": null,
+        "functions": null,
+        "barrister_version": "",
+        "date_generated": 0,
+        "checksum": ""
+    },
+    {
+        "type": "struct",
+        "name": "Reward",
+        "comment": "",
+        "value": "",
+        "extends": "",
+        "fields": [
+            {
+                "name": "height",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "delegate",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "voter",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "amount",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            }
+        ],
+        "values": null,
+        "functions": null,
+        "barrister_version": "",
+        "date_generated": 0,
+        "checksum": ""
+    },
+    {
+        "type": "struct",
+        "name": "SimulationResult",
+        "comment": "",
+        "value": "",
+        "extends": "",
+        "fields": [
+            {
+                "name": "error",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "accounts",
+                "type": "AddressDetails",
+                "optional": false,
+                "is_array": true,
+                "comment": ""
+            }
+        ],
+        "values": null,
+        "functions": null,
+        "barrister_version": "",
+        "date_generated": 0,
+        "checksum": ""
+    },
+    {
+        "type": "struct",
+        "name": "AccountChange",
+        "comment": "",
+        "value": "",
+        "extends": "",
+        "fields": [
+            {
+                "name": "height",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "address",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "oldBalance",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "newBalance",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "oldNonce",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "newNonce",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "oldVotee",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "newVotee",
+                "type": "string",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "oldVotingWeight",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            },
+            {
+                "name": "newVotingWeight",
+                "type": "int",
+                "optional": false,
+                "is_array": false,
+                "comment": ""
+            }
+        ],
+        "values": null,
+        "functions": null,
+        "barrister_version": "",
+        "date_generated": 0,
+        "checksum": ""
+    },
     {
         "type": "interface",
         "name": "Explorer",
@@ -1168,6 +1515,133 @@ var IdlJsonRaw = `[
                     "is_array": false,
                     "comment": ""
                 }
+            },
+            {
+                "name": "getSyncStatus",
+                "comment": "get the progress of block sync",
+                "params": [],
+                "returns": {
+                    "name": "",
+                    "type": "SyncStatus",
+                    "optional": false,
+                    "is_array": false,
+                    "comment": ""
+                }
+            },
+            {
+                "name": "getRewardsByAddress",
+                "comment": "get list of voter rewards credited to an address",
+                "params": [
+                    {
+                        "name": "address",
+                        "type": "string",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    },
+                    {
+                        "name": "offset",
+                        "type": "int",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    },
+                    {
+                        "name": "limit",
+                        "type": "int",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    }
+                ],
+                "returns": {
+                    "name": "",
+                    "type": "Reward",
+                    "optional": false,
+                    "is_array": true,
+                    "comment": ""
+                }
+            },
+            {
+                "name": "simulateActions",
+                "comment": "preview the accounts which the hex encoded serialized transfers and votes would lead to",
+                "params": [
+                    {
+                        "name": "transfers",
+                        "type": "string",
+                        "optional": false,
+                        "is_array": true,
+                        "comment": ""
+                    },
+                    {
+                        "name": "votes",
+                        "type": "string",
+                        "optional": false,
+                        "is_array": true,
+                        "comment": ""
+                    }
+                ],
+                "returns": {
+                    "name": "",
+                    "type": "SimulationResult",
+                    "optional": false,
+                    "is_array": false,
+                    "comment": ""
+                }
+            },
+            {
+                "name": "getStateDiff",
+                "comment": "get list of account changes made by the block at a height",
+                "params": [
+                    {
+                        "name": "height",
+                        "type": "int",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    }
+                ],
+                "returns": {
+                    "name": "",
+                    "type": "AccountChange",
+                    "optional": false,
+                    "is_array": true,
+                    "comment": ""
+                }
+            },
+            {
+                "name": "getStateHistoryByAddress",
+                "comment": "get list of changes of an address made by the blocks",
+                "params": [
+                    {
+                        "name": "address",
+                        "type": "string",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    },
+                    {
+                        "name": "offset",
+                        "type": "int",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    },
+                    {
+                        "name": "limit",
+                        "type": "int",
+                        "optional": false,
+                        "is_array": false,
+                        "comment": ""
+                    }
+                ],
+                "returns": {
+                    "name": "",
+                    "type": "AccountChange",
+                    "optional": false,
+                    "is_array": true,
+                    "comment": ""
+                }
             }
         ],
         "barrister_version": "",
@@ -12,6 +12,7 @@ import (
 	"github.com/coopernurse/barrister-go"
 
 	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blocksync"
 	"github.com/iotexproject/iotex-core/consensus"
 	"github.com/iotexproject/iotex-core/explorer/idl/explorer"
 	"github.com/iotexproject/iotex-core/logger"
@@ -36,6 +37,7 @@ func (f LogFilter) PostInvoke(r *barrister.RequestResponse) bool {
 func StartJSONServer(
 	blockchain blockchain.Blockchain,
 	consensus consensus.Consensus,
+	blocksync blocksync.BlockSync,
 	isTest bool,
 	port string,
 	tpsWindow int,
@@ -43,6 +45,7 @@ func StartJSONServer(
 	svc := Service{
 		bc:        blockchain,
 		c:         consensus,
+		bs:        blocksync,
 		tpsWindow: tpsWindow,
 	}
 	idl := barrister.MustParseIdlJson([]byte(explorer.IdlJsonRaw))
@@ -123,6 +123,59 @@ func (exp *TestExplorer) GetConsensusMetrics() (explorer.ConsensusMetrics, error
 	}, nil
 }
 
+// GetSyncStatus returns the fake sync status
+func (exp *TestExplorer) GetSyncStatus() (explorer.SyncStatus, error) {
+	localHeight := randInt64()
+	return explorer.SyncStatus{
+		State:           "Active",
+		Syncing:         true,
+		LocalHeight:     localHeight,
+		BestHeight:      localHeight + randInt64(),
+		BlocksPerSecond: float64(randInt64()),
+		Eta:             randInt64(),
+		ActivePeers:     []string{randString(), randString()},
+	}, nil
+}
+
+// GetRewardsByAddress returns the fake voter rewards of an address
+func (exp *TestExplorer) GetRewardsByAddress(address string, offset int64, limit int64) ([]explorer.Reward, error) {
+	var rewards []explorer.Reward
+	for i := int64(0); i < limit; i++ {
+		rewards = append(rewards, explorer.Reward{
+			Height:   randInt64(),
+			Delegate: randString(),
+			Voter:    address,
+			Amount:   randInt64(),
+		})
+	}
+	return rewards, nil
+}
+
+// SimulateActions returns the fake accounts the actions would lead to
+func (exp *TestExplorer) SimulateActions(transfers []string, votes []string) (explorer.SimulationResult, error) {
+	return explorer.SimulationResult{
+		Accounts: []explorer.AddressDetails{
+			{Address: randString(), TotalBalance: randInt64(), Nonce: randInt64()},
+			{Address: randString(), TotalBalance: randInt64(), Nonce: randInt64()},
+		},
+	}, nil
+}
+
+// GetStateDiff returns the fake account changes of a block
+func (exp *TestExplorer) GetStateDiff(height int64) ([]explorer.AccountChange, error) {
+	return []explorer.AccountChange{randAccountChange(height, randString()), randAccountChange(height, randString())}, nil
+}
+
+// GetStateHistoryByAddress returns the fake changes of an address
+func (exp *TestExplorer) GetStateHistoryByAddress(address string, offset int64, limit int64) (
+	[]explorer.AccountChange, error) {
+	var changes []explorer.AccountChange
+	for i := int64(0); i < limit; i++ {
+		changes = append(changes, randAccountChange(randInt64(), address))
+	}
+	return changes, nil
+}
+
 func randInt64() int64 {
 	rand.Seed(time.Now().UnixNano())
 	amount := int64(0)
@@ -173,3 +226,18 @@ func randBlock() explorer.Block {
 		Forged: randInt64(),
 	}
 }
+
+func randAccountChange(height int64, address string) explorer.AccountChange {
+	return explorer.AccountChange{
+		Height:          height,
+		Address:         address,
+		OldBalance:      randInt64(),
+		NewBalance:      randInt64(),
+		OldNonce:        randInt64(),
+		NewNonce:        randInt64(),
+		OldVotee:        randString(),
+		NewVotee:        randString(),
+		OldVotingWeight: randInt64(),
+		NewVotingWeight: randInt64(),
+	}
+}
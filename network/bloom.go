@@ -0,0 +1,117 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// bloomFilter is a plain bloom filter over the hashes of the items
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+func newBloomFilter(size uint, hashes uint) *bloomFilter {
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   uint64(size),
+		hashes: uint64(hashes),
+	}
+}
+
+func (f *bloomFilter) add(hash []byte) {
+	h1, h2 := splitHash(hash)
+	for i := uint64(0); i < f.hashes; i++ {
+		pos := (h1 + i*h2) % f.size
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+func (f *bloomFilter) contains(hash []byte) bool {
+	h1, h2 := splitHash(hash)
+	for i := uint64(0); i < f.hashes; i++ {
+		pos := (h1 + i*h2) % f.size
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// splitHash derives the two base hashes of the double hashing from a (cryptographic) hash of at least 16 bytes
+func splitHash(hash []byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(hash[:8]), binary.BigEndian.Uint64(hash[8:16]) | 1
+}
+
+// RotatingBloomFilter remembers the recently added items with bounded memory. It consists of two bloom filters. Items
+// are added into the current one, and both are checked. On every rotation, the previous filter is dropped and the
+// current one becomes the previous, so that an item is remembered for one to two rotation periods.
+type RotatingBloomFilter struct {
+	Size      uint
+	Hashes    uint
+	current   *bloomFilter
+	previous  *bloomFilter
+	rotatedAt time.Time
+	mutex     sync.RWMutex
+}
+
+// NewRotatingBloomFilter creates an instance of RotatingBloomFilter. Size is the number of bits of each filter and
+// hashes is the number of hash functions.
+func NewRotatingBloomFilter(size uint, hashes uint) *RotatingBloomFilter {
+	return &RotatingBloomFilter{
+		Size:      size,
+		Hashes:    hashes,
+		current:   newBloomFilter(size, hashes),
+		previous:  newBloomFilter(size, hashes),
+		rotatedAt: time.Now(),
+	}
+}
+
+// Add remembers the hash, which needs to be at least 16 bytes long
+func (r *RotatingBloomFilter) Add(hash []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.current.add(hash)
+}
+
+// Contains returns true if the hash has been added recently. False positive is possible.
+func (r *RotatingBloomFilter) Contains(hash []byte) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.current.contains(hash) || r.previous.contains(hash)
+}
+
+// CheckAndAdd returns true if the hash has been added recently, otherwise remembers it
+func (r *RotatingBloomFilter) CheckAndAdd(hash []byte) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.current.contains(hash) || r.previous.contains(hash) {
+		return true
+	}
+	r.current.add(hash)
+	return false
+}
+
+// Rotate drops the previous filter and starts a new current one
+func (r *RotatingBloomFilter) Rotate() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.previous = r.current
+	r.current = newBloomFilter(r.Size, r.Hashes)
+	r.rotatedAt = time.Now()
+}
+
+// RotatedAt returns the time of the last rotation
+func (r *RotatingBloomFilter) RotatedAt() time.Time {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.rotatedAt
+}
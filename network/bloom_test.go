@@ -0,0 +1,54 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/iotexproject/iotex-core/common/utils"
+)
+
+func TestRotatingBloomFilter(t *testing.T) {
+	f := NewRotatingBloomFilter(1<<16, 7)
+	h1 := blake2b.Sum256([]byte("msg1"))
+	h2 := blake2b.Sum256([]byte("msg2"))
+
+	assert.False(t, f.Contains(h1[:]))
+	f.Add(h1[:])
+	assert.True(t, f.Contains(h1[:]))
+	assert.False(t, f.Contains(h2[:]))
+	assert.False(t, f.CheckAndAdd(h2[:]))
+	assert.True(t, f.CheckAndAdd(h2[:]))
+
+	// The items are still remembered after one rotation, but forgotten after two
+	f.Rotate()
+	assert.True(t, f.Contains(h1[:]))
+	assert.True(t, f.Contains(h2[:]))
+	f.Rotate()
+	assert.False(t, f.Contains(h1[:]))
+	assert.False(t, f.Contains(h2[:]))
+}
+
+func TestRotatingBloomFilterFalsePositive(t *testing.T) {
+	f := NewRotatingBloomFilter(1<<16, 7)
+	for i := uint64(0); i < 2000; i++ {
+		h := blake2b.Sum256(utils.Uint64ToBytes(i))
+		f.Add(h[:])
+	}
+	fp := 0
+	for i := uint64(2000); i < 12000; i++ {
+		h := blake2b.Sum256(utils.Uint64ToBytes(i))
+		if f.Contains(h[:]) {
+			fp++
+		}
+	}
+	// The expected false positive rate is lower than 0.01% with 2000 items in 64K bits
+	assert.True(t, fp < 10)
+}
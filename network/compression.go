@@ -0,0 +1,95 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FlateCompression compresses the message bodies with DEFLATE
+	FlateCompression = "flate"
+)
+
+var (
+	// ErrUnknownCompression means the message body is compressed by an unsupported algorithm
+	ErrUnknownCompression = errors.New("unknown compression algorithm")
+	// ErrMsgTooLarge means the decompressed message body exceeds the max message size
+	ErrMsgTooLarge = errors.New("decompressed message is too large")
+)
+
+// supportedCompressions are the compression algorithms that the node supports, in the order of preference
+var supportedCompressions = []string{FlateCompression}
+
+// compressions returns the compression algorithms that the node advertises in the handshake
+func (o *Overlay) compressions() []string {
+	if !o.Config.EnableCompression {
+		return nil
+	}
+	return supportedCompressions
+}
+
+// negotiateCompression picks the most preferred algorithm that both sides support. Empty means no compression, which
+// is always the case for the peers that don't advertise any algorithm.
+func (o *Overlay) negotiateCompression(theirs []string) string {
+	for _, mine := range o.compressions() {
+		for _, t := range theirs {
+			if mine == t {
+				return mine
+			}
+		}
+	}
+	return ""
+}
+
+// compressMsg compresses the message body with the algorithm
+func compressMsg(algorithm string, msgBody []byte) ([]byte, error) {
+	switch algorithm {
+	case FlateCompression:
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(msgBody); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownCompression, "algorithm %s", algorithm)
+	}
+}
+
+// decompressMsg decompresses the message body with the algorithm. The output is capped by maxSize to avoid the
+// decompression bombs.
+func decompressMsg(algorithm string, msgBody []byte, maxSize int) ([]byte, error) {
+	switch algorithm {
+	case "":
+		return msgBody, nil
+	case FlateCompression:
+		r := flate.NewReader(bytes.NewReader(msgBody))
+		defer r.Close()
+		out, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decompress the message")
+		}
+		if len(out) > maxSize {
+			return nil, errors.Wrapf(ErrMsgTooLarge, "exceeding %d bytes", maxSize)
+		}
+		return out, nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownCompression, "algorithm %s", algorithm)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+func TestCompressMsg(t *testing.T) {
+	msgBody := bytes.Repeat([]byte("iotex"), 1000)
+	compressed, err := compressMsg(FlateCompression, msgBody)
+	assert.Nil(t, err)
+	assert.True(t, len(compressed) < len(msgBody))
+	decompressed, err := decompressMsg(FlateCompression, compressed, len(msgBody))
+	assert.Nil(t, err)
+	assert.Equal(t, msgBody, decompressed)
+
+	// The output beyond the max size is rejected
+	_, err = decompressMsg(FlateCompression, compressed, len(msgBody)-1)
+	assert.Equal(t, ErrMsgTooLarge, errors.Cause(err))
+
+	decompressed, err = decompressMsg("", msgBody, len(msgBody))
+	assert.Nil(t, err)
+	assert.Equal(t, msgBody, decompressed)
+
+	_, err = compressMsg("unknown", msgBody)
+	assert.Equal(t, ErrUnknownCompression, errors.Cause(err))
+	_, err = decompressMsg("unknown", msgBody, len(msgBody))
+	assert.Equal(t, ErrUnknownCompression, errors.Cause(err))
+	_, err = decompressMsg(FlateCompression, msgBody, len(msgBody))
+	assert.NotNil(t, err)
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	cfg := LoadTestConfig("", true)
+	cfg.EnableCompression = true
+	o := NewOverlay(cfg)
+	assert.Equal(t, FlateCompression, o.negotiateCompression([]string{"unknown", FlateCompression}))
+	assert.Equal(t, "", o.negotiateCompression([]string{"unknown"}))
+	assert.Equal(t, "", o.negotiateCompression(nil))
+
+	cfg.EnableCompression = false
+	assert.Equal(t, "", o.negotiateCompression([]string{FlateCompression}))
+}
+
+func TestPeerCompressMsgBody(t *testing.T) {
+	p := NewTCPPeer("127.0.0.1:10001")
+	msgBody := bytes.Repeat([]byte("iotex"), 1000)
+	algorithm, body := p.compressMsgBody(msgBody)
+	assert.Equal(t, "", algorithm)
+	assert.Equal(t, msgBody, body)
+
+	p.Compression = FlateCompression
+	p.CompressionThreshold = len(msgBody)
+	algorithm, body = p.compressMsgBody(msgBody)
+	assert.Equal(t, "", algorithm)
+	assert.Equal(t, msgBody, body)
+
+	p.CompressionThreshold = 100
+	algorithm, body = p.compressMsgBody(msgBody)
+	assert.Equal(t, FlateCompression, algorithm)
+	assert.True(t, len(body) < len(msgBody))
+}
+
+func TestCompressedTell(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg1 := LoadTestConfig("127.0.0.1:10001", true)
+	cfg1.EnableCompression = true
+	o1 := NewOverlay(cfg1)
+	cfg2 := LoadTestConfig("127.0.0.1:10002", true)
+	cfg2.EnableCompression = true
+	o2 := NewOverlay(cfg2)
+	// The node without the compression support
+	o3 := NewOverlay(LoadTestConfig("127.0.0.1:10003", true))
+
+	msg := &iproto.TxPb{TxIn: []*iproto.TxInputPb{{UnlockScript: bytes.Repeat([]byte("iotex"), 1000)}}}
+	msgBody, err := proto.Marshal(msg)
+	assert.Nil(t, err)
+	received := 0
+	dp := mock_dispatcher.NewMockDispatcher(ctrl)
+	dp.EXPECT().HandleTell(gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(_ net.Addr, m proto.Message, _ chan bool) {
+			body, err := proto.Marshal(m)
+			assert.Nil(t, err)
+			assert.Equal(t, msgBody, body)
+			received++
+		}).Times(3)
+	o2.AttachDispatcher(dp)
+	o3.AttachDispatcher(dp)
+	for _, o := range []*Overlay{o1, o2, o3} {
+		o.PRC.Start()
+	}
+
+	defer func() {
+		for _, o := range []*Overlay{o1, o2, o3} {
+			o.PRC.Stop()
+		}
+	}()
+
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o1.PRC.Started() && o2.PRC.Started() && o3.PRC.Started(), nil
+	})
+
+	p2 := o1.PM.GetOrAddPeer("127.0.0.1:10002")
+	assert.NotNil(t, p2)
+	assert.Equal(t, FlateCompression, p2.Compression)
+	p3 := o1.PM.GetOrAddPeer("127.0.0.1:10003")
+	assert.NotNil(t, p3)
+	assert.Equal(t, "", p3.Compression)
+
+	assert.Nil(t, o1.Tell(o2.Self(), msg))
+	assert.Nil(t, o1.Tell(o3.Self(), msg))
+	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return received == 2, nil
+	})
+	assert.Nil(t, err)
+
+	// The uncompressed message is still accepted
+	_, err = p2.Client.Tell(p2.Ctx, &pb.TellReq{
+		Header:  iproto.MagicBroadcastMsgHeader,
+		MsgType: iproto.MsgTxProtoMsgType,
+		MsgBody: msgBody,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, received)
+	_, err = p2.Client.Tell(p2.Ctx, &pb.TellReq{
+		Header:      iproto.MagicBroadcastMsgHeader,
+		MsgType:     iproto.MsgTxProtoMsgType,
+		MsgBody:     msgBody,
+		Compression: "unknown",
+	})
+	assert.NotNil(t, err)
+}
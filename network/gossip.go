@@ -7,32 +7,83 @@
 package network
 
 import (
-	"encoding/hex"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/blake2b"
 
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/common/service"
+	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/dispatch/dispatcher"
 	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	pb1 "github.com/iotexproject/iotex-core/proto"
 )
 
+const (
+	// OthersTopic is the topic of the messages which don't belong to any subscribable topic. They're always delivered
+	OthersTopic = "others"
+
+	defaultBloomFilterSize   = 1 << 20
+	defaultBloomFilterHashes = 7
+)
+
+// TopicOf returns the gossip topic of the given message type
+func TopicOf(msgType uint32) string {
+	switch msgType {
+	case pb1.MsgTxProtoMsgType, pb1.MsgActionType:
+		return config.ActionsTopic
+	case pb1.MsgBlockProtoMsgType:
+		return config.BlocksTopic
+	case pb1.ViewChangeMsgType:
+		return config.ConsensusTopic
+	default:
+		return OthersTopic
+	}
+}
+
+// TopicCounters counts the broadcast messages and bytes of a topic
+type TopicCounters struct {
+	MsgsReceived  uint64
+	BytesReceived uint64
+	MsgsSent      uint64
+	BytesSent     uint64
+}
+
 // Gossip relays messages in the overlay (at least once semantics)
 type Gossip struct {
 	service.CompositeService
 	Overlay     *Overlay
 	Dispatcher  dispatcher.Dispatcher
-	MsgLogs     sync.Map
+	MsgLogs     *RotatingBloomFilter
 	CleanerTask *routine.RecurringTask
+	topics      map[string]bool
+	counters    map[string]*TopicCounters
 }
 
 // NewGossip generates a Gossip instance
 func NewGossip(o *Overlay) *Gossip {
-	g := &Gossip{Overlay: o}
+	size := o.Config.BloomFilterSize
+	if size == 0 {
+		size = defaultBloomFilterSize
+	}
+	hashes := o.Config.BloomFilterHashes
+	if hashes == 0 {
+		hashes = defaultBloomFilterHashes
+	}
+	g := &Gossip{
+		Overlay:  o,
+		MsgLogs:  NewRotatingBloomFilter(size, hashes),
+		topics:   make(map[string]bool),
+		counters: make(map[string]*TopicCounters),
+	}
+	for _, topic := range o.Config.Topics {
+		g.topics[topic] = true
+	}
+	for _, topic := range []string{config.ActionsTopic, config.BlocksTopic, config.ConsensusTopic, OthersTopic} {
+		g.counters[topic] = &TopicCounters{}
+	}
 	cleaner := NewMsgLogsCleaner(g)
 	cleanerTask :=
 		routine.NewRecurringTask(cleaner, o.Config.MsgLogsCleaningInterval)
@@ -46,15 +97,43 @@ func (g *Gossip) AttachDispatcher(dispatcher dispatcher.Dispatcher) {
 	g.Dispatcher = dispatcher
 }
 
+// Subscribes returns true if the node subscribes to the topic
+func (g *Gossip) Subscribes(topic string) bool {
+	return topic == OthersTopic || len(g.topics) == 0 || g.topics[topic]
+}
+
+// Counters returns a snapshot of the counters of the topic
+func (g *Gossip) Counters(topic string) TopicCounters {
+	c, ok := g.counters[topic]
+	if !ok {
+		return TopicCounters{}
+	}
+	return TopicCounters{
+		MsgsReceived:  atomic.LoadUint64(&c.MsgsReceived),
+		BytesReceived: atomic.LoadUint64(&c.BytesReceived),
+		MsgsSent:      atomic.LoadUint64(&c.MsgsSent),
+		BytesSent:     atomic.LoadUint64(&c.BytesSent),
+	}
+}
+
 // OnReceivingMsg listens to and handles the incoming broadcast message
 func (g *Gossip) OnReceivingMsg(msg *pb.BroadcastReq) error {
+	topic := TopicOf(msg.MsgType)
+	c := g.counters[topic]
+	atomic.AddUint64(&c.MsgsReceived, 1)
+	atomic.AddUint64(&c.BytesReceived, uint64(len(msg.MsgBody)))
 	checksum := g.getBroadcastMsgChecksum(msg.MsgBody)
-	_, ok := g.MsgLogs.Load(checksum)
-	if ok {
+	// Check and record the message
+	if g.MsgLogs.CheckAndAdd(checksum[:]) {
+		return nil
+	}
+	if !g.Subscribes(topic) {
+		logger.Debug().
+			Str("name", g.Overlay.PRC.String()).
+			Str("topic", topic).
+			Msg("drop the message of the unsubscribed topic")
 		return nil
 	}
-	// Record the message
-	g.storeBroadcastMsgChecksum(checksum)
 	// Call dispatch to notify that a new message comes in
 	err := g.processMsg(msg.MsgType, msg.MsgBody)
 	if err != nil {
@@ -87,23 +166,49 @@ func (g *Gossip) processMsg(msgType uint32, msgBody []byte) error {
 }
 
 func (g *Gossip) relayMsg(msgType uint32, msgBody []byte, ttl uint32) error {
-	// Send the message to all neighbors
+	topic := TopicOf(msgType)
+	// Only relay to the neighbors who subscribe to the topic
+	peers := []*Peer{}
 	g.Overlay.PM.Peers.Range(func(_, value interface{}) bool {
-		go func() {
-			value.(*Peer).BroadcastMsg(&pb.BroadcastReq{MsgType: msgType, MsgBody: msgBody, Ttl: ttl})
-		}()
+		if peerSubscribes(value.(*Peer), topic) {
+			peers = append(peers, value.(*Peer))
+		}
 		return true
 	})
+	peersAreShuffled(peers)
+	if fanout := int(g.Overlay.Config.GossipFanout); fanout > 0 && len(peers) > fanout {
+		peers = peers[:fanout]
+	}
+	c := g.counters[topic]
+	for _, peer := range peers {
+		atomic.AddUint64(&c.MsgsSent, 1)
+		atomic.AddUint64(&c.BytesSent, uint64(len(msgBody)))
+		go func(p *Peer) {
+			p.BroadcastMsg(&pb.BroadcastReq{MsgType: msgType, MsgBody: msgBody, Ttl: ttl})
+		}(peer)
+	}
 	return nil
 }
 
-func (g *Gossip) getBroadcastMsgChecksum(msgBody []byte) string {
-	b := blake2b.Sum256(msgBody)
-	return hex.EncodeToString(b[:])
+func (g *Gossip) getBroadcastMsgChecksum(msgBody []byte) [32]byte {
+	return blake2b.Sum256(msgBody)
 }
 
-func (g *Gossip) storeBroadcastMsgChecksum(checksum string) {
-	g.MsgLogs.Store(checksum, time.Now())
+func (g *Gossip) storeBroadcastMsgChecksum(checksum [32]byte) {
+	g.MsgLogs.Add(checksum[:])
+}
+
+// peerSubscribes returns true if the peer subscribes to the topic, or its subscriptions are unknown
+func peerSubscribes(p *Peer, topic string) bool {
+	if topic == OthersTopic || p.Identity == nil || len(p.Identity.Topics) == 0 {
+		return true
+	}
+	for _, t := range p.Identity.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
 }
 
 // MsgLogsCleaner periodically refreshes the recent received message log
@@ -117,16 +222,10 @@ func NewMsgLogsCleaner(g *Gossip) *MsgLogsCleaner {
 	return c
 }
 
-// Do log cleaning
+// Do rotates the message log once it has been kept for the retention period, so that a message is remembered for one
+// to two retention periods
 func (c *MsgLogsCleaner) Do() {
-	keys := []string{}
-	c.G.MsgLogs.Range(func(key, value interface{}) bool {
-		if time.Since(value.(time.Time)) > c.G.Overlay.Config.MsgLogRetention {
-			keys = append(keys, key.(string))
-		}
-		return true
-	})
-	for _, key := range keys {
-		c.G.MsgLogs.Delete(key)
+	if time.Since(c.G.MsgLogs.RotatedAt()) >= c.G.Overlay.Config.MsgLogRetention {
+		c.G.MsgLogs.Rotate()
 	}
 }
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+func TestTopicOf(t *testing.T) {
+	assert.Equal(t, config.ActionsTopic, TopicOf(iproto.MsgTxProtoMsgType))
+	assert.Equal(t, config.ActionsTopic, TopicOf(iproto.MsgActionType))
+	assert.Equal(t, config.BlocksTopic, TopicOf(iproto.MsgBlockProtoMsgType))
+	assert.Equal(t, config.ConsensusTopic, TopicOf(iproto.ViewChangeMsgType))
+	assert.Equal(t, OthersTopic, TopicOf(iproto.MsgBlockSyncReqType))
+}
+
+func TestGossipSubscription(t *testing.T) {
+	cfg := LoadTestConfig("", true)
+	cfg.Topics = []string{config.ActionsTopic, config.BlocksTopic}
+	o := NewOverlay(cfg)
+	dp := &MockDispatcher1{}
+	o.AttachDispatcher(dp)
+	assert.True(t, o.Gossip.Subscribes(config.ActionsTopic))
+	assert.False(t, o.Gossip.Subscribes(config.ConsensusTopic))
+	assert.True(t, o.Gossip.Subscribes(OthersTopic))
+
+	msgBody, err := proto.Marshal(&iproto.ViewChangeMsg{SenderAddr: "127.0.0.1:10001"})
+	assert.Nil(t, err)
+	req := &pb.BroadcastReq{MsgType: iproto.ViewChangeMsgType, MsgBody: msgBody, Ttl: 3}
+	assert.Nil(t, o.Gossip.OnReceivingMsg(req))
+	assert.Nil(t, o.Gossip.OnReceivingMsg(req))
+	assert.Equal(t, uint32(0), dp.Count)
+	c := o.Gossip.Counters(config.ConsensusTopic)
+	assert.Equal(t, uint64(2), c.MsgsReceived)
+	assert.Equal(t, uint64(2*len(msgBody)), c.BytesReceived)
+
+	msgBody, err = proto.Marshal(&iproto.TxPb{Version: 1})
+	assert.Nil(t, err)
+	req = &pb.BroadcastReq{MsgType: iproto.MsgTxProtoMsgType, MsgBody: msgBody, Ttl: 3}
+	assert.Nil(t, o.Gossip.OnReceivingMsg(req))
+	// The duplicate is not dispatched again
+	assert.Nil(t, o.Gossip.OnReceivingMsg(req))
+	assert.Equal(t, uint32(1), dp.Count)
+
+	p := NewTCPPeer("127.0.0.1:10001")
+	assert.True(t, peerSubscribes(p, config.ConsensusTopic))
+	p.Identity = &PeerIdentity{Topics: cfg.Topics}
+	assert.True(t, peerSubscribes(p, config.BlocksTopic))
+	assert.False(t, peerSubscribes(p, config.ConsensusTopic))
+	assert.True(t, peerSubscribes(p, OthersTopic))
+}
+
+func TestGossipFanout(t *testing.T) {
+	cfg := LoadTestConfig("", true)
+	cfg.GossipFanout = 2
+	o := NewOverlay(cfg)
+	o2 := NewOverlay(LoadTestConfig("", true))
+	dp := &MockDispatcher1{}
+	o2.AttachDispatcher(dp)
+	o2.PRC.Start()
+	defer o2.PRC.Stop()
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o2.PRC.Started(), nil })
+
+	// All the peers point to the same node, and one of them doesn't subscribe to the blocks
+	for i := 0; i < 4; i++ {
+		p := NewTCPPeer(o2.PRC.String())
+		assert.Nil(t, p.Connect(cfg))
+		defer p.Close()
+		assert.Nil(t, o.handshake(p))
+		if i == 0 {
+			p.Identity = &PeerIdentity{Topics: []string{config.ActionsTopic}}
+		}
+		o.PM.Peers.Store(fmt.Sprintf("peer%d", i), p)
+	}
+
+	msgBody, err := proto.Marshal(&iproto.BlockPb{})
+	assert.Nil(t, err)
+	assert.Nil(t, o.Broadcast(&iproto.BlockPb{}))
+	c := o.Gossip.Counters(config.BlocksTopic)
+	assert.Equal(t, uint64(2), c.MsgsSent)
+	assert.Equal(t, uint64(2*len(msgBody)), c.BytesSent)
+
+	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o2.Gossip.Counters(config.BlocksTopic).MsgsReceived == 2, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), dp.Count)
+
+	cfg.GossipFanout = 0
+	assert.Nil(t, o.Broadcast(&iproto.BlockPb{Header: &iproto.BlockHeaderPb{Version: 1}}))
+	assert.Equal(t, uint64(5), o.Gossip.Counters(config.BlocksTopic).MsgsSent)
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // HealthChecker will check its peers at constant interval. If a peer is found not reachable for given period, it would
-// be removed from the peer list
+// be removed from the peer list, and so would the handshakes of the clients silent for the period
 type HealthChecker struct {
 	Overlay        *Overlay
 	SilentInterval time.Duration
@@ -36,4 +36,5 @@ func (hc *HealthChecker) Do() {
 	for _, addr := range addrs {
 		go hc.Overlay.PM.RemovePeer(addr)
 	}
+	hc.Overlay.PRC.pruneHandshakes(hc.SilentInterval)
 }
@@ -0,0 +1,288 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+)
+
+const (
+	// ProtocolVersion is the version of the P2P protocol that this node speaks
+	ProtocolVersion = uint32(1)
+	// MinProtocolVersion is the lowest version of the P2P protocol that this node could talk to
+	MinProtocolVersion = uint32(1)
+)
+
+var (
+	// ErrNoIdentity means the node doesn't have an identity key to handshake with others
+	ErrNoIdentity = errors.New("node doesn't have an identity")
+	// ErrInvalidHandshake means the handshake message is malformed or its signature doesn't match the key
+	ErrInvalidHandshake = errors.New("invalid handshake")
+	// ErrChainIDMismatch means the peer is on a different chain
+	ErrChainIDMismatch = errors.New("peer is on a different chain")
+	// ErrIncompatibleVersion means the peer speaks an incompatible P2P protocol version
+	ErrIncompatibleVersion = errors.New("peer has an incompatible protocol version")
+	// ErrNoHandshake means the peer hasn't completed the handshake on the connection
+	ErrNoHandshake = errors.New("peer hasn't completed the handshake")
+	// ErrIdentityKeyExposed means the identity key file is accessible by others than its owner
+	ErrIdentityKeyExposed = errors.New("identity key file is accessible by others")
+)
+
+// PeerIdentity is what a peer has proved about itself in the handshake
+type PeerIdentity struct {
+	// PublicKey is the identity key of the peer
+	PublicKey []byte
+	// RawAddress is the iotex address derived from the public key
+	RawAddress string
+	ChainID    uint32
+	Version    uint32
+	// TipHeight is the peer's tip height at the time of the handshake
+	TipHeight uint64
+	// Topics are the gossip topics that the peer subscribes to. Empty means all topics
+	Topics []string
+	// Compressions are the compression algorithms that the peer supports
+	Compressions []string
+}
+
+// PublicKey returns the public key of the node at the given address. The key is known only if this node has completed
+// a handshake with it, in which the node at that address proved the ownership of the key.
+func (o *Overlay) PublicKey(addr string) ([]byte, bool) {
+	if addr == o.PRC.String() {
+		if o.Identity == nil {
+			return nil, false
+		}
+		return o.Identity.PublicKey, true
+	}
+	id, ok := o.Identities.Load(addr)
+	if !ok {
+		return nil, false
+	}
+	return id.(*PeerIdentity).PublicKey, true
+}
+
+// handshake runs the handshake with a connected peer and records the identity it proves. Only identities which are
+// verified on the outgoing connections are recorded, because the address claimed by an incoming one can't be trusted.
+func (o *Overlay) handshake(p *Peer) error {
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	req, err := o.newHandshake(nonce, 0)
+	if err != nil {
+		return err
+	}
+	res, err := p.Handshake(req)
+	if err != nil {
+		return err
+	}
+	if res.AckNonce != nonce {
+		return errors.Wrapf(ErrInvalidHandshake, "ack nonce %d doesn't match %d", res.AckNonce, nonce)
+	}
+	id, err := o.verifyHandshake(res)
+	if err != nil {
+		return err
+	}
+	// answer the server's challenge, so that the server records the identity on this connection
+	confirm := &pb.HandshakeConfirm{Nonce: nonce, AckNonce: res.Nonce}
+	hash := handshakeConfirmHash(confirm, req.Addr, res.Addr)
+	if confirm.Signature = cp.Sign(o.Identity.PrivateKey, hash[:]); confirm.Signature == nil {
+		return errors.Wrap(ErrInvalidHandshake, "failed to sign the handshake confirmation")
+	}
+	if _, err := p.ConfirmHandshake(confirm); err != nil {
+		return err
+	}
+	p.Identity = id
+	p.Compression = o.negotiateCompression(id.Compressions)
+	p.CompressionThreshold = o.Config.CompressionThreshold
+	o.Identities.Store(p.String(), id)
+	logger.Debug().
+		Str("addr", p.String()).
+		Str("iotxAddr", id.RawAddress).
+		Uint64("tipHeight", id.TipHeight).
+		Str("compression", p.Compression).
+		Msg("Completed the handshake")
+	return nil
+}
+
+// newHandshake creates a handshake message signed by this node's identity key
+func (o *Overlay) newHandshake(nonce uint64, ackNonce uint64) (*pb.Handshake, error) {
+	if o.Identity == nil {
+		return nil, ErrNoIdentity
+	}
+	hs := &pb.Handshake{
+		ChainId:      o.Config.ChainID,
+		Version:      ProtocolVersion,
+		Addr:         o.PRC.String(),
+		PubKey:       o.Identity.PublicKey,
+		Nonce:        nonce,
+		AckNonce:     ackNonce,
+		Topics:       o.Config.Topics,
+		Compressions: o.compressions(),
+	}
+	if o.Blockchain != nil {
+		height, err := o.Blockchain.TipHeight()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the tip height")
+		}
+		hs.TipHeight = height
+	}
+	hash := handshakeHash(hs)
+	hs.Signature = cp.Sign(o.Identity.PrivateKey, hash[:])
+	if hs.Signature == nil {
+		return nil, errors.Wrap(ErrInvalidHandshake, "failed to sign the handshake")
+	}
+	return hs, nil
+}
+
+// verifyHandshake checks the signature of the handshake message and whether the sender is compatible with this node
+func (o *Overlay) verifyHandshake(hs *pb.Handshake) (*PeerIdentity, error) {
+	if hs == nil || len(hs.PubKey) == 0 || len(hs.Signature) == 0 {
+		return nil, errors.Wrap(ErrInvalidHandshake, "missing public key or signature")
+	}
+	hash := handshakeHash(hs)
+	if !cp.Verify(hs.PubKey, hash[:], hs.Signature) {
+		return nil, errors.Wrapf(ErrInvalidHandshake, "signature doesn't match the key of %s", hs.Addr)
+	}
+	if hs.ChainId != o.Config.ChainID {
+		return nil, errors.Wrapf(ErrChainIDMismatch, "peer %s is on chain %d, but expecting %d",
+			hs.Addr, hs.ChainId, o.Config.ChainID)
+	}
+	if hs.Version < MinProtocolVersion {
+		return nil, errors.Wrapf(ErrIncompatibleVersion, "peer %s speaks version %d, but expecting at least %d",
+			hs.Addr, hs.Version, MinProtocolVersion)
+	}
+	addr, err := iotxaddress.GetAddress(hs.PubKey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidHandshake, "failed to derive the address of %s: %v", hs.Addr, err)
+	}
+	return &PeerIdentity{
+		PublicKey:    hs.PubKey,
+		RawAddress:   addr.RawAddress,
+		ChainID:      hs.ChainId,
+		Version:      hs.Version,
+		TipHeight:    hs.TipHeight,
+		Topics:       hs.Topics,
+		Compressions: hs.Compressions,
+	}, nil
+}
+
+// loadIdentityKey loads the identity key pair from the key file, or keeps the given one in a new key file which only its
+// owner could access if there isn't any yet. It refuses a key file which others could access.
+func loadIdentityKey(path string, id *iotxaddress.Address) (*iotxaddress.Address, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		if err := saveIdentityKey(path, id); err != nil {
+			return nil, err
+		}
+		return id, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat the identity key file")
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return nil, errors.Wrapf(ErrIdentityKeyExposed, "%s has permissions %s", path, perm)
+	}
+	value, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the identity key file")
+	}
+	persisted := &pb.NodeIdentity{}
+	if err := proto.Unmarshal(value, persisted); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the identity")
+	}
+	return newIdentity(persisted.PubKey, persisted.PriKey)
+}
+
+// saveIdentityKey writes the identity key pair into a new key file which only its owner could access
+func saveIdentityKey(path string, id *iotxaddress.Address) error {
+	if id == nil {
+		return ErrNoIdentity
+	}
+	value, err := proto.Marshal(&pb.NodeIdentity{PubKey: id.PublicKey, PriKey: id.PrivateKey})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the identity")
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the identity key file")
+	}
+	if _, err := file.Write(value); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to write the identity key file")
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to sync the identity key file")
+	}
+	return errors.Wrap(file.Close(), "failed to close the identity key file")
+}
+
+// newIdentity returns the identity of the given key pair
+func newIdentity(pubKey []byte, priKey []byte) (*iotxaddress.Address, error) {
+	id, err := iotxaddress.GetAddress(pubKey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive the address of the identity")
+	}
+	id.PrivateKey = priKey
+	return id, nil
+}
+
+// newNonce returns a random nonce, which the peer can't predict to replay a handshake signed before
+func newNonce() (uint64, error) {
+	var nonce [8]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return 0, errors.Wrap(err, "failed to generate the nonce")
+	}
+	return cm.MachineEndian.Uint64(nonce[:]), nil
+}
+
+// handshakeHash returns the hash of all the handshake fields except the signature
+func handshakeHash(hs *pb.Handshake) [32]byte {
+	var stream bytes.Buffer
+	stream.Write(utils.Uint32ToBytes(hs.ChainId))
+	stream.Write(utils.Uint32ToBytes(hs.Version))
+	stream.Write(utils.Uint64ToBytes(hs.TipHeight))
+	stream.Write(utils.Uint64ToBytes(hs.Nonce))
+	stream.Write(utils.Uint64ToBytes(hs.AckNonce))
+	stream.Write(hs.PubKey)
+	stream.WriteString(hs.Addr)
+	for _, topic := range hs.Topics {
+		stream.WriteByte(0)
+		stream.WriteString(topic)
+	}
+	for _, compression := range hs.Compressions {
+		stream.WriteByte(1)
+		stream.WriteString(compression)
+	}
+	return blake2b.Sum256(stream.Bytes())
+}
+
+// handshakeConfirmHash returns the hash of the handshake confirmation except the signature, bound to the addresses of
+// the client and the server, so that the challenge of a server can't be answered on the way to another one
+func handshakeConfirmHash(confirm *pb.HandshakeConfirm, clientAddr string, serverAddr string) [32]byte {
+	var stream bytes.Buffer
+	stream.Write(utils.Uint64ToBytes(confirm.Nonce))
+	stream.Write(utils.Uint64ToBytes(confirm.AckNonce))
+	stream.WriteString(clientAddr)
+	stream.WriteByte(0)
+	stream.WriteString(serverAddr)
+	return blake2b.Sum256(stream.Bytes())
+}
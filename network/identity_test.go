@@ -0,0 +1,114 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+func TestVerifyHandshake(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	o1 := NewOverlay(LoadTestConfig("127.0.0.1:10001", true))
+	bc := mock_blockchain.NewMockBlockchain(ctrl)
+	bc.EXPECT().TipHeight().Return(uint64(10), nil).AnyTimes()
+	o1.AttachBlockchain(bc)
+	o2 := NewOverlay(LoadTestConfig("127.0.0.1:10002", true))
+
+	hs, err := o1.newHandshake(1, 2)
+	assert.Nil(t, err)
+	id, err := o2.verifyHandshake(hs)
+	assert.Nil(t, err)
+	assert.Equal(t, o1.Identity.PublicKey, id.PublicKey)
+	assert.Equal(t, o1.Identity.RawAddress, id.RawAddress)
+	assert.Equal(t, uint64(10), id.TipHeight)
+	assert.Equal(t, ProtocolVersion, id.Version)
+
+	// The signature doesn't cover the tampered field anymore
+	hs.TipHeight = 11
+	_, err = o2.verifyHandshake(hs)
+	assert.Equal(t, ErrInvalidHandshake, errors.Cause(err))
+
+	resign := func() {
+		hash := handshakeHash(hs)
+		hs.Signature = cp.Sign(o1.Identity.PrivateKey, hash[:])
+	}
+	resign()
+	_, err = o2.verifyHandshake(hs)
+	assert.Nil(t, err)
+
+	hs.Version = MinProtocolVersion - 1
+	resign()
+	_, err = o2.verifyHandshake(hs)
+	assert.Equal(t, ErrIncompatibleVersion, errors.Cause(err))
+
+	hs.Version = ProtocolVersion
+	hs.ChainId = o2.Config.ChainID + 1
+	resign()
+	_, err = o2.verifyHandshake(hs)
+	assert.Equal(t, ErrChainIDMismatch, errors.Cause(err))
+
+	o2.Identity = nil
+	_, err = o2.newHandshake(1, 0)
+	assert.Equal(t, ErrNoIdentity, err)
+}
+
+func TestHandshake(t *testing.T) {
+	o1 := NewOverlay(LoadTestConfig("127.0.0.1:10001", true))
+	o2 := NewOverlay(LoadTestConfig("127.0.0.1:10002", true))
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	assert.Nil(t, err)
+	o2.AttachIdentity(id)
+	cfg3 := LoadTestConfig("127.0.0.1:10003", true)
+	cfg3.ChainID = o1.Config.ChainID + 1
+	o3 := NewOverlay(cfg3)
+	for _, o := range []*Overlay{o1, o2, o3} {
+		o.PRC.Start()
+	}
+
+	defer func() {
+		for _, o := range []*Overlay{o1, o2, o3} {
+			o.PRC.Stop()
+		}
+	}()
+
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o1.PRC.Started() && o2.PRC.Started() && o3.PRC.Started(), nil
+	})
+
+	o1.PM.AddPeer("127.0.0.1:10002")
+	value, ok := o1.PM.Peers.Load("127.0.0.1:10002")
+	assert.True(t, ok)
+	assert.Equal(t, id.RawAddress, value.(*Peer).Identity.RawAddress)
+	key, ok := o1.PublicKey("127.0.0.1:10002")
+	assert.True(t, ok)
+	assert.Equal(t, id.PublicKey, key)
+	key, ok = o1.PublicKey("127.0.0.1:10001")
+	assert.True(t, ok)
+	assert.Equal(t, o1.Identity.PublicKey, key)
+
+	// The peers on a different chain are rejected in both directions
+	o1.PM.AddPeer("127.0.0.1:10003")
+	_, ok = o1.PM.Peers.Load("127.0.0.1:10003")
+	assert.False(t, ok)
+	_, ok = o1.PublicKey("127.0.0.1:10003")
+	assert.False(t, ok)
+	o3.PM.AddPeer("127.0.0.1:10001")
+	_, ok = o3.PM.Peers.Load("127.0.0.1:10001")
+	assert.False(t, ok)
+}
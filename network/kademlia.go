@@ -0,0 +1,475 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/service"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+)
+
+var (
+	// ErrInvalidNodeID means the node ID is not of the right length
+	ErrInvalidNodeID = errors.New("Invalid node ID")
+	// ErrKademliaNotStarted means the Kademlia routing table is not available yet
+	ErrKademliaNotStarted = errors.New("Kademlia is not started")
+)
+
+// NodeID is the identifier of a node in the Kademlia key space
+type NodeID [cm.HashSize]byte
+
+// NewNodeID derives the node ID from the identity key of the node, whose ownership the node proves in the handshake
+func NewNodeID(pubKey []byte) NodeID {
+	return NodeID(blake2b.Sum256(pubKey))
+}
+
+// BytesToNodeID converts a byte slice into a node ID
+func BytesToNodeID(b []byte) (NodeID, error) {
+	var id NodeID
+	if len(b) != len(id) {
+		return id, ErrInvalidNodeID
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// Distance returns the XOR distance between two node IDs
+func (id NodeID) Distance(other NodeID) NodeID {
+	var d NodeID
+	for i := range id {
+		d[i] = id[i] ^ other[i]
+	}
+	return d
+}
+
+// commonPrefixLen returns the number of leading bits shared by two node IDs
+func (id NodeID) commonPrefixLen(other NodeID) int {
+	for i := range id {
+		if x := id[i] ^ other[i]; x != 0 {
+			return i*8 + bits.LeadingZeros8(x)
+		}
+	}
+	return len(id) * 8
+}
+
+// contact is an entry in a k-bucket
+type contact struct {
+	ID       NodeID
+	Addr     string
+	LastSeen time.Time
+}
+
+// RoutingTable is the Kademlia routing table. The i-th k-bucket keeps the nodes sharing exactly i leading bits with
+// the node itself, and the contacts in a bucket are ordered from the least recently seen to the most recently seen.
+type RoutingTable struct {
+	mutex      sync.RWMutex
+	self       NodeID
+	bucketSize int
+	buckets    [][]*contact
+}
+
+// NewRoutingTable creates an instance of RoutingTable
+func NewRoutingTable(self NodeID, bucketSize uint) *RoutingTable {
+	return &RoutingTable{
+		self:       self,
+		bucketSize: int(bucketSize),
+		buckets:    make([][]*contact, len(self)*8),
+	}
+}
+
+// Self returns the ID of the node owning the routing table
+func (rt *RoutingTable) Self() NodeID {
+	return rt.self
+}
+
+// Update records that the node of the given ID is seen at the address, which the caller needs to have verified in a
+// handshake. If the bucket is full, the new node is not added, and the address of the least recently seen contact of
+// the bucket is returned with true, so that the caller could check if it is still alive. Keeping the long-lived nodes
+// makes it much harder to flood the table with the new ones.
+func (rt *RoutingTable) Update(id NodeID, addr string) (string, bool) {
+	if id == rt.self {
+		return "", false
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	// The node at the address may have restarted with another identity
+	rt.remove(func(c *contact) bool { return c.Addr == addr && c.ID != id })
+	idx := rt.bucketIndex(id)
+	bucket := rt.buckets[idx]
+	for i, c := range bucket {
+		if c.ID == id {
+			// Move the contact to the tail
+			c.Addr = addr
+			c.LastSeen = time.Now()
+			rt.buckets[idx] = append(append(bucket[:i], bucket[i+1:]...), c)
+			return "", false
+		}
+	}
+	if len(bucket) >= rt.bucketSize {
+		return bucket[0].Addr, true
+	}
+	rt.buckets[idx] = append(bucket, &contact{ID: id, Addr: addr, LastSeen: time.Now()})
+	return "", false
+}
+
+// Remove removes the node at the given address from the routing table
+func (rt *RoutingTable) Remove(addr string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.remove(func(c *contact) bool { return c.Addr == addr })
+}
+
+// Contains checks if the node at the given address is in the routing table
+func (rt *RoutingTable) Contains(addr string) bool {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	for _, bucket := range rt.buckets {
+		for _, c := range bucket {
+			if c.Addr == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Len returns the number of nodes in the routing table
+func (rt *RoutingTable) Len() int {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	n := 0
+	for _, bucket := range rt.buckets {
+		n += len(bucket)
+	}
+	return n
+}
+
+// Closest returns the addresses of at most count nodes which are the closest to the target, sorted by the distance
+func (rt *RoutingTable) Closest(target NodeID, count int) []string {
+	addrs := []string{}
+	for _, c := range rt.closest(target, count) {
+		addrs = append(addrs, c.Addr)
+	}
+	return addrs
+}
+
+// closest returns the copies of at most count contacts which are the closest to the target, sorted by the distance
+func (rt *RoutingTable) closest(target NodeID, count int) []*contact {
+	rt.mutex.RLock()
+	contacts := []*contact{}
+	for _, bucket := range rt.buckets {
+		for _, c := range bucket {
+			copied := *c
+			contacts = append(contacts, &copied)
+		}
+	}
+	rt.mutex.RUnlock()
+	sortByDistance(target, contacts)
+	if len(contacts) > count {
+		contacts = contacts[:count]
+	}
+	return contacts
+}
+
+// Sample returns the addresses of at most count nodes, taken from the buckets in turn, starting from the farthest
+// ones. It spreads the picked nodes all over the key space instead of concentrating on the neighborhood of the node.
+func (rt *RoutingTable) Sample(count int) []string {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	addrs := []string{}
+	for round := 0; round < rt.bucketSize && len(addrs) < count; round++ {
+		for _, bucket := range rt.buckets {
+			if len(addrs) >= count {
+				break
+			}
+			if round < len(bucket) {
+				addrs = append(addrs, bucket[round].Addr)
+			}
+		}
+	}
+	return addrs
+}
+
+// remove removes the contacts matching the filter. It needs to be called with the mutex held.
+func (rt *RoutingTable) remove(match func(*contact) bool) {
+	for idx, bucket := range rt.buckets {
+		kept := bucket[:0]
+		for _, c := range bucket {
+			if !match(c) {
+				kept = append(kept, c)
+			}
+		}
+		rt.buckets[idx] = kept
+	}
+}
+
+func (rt *RoutingTable) bucketIndex(id NodeID) int {
+	idx := rt.self.commonPrefixLen(id)
+	if idx >= len(rt.buckets) {
+		idx = len(rt.buckets) - 1
+	}
+	return idx
+}
+
+func sortByDistance(target NodeID, contacts []*contact) {
+	sort.Slice(contacts, func(i, j int) bool {
+		di := target.Distance(contacts[i].ID)
+		dj := target.Distance(contacts[j].ID)
+		return bytes.Compare(di[:], dj[:]) < 0
+	})
+}
+
+// Kademlia finds peers through iterative FIND_NODE lookups on the Kademlia DHT
+type Kademlia struct {
+	service.AbstractService
+	Overlay    *Overlay
+	Table      *RoutingTable
+	BucketSize uint
+	Alpha      uint
+	// probing are the addresses claimed by the requesters, which are being verified before being recorded
+	probing sync.Map
+}
+
+// NewKademlia creates an instance of Kademlia
+func NewKademlia(o *Overlay) *Kademlia {
+	return &Kademlia{Overlay: o, BucketSize: o.Config.KadBucketSize, Alpha: o.Config.KadAlpha}
+}
+
+// Start creates the routing table. It needs to happen after the identity of the node is loaded, as the node ID is
+// derived from its key.
+func (k *Kademlia) Start() error {
+	if k.Overlay.Identity == nil {
+		return ErrNoIdentity
+	}
+	k.Table = NewRoutingTable(NewNodeID(k.Overlay.Identity.PublicKey), k.BucketSize)
+	return nil
+}
+
+// Do refreshes the routing table and keeps the number of peers within the bounds. If the node doesn't know any one
+// yet, it bootstraps from the known peers and the configured nodes, which are recorded once they are verified. It then
+// looks up itself to learn about its neighborhood, plus a random ID to refresh a random part of the key space.
+func (k *Kademlia) Do() {
+	if k.Table == nil {
+		return
+	}
+	if k.Table.Len() == 0 {
+		for _, addr := range append(k.Overlay.PeerStore.Prioritized(), k.Overlay.Config.BootstrapNodes...) {
+			if _, _, err := k.findNode(addr, k.Table.Self()); err != nil {
+				logger.Debug().Err(err).Str("addr", addr).Msg("error when bootstrapping from node")
+			}
+		}
+	}
+	k.Lookup(k.Table.Self())
+	var random NodeID
+	if _, err := rand.Read(random[:]); err == nil {
+		k.Lookup(random)
+	}
+
+	count := uint(0)
+	k.Overlay.PM.Peers.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	if count < k.Overlay.PM.NumPeersLowerBound {
+		for _, addr := range k.Table.Sample(int(k.Overlay.PM.NumPeersUpperBound)) {
+			k.Overlay.PM.AddPeer(addr)
+		}
+	}
+	for ; count > k.Overlay.PM.NumPeersUpperBound; count-- {
+		k.Overlay.PM.RemoveLRUPeer()
+	}
+}
+
+// Lookup iteratively queries the closest nodes known so far, alpha of them at a time, for the nodes closer to the
+// target, until the closest nodes have all been queried. It returns the addresses of the closest nodes found.
+func (k *Kademlia) Lookup(target NodeID) []string {
+	if k.Table == nil {
+		return nil
+	}
+	self := k.Overlay.PRC.String()
+	shortlist := k.Table.closest(target, int(k.BucketSize))
+	seen := map[string]bool{self: true}
+	for _, c := range shortlist {
+		seen[c.Addr] = true
+	}
+	queried := map[string]bool{}
+	responded := map[string]bool{}
+	type result struct {
+		addr  string
+		id    NodeID
+		found []*contact
+	}
+	for {
+		batch := []string{}
+		for _, c := range shortlist {
+			if uint(len(batch)) >= k.Alpha {
+				break
+			}
+			if !queried[c.Addr] {
+				batch = append(batch, c.Addr)
+			}
+		}
+		if len(batch) == 0 {
+			break
+		}
+		results := make(chan *result, len(batch))
+		for _, addr := range batch {
+			queried[addr] = true
+			go func(addr string) {
+				id, found, err := k.findNode(addr, target)
+				if err != nil {
+					logger.Debug().Err(err).Str("addr", addr).Msg("error when finding node")
+					k.Table.Remove(addr)
+					results <- nil
+					return
+				}
+				results <- &result{addr: addr, id: id, found: found}
+			}(addr)
+		}
+		for range batch {
+			r := <-results
+			if r == nil {
+				continue
+			}
+			responded[r.addr] = true
+			// The ID claimed by another node is replaced by the one verified in the handshake
+			for _, c := range shortlist {
+				if c.Addr == r.addr {
+					c.ID = r.id
+				}
+			}
+			for _, c := range r.found {
+				if !seen[c.Addr] {
+					seen[c.Addr] = true
+					shortlist = append(shortlist, c)
+				}
+			}
+		}
+		// Drop the nodes failing to respond, and keep the closest k ones
+		alive := []*contact{}
+		for _, c := range shortlist {
+			if !queried[c.Addr] || responded[c.Addr] {
+				alive = append(alive, c)
+			}
+		}
+		sortByDistance(target, alive)
+		if len(alive) > int(k.BucketSize) {
+			alive = alive[:k.BucketSize]
+		}
+		shortlist = alive
+	}
+	addrs := []string{}
+	for _, c := range shortlist {
+		if responded[c.Addr] {
+			addrs = append(addrs, c.Addr)
+		}
+	}
+	return addrs
+}
+
+// OnFindNode handles the incoming FIND_NODE request. The address claimed by the requester is recorded only after the
+// node at it completes a handshake.
+func (k *Kademlia) OnFindNode(req *pb.FindNodeReq) (*pb.FindNodeRes, error) {
+	if k.Table == nil {
+		return nil, ErrKademliaNotStarted
+	}
+	target, err := BytesToNodeID(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if req.Addr != "" && !k.Table.Contains(req.Addr) {
+		k.probe(req.Addr)
+	}
+	res := &pb.FindNodeRes{}
+	for _, c := range k.Table.closest(target, int(k.BucketSize)+1) {
+		if c.Addr != req.Addr && uint(len(res.Addr)) < k.BucketSize {
+			res.Addr = append(res.Addr, c.Addr)
+			res.Id = append(res.Id, c.ID[:])
+		}
+	}
+	return res, nil
+}
+
+// probe verifies the node at the address in the background, which records it on success
+func (k *Kademlia) probe(addr string) {
+	if _, ok := k.probing.LoadOrStore(addr, true); ok {
+		return
+	}
+	go func() {
+		defer k.probing.Delete(addr)
+		if _, _, err := k.findNode(addr, k.Table.Self()); err != nil {
+			logger.Debug().Err(err).Str("addr", addr).Msg("error when probing node")
+		}
+	}()
+}
+
+// seen updates the routing table with the node which is just verified. If the bucket is full, the least recently seen
+// node is checked, and it will be replaced only if it doesn't respond.
+func (k *Kademlia) seen(id NodeID, addr string) {
+	oldest, full := k.Table.Update(id, addr)
+	if !full {
+		return
+	}
+	go func() {
+		// The oldest node responding is moved to the tail by findNode
+		if _, _, err := k.findNode(oldest, k.Table.Self()); err != nil {
+			k.Table.Remove(oldest)
+			k.Table.Update(id, addr)
+		}
+	}()
+}
+
+// findNode sends the FIND_NODE request to the node at the given address, and records the node once it's verified. The
+// node isn't necessarily a peer, so a temporary connection is made and handshaked if needed. It returns the ID of the
+// node verified, together with the nodes it returns, whose IDs are only claimed by it.
+func (k *Kademlia) findNode(addr string, target NodeID) (NodeID, []*contact, error) {
+	var p *Peer
+	if value, ok := k.Overlay.PM.Peers.Load(addr); ok {
+		p = value.(*Peer)
+	} else {
+		p = NewTCPPeer(addr)
+		if err := p.Dial(k.Overlay.PRC.Transport); err != nil {
+			return NodeID{}, nil, err
+		}
+		defer p.Close()
+		if err := k.Overlay.handshake(p); err != nil {
+			return NodeID{}, nil, err
+		}
+	}
+	if p.Identity == nil {
+		return NodeID{}, nil, errors.Wrapf(ErrNoHandshake, "node %s", addr)
+	}
+	id := NewNodeID(p.Identity.PublicKey)
+	res, err := p.FindNode(&pb.FindNodeReq{Target: target[:], Addr: k.Overlay.PRC.String()})
+	if err != nil {
+		return NodeID{}, nil, err
+	}
+	if len(res.Id) != len(res.Addr) {
+		return NodeID{}, nil, errors.Wrapf(ErrInvalidNodeID, "%d IDs for %d nodes from %s", len(res.Id), len(res.Addr), addr)
+	}
+	found := make([]*contact, 0, len(res.Addr))
+	for i, foundAddr := range res.Addr {
+		foundID, err := BytesToNodeID(res.Id[i])
+		if err != nil {
+			return NodeID{}, nil, err
+		}
+		found = append(found, &contact{ID: foundID, Addr: foundAddr})
+	}
+	k.seen(id, addr)
+	return id, found, nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotexproject/iotex-core/config"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+// testNodeID derives a node ID for the tests from the address instead of a key
+func testNodeID(addr string) NodeID {
+	return NewNodeID([]byte(addr))
+}
+
+func TestNodeID(t *testing.T) {
+	id1 := NewNodeID([]byte{1, 2, 3})
+	id2 := NewNodeID([]byte{4, 5, 6})
+	assert.Equal(t, id1, NewNodeID([]byte{1, 2, 3}))
+	assert.NotEqual(t, id1, id2)
+	assert.Equal(t, NodeID{}, id1.Distance(id1))
+	assert.Equal(t, id1.Distance(id2), id2.Distance(id1))
+	assert.Equal(t, 256, id1.commonPrefixLen(id1))
+
+	id3, err := BytesToNodeID(id1[:])
+	assert.Nil(t, err)
+	assert.Equal(t, id1, id3)
+	_, err = BytesToNodeID([]byte{1, 2, 3})
+	assert.Equal(t, ErrInvalidNodeID, err)
+}
+
+func TestRoutingTable(t *testing.T) {
+	self := testNodeID("127.0.0.1:10000")
+	rt := NewRoutingTable(self, 2)
+
+	// The node itself is never added
+	_, full := rt.Update(self, "127.0.0.1:10000")
+	assert.False(t, full)
+	assert.Equal(t, 0, rt.Len())
+
+	// Find 3 addresses which fall into the same bucket
+	addrs := []string{}
+	for i := 10001; len(addrs) < 3; i++ {
+		addr := fmt.Sprintf("127.0.0.1:%d", i)
+		if self.commonPrefixLen(testNodeID(addr)) == 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	_, full = rt.Update(testNodeID(addrs[0]), addrs[0])
+	assert.False(t, full)
+	_, full = rt.Update(testNodeID(addrs[1]), addrs[1])
+	assert.False(t, full)
+	assert.Equal(t, 2, rt.Len())
+
+	// The bucket is full, so the least recently seen one is returned
+	oldest, full := rt.Update(testNodeID(addrs[2]), addrs[2])
+	assert.True(t, full)
+	assert.Equal(t, addrs[0], oldest)
+	assert.False(t, rt.Contains(addrs[2]))
+
+	// Seeing the first node again moves it to the tail
+	rt.Update(testNodeID(addrs[0]), addrs[0])
+	oldest, full = rt.Update(testNodeID(addrs[2]), addrs[2])
+	assert.True(t, full)
+	assert.Equal(t, addrs[1], oldest)
+
+	rt.Remove(addrs[1])
+	assert.False(t, rt.Contains(addrs[1]))
+	_, full = rt.Update(testNodeID(addrs[2]), addrs[2])
+	assert.False(t, full)
+	assert.True(t, rt.Contains(addrs[2]))
+	assert.Equal(t, 2, rt.Len())
+
+	// The node moving to another address, or the address taken by another node, replaces the contact
+	rt.Update(testNodeID(addrs[2]), addrs[1])
+	assert.False(t, rt.Contains(addrs[2]))
+	assert.True(t, rt.Contains(addrs[1]))
+	rt.Update(testNodeID(addrs[0]), addrs[1])
+	assert.Equal(t, 1, rt.Len())
+	assert.Equal(t, []string{addrs[1]}, rt.Closest(testNodeID(addrs[2]), 2))
+}
+
+func TestRoutingTableClosest(t *testing.T) {
+	self := testNodeID("127.0.0.1:10000")
+	rt := NewRoutingTable(self, 16)
+	for i := 10001; i <= 10100; i++ {
+		addr := fmt.Sprintf("127.0.0.1:%d", i)
+		rt.Update(testNodeID(addr), addr)
+	}
+	target := testNodeID("127.0.0.1:10050")
+	closest := rt.Closest(target, 10)
+	assert.Equal(t, 10, len(closest))
+	assert.Equal(t, "127.0.0.1:10050", closest[0])
+	for i := 1; i < len(closest); i++ {
+		d1 := target.Distance(testNodeID(closest[i-1]))
+		d2 := target.Distance(testNodeID(closest[i]))
+		assert.True(t, bytes.Compare(d1[:], d2[:]) < 0)
+	}
+
+	sample := rt.Sample(5)
+	assert.Equal(t, 5, len(sample))
+	assert.Equal(t, 0, self.commonPrefixLen(testNodeID(sample[0])))
+}
+
+func TestKademliaOnFindNode(t *testing.T) {
+	o := NewOverlay(LoadTestConfig("127.0.0.1:10000", true))
+	k := NewKademlia(o)
+	k.BucketSize = 4
+	_, err := k.OnFindNode(&pb.FindNodeReq{})
+	assert.Equal(t, ErrKademliaNotStarted, err)
+
+	assert.Nil(t, k.Start())
+	for i := 10001; i <= 10010; i++ {
+		addr := fmt.Sprintf("127.0.0.1:%d", i)
+		k.Table.Update(testNodeID(addr), addr)
+	}
+	_, err = k.OnFindNode(&pb.FindNodeReq{Target: []byte{1}})
+	assert.Equal(t, ErrInvalidNodeID, err)
+
+	target := testNodeID("127.0.0.1:10005")
+	res, err := k.OnFindNode(&pb.FindNodeReq{Target: target[:], Addr: "127.0.0.1:10005"})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(res.Addr))
+	assert.NotContains(t, res.Addr, "127.0.0.1:10005")
+	for i, addr := range res.Addr {
+		id := testNodeID(addr)
+		assert.Equal(t, id[:], res.Id[i])
+	}
+
+	// The requester isn't recorded, as there is no node at the address it claims to verify
+	_, err = k.OnFindNode(&pb.FindNodeReq{Target: target[:], Addr: "127.0.0.1:10011"})
+	assert.Nil(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, k.Table.Contains("127.0.0.1:10011"))
+}
+
+func TestKademliaVerifiedContacts(t *testing.T) {
+	o1 := NewOverlay(LoadTestConfig("127.0.0.1:10001", true))
+	o2 := NewOverlay(LoadTestConfig("127.0.0.1:10002", true))
+	k1 := NewKademlia(o1)
+	k2 := NewKademlia(o2)
+	k1.BucketSize = 4
+	k2.BucketSize = 4
+	o1.Kad = k1
+	o2.Kad = k2
+	for _, o := range []*Overlay{o1, o2} {
+		o.PRC.Start()
+	}
+
+	defer func() {
+		for _, o := range []*Overlay{o1, o2} {
+			o.PRC.Stop()
+		}
+	}()
+
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o1.PRC.Started() && o2.PRC.Started(), nil
+	})
+	assert.Nil(t, k1.Start())
+	assert.Nil(t, k2.Start())
+
+	// The node found is recorded under the ID of the key it proves in the handshake
+	id, _, err := k1.findNode(o2.PRC.String(), k1.Table.Self())
+	assert.Nil(t, err)
+	assert.Equal(t, NewNodeID(o2.Identity.PublicKey), id)
+	assert.Equal(t, []string{o2.PRC.String()}, k1.Table.Closest(id, 1))
+
+	// and so is the requester, once it's verified by the node
+	err = util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return k2.Table.Contains(o1.PRC.String()), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{o1.PRC.String()}, k2.Table.Closest(NewNodeID(o1.Identity.PublicKey), 1))
+}
+
+func TestKademliaDiscovery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping the kademlia test in short mode.")
+	}
+	size := 16
+	nodes := []*Overlay{}
+	for i := 0; i < size; i++ {
+		var cfg *config.Network
+		if i == 0 {
+			cfg = LoadTestConfig("127.0.0.1:10001", true)
+		} else {
+			cfg = LoadTestConfig("", true)
+		}
+		cfg.BootstrapNodes = []string{"127.0.0.1:10001"}
+		cfg.PeerDiscoveryScheme = config.KademliaPeerDiscovery
+		cfg.KadBucketSize = 4
+		cfg.KadAlpha = 3
+		cfg.NumPeersLowerBound = 3
+		cfg.NumPeersUpperBound = 3
+		node := NewOverlay(cfg)
+		node.AttachDispatcher(&MockDispatcher{})
+		node.Init()
+		node.Start()
+		nodes = append(nodes, node)
+	}
+
+	defer func() {
+		for _, node := range nodes {
+			node.Stop()
+		}
+	}()
+
+	time.Sleep(5 * time.Second)
+	for _, node := range nodes {
+		assert.True(t, node.Kad.Table.Len() >= 3)
+		count := uint(0)
+		node.PM.Peers.Range(func(_, _ interface{}) bool {
+			count++
+			return true
+		})
+		assert.True(t, count >= node.PM.NumPeersLowerBound)
+	}
+
+	// Every node could be found by the iterative lookup from any other node
+	for i := 1; i < size; i++ {
+		target := nodes[(i+size/2)%size]
+		found := nodes[i].Kad.Lookup(NewNodeID(target.Identity.PublicKey))
+		assert.NotEmpty(t, found)
+		assert.Equal(t, target.PRC.String(), found[0])
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	cm "github.com/iotexproject/iotex-core/common"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+)
+
+var (
+	// ErrAddrInUse means another node already listens at the address of the in-memory network
+	ErrAddrInUse = errors.New("address already in use")
+	// ErrUnreachable means no node listens at the address, or it is partitioned away from the sender
+	ErrUnreachable = errors.New("node is unreachable")
+	// ErrMsgLost means the message is dropped by the simulated packet loss
+	ErrMsgLost = errors.New("message is lost")
+)
+
+// MemNetwork is an in-memory network which connects the nodes running in the same process, so that a multi-node test
+// doesn't need to bind real ports. It can simulate the latency, the packet loss and the network partitions. The RPCs
+// are delivered by calling the servers directly on the copies of the messages.
+type MemNetwork struct {
+	servers    map[string]pb.PeerServer
+	groups     map[string]int
+	latency    time.Duration
+	lossRate   float64
+	random     *rand.Rand
+	nextPort   int
+	mutex      sync.RWMutex
+	randomLock sync.Mutex
+}
+
+// NewMemNetwork creates an instance of MemNetwork. The seed decides which messages are lost.
+func NewMemNetwork(seed int64) *MemNetwork {
+	return &MemNetwork{
+		servers:  make(map[string]pb.PeerServer),
+		groups:   make(map[string]int),
+		random:   rand.New(rand.NewSource(seed)),
+		nextPort: 30000,
+	}
+}
+
+// NewTransport creates a transport for a node to join the network
+func (n *MemNetwork) NewTransport() Transport {
+	return &memTransport{network: n}
+}
+
+// SetLatency sets the one-way delay of the messages
+func (n *MemNetwork) SetLatency(latency time.Duration) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.latency = latency
+}
+
+// SetLossRate sets the probability that a request is lost, which is between 0 and 1
+func (n *MemNetwork) SetLossRate(rate float64) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.lossRate = rate
+}
+
+// Partition splits the network into the given groups of addresses. Nodes can only talk to the ones in the same group,
+// and all the nodes not listed form another group.
+func (n *MemNetwork) Partition(groups ...[]string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.groups = make(map[string]int)
+	for i, group := range groups {
+		for _, addr := range group {
+			n.groups[addr] = i + 1
+		}
+	}
+}
+
+// Heal removes all the partitions
+func (n *MemNetwork) Heal() {
+	n.Partition()
+}
+
+func (n *MemNetwork) listen(addr string, server pb.PeerServer) (string, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = "127.0.0.1", "0"
+	}
+	if port == "0" {
+		// Allocate a port that no node listens on, the same as what the OS does
+		for {
+			addr = net.JoinHostPort(host, strconv.Itoa(n.nextPort))
+			n.nextPort++
+			if _, ok := n.servers[addr]; !ok {
+				break
+			}
+		}
+	}
+	if _, ok := n.servers[addr]; ok {
+		return "", errors.Wrapf(ErrAddrInUse, "addr %s", addr)
+	}
+	n.servers[addr] = server
+	return addr, nil
+}
+
+func (n *MemNetwork) close(addr string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	delete(n.servers, addr)
+}
+
+// route finds the server that a request from the sender to the receiver is delivered to, after the simulated latency
+func (n *MemNetwork) route(from string, to string) (pb.PeerServer, error) {
+	n.mutex.RLock()
+	latency, lossRate := n.latency, n.lossRate
+	partitioned := n.groups[from] != n.groups[to]
+	n.mutex.RUnlock()
+	if partitioned {
+		return nil, errors.Wrapf(ErrUnreachable, "%s is partitioned from %s", to, from)
+	}
+	if lossRate > 0 {
+		n.randomLock.Lock()
+		lost := n.random.Float64() < lossRate
+		n.randomLock.Unlock()
+		if lost {
+			return nil, errors.Wrapf(ErrMsgLost, "from %s to %s", from, to)
+		}
+	}
+	time.Sleep(latency)
+	n.mutex.RLock()
+	server, ok := n.servers[to]
+	n.mutex.RUnlock()
+	if !ok {
+		return nil, errors.Wrapf(ErrUnreachable, "no node listens at %s", to)
+	}
+	return server, nil
+}
+
+// reply delays the response by the simulated latency
+func (n *MemNetwork) reply() {
+	n.mutex.RLock()
+	latency := n.latency
+	n.mutex.RUnlock()
+	time.Sleep(latency)
+}
+
+// memTransport is a node's access to the in-memory network
+type memTransport struct {
+	network *MemNetwork
+	addr    string
+}
+
+// Listen registers the server at the address of the in-memory network
+func (t *memTransport) Listen(addr string, server pb.PeerServer) (Listener, error) {
+	addr, err := t.network.listen(addr, server)
+	if err != nil {
+		return nil, err
+	}
+	t.addr = addr
+	return &memListener{network: t.network, addr: addr}, nil
+}
+
+// Dial creates a connection to the address of the in-memory network. Like gRPC, it doesn't fail if no node listens at
+// the address until the first RPC is made.
+func (t *memTransport) Dial(addr string) (Conn, error) {
+	return &memConn{network: t.network, from: t.addr, to: addr}, nil
+}
+
+type memListener struct {
+	network *MemNetwork
+	addr    string
+}
+
+// Close unregisters the server from the in-memory network
+func (l *memListener) Close() error {
+	l.network.close(l.addr)
+	return nil
+}
+
+// Addr returns the address of the in-memory network that the server is registered at
+func (l *memListener) Addr() string {
+	return l.addr
+}
+
+// memConn implements the client side RPCs by calling the server on the in-memory network
+type memConn struct {
+	network *MemNetwork
+	from    string
+	to      string
+	closed  int32
+}
+
+// Close closes the connection
+func (c *memConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return nil
+}
+
+// route finds the server to deliver the request to, and attaches the sender's address to the context in the same way
+// as gRPC does
+func (c *memConn) route(ctx context.Context) (context.Context, pb.PeerServer, error) {
+	if atomic.LoadInt32(&c.closed) == 1 {
+		return nil, nil, errors.Wrapf(ErrUnreachable, "connection to %s is closed", c.to)
+	}
+	server, err := c.network.route(c.from, c.to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return peer.NewContext(ctx, &peer.Peer{Addr: cm.NewTCPNode(c.from)}), server, nil
+}
+
+// Ping implements the client side RPC
+func (c *memConn) Ping(ctx context.Context, in *pb.Ping, _ ...grpc.CallOption) (*pb.Pong, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.Ping(ctx, proto.Clone(in).(*pb.Ping))
+	c.network.reply()
+	return res, err
+}
+
+// GetPeers implements the client side RPC
+func (c *memConn) GetPeers(ctx context.Context, in *pb.GetPeersReq, _ ...grpc.CallOption) (*pb.GetPeersRes, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.GetPeers(ctx, proto.Clone(in).(*pb.GetPeersReq))
+	c.network.reply()
+	return res, err
+}
+
+// Broadcast implements the client side RPC
+func (c *memConn) Broadcast(ctx context.Context, in *pb.BroadcastReq, _ ...grpc.CallOption) (*pb.BroadcastRes, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.Broadcast(ctx, proto.Clone(in).(*pb.BroadcastReq))
+	c.network.reply()
+	return res, err
+}
+
+// Tell implements the client side RPC
+func (c *memConn) Tell(ctx context.Context, in *pb.TellReq, _ ...grpc.CallOption) (*pb.TellRes, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.Tell(ctx, proto.Clone(in).(*pb.TellReq))
+	c.network.reply()
+	return res, err
+}
+
+// FindNode implements the client side RPC
+func (c *memConn) FindNode(ctx context.Context, in *pb.FindNodeReq, _ ...grpc.CallOption) (*pb.FindNodeRes, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.FindNode(ctx, proto.Clone(in).(*pb.FindNodeReq))
+	c.network.reply()
+	return res, err
+}
+
+// Handshake implements the client side RPC
+func (c *memConn) Handshake(ctx context.Context, in *pb.Handshake, _ ...grpc.CallOption) (*pb.Handshake, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.Handshake(ctx, proto.Clone(in).(*pb.Handshake))
+	c.network.reply()
+	return res, err
+}
+
+// ConfirmHandshake implements the client side RPC
+func (c *memConn) ConfirmHandshake(
+	ctx context.Context,
+	in *pb.HandshakeConfirm,
+	_ ...grpc.CallOption,
+) (*pb.HandshakeConfirmRes, error) {
+	ctx, server, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := server.ConfirmHandshake(ctx, proto.Clone(in).(*pb.HandshakeConfirm))
+	c.network.reply()
+	return res, err
+}
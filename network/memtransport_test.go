@@ -0,0 +1,154 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+type memDispatcher struct {
+	MockDispatcher
+	Count int32
+}
+
+func (d *memDispatcher) HandleBroadcast(proto.Message, chan bool) {
+	atomic.AddInt32(&d.Count, 1)
+}
+
+func startMemCluster(n *MemNetwork, size int) ([]*Overlay, []*memDispatcher) {
+	nodes := []*Overlay{}
+	dps := []*memDispatcher{}
+	for i := 0; i < size; i++ {
+		// The address is not bound, so that it's fine to reuse the ones of the other tests
+		o := NewOverlay(LoadTestConfig("", true))
+		o.AttachTransport(n.NewTransport())
+		dp := &memDispatcher{}
+		o.AttachDispatcher(dp)
+		o.PRC.Start()
+		nodes = append(nodes, o)
+		dps = append(dps, dp)
+	}
+	for _, o1 := range nodes {
+		for _, o2 := range nodes {
+			o1.PM.AddPeer(o2.PRC.String())
+		}
+	}
+	return nodes, dps
+}
+
+func TestMemTransportBroadcast(t *testing.T) {
+	n := NewMemNetwork(0)
+	nodes, dps := startMemCluster(n, 4)
+	defer func() {
+		for _, o := range nodes {
+			o.PRC.Stop()
+		}
+	}()
+	for _, o := range nodes {
+		assert.Equal(t, 3, len(o.GetPeers()))
+	}
+
+	received := func(counts ...int32) func() (bool, error) {
+		return func() (bool, error) {
+			for i, dp := range dps {
+				if atomic.LoadInt32(&dp.Count) != counts[i] {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	}
+	assert.Nil(t, nodes[0].Broadcast(&iproto.TxPb{Version: 1}))
+	assert.Nil(t, util.WaitUntil(10*time.Millisecond, 2*time.Second, received(0, 1, 1, 1)))
+
+	// The message doesn't cross the partition
+	n.Partition([]string{nodes[0].PRC.String(), nodes[1].PRC.String()})
+	assert.Nil(t, nodes[0].Broadcast(&iproto.TxPb{Version: 2}))
+	assert.Nil(t, util.WaitUntil(10*time.Millisecond, 2*time.Second, received(0, 2, 1, 1)))
+	time.Sleep(100 * time.Millisecond)
+	ok, _ := received(0, 2, 1, 1)()
+	assert.True(t, ok)
+
+	n.Heal()
+	assert.Nil(t, nodes[3].Broadcast(&iproto.TxPb{Version: 3}))
+	assert.Nil(t, util.WaitUntil(10*time.Millisecond, 2*time.Second, received(1, 3, 2, 1)))
+}
+
+func TestMemTransportFaults(t *testing.T) {
+	n := NewMemNetwork(0)
+	nodes, _ := startMemCluster(n, 2)
+	defer func() {
+		for _, o := range nodes {
+			o.PRC.Stop()
+		}
+	}()
+	p := nodes[0].PM.GetOrAddPeer(nodes[1].PRC.String())
+	assert.NotNil(t, p)
+
+	n.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	pong, err := p.Ping(&pb.Ping{Nonce: 1, Addr: nodes[0].PRC.String()})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), pong.AckNonce)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	n.SetLatency(0)
+
+	n.SetLossRate(1)
+	_, err = p.Ping(&pb.Ping{Nonce: 2, Addr: nodes[0].PRC.String()})
+	assert.Equal(t, ErrMsgLost, errors.Cause(err))
+	n.SetLossRate(0)
+
+	n.Partition([]string{nodes[0].PRC.String()})
+	_, err = p.Ping(&pb.Ping{Nonce: 3, Addr: nodes[0].PRC.String()})
+	assert.Equal(t, ErrUnreachable, errors.Cause(err))
+	n.Heal()
+
+	// The address is released once the server stops
+	addr := nodes[1].PRC.String()
+	nodes[1].PRC.Stop()
+	_, err = p.Ping(&pb.Ping{Nonce: 4, Addr: nodes[0].PRC.String()})
+	assert.Equal(t, ErrUnreachable, errors.Cause(err))
+	_, err = n.NewTransport().Listen(addr, nodes[1].PRC)
+	assert.Nil(t, err)
+	_, err = n.NewTransport().Listen(addr, nodes[1].PRC)
+	assert.Equal(t, ErrAddrInUse, errors.Cause(err))
+}
+
+func TestMemTransportKademlia(t *testing.T) {
+	n := NewMemNetwork(0)
+	nodes := []*Overlay{}
+	for i := 0; i < 2; i++ {
+		o := NewOverlay(LoadTestConfig("", true))
+		o.AttachTransport(n.NewTransport())
+		o.Kad = NewKademlia(o)
+		o.Kad.BucketSize = 4
+		assert.Nil(t, o.PRC.Start())
+		assert.Nil(t, o.Kad.Start())
+		nodes = append(nodes, o)
+	}
+	defer func() {
+		for _, o := range nodes {
+			o.PRC.Stop()
+		}
+	}()
+
+	// The node which isn't a peer is looked up through the transport
+	id, _, err := nodes[0].Kad.findNode(nodes[1].PRC.String(), nodes[0].Kad.Table.Self())
+	assert.Nil(t, err)
+	assert.Equal(t, NewNodeID(nodes[1].Identity.PublicKey), id)
+	assert.True(t, nodes[0].Kad.Table.Contains(nodes[1].PRC.String()))
+}
@@ -7,16 +7,20 @@
 package network
 
 import (
+	"bytes"
 	"net"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
+	"github.com/iotexproject/iotex-core/blockchain"
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/common/routine"
 	"github.com/iotexproject/iotex-core/common/service"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/dispatch/dispatcher"
+	"github.com/iotexproject/iotex-core/iotxaddress"
 	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
@@ -25,22 +29,40 @@ import (
 var (
 	// ErrPeerNotFound means the peer is not found
 	ErrPeerNotFound = errors.New("Peer not found")
+	// ErrPeerBanned means the peer is banned for misbehaving
+	ErrPeerBanned = errors.New("Peer is banned")
 )
 
 // Overlay represents the peer-to-peer network
 type Overlay struct {
 	service.CompositeService
 	PM         *PeerManager
+	PeerStore  *PeerStore
 	PRC        *RPCServer
 	Gossip     *Gossip
+	Kad        *Kademlia
 	Tasks      []*routine.RecurringTask
 	Config     *config.Network
 	Dispatcher dispatcher.Dispatcher
+	Blockchain blockchain.Blockchain
+	// Identity is the key pair that the node proves its identity with in the handshake
+	Identity *iotxaddress.Address
+	// identityAttached is true if the identity is attached instead of being kept in the identity key file
+	identityAttached bool
+	// Identities are the peer identities verified in the handshakes, which are keyed by the peer addresses
+	Identities sync.Map
 }
 
 // NewOverlay creates an instance of Overlay
 func NewOverlay(config *config.Network) *Overlay {
 	o := &Overlay{Config: config}
+	// Use a temporary identity until a long-lived one is attached or loaded from the identity key file on start
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error when generating the node identity")
+	}
+	o.Identity = id
+	o.PeerStore = NewPeerStore(config)
 	o.PRC = NewRPCServer(o)
 	o.PM = NewPeerManager(o, config.NumPeersLowerBound, config.NumPeersUpperBound)
 	o.Gossip = NewGossip(o)
@@ -50,6 +72,7 @@ func NewOverlay(config *config.Network) *Overlay {
 
 	o.addPingTask()
 	o.addHealthCheckTask()
+	o.addPeerStoreTask()
 	if config.PeerDiscovery {
 		o.addPeerMaintainer()
 	} else {
@@ -58,12 +81,93 @@ func NewOverlay(config *config.Network) *Overlay {
 	return o
 }
 
+// Init initializes the peer store and the other child services
+func (o *Overlay) Init() error {
+	if err := o.PeerStore.Init(); err != nil {
+		return err
+	}
+	return o.CompositeService.Init()
+}
+
+// Start opens the peer store first, whose error is returned as the node can't start without it, and then starts the
+// other child services. The node keeps the identity in the identity key file unless a long-lived one is attached.
+func (o *Overlay) Start() error {
+	if err := o.PeerStore.Start(); err != nil {
+		return err
+	}
+	if !o.identityAttached {
+		if err := o.loadIdentity(); err != nil {
+			return err
+		}
+	}
+	return o.CompositeService.Start()
+}
+
+// loadIdentity loads the identity from the identity key file, or keeps the temporary one in a new key file if there
+// isn't any yet. The peer store only keeps the public key of the identity. The key pair kept in the peer store by the
+// earlier versions is still used until the key file is configured, and then moved into the key file.
+func (o *Overlay) loadIdentity() error {
+	pubKey, legacyPriKey, err := o.PeerStore.IdentityPublicKey()
+	if err != nil {
+		return err
+	}
+	id := o.Identity
+	if legacyPriKey != nil {
+		if id, err = newIdentity(pubKey, legacyPriKey); err != nil {
+			return err
+		}
+		if o.Config.IdentityKeyPath == "" {
+			logger.Warn().Msg("The identity key is kept in the peer store, set identityKeyPath to move it out")
+			o.Identity = id
+			return nil
+		}
+	}
+	if o.Config.IdentityKeyPath != "" {
+		if id, err = loadIdentityKey(o.Config.IdentityKeyPath, id); err != nil {
+			return err
+		}
+		if pubKey != nil && !bytes.Equal(pubKey, id.PublicKey) {
+			logger.Warn().Str("path", o.Config.IdentityKeyPath).Msg("The node identity changed since the last start")
+		}
+	}
+	if err := o.PeerStore.SetIdentityPublicKey(id.PublicKey); err != nil {
+		return err
+	}
+	o.Identity = id
+	return nil
+}
+
+// Stop stops the child services, and then closes the peer store
+func (o *Overlay) Stop() error {
+	if err := o.CompositeService.Stop(); err != nil {
+		return err
+	}
+	return o.PeerStore.Stop()
+}
+
 // AttachDispatcher attaches to a Dispatcher instance
 func (o *Overlay) AttachDispatcher(dispatcher dispatcher.Dispatcher) {
 	o.Dispatcher = dispatcher
 	o.Gossip.AttachDispatcher(dispatcher)
 }
 
+// AttachTransport attaches the transport which carries the RPCs, replacing the default gRPC over TCP. It needs to be
+// called before the overlay is started.
+func (o *Overlay) AttachTransport(t Transport) {
+	o.PRC.Transport = t
+}
+
+// AttachIdentity attaches the long-lived identity key pair of the node
+func (o *Overlay) AttachIdentity(id *iotxaddress.Address) {
+	o.Identity = id
+	o.identityAttached = true
+}
+
+// AttachBlockchain attaches to a Blockchain instance, whose tip height is exchanged in the handshake
+func (o *Overlay) AttachBlockchain(bc blockchain.Blockchain) {
+	o.Blockchain = bc
+}
+
 func (o *Overlay) addPingTask() {
 	ping := NewPinger(o)
 	pingTask := routine.NewRecurringTask(ping, o.Config.PingInterval)
@@ -78,13 +182,31 @@ func (o *Overlay) addHealthCheckTask() {
 	o.Tasks = append(o.Tasks, hcTask)
 }
 
+func (o *Overlay) addPeerStoreTask() {
+	psTask := routine.NewRecurringTask(o.PeerStore, o.Config.PeerMaintainerInterval)
+	o.AddService(psTask)
+	o.Tasks = append(o.Tasks, psTask)
+}
+
 func (o *Overlay) addPeerMaintainer() {
+	if o.Config.PeerDiscoveryScheme == config.KademliaPeerDiscovery {
+		o.addKademlia()
+		return
+	}
 	pm := NewPeerMaintainer(o)
 	pmTask := routine.NewRecurringTask(pm, o.Config.PeerMaintainerInterval)
 	o.AddService(pmTask)
 	o.Tasks = append(o.Tasks, pmTask)
 }
 
+func (o *Overlay) addKademlia() {
+	o.Kad = NewKademlia(o)
+	o.AddService(o.Kad)
+	kadTask := routine.NewRecurringTask(o.Kad, o.Config.PeerMaintainerInterval)
+	o.AddService(kadTask)
+	o.Tasks = append(o.Tasks, kadTask)
+}
+
 func (o *Overlay) addConfigBasedPeerMaintainer() {
 	topology, err := config.LoadTopology(o.Config.TopologyPath)
 	if err != nil {
@@ -20,11 +20,13 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
+	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
 )
 
@@ -224,6 +226,45 @@ func TestOneConnPerIP(t *testing.T) {
 	assert.Equal(t, uint(1), LenSyncMap(p3.PM.Peers))
 }
 
+func TestBanPeer(t *testing.T) {
+	n := NewMemNetwork(0)
+	nodes, _ := startMemCluster(n, 3)
+	defer func() {
+		for _, o := range nodes {
+			o.PRC.Stop()
+		}
+	}()
+	addr := nodes[1].PRC.String()
+
+	nodes[0].PM.BanPeer(addr)
+	assert.True(t, nodes[0].PM.IsBanned(addr))
+	assert.False(t, nodes[1].PM.IsBanned(nodes[0].PRC.String()))
+	assert.False(t, nodes[0].PM.IsBanned(nodes[2].PRC.String()))
+	_, ok := nodes[0].PM.Peers.Load(addr)
+	assert.False(t, ok)
+
+	// The banned peer is neither connected again nor listened to, whatever address it claims
+	nodes[0].PM.AddPeer(addr)
+	_, ok = nodes[0].PM.Peers.Load(addr)
+	assert.False(t, ok)
+	p, ok := nodes[1].PM.Peers.Load(nodes[0].PRC.String())
+	assert.True(t, ok)
+	_, err := p.(*Peer).Tell(&pb.TellReq{Addr: "127.0.0.1:10000"})
+	assert.Equal(t, ErrPeerBanned, errors.Cause(err))
+	_, err = p.(*Peer).BroadcastMsg(&pb.BroadcastReq{})
+	assert.Equal(t, ErrPeerBanned, errors.Cause(err))
+	p2 := NewTCPPeer(nodes[0].PRC.String())
+	assert.Nil(t, p2.Dial(nodes[1].PRC.Transport))
+	defer p2.Close()
+	assert.NotNil(t, nodes[1].handshake(p2))
+
+	// Another peer can't claim the address of the banned one
+	p, ok = nodes[2].PM.Peers.Load(nodes[0].PRC.String())
+	assert.True(t, ok)
+	_, err = p.(*Peer).Tell(&pb.TellReq{Addr: addr})
+	assert.Equal(t, ErrInvalidHandshake, errors.Cause(err))
+}
+
 func TestConfigBasedTopology(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping the overlay test in short mode.")
@@ -7,10 +7,10 @@
 package network
 
 import (
+	"io"
 	"time"
 
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/config"
@@ -23,9 +23,15 @@ import (
 type Peer struct {
 	cm.Node
 	Client      pb.PeerClient
-	Conn        *grpc.ClientConn
+	Conn        io.Closer
 	Ctx         context.Context
 	LastResTime time.Time
+	// Identity is what the peer has proved in the handshake
+	Identity *PeerIdentity
+	// Compression is the algorithm negotiated in the handshake to compress the message bodies sent to the peer
+	Compression string
+	// CompressionThreshold is the size in bytes, above which the message bodies are compressed
+	CompressionThreshold int
 }
 
 // NewTCPPeer creates an instance of Peer with tcp transportation
@@ -41,37 +47,20 @@ func NewPeer(n string, addr string) *Peer {
 	return p
 }
 
-// Connect connects the peer
+// Connect connects the peer by gRPC over TCP
 func (p *Peer) Connect(config *config.Network) error {
-	// Set up a connection to the peer.
-	var conn *grpc.ClientConn
-	var err error
-	if config.TLSEnabled {
-		creds, err := generateClientCredentials(config)
-		if err != nil {
-			return err
-		}
-		conn, err = grpc.Dial(
-			p.String(),
-			grpc.WithTransportCredentials(creds),
-			grpc.WithKeepaliveParams(config.KLClientParams),
-			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(config.MaxMsgSize)))
-		if err != nil {
-			return err
-		}
-	} else {
-		conn, err = grpc.Dial(
-			p.String(),
-			grpc.WithInsecure(),
-			grpc.WithKeepaliveParams(config.KLClientParams))
-	}
+	return p.Dial(NewGRPCTransport(config))
+}
 
+// Dial connects the peer on the transport
+func (p *Peer) Dial(t Transport) error {
+	conn, err := t.Dial(p.String())
 	if err != nil {
 		logger.Error().Err(err).Msg("Peer did not connect")
 		return err
 	}
 	p.Conn = conn
-	p.Client = pb.NewPeerClient(conn)
+	p.Client = conn
 	p.Ctx = context.Background()
 	return nil
 }
@@ -98,6 +87,7 @@ func (p *Peer) GetPeers(req *pb.GetPeersReq) (*pb.GetPeersRes, error) {
 // BroadcastMsg implements the client side RPC
 func (p *Peer) BroadcastMsg(req *pb.BroadcastReq) (*pb.BroadcastRes, error) {
 	req.Header = iproto.MagicBroadcastMsgHeader
+	req.Compression, req.MsgBody = p.compressMsgBody(req.MsgBody)
 	res, e := p.Client.Broadcast(p.Ctx, req)
 	p.updateLastResTime()
 	return res, e
@@ -106,11 +96,50 @@ func (p *Peer) BroadcastMsg(req *pb.BroadcastReq) (*pb.BroadcastRes, error) {
 // Tell implements the client side RPC
 func (p *Peer) Tell(req *pb.TellReq) (*pb.TellRes, error) {
 	req.Header = iproto.MagicBroadcastMsgHeader
+	req.Compression, req.MsgBody = p.compressMsgBody(req.MsgBody)
 	res, e := p.Client.Tell(p.Ctx, req)
 	p.updateLastResTime()
 	return res, e
 }
 
+// FindNode implements the client side RPC
+func (p *Peer) FindNode(req *pb.FindNodeReq) (*pb.FindNodeRes, error) {
+	res, e := p.Client.FindNode(p.Ctx, req)
+	p.updateLastResTime()
+	return res, e
+}
+
+// Handshake implements the client side RPC
+func (p *Peer) Handshake(req *pb.Handshake) (*pb.Handshake, error) {
+	res, e := p.Client.Handshake(p.Ctx, req)
+	p.updateLastResTime()
+	return res, e
+}
+
+// ConfirmHandshake implements the client side RPC
+func (p *Peer) ConfirmHandshake(req *pb.HandshakeConfirm) (*pb.HandshakeConfirmRes, error) {
+	res, e := p.Client.ConfirmHandshake(p.Ctx, req)
+	p.updateLastResTime()
+	return res, e
+}
+
+// compressMsgBody compresses the message body if the compression is negotiated and the body is large enough. It
+// returns the algorithm together with the compressed body, or an empty algorithm with the original body.
+func (p *Peer) compressMsgBody(msgBody []byte) (string, []byte) {
+	if p.Compression == "" || len(msgBody) <= p.CompressionThreshold {
+		return "", msgBody
+	}
+	compressed, err := compressMsg(p.Compression, msgBody)
+	if err != nil {
+		logger.Error().Err(err).Str("addr", p.String()).Msg("Error when compressing the message")
+		return "", msgBody
+	}
+	if len(compressed) >= len(msgBody) {
+		return "", msgBody
+	}
+	return p.Compression, compressed
+}
+
 // Update the last time when successfully getting an response from the peer
 func (p *Peer) updateLastResTime() {
 	p.LastResTime = time.Now()
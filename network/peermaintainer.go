@@ -30,16 +30,20 @@ func NewPeerMaintainer(o *Overlay) *PeerMaintainer {
 func (pm *PeerMaintainer) Do() {
 	count := LenSyncMap(pm.Overlay.PM.Peers)
 	if count == 0 {
-		// TODO: Now we simply read the bootstrap nodes from the config. This needs to be changed in the future
+		// Reconnect to the known peers in the order of priority first, and then fall back to the bootstrap nodes
 		bns1 := pm.Overlay.Config.BootstrapNodes
 		bns2 := make([]string, len(bns1))
 		copy(bns2, bns1)
 		stringsAreShuffled(bns2)
-		for i, bn := range bns2 {
-			if uint(i) >= pm.Overlay.PM.NumPeersLowerBound {
+		added := uint(0)
+		for _, addr := range append(pm.Overlay.PeerStore.Prioritized(), bns2...) {
+			if added >= pm.Overlay.PM.NumPeersLowerBound {
 				break
 			}
-			pm.Overlay.PM.AddPeer(bn)
+			pm.Overlay.PM.AddPeer(addr)
+			if _, ok := pm.Overlay.PM.Peers.Load(addr); ok {
+				added++
+			}
 		}
 	} else if count < pm.Overlay.PM.NumPeersLowerBound {
 		targetIdx := rand.Intn(int(count))
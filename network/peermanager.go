@@ -20,6 +20,7 @@ type PeerManager struct {
 	service.CompositeService
 	// TODO: Need to revisit sync.Map: https://github.com/golang/go/issues/24112
 	Peers              sync.Map
+	banned             sync.Map
 	Overlay            *Overlay
 	NumPeersLowerBound uint
 	NumPeersUpperBound uint
@@ -38,6 +39,12 @@ func (pm *PeerManager) AddPeer(addr string) {
 			Msg("Node already reached the max number of peers")
 		return
 	}
+	if pm.IsBanned(addr) {
+		logger.Debug().
+			Str("addr", addr).
+			Msg("Node at address is banned")
+		return
+	}
 	if pm.Overlay.PRC.String() == addr {
 		logger.Debug().
 			Str("addr", addr).
@@ -83,8 +90,29 @@ func (pm *PeerManager) AddPeer(addr string) {
 		}
 	}
 	p := NewTCPPeer(addr)
-	p.Connect(pm.Overlay.Config)
+	if err := p.Dial(pm.Overlay.PRC.Transport); err != nil {
+		pm.Overlay.PeerStore.RecordFailure(addr)
+		return
+	}
+	if err := pm.Overlay.handshake(p); err != nil {
+		logger.Error().
+			Err(err).
+			Str("addr", addr).
+			Msg("Error when handshaking with the node")
+		pm.Overlay.PeerStore.RecordFailure(addr)
+		p.Close()
+		return
+	}
+	if pm.isBannedIdentity(p.Identity) {
+		logger.Debug().
+			Str("addr", addr).
+			Str("iotxAddr", p.Identity.RawAddress).
+			Msg("Node identity is banned")
+		p.Close()
+		return
+	}
 	pm.Peers.Store(addr, p)
+	pm.Overlay.PeerStore.RecordSuccess(addr)
 	logger.Debug().
 		Str("src", pm.Overlay.PRC.String()).
 		Str("dst", addr).
@@ -104,6 +132,40 @@ func (pm *PeerManager) RemovePeer(addr string) {
 	p.(*Peer).Close()
 }
 
+// BanPeer removes the peer and refuses to connect to or to be told by it again. The ban is keyed on the identity that
+// the node at the address has proved in the handshake, so that the node can't escape it by claiming another address.
+func (pm *PeerManager) BanPeer(addr string) {
+	id, ok := pm.identityAt(addr)
+	if !ok {
+		logger.Warn().Str("addr", addr).Msg("Identity of the peer is unknown, only remove it")
+		pm.RemovePeer(addr)
+		return
+	}
+	logger.Warn().Str("addr", addr).Str("iotxAddr", id.RawAddress).Msg("Ban the peer")
+	pm.banned.Store(id.RawAddress, true)
+	pm.RemovePeer(addr)
+}
+
+// IsBanned returns true if the identity of the peer at the address is banned
+func (pm *PeerManager) IsBanned(addr string) bool {
+	id, ok := pm.identityAt(addr)
+	return ok && pm.isBannedIdentity(id)
+}
+
+func (pm *PeerManager) isBannedIdentity(id *PeerIdentity) bool {
+	_, ok := pm.banned.Load(id.RawAddress)
+	return ok
+}
+
+// identityAt returns the identity verified on the outgoing connection to the address, or else the one proved on the
+// incoming connection whose tell requests claim the address
+func (pm *PeerManager) identityAt(addr string) (*PeerIdentity, bool) {
+	if id, ok := pm.Overlay.Identities.Load(addr); ok {
+		return id.(*PeerIdentity), true
+	}
+	return pm.Overlay.PRC.senderIdentity(addr)
+}
+
 // RemoveLRUPeer removes the least recently used (contacted) peer
 func (pm *PeerManager) RemoveLRUPeer() {
 	minLastResTime := int64(0)
@@ -0,0 +1,285 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+)
+
+const (
+	peerStoreNS = "peers"
+	// peerStoreOpenTimeout is how long to wait for the lock of the DB file, which another node may be holding
+	peerStoreOpenTimeout = time.Second
+)
+
+var (
+	peerStoreKey = []byte("records")
+	identityKey  = []byte("identity")
+)
+
+// PeerStore remembers the peers that the node has successfully talked to, together with when they were last seen and
+// how reliable they have been, so that a restarted node could reconnect to them before falling back to the bootstrap
+// nodes. Only the peers which have been seen at least once are remembered.
+type PeerStore struct {
+	kvstore db.KVStore
+	records map[string]*pb.PeerRecord
+	ttl     time.Duration
+	size    uint
+	dirty   bool
+	started bool
+	mutex   sync.RWMutex
+}
+
+// NewPeerStore creates an instance of PeerStore. The records are persisted into a bolt DB if the path is configured,
+// otherwise they only live in memory.
+func NewPeerStore(cfg *config.Network) *PeerStore {
+	var kvstore db.KVStore
+	if cfg.PeerStorePath == "" {
+		kvstore = db.NewMemKVStore()
+	} else {
+		kvstore = db.NewBoltDB(cfg.PeerStorePath, &bolt.Options{Timeout: peerStoreOpenTimeout})
+	}
+	return &PeerStore{
+		kvstore: kvstore,
+		records: make(map[string]*pb.PeerRecord),
+		ttl:     cfg.PeerStoreTTL,
+		size:    cfg.PeerStoreSize,
+	}
+}
+
+// Init initializes the peer store
+func (s *PeerStore) Init() error {
+	return s.kvstore.Init()
+}
+
+// Start opens the underlying DB and loads the persisted records
+func (s *PeerStore) Start() error {
+	if err := s.kvstore.Start(); err != nil {
+		return errors.Wrap(err, "failed to open the peer store")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.started = true
+	value, err := s.kvstore.Get(peerStoreNS, peerStoreKey)
+	if err != nil {
+		cause := errors.Cause(err)
+		if cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "failed to load the peer records")
+	}
+	records := &pb.PeerRecords{}
+	if err := proto.Unmarshal(value, records); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the peer records")
+	}
+	for _, r := range records.Records {
+		s.records[r.Addr] = r
+	}
+	s.prune()
+	logger.Info().Int("peers", len(s.records)).Msg("Loaded the known peers")
+	return nil
+}
+
+// Stop persists the records and closes the underlying DB
+func (s *PeerStore) Stop() error {
+	if err := s.Save(); err != nil {
+		logger.Error().Err(err).Msg("Error when saving the peer records")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.started = false
+	return s.kvstore.Stop()
+}
+
+// Do ages out the stale records and persists the changes
+func (s *PeerStore) Do() {
+	s.mutex.Lock()
+	s.prune()
+	s.mutex.Unlock()
+	if err := s.Save(); err != nil {
+		logger.Error().Err(err).Msg("Error when saving the peer records")
+	}
+}
+
+// RecordSuccess records that the node has successfully connected to the peer at the address
+func (s *PeerStore) RecordSuccess(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, ok := s.records[addr]
+	if !ok {
+		r = &pb.PeerRecord{Addr: addr}
+		s.records[addr] = r
+	}
+	r.LastSeen = time.Now().Unix()
+	r.Successes++
+	s.dirty = true
+}
+
+// Touch refreshes the last seen time of the known peer at the address, which is still alive on the connection
+func (s *PeerStore) Touch(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, ok := s.records[addr]
+	if !ok {
+		return
+	}
+	r.LastSeen = time.Now().Unix()
+	s.dirty = true
+}
+
+// RecordFailure records that the node has failed to connect to the peer at the address. Failures of the unknown peers
+// are not recorded.
+func (s *PeerStore) RecordFailure(addr string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, ok := s.records[addr]
+	if !ok {
+		return
+	}
+	r.Failures++
+	s.dirty = true
+}
+
+// Get returns a copy of the record of the peer at the address
+func (s *PeerStore) Get(addr string) (*pb.PeerRecord, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	r, ok := s.records[addr]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(r).(*pb.PeerRecord), true
+}
+
+// Len returns the number of known peers
+func (s *PeerStore) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.records)
+}
+
+// Prioritized returns the addresses of the known peers, from the most preferred to the least. Peers with higher scores
+// come first, and the ties are broken by the more recently seen ones.
+func (s *PeerStore) Prioritized() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	records := s.sortedRecords()
+	addrs := make([]string, 0, len(records))
+	for _, r := range records {
+		addrs = append(addrs, r.Addr)
+	}
+	return addrs
+}
+
+// Save persists the records if they have been changed since the last save
+func (s *PeerStore) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.started || !s.dirty {
+		return nil
+	}
+	records := &pb.PeerRecords{Records: s.sortedRecords()}
+	value, err := proto.Marshal(records)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the peer records")
+	}
+	if err := s.kvstore.Put(peerStoreNS, peerStoreKey, value); err != nil {
+		return errors.Wrap(err, "failed to persist the peer records")
+	}
+	s.dirty = false
+	return nil
+}
+
+// IdentityPublicKey returns the public key of the node identity kept in the peer store, together with the private key
+// if the store was written by an earlier version, which kept the whole key pair in it. It needs to be called after the
+// peer store is started.
+func (s *PeerStore) IdentityPublicKey() ([]byte, []byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, err := s.kvstore.Get(peerStoreNS, identityKey)
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load the identity")
+	}
+	persisted := &pb.NodeIdentity{}
+	if err := proto.Unmarshal(value, persisted); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal the identity")
+	}
+	return persisted.PubKey, persisted.PriKey, nil
+}
+
+// SetIdentityPublicKey keeps the public key of the node identity in the peer store, replacing the key pair kept by the
+// earlier versions. The private key is never kept in the peer store.
+func (s *PeerStore) SetIdentityPublicKey(pubKey []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, err := proto.Marshal(&pb.NodeIdentity{PubKey: pubKey})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the identity")
+	}
+	if err := s.kvstore.Put(peerStoreNS, identityKey, value); err != nil {
+		return errors.Wrap(err, "failed to persist the identity")
+	}
+	return nil
+}
+
+// PeerScore estimates how likely the peer is reachable from its successes and failures. A fresh record scores 0.5.
+func PeerScore(r *pb.PeerRecord) float64 {
+	return float64(r.Successes+1) / float64(r.Successes+r.Failures+2)
+}
+
+// prune drops the records not seen within the TTL, and then the least preferred ones beyond the size limit. It needs
+// to be called with the mutex held.
+func (s *PeerStore) prune() {
+	if s.ttl > 0 {
+		deadline := time.Now().Add(-s.ttl).Unix()
+		for addr, r := range s.records {
+			if r.LastSeen < deadline {
+				delete(s.records, addr)
+				s.dirty = true
+			}
+		}
+	}
+	if s.size > 0 && uint(len(s.records)) > s.size {
+		for _, r := range s.sortedRecords()[s.size:] {
+			delete(s.records, r.Addr)
+		}
+		s.dirty = true
+	}
+}
+
+// sortedRecords returns the records in the order of priority. It needs to be called with the mutex held.
+func (s *PeerStore) sortedRecords() []*pb.PeerRecord {
+	records := make([]*pb.PeerRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		si, sj := PeerScore(records[i]), PeerScore(records[j])
+		if si != sj {
+			return si > sj
+		}
+		if records[i].LastSeen != records[j].LastSeen {
+			return records[i].LastSeen > records[j].LastSeen
+		}
+		return records[i].Addr < records[j].Addr
+	})
+	return records
+}
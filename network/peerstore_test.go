@@ -0,0 +1,203 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/iotexproject/iotex-core/network/proto"
+	"github.com/iotexproject/iotex-core/test/util"
+)
+
+const (
+	testPeerStorePath   = "peer.test"
+	testIdentityKeyPath = "identity.key.test"
+)
+
+func TestPeerStorePriority(t *testing.T) {
+	cfg := LoadTestConfig("127.0.0.1:10001", true)
+	cfg.PeerStoreTTL = time.Hour
+	s := NewPeerStore(cfg)
+	assert.Nil(t, s.Start())
+	defer s.Stop()
+
+	// Failures of the unknown peers are not recorded
+	s.RecordFailure("127.0.0.1:10002")
+	assert.Equal(t, 0, s.Len())
+
+	s.RecordSuccess("127.0.0.1:10002")
+	s.RecordSuccess("127.0.0.1:10002")
+	s.RecordSuccess("127.0.0.1:10003")
+	s.RecordSuccess("127.0.0.1:10004")
+	s.RecordFailure("127.0.0.1:10004")
+	assert.Equal(t, []string{"127.0.0.1:10002", "127.0.0.1:10003", "127.0.0.1:10004"}, s.Prioritized())
+
+	r, ok := s.Get("127.0.0.1:10004")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), r.Successes)
+	assert.Equal(t, uint32(1), r.Failures)
+	assert.Equal(t, 0.5, PeerScore(r))
+
+	// The stale peers age out, and then the least preferred ones beyond the size limit
+	s.records["127.0.0.1:10003"].LastSeen = time.Now().Add(-cfg.PeerStoreTTL - time.Minute).Unix()
+	s.size = 1
+	s.Do()
+	assert.Equal(t, []string{"127.0.0.1:10002"}, s.Prioritized())
+}
+
+func TestPeerStorePersistence(t *testing.T) {
+	util.CleanupPath(t, testPeerStorePath)
+	defer util.CleanupPath(t, testPeerStorePath)
+
+	cfg := LoadTestConfig("127.0.0.1:10001", true)
+	cfg.PeerStorePath = testPeerStorePath
+	s1 := NewPeerStore(cfg)
+	assert.Nil(t, s1.Start())
+	assert.Equal(t, 0, s1.Len())
+	s1.RecordSuccess("127.0.0.1:10002")
+	s1.RecordSuccess("127.0.0.1:10003")
+	s1.RecordFailure("127.0.0.1:10003")
+	id := newTestIdentity(t)
+	assert.Nil(t, s1.SetIdentityPublicKey(id.PublicKey))
+	assert.Nil(t, s1.Stop())
+
+	s2 := NewPeerStore(cfg)
+	assert.Nil(t, s2.Start())
+	defer s2.Stop()
+	assert.Equal(t, []string{"127.0.0.1:10002", "127.0.0.1:10003"}, s2.Prioritized())
+	r, ok := s2.Get("127.0.0.1:10003")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), r.Failures)
+	// The peer store keeps the public key of the identity only
+	pubKey, priKey, err := s2.IdentityPublicKey()
+	assert.Nil(t, err)
+	assert.Equal(t, id.PublicKey, pubKey)
+	assert.Nil(t, priKey)
+}
+
+func TestIdentityKeyFile(t *testing.T) {
+	util.CleanupPath(t, testPeerStorePath)
+	defer util.CleanupPath(t, testPeerStorePath)
+	util.CleanupPath(t, testIdentityKeyPath)
+	defer util.CleanupPath(t, testIdentityKeyPath)
+
+	cfg := LoadTestConfig("127.0.0.1:10001", true)
+	cfg.PeerStorePath = testPeerStorePath
+	cfg.IdentityKeyPath = testIdentityKeyPath
+	loadIdentity := func() (*Overlay, error) {
+		o := NewOverlay(cfg)
+		require.Nil(t, o.PeerStore.Start())
+		defer o.PeerStore.Stop()
+		return o, o.loadIdentity()
+	}
+
+	// The node keeps its identity in a new key file which only the owner could access
+	o1, err := loadIdentity()
+	require.Nil(t, err)
+	info, err := os.Stat(testIdentityKeyPath)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The node keeps the identity across restarts, while the peer store only keeps the public key
+	o2, err := loadIdentity()
+	require.Nil(t, err)
+	assert.Equal(t, o1.Identity, o2.Identity)
+	s := NewPeerStore(cfg)
+	require.Nil(t, s.Start())
+	pubKey, priKey, err := s.IdentityPublicKey()
+	assert.Nil(t, err)
+	assert.Equal(t, o1.Identity.PublicKey, pubKey)
+	assert.Nil(t, priKey)
+
+	// The key pair kept in the peer store by the earlier versions is moved into the key file
+	util.CleanupPath(t, testIdentityKeyPath)
+	legacy := newTestIdentity(t)
+	value, err := proto.Marshal(&pb.NodeIdentity{PubKey: legacy.PublicKey, PriKey: legacy.PrivateKey})
+	require.Nil(t, err)
+	require.Nil(t, s.kvstore.Put(peerStoreNS, identityKey, value))
+	require.Nil(t, s.Stop())
+	o3, err := loadIdentity()
+	require.Nil(t, err)
+	assert.Equal(t, legacy, o3.Identity)
+	o4, err := loadIdentity()
+	require.Nil(t, err)
+	assert.Equal(t, legacy, o4.Identity)
+	s = NewPeerStore(cfg)
+	require.Nil(t, s.Start())
+	_, priKey, err = s.IdentityPublicKey()
+	assert.Nil(t, err)
+	assert.Nil(t, priKey)
+	require.Nil(t, s.Stop())
+
+	// The node refuses the key file which others could access
+	require.Nil(t, os.Chmod(testIdentityKeyPath, 0644))
+	_, err = loadIdentity()
+	assert.Equal(t, ErrIdentityKeyExposed, errors.Cause(err))
+}
+
+func TestPeerStoreLocked(t *testing.T) {
+	util.CleanupPath(t, testPeerStorePath)
+	defer util.CleanupPath(t, testPeerStorePath)
+
+	cfg := LoadTestConfig("127.0.0.1:10001", true)
+	cfg.PeerStorePath = testPeerStorePath
+	s1 := NewPeerStore(cfg)
+	assert.Nil(t, s1.Start())
+	defer s1.Stop()
+
+	// Another node sharing the DB file fails to start instead of waiting for the lock forever
+	o := NewOverlay(cfg)
+	assert.NotNil(t, o.Start())
+}
+
+func TestReconnectKnownPeers(t *testing.T) {
+	cfg1 := LoadTestConfig("127.0.0.1:10001", true)
+	cfg1.NumPeersLowerBound = 1
+	o1 := NewOverlay(cfg1)
+	o2 := NewOverlay(LoadTestConfig("127.0.0.1:10002", true))
+	for _, o := range []*Overlay{o1, o2} {
+		o.PRC.Start()
+	}
+
+	defer func() {
+		for _, o := range []*Overlay{o1, o2} {
+			o.PRC.Stop()
+		}
+	}()
+
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o1.PRC.Started() && o2.PRC.Started(), nil
+	})
+
+	// The unreachable known peer is tried first but fails, and the bootstrap nodes are not needed
+	for i := 0; i < 3; i++ {
+		o1.PeerStore.RecordSuccess("127.0.0.1:10003")
+	}
+	o1.PeerStore.RecordSuccess("127.0.0.1:10002")
+	o1.PeerStore.RecordSuccess("127.0.0.1:10002")
+	cfg1.BootstrapNodes = []string{"127.0.0.1:10004"}
+	NewPeerMaintainer(o1).Do()
+	_, ok := o1.PM.Peers.Load("127.0.0.1:10002")
+	assert.True(t, ok)
+	_, ok = o1.PM.Peers.Load("127.0.0.1:10003")
+	assert.False(t, ok)
+	r, ok := o1.PeerStore.Get("127.0.0.1:10003")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), r.Failures)
+	r, ok = o1.PeerStore.Get("127.0.0.1:10002")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3), r.Successes)
+	_, ok = o1.PeerStore.Get("127.0.0.1:10004")
+	assert.False(t, ok)
+}
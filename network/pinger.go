@@ -46,7 +46,9 @@ func (h *Pinger) Do() {
 					Uint64("out-nonce", n).
 					Uint64("in-nonce", pong.AckNonce).
 					Msg("pong carries an unmatched nonce")
+				return
 			}
+			h.Overlay.PeerStore.Touch(p.String())
 		}()
 		return true
 	})
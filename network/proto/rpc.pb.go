@@ -37,7 +37,7 @@ func (m *Ping) Reset()         { *m = Ping{} }
 func (m *Ping) String() string { return proto.CompactTextString(m) }
 func (*Ping) ProtoMessage()    {}
 func (*Ping) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{0}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{0}
 }
 func (m *Ping) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Ping.Unmarshal(m, b)
@@ -82,7 +82,7 @@ func (m *Pong) Reset()         { *m = Pong{} }
 func (m *Pong) String() string { return proto.CompactTextString(m) }
 func (*Pong) ProtoMessage()    {}
 func (*Pong) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{1}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{1}
 }
 func (m *Pong) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Pong.Unmarshal(m, b)
@@ -120,7 +120,7 @@ func (m *GetPeersReq) Reset()         { *m = GetPeersReq{} }
 func (m *GetPeersReq) String() string { return proto.CompactTextString(m) }
 func (*GetPeersReq) ProtoMessage()    {}
 func (*GetPeersReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{2}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{2}
 }
 func (m *GetPeersReq) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetPeersReq.Unmarshal(m, b)
@@ -158,7 +158,7 @@ func (m *GetPeersRes) Reset()         { *m = GetPeersRes{} }
 func (m *GetPeersRes) String() string { return proto.CompactTextString(m) }
 func (*GetPeersRes) ProtoMessage()    {}
 func (*GetPeersRes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{3}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{3}
 }
 func (m *GetPeersRes) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetPeersRes.Unmarshal(m, b)
@@ -190,6 +190,7 @@ type BroadcastReq struct {
 	MsgType              uint32   `protobuf:"varint,2,opt,name=msg_type,json=msgType" json:"msg_type,omitempty"`
 	MsgBody              []byte   `protobuf:"bytes,3,opt,name=msg_body,json=msgBody,proto3" json:"msg_body,omitempty"`
 	Ttl                  uint32   `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+	Compression          string   `protobuf:"bytes,5,opt,name=compression" json:"compression,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -199,7 +200,7 @@ func (m *BroadcastReq) Reset()         { *m = BroadcastReq{} }
 func (m *BroadcastReq) String() string { return proto.CompactTextString(m) }
 func (*BroadcastReq) ProtoMessage()    {}
 func (*BroadcastReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{4}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{4}
 }
 func (m *BroadcastReq) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BroadcastReq.Unmarshal(m, b)
@@ -247,6 +248,13 @@ func (m *BroadcastReq) GetTtl() uint32 {
 	return 0
 }
 
+func (m *BroadcastReq) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 type BroadcastRes struct {
 	Header               uint32   `protobuf:"varint,1,opt,name=header" json:"header,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -258,7 +266,7 @@ func (m *BroadcastRes) Reset()         { *m = BroadcastRes{} }
 func (m *BroadcastRes) String() string { return proto.CompactTextString(m) }
 func (*BroadcastRes) ProtoMessage()    {}
 func (*BroadcastRes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{5}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{5}
 }
 func (m *BroadcastRes) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BroadcastRes.Unmarshal(m, b)
@@ -290,6 +298,7 @@ type TellReq struct {
 	Addr                 string   `protobuf:"bytes,2,opt,name=addr" json:"addr,omitempty"`
 	MsgType              uint32   `protobuf:"varint,3,opt,name=msg_type,json=msgType" json:"msg_type,omitempty"`
 	MsgBody              []byte   `protobuf:"bytes,4,opt,name=msg_body,json=msgBody,proto3" json:"msg_body,omitempty"`
+	Compression          string   `protobuf:"bytes,5,opt,name=compression" json:"compression,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -299,7 +308,7 @@ func (m *TellReq) Reset()         { *m = TellReq{} }
 func (m *TellReq) String() string { return proto.CompactTextString(m) }
 func (*TellReq) ProtoMessage()    {}
 func (*TellReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{6}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{6}
 }
 func (m *TellReq) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TellReq.Unmarshal(m, b)
@@ -347,6 +356,13 @@ func (m *TellReq) GetMsgBody() []byte {
 	return nil
 }
 
+func (m *TellReq) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 type TellRes struct {
 	Header               uint32   `protobuf:"varint,1,opt,name=header" json:"header,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -358,7 +374,7 @@ func (m *TellRes) Reset()         { *m = TellRes{} }
 func (m *TellRes) String() string { return proto.CompactTextString(m) }
 func (*TellRes) ProtoMessage()    {}
 func (*TellRes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e452c36976e6f4c9, []int{7}
+	return fileDescriptor_rpc_e7a16240c939363e, []int{7}
 }
 func (m *TellRes) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TellRes.Unmarshal(m, b)
@@ -385,6 +401,447 @@ func (m *TellRes) GetHeader() uint32 {
 	return 0
 }
 
+type FindNodeReq struct {
+	Target []byte `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// The requester's address, so that the receiver could put it into its routing table after handshaking with it
+	Addr                 string   `protobuf:"bytes,2,opt,name=addr" json:"addr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FindNodeReq) Reset()         { *m = FindNodeReq{} }
+func (m *FindNodeReq) String() string { return proto.CompactTextString(m) }
+func (*FindNodeReq) ProtoMessage()    {}
+func (*FindNodeReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{8}
+}
+func (m *FindNodeReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindNodeReq.Unmarshal(m, b)
+}
+func (m *FindNodeReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindNodeReq.Marshal(b, m, deterministic)
+}
+func (dst *FindNodeReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindNodeReq.Merge(dst, src)
+}
+func (m *FindNodeReq) XXX_Size() int {
+	return xxx_messageInfo_FindNodeReq.Size(m)
+}
+func (m *FindNodeReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindNodeReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindNodeReq proto.InternalMessageInfo
+
+func (m *FindNodeReq) GetTarget() []byte {
+	if m != nil {
+		return m.Target
+	}
+	return nil
+}
+
+func (m *FindNodeReq) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+type FindNodeRes struct {
+	Addr                 []string `protobuf:"bytes,1,rep,name=addr" json:"addr,omitempty"`
+	Id                   [][]byte `protobuf:"bytes,2,rep,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FindNodeRes) Reset()         { *m = FindNodeRes{} }
+func (m *FindNodeRes) String() string { return proto.CompactTextString(m) }
+func (*FindNodeRes) ProtoMessage()    {}
+func (*FindNodeRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{9}
+}
+func (m *FindNodeRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindNodeRes.Unmarshal(m, b)
+}
+func (m *FindNodeRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindNodeRes.Marshal(b, m, deterministic)
+}
+func (dst *FindNodeRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindNodeRes.Merge(dst, src)
+}
+func (m *FindNodeRes) XXX_Size() int {
+	return xxx_messageInfo_FindNodeRes.Size(m)
+}
+func (m *FindNodeRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindNodeRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindNodeRes proto.InternalMessageInfo
+
+func (m *FindNodeRes) GetAddr() []string {
+	if m != nil {
+		return m.Addr
+	}
+	return nil
+}
+
+func (m *FindNodeRes) GetId() [][]byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+// Handshake is exchanged when a node connects to another one. It's used for both the request and the response, and is
+// signed by the sender's identity key.
+type Handshake struct {
+	ChainId              uint32   `protobuf:"varint,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	Version              uint32   `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
+	TipHeight            uint64   `protobuf:"varint,3,opt,name=tip_height,json=tipHeight" json:"tip_height,omitempty"`
+	Addr                 string   `protobuf:"bytes,4,opt,name=addr" json:"addr,omitempty"`
+	PubKey               []byte   `protobuf:"bytes,5,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	Nonce                uint64   `protobuf:"varint,6,opt,name=nonce" json:"nonce,omitempty"`
+	AckNonce             uint64   `protobuf:"varint,7,opt,name=ack_nonce,json=ackNonce" json:"ack_nonce,omitempty"`
+	Signature            []byte   `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	Topics               []string `protobuf:"bytes,9,rep,name=topics" json:"topics,omitempty"`
+	Compressions         []string `protobuf:"bytes,10,rep,name=compressions" json:"compressions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Handshake) Reset()         { *m = Handshake{} }
+func (m *Handshake) String() string { return proto.CompactTextString(m) }
+func (*Handshake) ProtoMessage()    {}
+func (*Handshake) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{10}
+}
+func (m *Handshake) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Handshake.Unmarshal(m, b)
+}
+func (m *Handshake) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Handshake.Marshal(b, m, deterministic)
+}
+func (dst *Handshake) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Handshake.Merge(dst, src)
+}
+func (m *Handshake) XXX_Size() int {
+	return xxx_messageInfo_Handshake.Size(m)
+}
+func (m *Handshake) XXX_DiscardUnknown() {
+	xxx_messageInfo_Handshake.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Handshake proto.InternalMessageInfo
+
+func (m *Handshake) GetChainId() uint32 {
+	if m != nil {
+		return m.ChainId
+	}
+	return 0
+}
+
+func (m *Handshake) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Handshake) GetTipHeight() uint64 {
+	if m != nil {
+		return m.TipHeight
+	}
+	return 0
+}
+
+func (m *Handshake) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *Handshake) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *Handshake) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *Handshake) GetAckNonce() uint64 {
+	if m != nil {
+		return m.AckNonce
+	}
+	return 0
+}
+
+func (m *Handshake) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Handshake) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *Handshake) GetCompressions() []string {
+	if m != nil {
+		return m.Compressions
+	}
+	return nil
+}
+
+// HandshakeConfirm completes the handshake started by the client. The client signs the nonce that the server has
+// challenged it with, together with its own nonce and the addresses of both sides, to prove that it holds the key
+// claimed in the handshake on this connection.
+type HandshakeConfirm struct {
+	Nonce                uint64   `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
+	AckNonce             uint64   `protobuf:"varint,2,opt,name=ack_nonce,json=ackNonce" json:"ack_nonce,omitempty"`
+	Signature            []byte   `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HandshakeConfirm) Reset()         { *m = HandshakeConfirm{} }
+func (m *HandshakeConfirm) String() string { return proto.CompactTextString(m) }
+func (*HandshakeConfirm) ProtoMessage()    {}
+func (*HandshakeConfirm) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{11}
+}
+func (m *HandshakeConfirm) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HandshakeConfirm.Unmarshal(m, b)
+}
+func (m *HandshakeConfirm) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HandshakeConfirm.Marshal(b, m, deterministic)
+}
+func (dst *HandshakeConfirm) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HandshakeConfirm.Merge(dst, src)
+}
+func (m *HandshakeConfirm) XXX_Size() int {
+	return xxx_messageInfo_HandshakeConfirm.Size(m)
+}
+func (m *HandshakeConfirm) XXX_DiscardUnknown() {
+	xxx_messageInfo_HandshakeConfirm.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HandshakeConfirm proto.InternalMessageInfo
+
+func (m *HandshakeConfirm) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *HandshakeConfirm) GetAckNonce() uint64 {
+	if m != nil {
+		return m.AckNonce
+	}
+	return 0
+}
+
+func (m *HandshakeConfirm) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type HandshakeConfirmRes struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HandshakeConfirmRes) Reset()         { *m = HandshakeConfirmRes{} }
+func (m *HandshakeConfirmRes) String() string { return proto.CompactTextString(m) }
+func (*HandshakeConfirmRes) ProtoMessage()    {}
+func (*HandshakeConfirmRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{12}
+}
+func (m *HandshakeConfirmRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HandshakeConfirmRes.Unmarshal(m, b)
+}
+func (m *HandshakeConfirmRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HandshakeConfirmRes.Marshal(b, m, deterministic)
+}
+func (dst *HandshakeConfirmRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HandshakeConfirmRes.Merge(dst, src)
+}
+func (m *HandshakeConfirmRes) XXX_Size() int {
+	return xxx_messageInfo_HandshakeConfirmRes.Size(m)
+}
+func (m *HandshakeConfirmRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_HandshakeConfirmRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HandshakeConfirmRes proto.InternalMessageInfo
+
+// PeerRecord is what the node remembers about a known peer across restarts
+type PeerRecord struct {
+	Addr                 string   `protobuf:"bytes,1,opt,name=addr" json:"addr,omitempty"`
+	LastSeen             int64    `protobuf:"varint,2,opt,name=last_seen,json=lastSeen" json:"last_seen,omitempty"`
+	Successes            uint32   `protobuf:"varint,3,opt,name=successes" json:"successes,omitempty"`
+	Failures             uint32   `protobuf:"varint,4,opt,name=failures" json:"failures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerRecord) Reset()         { *m = PeerRecord{} }
+func (m *PeerRecord) String() string { return proto.CompactTextString(m) }
+func (*PeerRecord) ProtoMessage()    {}
+func (*PeerRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{13}
+}
+func (m *PeerRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerRecord.Unmarshal(m, b)
+}
+func (m *PeerRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerRecord.Marshal(b, m, deterministic)
+}
+func (dst *PeerRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerRecord.Merge(dst, src)
+}
+func (m *PeerRecord) XXX_Size() int {
+	return xxx_messageInfo_PeerRecord.Size(m)
+}
+func (m *PeerRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerRecord proto.InternalMessageInfo
+
+func (m *PeerRecord) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *PeerRecord) GetLastSeen() int64 {
+	if m != nil {
+		return m.LastSeen
+	}
+	return 0
+}
+
+func (m *PeerRecord) GetSuccesses() uint32 {
+	if m != nil {
+		return m.Successes
+	}
+	return 0
+}
+
+func (m *PeerRecord) GetFailures() uint32 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+// PeerRecords is the persisted form of the peer store
+type PeerRecords struct {
+	Records              []*PeerRecord `protobuf:"bytes,1,rep,name=records" json:"records,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *PeerRecords) Reset()         { *m = PeerRecords{} }
+func (m *PeerRecords) String() string { return proto.CompactTextString(m) }
+func (*PeerRecords) ProtoMessage()    {}
+func (*PeerRecords) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{14}
+}
+func (m *PeerRecords) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerRecords.Unmarshal(m, b)
+}
+func (m *PeerRecords) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerRecords.Marshal(b, m, deterministic)
+}
+func (dst *PeerRecords) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerRecords.Merge(dst, src)
+}
+func (m *PeerRecords) XXX_Size() int {
+	return xxx_messageInfo_PeerRecords.Size(m)
+}
+func (m *PeerRecords) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerRecords.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerRecords proto.InternalMessageInfo
+
+func (m *PeerRecords) GetRecords() []*PeerRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+// NodeIdentity is the identity key pair of the node kept in the key file. The peer store keeps the public key only.
+type NodeIdentity struct {
+	PubKey               []byte   `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	PriKey               []byte   `protobuf:"bytes,2,opt,name=pri_key,json=priKey,proto3" json:"pri_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeIdentity) Reset()         { *m = NodeIdentity{} }
+func (m *NodeIdentity) String() string { return proto.CompactTextString(m) }
+func (*NodeIdentity) ProtoMessage()    {}
+func (*NodeIdentity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_e7a16240c939363e, []int{15}
+}
+func (m *NodeIdentity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeIdentity.Unmarshal(m, b)
+}
+func (m *NodeIdentity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeIdentity.Marshal(b, m, deterministic)
+}
+func (dst *NodeIdentity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeIdentity.Merge(dst, src)
+}
+func (m *NodeIdentity) XXX_Size() int {
+	return xxx_messageInfo_NodeIdentity.Size(m)
+}
+func (m *NodeIdentity) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeIdentity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeIdentity proto.InternalMessageInfo
+
+func (m *NodeIdentity) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *NodeIdentity) GetPriKey() []byte {
+	if m != nil {
+		return m.PriKey
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Ping)(nil), "network.Ping")
 	proto.RegisterType((*Pong)(nil), "network.Pong")
@@ -394,6 +851,14 @@ func init() {
 	proto.RegisterType((*BroadcastRes)(nil), "network.BroadcastRes")
 	proto.RegisterType((*TellReq)(nil), "network.TellReq")
 	proto.RegisterType((*TellRes)(nil), "network.TellRes")
+	proto.RegisterType((*FindNodeReq)(nil), "network.FindNodeReq")
+	proto.RegisterType((*FindNodeRes)(nil), "network.FindNodeRes")
+	proto.RegisterType((*Handshake)(nil), "network.Handshake")
+	proto.RegisterType((*HandshakeConfirm)(nil), "network.HandshakeConfirm")
+	proto.RegisterType((*HandshakeConfirmRes)(nil), "network.HandshakeConfirmRes")
+	proto.RegisterType((*PeerRecord)(nil), "network.PeerRecord")
+	proto.RegisterType((*PeerRecords)(nil), "network.PeerRecords")
+	proto.RegisterType((*NodeIdentity)(nil), "network.NodeIdentity")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -412,6 +877,9 @@ type PeerClient interface {
 	GetPeers(ctx context.Context, in *GetPeersReq, opts ...grpc.CallOption) (*GetPeersRes, error)
 	Broadcast(ctx context.Context, in *BroadcastReq, opts ...grpc.CallOption) (*BroadcastRes, error)
 	Tell(ctx context.Context, in *TellReq, opts ...grpc.CallOption) (*TellRes, error)
+	FindNode(ctx context.Context, in *FindNodeReq, opts ...grpc.CallOption) (*FindNodeRes, error)
+	Handshake(ctx context.Context, in *Handshake, opts ...grpc.CallOption) (*Handshake, error)
+	ConfirmHandshake(ctx context.Context, in *HandshakeConfirm, opts ...grpc.CallOption) (*HandshakeConfirmRes, error)
 }
 
 type peerClient struct {
@@ -458,12 +926,42 @@ func (c *peerClient) Tell(ctx context.Context, in *TellReq, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *peerClient) FindNode(ctx context.Context, in *FindNodeReq, opts ...grpc.CallOption) (*FindNodeRes, error) {
+	out := new(FindNodeRes)
+	err := c.cc.Invoke(ctx, "/network.Peer/findNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) Handshake(ctx context.Context, in *Handshake, opts ...grpc.CallOption) (*Handshake, error) {
+	out := new(Handshake)
+	err := c.cc.Invoke(ctx, "/network.Peer/handshake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) ConfirmHandshake(ctx context.Context, in *HandshakeConfirm, opts ...grpc.CallOption) (*HandshakeConfirmRes, error) {
+	out := new(HandshakeConfirmRes)
+	err := c.cc.Invoke(ctx, "/network.Peer/confirmHandshake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PeerServer is the server API for Peer service.
 type PeerServer interface {
 	Ping(context.Context, *Ping) (*Pong, error)
 	GetPeers(context.Context, *GetPeersReq) (*GetPeersRes, error)
 	Broadcast(context.Context, *BroadcastReq) (*BroadcastRes, error)
 	Tell(context.Context, *TellReq) (*TellRes, error)
+	FindNode(context.Context, *FindNodeReq) (*FindNodeRes, error)
+	Handshake(context.Context, *Handshake) (*Handshake, error)
+	ConfirmHandshake(context.Context, *HandshakeConfirm) (*HandshakeConfirmRes, error)
 }
 
 func RegisterPeerServer(s *grpc.Server, srv PeerServer) {
@@ -542,6 +1040,60 @@ func _Peer_Tell_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Peer_FindNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindNodeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).FindNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.Peer/FindNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).FindNode(ctx, req.(*FindNodeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peer_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Handshake)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.Peer/Handshake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).Handshake(ctx, req.(*Handshake))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peer_ConfirmHandshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeConfirm)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).ConfirmHandshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.Peer/ConfirmHandshake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).ConfirmHandshake(ctx, req.(*HandshakeConfirm))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Peer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "network.Peer",
 	HandlerType: (*PeerServer)(nil),
@@ -562,35 +1114,71 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "tell",
 			Handler:    _Peer_Tell_Handler,
 		},
+		{
+			MethodName: "findNode",
+			Handler:    _Peer_FindNode_Handler,
+		},
+		{
+			MethodName: "handshake",
+			Handler:    _Peer_Handshake_Handler,
+		},
+		{
+			MethodName: "confirmHandshake",
+			Handler:    _Peer_ConfirmHandshake_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "network/proto/rpc.proto",
 }
 
-func init() { proto.RegisterFile("network/proto/rpc.proto", fileDescriptor_rpc_e452c36976e6f4c9) }
-
-var fileDescriptor_rpc_e452c36976e6f4c9 = []byte{
-	// 348 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0x4d, 0x4f, 0xf2, 0x40,
-	0x18, 0xa4, 0x6f, 0xf7, 0xe5, 0xe3, 0x11, 0x12, 0xf2, 0x04, 0xb5, 0xd6, 0x4b, 0x59, 0x12, 0xc2,
-	0xc1, 0x80, 0xd1, 0x8b, 0x89, 0x37, 0x2e, 0xde, 0x0c, 0x69, 0xb8, 0x93, 0xd2, 0x6e, 0xaa, 0x69,
-	0xd9, 0xad, 0xbb, 0x6b, 0x4c, 0x7f, 0xa7, 0x7f, 0xc8, 0xec, 0x52, 0x9a, 0x62, 0xc0, 0xdb, 0xce,
-	0xf3, 0x35, 0x93, 0x99, 0x85, 0x6b, 0xce, 0xf4, 0x97, 0x90, 0xd9, 0xa2, 0x90, 0x42, 0x8b, 0x85,
-	0x2c, 0xe2, 0xb9, 0x7d, 0x61, 0xa7, 0x6a, 0xd0, 0x7b, 0x20, 0xab, 0x77, 0x9e, 0xe2, 0x08, 0xfe,
-	0x73, 0xc1, 0x63, 0xe6, 0x39, 0x81, 0x33, 0x23, 0xe1, 0x1e, 0x20, 0x02, 0x89, 0x92, 0x44, 0x7a,
-	0xff, 0x02, 0x67, 0xd6, 0x0b, 0xed, 0x9b, 0x4e, 0x80, 0xac, 0x04, 0x4f, 0xf1, 0x16, 0x7a, 0x51,
-	0x9c, 0x6d, 0x9a, 0x5b, 0xdd, 0x28, 0xce, 0x5e, 0x0d, 0xa6, 0x13, 0xb8, 0x78, 0x61, 0x7a, 0xc5,
-	0x98, 0x54, 0x21, 0xfb, 0x30, 0xd7, 0x63, 0xf1, 0xc9, 0xb5, 0x9d, 0x1b, 0x84, 0x7b, 0x40, 0xc7,
-	0xcd, 0x21, 0x55, 0x93, 0x39, 0x81, 0x5b, 0x93, 0x71, 0xe8, 0x2f, 0xa5, 0x88, 0x92, 0x38, 0x52,
-	0xda, 0x1c, 0xba, 0x82, 0xf6, 0x1b, 0x8b, 0x12, 0x26, 0xab, 0x4b, 0x15, 0xc2, 0x1b, 0xe8, 0xee,
-	0x54, 0xba, 0xd1, 0x65, 0xc1, 0xac, 0xd8, 0x41, 0xd8, 0xd9, 0xa9, 0x74, 0x5d, 0x16, 0xec, 0xd0,
-	0xda, 0x8a, 0xa4, 0xf4, 0xdc, 0xc0, 0x99, 0xf5, 0x6d, 0x6b, 0x29, 0x92, 0x12, 0x87, 0xe0, 0x6a,
-	0x9d, 0x7b, 0xc4, 0x2e, 0x98, 0x27, 0x9d, 0x1e, 0xf1, 0xa9, 0x73, 0x7c, 0x34, 0x83, 0xce, 0x9a,
-	0xe5, 0xf9, 0x5f, 0x92, 0x4e, 0x78, 0x77, 0x24, 0xd3, 0x3d, 0x2f, 0x93, 0x1c, 0xc9, 0xa4, 0xe3,
-	0x03, 0xd9, 0x59, 0x3d, 0x0f, 0xdf, 0x0e, 0x10, 0x63, 0x24, 0x4e, 0x81, 0x14, 0x26, 0xcf, 0xc1,
-	0xbc, 0x4a, 0x78, 0x6e, 0xe2, 0xf5, 0x1b, 0x50, 0xf0, 0x94, 0xb6, 0xf0, 0x09, 0xba, 0x69, 0xe5,
-	0x3d, 0x8e, 0xea, 0x66, 0x23, 0x33, 0xff, 0x54, 0x55, 0xd1, 0x16, 0x3e, 0x43, 0x6f, 0x7b, 0xb0,
-	0x08, 0x2f, 0xeb, 0xa1, 0x66, 0x4c, 0xfe, 0xc9, 0xb2, 0x59, 0xbe, 0x03, 0xa2, 0x59, 0x9e, 0xe3,
-	0xb0, 0x1e, 0xa8, 0x6c, 0xf4, 0x7f, 0x57, 0x14, 0x6d, 0x6d, 0xdb, 0xf6, 0xb3, 0x3e, 0xfe, 0x04,
-	0x00, 0x00, 0xff, 0xff, 0xb2, 0x38, 0xd7, 0x27, 0xc7, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("network/proto/rpc.proto", fileDescriptor_rpc_e7a16240c939363e) }
+
+var fileDescriptor_rpc_e7a16240c939363e = []byte{
+	// 725 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6f, 0xd3, 0x3e,
+	0x10, 0x5f, 0x9a, 0xac, 0x69, 0xae, 0xdd, 0x57, 0x95, 0xb7, 0x7d, 0x17, 0xb2, 0x21, 0x75, 0x9e,
+	0x34, 0xf5, 0x01, 0x36, 0x18, 0x42, 0x02, 0xc1, 0x03, 0x1a, 0x12, 0x6c, 0x9a, 0x98, 0xa6, 0xb0,
+	0xf7, 0x2a, 0x4d, 0xbc, 0xd4, 0x6a, 0x6b, 0x07, 0xdb, 0x65, 0xca, 0x1f, 0x81, 0x84, 0xc4, 0x13,
+	0xff, 0x2d, 0x8a, 0xeb, 0xa6, 0xc9, 0x68, 0xc7, 0x5b, 0xee, 0x73, 0xe7, 0xbb, 0xcf, 0xfd, 0x0c,
+	0xec, 0x31, 0xa2, 0xee, 0xb9, 0x18, 0x9f, 0x66, 0x82, 0x2b, 0x7e, 0x2a, 0xb2, 0xf8, 0x44, 0x7f,
+	0x21, 0xd7, 0x28, 0xf0, 0x0b, 0x70, 0x6e, 0x28, 0x4b, 0xd1, 0x0e, 0x6c, 0x32, 0xce, 0x62, 0xe2,
+	0x5b, 0x3d, 0xab, 0xef, 0x84, 0x73, 0x01, 0x21, 0x70, 0xa2, 0x24, 0x11, 0x7e, 0xa3, 0x67, 0xf5,
+	0xbd, 0x50, 0x7f, 0xe3, 0x23, 0x70, 0x6e, 0x38, 0x4b, 0xd1, 0x3e, 0x78, 0x51, 0x3c, 0x1e, 0x54,
+	0x5f, 0xb5, 0xa2, 0x78, 0x7c, 0x5d, 0xc8, 0xf8, 0x08, 0xda, 0x9f, 0x89, 0xba, 0x21, 0x44, 0xc8,
+	0x90, 0x7c, 0x2b, 0xbc, 0xc7, 0x7c, 0xc6, 0x94, 0xb6, 0xdb, 0x0a, 0xe7, 0x02, 0x3e, 0xac, 0x1a,
+	0xc9, 0x32, 0x98, 0xd5, 0xb3, 0xcb, 0x60, 0x3f, 0x2d, 0xe8, 0x9c, 0x0b, 0x1e, 0x25, 0x71, 0x24,
+	0x55, 0xe1, 0xe9, 0x7f, 0x68, 0x8e, 0x48, 0x94, 0x10, 0x61, 0x5c, 0x19, 0x09, 0x3d, 0x81, 0xd6,
+	0x54, 0xa6, 0x03, 0x95, 0x67, 0x44, 0xb3, 0xdd, 0x0a, 0xdd, 0xa9, 0x4c, 0x6f, 0xf3, 0x8c, 0x2c,
+	0x54, 0x43, 0x9e, 0xe4, 0xbe, 0xdd, 0xb3, 0xfa, 0x1d, 0xad, 0x3a, 0xe7, 0x49, 0x8e, 0xba, 0x60,
+	0x2b, 0x35, 0xf1, 0x1d, 0xfd, 0xa0, 0xf8, 0x44, 0x3d, 0x68, 0xc7, 0x7c, 0x9a, 0x09, 0x22, 0x25,
+	0xe5, 0xcc, 0xdf, 0xd4, 0x89, 0x57, 0x21, 0x7c, 0x5c, 0x63, 0x24, 0xd7, 0x31, 0xc2, 0x3f, 0x2c,
+	0x70, 0x6f, 0xc9, 0x64, 0xf2, 0x18, 0xeb, 0x15, 0xf5, 0xad, 0x65, 0x62, 0xaf, 0xcf, 0xc4, 0xa9,
+	0x67, 0xf2, 0x6f, 0xde, 0x87, 0x0b, 0x3a, 0xeb, 0x29, 0xbf, 0x85, 0xf6, 0x27, 0xca, 0x92, 0x6b,
+	0x9e, 0x10, 0xc3, 0x5a, 0x45, 0x22, 0x25, 0xf3, 0xb6, 0x75, 0x42, 0x23, 0xad, 0x9c, 0x8a, 0x97,
+	0xd5, 0xa7, 0x2b, 0x7b, 0x89, 0xfe, 0x83, 0x06, 0x4d, 0xfc, 0x46, 0xcf, 0xee, 0x77, 0xc2, 0x06,
+	0x4d, 0xf0, 0xef, 0x06, 0x78, 0x17, 0x11, 0x4b, 0xe4, 0x28, 0x1a, 0xeb, 0xdc, 0xe2, 0x51, 0x44,
+	0xd9, 0x80, 0x26, 0x86, 0x95, 0xab, 0xe5, 0xcb, 0x04, 0xf9, 0xe0, 0x7e, 0x27, 0x42, 0xe7, 0x65,
+	0x5a, 0x6b, 0x44, 0xf4, 0x14, 0x40, 0xd1, 0x6c, 0x30, 0x22, 0x34, 0x1d, 0x29, 0x5d, 0x2d, 0x27,
+	0xf4, 0x14, 0xcd, 0x2e, 0x34, 0x50, 0xb2, 0x70, 0x2a, 0xe5, 0xdd, 0x03, 0x37, 0x9b, 0x0d, 0x07,
+	0x63, 0x92, 0xeb, 0x22, 0x75, 0xc2, 0x66, 0x36, 0x1b, 0x5e, 0x91, 0x7c, 0xb9, 0x01, 0xcd, 0xea,
+	0x06, 0xd4, 0xa6, 0xdc, 0xad, 0x4f, 0x39, 0x3a, 0x00, 0x4f, 0xd2, 0x94, 0x45, 0x6a, 0x26, 0x88,
+	0xdf, 0xd2, 0xde, 0x96, 0x80, 0x2e, 0x1f, 0xcf, 0x68, 0x2c, 0x7d, 0x4f, 0x57, 0xc1, 0x48, 0x08,
+	0x43, 0xa7, 0xd2, 0x17, 0xe9, 0x83, 0xd6, 0xd6, 0x30, 0x1c, 0x43, 0xb7, 0x2c, 0xcd, 0x47, 0xce,
+	0xee, 0xa8, 0x98, 0xae, 0x59, 0xd1, 0x1a, 0xc1, 0xc6, 0x63, 0x04, 0xed, 0x07, 0x04, 0xf1, 0x2e,
+	0x6c, 0x3f, 0x0c, 0x12, 0x12, 0x89, 0xef, 0x01, 0x8a, 0x9d, 0x0c, 0x49, 0xcc, 0x45, 0x52, 0xe9,
+	0xe4, 0xb2, 0x86, 0xfb, 0xe0, 0x4d, 0x22, 0xa9, 0x06, 0x92, 0x90, 0x79, 0x4b, 0xec, 0xb0, 0x55,
+	0x00, 0x5f, 0x09, 0x61, 0x3a, 0xe6, 0x2c, 0x8e, 0x89, 0x94, 0x44, 0x9a, 0x01, 0x5e, 0x02, 0x28,
+	0x80, 0xd6, 0x5d, 0x44, 0x27, 0x33, 0x41, 0xa4, 0x59, 0xbb, 0x52, 0xc6, 0xef, 0xa1, 0xbd, 0x0c,
+	0x2c, 0xd1, 0x73, 0x70, 0xc5, 0xfc, 0x53, 0x8f, 0x51, 0xfb, 0x6c, 0xfb, 0xc4, 0x5c, 0xad, 0x93,
+	0xa5, 0x59, 0xb8, 0xb0, 0xc1, 0x1f, 0xa0, 0x53, 0x4c, 0xdf, 0x65, 0x42, 0x98, 0xa2, 0x2a, 0xaf,
+	0x36, 0xda, 0xaa, 0x35, 0xba, 0x50, 0x08, 0xaa, 0x15, 0x0d, 0xa3, 0x10, 0xf4, 0x8a, 0xe4, 0x67,
+	0xbf, 0x6c, 0x70, 0x0a, 0xcf, 0xe8, 0x18, 0x9c, 0xac, 0x38, 0x8a, 0x5b, 0xcb, 0x80, 0x94, 0xa5,
+	0x41, 0x45, 0xe4, 0x2c, 0xc5, 0x1b, 0xe8, 0x0d, 0xb4, 0x52, 0x73, 0xc0, 0xd0, 0x4e, 0xa9, 0xac,
+	0x1c, 0xbe, 0x60, 0x15, 0x2a, 0xf1, 0x06, 0x7a, 0x07, 0xde, 0x70, 0x71, 0x44, 0xd0, 0x6e, 0x69,
+	0x54, 0x3d, 0x75, 0xc1, 0x4a, 0xb8, 0x78, 0xfc, 0x0c, 0x1c, 0x45, 0x26, 0x13, 0xd4, 0x2d, 0x0d,
+	0xcc, 0x9d, 0x09, 0x1e, 0x22, 0x72, 0x4e, 0xf2, 0xce, 0x6c, 0x66, 0x85, 0x64, 0x65, 0xcf, 0x83,
+	0x55, 0x68, 0xf1, 0xf2, 0x35, 0x78, 0xa3, 0x72, 0x3f, 0x51, 0x69, 0x54, 0xce, 0x4c, 0xb0, 0x02,
+	0xc3, 0x1b, 0xe8, 0x0b, 0x74, 0xe3, 0xf9, 0x34, 0x55, 0xb6, 0xfb, 0x6f, 0x4b, 0x33, 0x71, 0xc1,
+	0xc1, 0x5a, 0x95, 0x66, 0x31, 0x6c, 0xea, 0x3f, 0xd6, 0xab, 0x3f, 0x03, 0x00, 0x43, 0x51, 0x81,
+	0x85, 0xcc, 0x06, 0x00, 0x00,
 }
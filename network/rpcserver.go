@@ -7,19 +7,21 @@
 package network
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
-	"google.golang.org/grpc/reflection"
 
 	cm "github.com/iotexproject/iotex-core/common"
 	"github.com/iotexproject/iotex-core/common/service"
 	"github.com/iotexproject/iotex-core/common/utils"
+	cp "github.com/iotexproject/iotex-core/crypto"
 	"github.com/iotexproject/iotex-core/logger"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
@@ -29,18 +31,45 @@ import (
 type RPCServer struct {
 	service.AbstractService
 	cm.Node
-	Server    *grpc.Server
+	// Transport carries the RPCs that the node serves and makes
+	Transport Transport
 	Overlay   *Overlay
+	listener  Listener
 	counters  sync.Map
-	rateLimit uint64
+	// handshakes are the handshakes completed by the clients, which are keyed by their transport addresses
+	handshakes sync.Map
+	// challenges are the handshakes started by the clients, which wait for the clients to answer the challenges
+	challenges sync.Map
+	rateLimit  uint64
 	// TODO: mutation of this field is not thread safe
 	started     bool
 	lastReqTime time.Time
 }
 
+// clientHandshake is the handshake completed by a client on its connection
+type clientHandshake struct {
+	identity *PeerIdentity
+	// lastSeen is the unix time in nanoseconds of the last request on the connection
+	lastSeen int64
+	// sender is the address that the client claims in its tell requests
+	sender atomic.Value
+}
+
+// handshakeChallenge is the handshake started by a client, which is completed once the client signs the challenge
+type handshakeChallenge struct {
+	identity *PeerIdentity
+	// clientAddr is the address that the client claims in its handshake
+	clientAddr string
+	// nonce is the nonce of the client's handshake, and challenge is the one of the server's
+	nonce     uint64
+	challenge uint64
+	// issued is the unix time in nanoseconds when the challenge is sent
+	issued int64
+}
+
 // NewRPCServer creates an instance of RPCServer
 func NewRPCServer(o *Overlay) *RPCServer {
-	s := &RPCServer{Overlay: o}
+	s := &RPCServer{Overlay: o, Transport: NewGRPCTransport(o.Config)}
 	s.Addr = o.Config.Addr
 	s.rateLimit = o.Config.RateLimitPerSec * uint64(o.Config.RateLimitWindowSize) / uint64(time.Second)
 	return s
@@ -56,6 +85,8 @@ func (s *RPCServer) Ping(ctx context.Context, ping *pb.Ping) (*pb.Pong, error) {
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
+	// keep the handshake on the connection if any
+	s.handshakedPeer(ctx)
 	s.Overlay.PM.AddPeer(ping.Addr)
 	return &pb.Pong{AckNonce: ping.Nonce}, nil
 }
@@ -95,6 +126,13 @@ func (s *RPCServer) Broadcast(ctx context.Context, req *pb.BroadcastReq) (*pb.Br
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
+	if _, err := s.handshakedPeer(ctx); err != nil {
+		return nil, err
+	}
+	if req.MsgBody, err = decompressMsg(req.Compression, req.MsgBody, s.maxMsgSize()); err != nil {
+		return nil, err
+	}
+	req.Compression = ""
 	err = s.Overlay.Gossip.OnReceivingMsg(req)
 	if err == nil {
 		return &pb.BroadcastRes{Header: iproto.MagicBroadcastMsgHeader}, nil
@@ -112,7 +150,21 @@ func (s *RPCServer) Tell(ctx context.Context, req *pb.TellReq) (*pb.TellRes, err
 	if drop {
 		return nil, fmt.Errorf("sended requests too frequently")
 	}
-	protoMsg, err := iproto.TypifyProtoMsg(req.MsgType, req.MsgBody)
+	hs, err := s.handshakedPeer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The claimed sender address is where the replies go, so it must not be the one of another verified identity
+	if id, ok := s.Overlay.Identities.Load(req.Addr); ok &&
+		!bytes.Equal(id.(*PeerIdentity).PublicKey, hs.identity.PublicKey) {
+		return nil, errors.Wrapf(ErrInvalidHandshake, "sender %s has another identity", req.Addr)
+	}
+	hs.sender.Store(req.Addr)
+	msgBody, err := decompressMsg(req.Compression, req.MsgBody, s.maxMsgSize())
+	if err != nil {
+		return nil, err
+	}
+	protoMsg, err := iproto.TypifyProtoMsg(req.MsgType, msgBody)
 	if err != nil {
 		return nil, err
 	}
@@ -122,45 +174,121 @@ func (s *RPCServer) Tell(ctx context.Context, req *pb.TellReq) (*pb.TellRes, err
 	return &pb.TellRes{Header: iproto.MagicBroadcastMsgHeader}, nil
 }
 
+// FindNode implements the server side RPC logic
+func (s *RPCServer) FindNode(ctx context.Context, req *pb.FindNodeReq) (*pb.FindNodeRes, error) {
+	drop, err := s.shouldDropRequest(ctx)
+	s.updateLastResTime()
+	if err != nil {
+		return nil, err
+	}
+	if drop {
+		return nil, fmt.Errorf("sended requests too frequently")
+	}
+	if s.Overlay.Kad == nil {
+		return nil, ErrKademliaNotStarted
+	}
+	return s.Overlay.Kad.OnFindNode(req)
+}
+
+// Handshake implements the server side RPC logic
+func (s *RPCServer) Handshake(ctx context.Context, req *pb.Handshake) (*pb.Handshake, error) {
+	drop, err := s.shouldDropRequest(ctx)
+	s.updateLastResTime()
+	if err != nil {
+		return nil, err
+	}
+	if drop {
+		return nil, fmt.Errorf("sended requests too frequently")
+	}
+	addr, err := s.getClientAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.Overlay.verifyHandshake(req)
+	if err != nil {
+		return nil, err
+	}
+	if s.Overlay.PM != nil && s.Overlay.PM.isBannedIdentity(id) {
+		return nil, ErrPeerBanned
+	}
+	// the client's signature is over a nonce of its own, which could be replayed, so the identity is only recorded once
+	// the client signs the fresh nonce of this response as well
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Overlay.newHandshake(nonce, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	s.challenges.Store(addr, &handshakeChallenge{
+		identity:   id,
+		clientAddr: req.Addr,
+		nonce:      req.Nonce,
+		challenge:  nonce,
+		issued:     time.Now().UnixNano(),
+	})
+	return res, nil
+}
+
+// ConfirmHandshake implements the server side RPC logic
+func (s *RPCServer) ConfirmHandshake(ctx context.Context, req *pb.HandshakeConfirm) (*pb.HandshakeConfirmRes, error) {
+	drop, err := s.shouldDropRequest(ctx)
+	s.updateLastResTime()
+	if err != nil {
+		return nil, err
+	}
+	if drop {
+		return nil, fmt.Errorf("sended requests too frequently")
+	}
+	addr, err := s.getClientAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// a challenge is answered once only
+	value, ok := s.challenges.Load(addr)
+	if !ok {
+		return nil, errors.Wrapf(ErrNoHandshake, "client %s", addr)
+	}
+	s.challenges.Delete(addr)
+	ch := value.(*handshakeChallenge)
+	if req.Nonce != ch.nonce || req.AckNonce != ch.challenge {
+		return nil, errors.Wrapf(ErrInvalidHandshake, "nonces %d and %d don't match the handshake of %s",
+			req.Nonce, req.AckNonce, addr)
+	}
+	hash := handshakeConfirmHash(req, ch.clientAddr, s.String())
+	if !cp.Verify(ch.identity.PublicKey, hash[:], req.Signature) {
+		return nil, errors.Wrapf(ErrInvalidHandshake, "confirmation doesn't match the key of %s", ch.clientAddr)
+	}
+	if s.Overlay.PM != nil && s.Overlay.PM.isBannedIdentity(ch.identity) {
+		return nil, ErrPeerBanned
+	}
+	s.handshakes.Store(addr, &clientHandshake{identity: ch.identity, lastSeen: time.Now().UnixNano()})
+	return &pb.HandshakeConfirmRes{}, nil
+}
+
 // Start starts the rpc server
 func (s *RPCServer) Start() error {
-	lis, err := net.Listen(s.Network(), s.String())
+	lis, err := s.Transport.Listen(s.String(), s)
 	if err != nil {
 		logger.Error().Err(err).Msg("Node failed to listen")
 		return err
 	}
-	s.Addr = lis.Addr().String()
-	// Create the gRPC server with the credentials
-	if s.Overlay.Config.TLSEnabled {
-		creds, err := generateServerCredentials(s.Overlay.Config)
-		if err != nil {
-			return err
-		}
-		s.Server = grpc.NewServer(
-			grpc.Creds(creds),
-			grpc.KeepaliveEnforcementPolicy(s.Overlay.Config.KLPolicy),
-			grpc.KeepaliveParams(s.Overlay.Config.KLServerParams),
-			grpc.MaxRecvMsgSize(s.Overlay.Config.MaxMsgSize))
-	} else {
-		s.Server = grpc.NewServer(
-			grpc.KeepaliveEnforcementPolicy(s.Overlay.Config.KLPolicy),
-			grpc.KeepaliveParams(s.Overlay.Config.KLServerParams),
-			grpc.MaxRecvMsgSize(1024*1024*10))
-	}
-
-	pb.RegisterPeerServer(s.Server, s)
-	// Register reflection service on gRPC peer.
-	reflection.Register(s.Server)
-	go func() {
-		logger.Info().Str("addr", s.String()).Msg("start PRC server")
-		s.started = true
-		if err := s.Server.Serve(lis); err != nil {
-			logger.Fatal().Err(err).Msg("Node failed to serve")
-		}
-	}()
+	s.listener = lis
+	s.Addr = lis.Addr()
+	logger.Info().Str("addr", s.String()).Msg("start PRC server")
+	s.started = true
 	return nil
 }
 
+// maxMsgSize returns the max size of the (decompressed) messages that the server accepts
+func (s *RPCServer) maxMsgSize() int {
+	if s.Overlay.Config.MaxMsgSize > 0 {
+		return s.Overlay.Config.MaxMsgSize
+	}
+	return 1024 * 1024 * 10
+}
+
 // Started returns the boolean to indicate whether the rpc server is started
 func (s *RPCServer) Started() bool {
 	return s.started
@@ -169,7 +297,9 @@ func (s *RPCServer) Started() bool {
 // Stop stops the rpc server
 func (s *RPCServer) Stop() error {
 	logger.Info().Str("addr", s.String()).Msg("stop PRC server")
-	s.Server.Stop()
+	if s.listener != nil {
+		s.listener.Close()
+	}
 	s.started = false
 	return nil
 }
@@ -208,6 +338,59 @@ func (s *RPCServer) getClientAddr(ctx context.Context) (string, error) {
 	return p.Addr.String(), nil
 }
 
+// handshakedPeer returns the handshake that the client has completed on the connection of the request. The messages are
+// only taken from the clients which have completed the handshake with an identity that isn't banned.
+func (s *RPCServer) handshakedPeer(ctx context.Context) (*clientHandshake, error) {
+	addr, err := s.getClientAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := s.handshakes.Load(addr)
+	if !ok {
+		return nil, errors.Wrapf(ErrNoHandshake, "client %s", addr)
+	}
+	hs := value.(*clientHandshake)
+	if s.Overlay.PM != nil && s.Overlay.PM.isBannedIdentity(hs.identity) {
+		return nil, ErrPeerBanned
+	}
+	atomic.StoreInt64(&hs.lastSeen, time.Now().UnixNano())
+	return hs, nil
+}
+
+// senderIdentity returns the identity proved on the connection of the client which claims the sender address in its
+// tell requests
+func (s *RPCServer) senderIdentity(addr string) (*PeerIdentity, bool) {
+	var id *PeerIdentity
+	s.handshakes.Range(func(key, value interface{}) bool {
+		hs := value.(*clientHandshake)
+		if sender, ok := hs.sender.Load().(string); ok && sender == addr {
+			id = hs.identity
+			return false
+		}
+		return true
+	})
+	return id, id != nil
+}
+
+// pruneHandshakes forgets the handshakes on the connections which have been silent for longer than the interval, and
+// the challenges not answered within it. The peers ping their connections regularly, so the connections still open are
+// kept.
+func (s *RPCServer) pruneHandshakes(silentInterval time.Duration) {
+	deadline := time.Now().Add(-silentInterval).UnixNano()
+	s.handshakes.Range(func(key, value interface{}) bool {
+		if atomic.LoadInt64(&value.(*clientHandshake).lastSeen) < deadline {
+			s.handshakes.Delete(key)
+		}
+		return true
+	})
+	s.challenges.Range(func(key, value interface{}) bool {
+		if value.(*handshakeChallenge).issued < deadline {
+			s.challenges.Delete(key)
+		}
+		return true
+	})
+}
+
 // Update the last time when successfully getting an req from the peer
 func (s *RPCServer) updateLastResTime() {
 	s.lastReqTime = time.Now()
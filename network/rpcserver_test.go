@@ -15,6 +15,9 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/iotexproject/iotex-core/config"
+	cp "github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/iotxaddress"
 	pb "github.com/iotexproject/iotex-core/network/proto"
 	"github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
@@ -24,19 +27,27 @@ import (
 func TestRpcPingPong(t *testing.T) {
 	config := LoadTestConfig("", true)
 	o := &Overlay{Config: config}
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	assert.Nil(t, err)
+	o.Identity = id
 	o.PM = &PeerManager{Overlay: o, NumPeersLowerBound: 1, NumPeersUpperBound: 1}
+	o.PeerStore = NewPeerStore(config)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
 	p := NewPeer(s.Network(), s.String())
 	p.Connect(config)
+	o1 := startPingingNode(config)
 
 	defer func() {
 		p.Close()
 		s.Stop()
+		o1.PRC.Stop()
 	}()
 
-	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o.PRC.Started(), nil })
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o.PRC.Started() && o1.PRC.Started(), nil
+	})
 
 	pong, err := p.Ping(&pb.Ping{Nonce: uint64(4689), Addr: "127.0.0.1:10001"})
 	assert.Nil(t, err)
@@ -94,8 +105,9 @@ func TestGetPeers(t *testing.T) {
 func TestBroadcast(t *testing.T) {
 	config := LoadTestConfig("", true)
 	o := &Overlay{Config: config}
+	o.Identity = newTestIdentity(t)
 	o.PM = &PeerManager{Overlay: o}
-	o.Gossip = &Gossip{Overlay: o}
+	o.Gossip = NewGossip(o)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
@@ -111,8 +123,12 @@ func TestBroadcast(t *testing.T) {
 
 	txMsg := &iproto.TxPb{}
 	b, _ := proto.Marshal(txMsg)
-	res, err := p.BroadcastMsg(
-		&pb.BroadcastReq{Header: iproto.MagicBroadcastMsgHeader, MsgType: iproto.MsgTxProtoMsgType, MsgBody: b})
+	req := &pb.BroadcastReq{Header: iproto.MagicBroadcastMsgHeader, MsgType: iproto.MsgTxProtoMsgType, MsgBody: b}
+	// The message is taken only after the handshake on the connection
+	_, err := p.BroadcastMsg(req)
+	assert.NotNil(t, err)
+	handshakeWith(t, p, config)
+	res, err := p.BroadcastMsg(req)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 	assert.Equal(t, iproto.MagicBroadcastMsgHeader, res.Header)
@@ -125,6 +141,7 @@ func TestRPCTell(t *testing.T) {
 
 	config := LoadTestConfig("", true)
 	o := &Overlay{Dispatcher: dp, Config: config}
+	o.Identity = newTestIdentity(t)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
@@ -141,25 +158,101 @@ func TestRPCTell(t *testing.T) {
 
 	txMsg := &iproto.TxPb{}
 	b, _ := proto.Marshal(txMsg)
-	res, err := p.Tell(&pb.TellReq{Header: iproto.MagicBroadcastMsgHeader,
+	req := &pb.TellReq{Header: iproto.MagicBroadcastMsgHeader,
 		Addr:    s.String(),
 		MsgType: iproto.MsgTxProtoMsgType,
-		MsgBody: b})
+		MsgBody: b}
+	// The message is taken only after the handshake on the connection
+	_, err := p.Tell(req)
+	assert.NotNil(t, err)
+	handshakeWith(t, p, config)
+	res, err := p.Tell(req)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 	assert.Equal(t, iproto.MagicBroadcastMsgHeader, res.Header)
+
+	// The handshake is forgotten once the connection is silent for long
+	s.pruneHandshakes(0)
+	_, err = p.Tell(req)
+	assert.NotNil(t, err)
+}
+
+func TestHandshakeReplay(t *testing.T) {
+	config := LoadTestConfig("", true)
+	o := &Overlay{Config: config}
+	o.Identity = newTestIdentity(t)
+	s := NewRPCServer(o)
+	o.PRC = s
+	s.Start()
+	honest := NewPeer(s.Network(), s.String())
+	honest.Connect(config)
+	attacker := NewPeer(s.Network(), s.String())
+	attacker.Connect(config)
+
+	defer func() {
+		honest.Close()
+		attacker.Close()
+		s.Stop()
+	}()
+
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o.PRC.Started(), nil })
+
+	// the honest node starts the handshake, whose request the attacker captures
+	client := &Overlay{Config: config}
+	client.Identity = newTestIdentity(t)
+	client.PRC = NewRPCServer(client)
+	req, err := client.newHandshake(1, 0)
+	assert.Nil(t, err)
+	res, err := honest.Handshake(req)
+	assert.Nil(t, err)
+
+	// replaying the request doesn't complete the handshake on another connection
+	replayed, err := attacker.Handshake(proto.Clone(req).(*pb.Handshake))
+	assert.Nil(t, err)
+	assert.NotEqual(t, res.Nonce, replayed.Nonce)
+	tell := &pb.TellReq{Addr: "127.0.0.1:2", MsgType: iproto.MsgTxProtoMsgType}
+	_, err = attacker.Tell(tell)
+	assert.True(t, strings.Contains(err.Error(), ErrNoHandshake.Error()))
+	// nor does the confirmation of the honest node, which answers another challenge
+	confirm := &pb.HandshakeConfirm{Nonce: req.Nonce, AckNonce: res.Nonce}
+	hash := handshakeConfirmHash(confirm, req.Addr, res.Addr)
+	confirm.Signature = cp.Sign(client.Identity.PrivateKey, hash[:])
+	_, err = attacker.ConfirmHandshake(confirm)
+	assert.True(t, strings.Contains(err.Error(), ErrInvalidHandshake.Error()))
+	// the challenge is answered once only
+	_, err = attacker.ConfirmHandshake(confirm)
+	assert.True(t, strings.Contains(err.Error(), ErrNoHandshake.Error()))
+
+	// the confirmation for another server is rejected
+	forOther := &pb.HandshakeConfirm{Nonce: req.Nonce, AckNonce: res.Nonce}
+	hash = handshakeConfirmHash(forOther, req.Addr, "127.0.0.1:1")
+	forOther.Signature = cp.Sign(client.Identity.PrivateKey, hash[:])
+	_, err = honest.ConfirmHandshake(forOther)
+	assert.True(t, strings.Contains(err.Error(), ErrInvalidHandshake.Error()))
+
+	// the honest node completes the handshake once it answers the challenge
+	res, err = honest.Handshake(req)
+	assert.Nil(t, err)
+	confirm = &pb.HandshakeConfirm{Nonce: req.Nonce, AckNonce: res.Nonce}
+	hash = handshakeConfirmHash(confirm, req.Addr, res.Addr)
+	confirm.Signature = cp.Sign(client.Identity.PrivateKey, hash[:])
+	_, err = honest.ConfirmHandshake(confirm)
+	assert.Nil(t, err)
+	_, err = honest.Tell(tell)
+	assert.Nil(t, err)
 }
 
 func TestRateLimit(t *testing.T) {
 	mctrl := gomock.NewController(t)
 	dp := mock_dispatcher.NewMockDispatcher(mctrl)
-	dp.EXPECT().HandleTell(gomock.Any(), gomock.Any(), gomock.Any()).Times(5)
+	dp.EXPECT().HandleTell(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 
 	config := LoadTestConfig("", true)
 	config.RateLimitEnabled = true
 	config.RateLimitPerSec = 5
 	config.RateLimitWindowSize = time.Second
 	o := &Overlay{Dispatcher: dp, Config: config}
+	o.Identity = newTestIdentity(t)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
@@ -173,6 +266,8 @@ func TestRateLimit(t *testing.T) {
 	}()
 
 	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o.PRC.Started(), nil })
+	// The handshake and its confirmation are counted as requests as well
+	handshakeWith(t, p, config)
 
 	var res *pb.TellRes
 	var err error
@@ -183,7 +278,7 @@ func TestRateLimit(t *testing.T) {
 			Addr:    s.String(),
 			MsgType: iproto.MsgTxProtoMsgType,
 			MsgBody: b})
-		if i < 5 {
+		if i < 3 {
 			assert.Nil(t, err)
 			assert.NotNil(t, res, i)
 			assert.Equal(t, iproto.MagicBroadcastMsgHeader, res.Header)
@@ -202,19 +297,27 @@ func TestSecureRpcPingPong(t *testing.T) {
 	config.PeerCrtPath = "../test/assets/ssl/127.0.0.1.crt"
 	config.PeerKeyPath = "../test/assets/ssl/127.0.0.1.key"
 	o := &Overlay{Config: config}
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	assert.Nil(t, err)
+	o.Identity = id
 	o.PM = &PeerManager{Overlay: o, NumPeersLowerBound: 1, NumPeersUpperBound: 1}
+	o.PeerStore = NewPeerStore(config)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
 	p := NewPeer(s.Network(), s.String())
 	p.Connect(config)
+	o1 := startPingingNode(config)
 
 	defer func() {
 		p.Close()
 		s.Stop()
+		o1.PRC.Stop()
 	}()
 
-	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o.PRC.Started(), nil })
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o.PRC.Started() && o1.PRC.Started(), nil
+	})
 
 	pong, err := p.Ping(&pb.Ping{Nonce: uint64(4689), Addr: "127.0.0.1:10001"})
 	assert.Nil(t, err)
@@ -235,19 +338,27 @@ func TestKeepaliveParams(t *testing.T) {
 	config.KLClientParams.Timeout = 20 * time.Millisecond
 	config.KLPolicy.MinTime = 20 * time.Millisecond
 	o := &Overlay{Config: config}
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	assert.Nil(t, err)
+	o.Identity = id
 	o.PM = &PeerManager{Overlay: o, NumPeersLowerBound: 1, NumPeersUpperBound: 1}
+	o.PeerStore = NewPeerStore(config)
 	s := NewRPCServer(o)
 	o.PRC = s
 	s.Start()
 	p := NewPeer(s.Network(), s.String())
 	p.Connect(config)
+	o1 := startPingingNode(config)
 
 	defer func() {
 		p.Close()
 		s.Stop()
+		o1.PRC.Stop()
 	}()
 
-	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) { return o.PRC.Started(), nil })
+	util.WaitUntil(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return o.PRC.Started() && o1.PRC.Started(), nil
+	})
 
 	for i := 0; i < 5; i++ {
 		time.Sleep(100 * time.Millisecond)
@@ -261,3 +372,27 @@ func TestKeepaliveParams(t *testing.T) {
 		assert.True(t, "127.0.0.1:10001" == value.(*Peer).String())
 	}
 }
+
+// startPingingNode starts the RPC server of the node at 127.0.0.1:10001, which pings others in the tests. Its RPC server
+// needs to run for the handshake when it's added as a peer.
+func startPingingNode(cfg *config.Network) *Overlay {
+	c := *cfg
+	c.Addr = "127.0.0.1:10001"
+	o := NewOverlay(&c)
+	o.PRC.Start()
+	return o
+}
+
+func newTestIdentity(t *testing.T) *iotxaddress.Address {
+	id, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	assert.Nil(t, err)
+	return id
+}
+
+// handshakeWith completes the handshake on the connection to the peer with a new identity
+func handshakeWith(t *testing.T, p *Peer, cfg *config.Network) {
+	o := &Overlay{Config: cfg}
+	o.Identity = newTestIdentity(t)
+	o.PRC = NewRPCServer(o)
+	assert.Nil(t, o.handshake(p))
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package network
+
+import (
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+	pb "github.com/iotexproject/iotex-core/network/proto"
+)
+
+// Transport carries the RPCs between the nodes. A node listens and dials on the same transport instance.
+type Transport interface {
+	// Listen starts serving the RPCs with the server at the address
+	Listen(addr string, server pb.PeerServer) (Listener, error)
+	// Dial creates a connection to the node at the address
+	Dial(addr string) (Conn, error)
+}
+
+// Listener is a started server on the transport
+type Listener interface {
+	io.Closer
+	// Addr returns the actual address that the listener is bound to
+	Addr() string
+}
+
+// Conn is a connection to another node, on which the client side RPCs are made
+type Conn interface {
+	pb.PeerClient
+	io.Closer
+}
+
+// grpcTransport carries the RPCs by gRPC over TCP
+type grpcTransport struct {
+	config *config.Network
+}
+
+// NewGRPCTransport creates a transport which carries the RPCs by gRPC over TCP
+func NewGRPCTransport(config *config.Network) Transport {
+	return &grpcTransport{config: config}
+}
+
+type grpcListener struct {
+	server *grpc.Server
+	addr   string
+}
+
+// Close stops the gRPC server
+func (l *grpcListener) Close() error {
+	l.server.Stop()
+	return nil
+}
+
+// Addr returns the TCP address that the gRPC server listens on
+func (l *grpcListener) Addr() string {
+	return l.addr
+}
+
+type grpcConn struct {
+	pb.PeerClient
+	*grpc.ClientConn
+}
+
+// Listen starts a gRPC server at the TCP address
+func (t *grpcTransport) Listen(addr string, server pb.PeerServer) (Listener, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// Create the gRPC server with the credentials
+	var s *grpc.Server
+	if t.config.TLSEnabled {
+		creds, err := generateServerCredentials(t.config)
+		if err != nil {
+			return nil, err
+		}
+		s = grpc.NewServer(
+			grpc.Creds(creds),
+			grpc.KeepaliveEnforcementPolicy(t.config.KLPolicy),
+			grpc.KeepaliveParams(t.config.KLServerParams),
+			grpc.MaxRecvMsgSize(t.config.MaxMsgSize))
+	} else {
+		s = grpc.NewServer(
+			grpc.KeepaliveEnforcementPolicy(t.config.KLPolicy),
+			grpc.KeepaliveParams(t.config.KLServerParams),
+			grpc.MaxRecvMsgSize(1024*1024*10))
+	}
+
+	pb.RegisterPeerServer(s, server)
+	// Register reflection service on gRPC peer.
+	reflection.Register(s)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			logger.Fatal().Err(err).Msg("Node failed to serve")
+		}
+	}()
+	return &grpcListener{server: s, addr: lis.Addr().String()}, nil
+}
+
+// Dial sets up a gRPC connection to the TCP address
+func (t *grpcTransport) Dial(addr string) (Conn, error) {
+	opts := []grpc.DialOption{grpc.WithKeepaliveParams(t.config.KLClientParams)}
+	if t.config.TLSEnabled {
+		creds, err := generateClientCredentials(t.config)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(
+			opts,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(t.config.MaxMsgSize)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcConn{PeerClient: pb.NewPeerClient(conn), ClientConn: conn}, nil
+}
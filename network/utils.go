@@ -38,6 +38,14 @@ func stringsAreShuffled(slice []string) {
 	}
 }
 
+// peersAreShuffled shuffles a peer slice
+func peersAreShuffled(slice []*Peer) {
+	for i := range slice {
+		j := rand.Intn(i + 1)
+		slice[i], slice[j] = slice[j], slice[i]
+	}
+}
+
 func loadCertAndCertPool(config *config.Network) (*tls.Certificate, *x509.CertPool, error) {
 	// Load the certificates from disk
 	cert, err := tls.LoadX509KeyPair(config.PeerCrtPath, config.PeerKeyPath)
@@ -19,16 +19,40 @@ It has these top-level messages:
 	BlockHeaderPb
 	BlockPb
 	BlockIndex
+	AccountStatePb
+	VoterPb
+	CandidatePoolPb
+	CandidatePb
+	VoterRewardPoolPb
+	DelegateRewardPb
+	RewardReceiptsPb
+	RewardReceiptPb
+	StateDiffPb
+	AccountDiffPb
 	PingMsg
 	PongMsg
 	BlockSync
 	BlockContainer
+	BlockHeaderSync
+	BlockHeaderContainer
+	StateSnapshotSync
+	StateSnapshotChunk
 	ViewChangeMsg
 	TestPayload
 	CreateRawTransferRequest
 	CreateRawTransferResponse
+	CreateRawVoteRequest
+	CreateRawVoteResponse
 	SendTransferRequest
 	SendTransferResponse
+	SendVoteRequest
+	SendVoteResponse
+	SyncStatus
+	GetSyncStatusRequest
+	GetSyncStatusResponse
+	SimulateActionsRequest
+	SimulatedAccount
+	SimulateActionsResponse
 	UtxoPb
 	UtxoEntryPb
 	UtxoMapPb
@@ -76,7 +100,7 @@ func (x ViewChangeMsg_ViewChangeType) String() string {
 	return proto.EnumName(ViewChangeMsg_ViewChangeType_name, int32(x))
 }
 func (ViewChangeMsg_ViewChangeType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{13, 0}
+	return fileDescriptor0, []int{27, 0}
 }
 
 type TxInputPb struct {
@@ -295,6 +319,7 @@ type VotePb struct {
 	Timestamp  uint64 `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
 	SelfPubkey []byte `protobuf:"bytes,5,opt,name=selfPubkey,proto3" json:"selfPubkey,omitempty"`
 	VotePubkey []byte `protobuf:"bytes,6,opt,name=votePubkey,proto3" json:"votePubkey,omitempty"`
+	Unvote     bool   `protobuf:"varint,7,opt,name=unvote" json:"unvote,omitempty"`
 }
 
 func (m *VotePb) Reset()                    { *m = VotePb{} }
@@ -344,6 +369,13 @@ func (m *VotePb) GetVotePubkey() []byte {
 	return nil
 }
 
+func (m *VotePb) GetUnvote() bool {
+	if m != nil {
+		return m.Unvote
+	}
+	return false
+}
+
 type ActionPb struct {
 	// Types that are valid to be assigned to Action:
 	//	*ActionPb_Tx
@@ -652,6 +684,339 @@ func (m *BlockIndex) GetOffset() []uint32 {
 	return nil
 }
 
+// account state kept in the trie, whose amounts are the big-endian bytes of non-negative big integers
+type AccountStatePb struct {
+	Nonce         uint64     `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
+	Balance       []byte     `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Address       string     `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
+	IsCandidate   bool       `protobuf:"varint,4,opt,name=isCandidate" json:"isCandidate,omitempty"`
+	VotingWeight  []byte     `protobuf:"bytes,5,opt,name=votingWeight,proto3" json:"votingWeight,omitempty"`
+	Votee         string     `protobuf:"bytes,6,opt,name=votee" json:"votee,omitempty"`
+	Voters        []*VoterPb `protobuf:"bytes,7,rep,name=voters" json:"voters,omitempty"`
+	LockedBalance []byte     `protobuf:"bytes,8,opt,name=lockedBalance,proto3" json:"lockedBalance,omitempty"`
+	UnlockHeight  uint64     `protobuf:"varint,9,opt,name=unlockHeight" json:"unlockHeight,omitempty"`
+}
+
+func (m *AccountStatePb) Reset()                    { *m = AccountStatePb{} }
+func (m *AccountStatePb) String() string            { return proto.CompactTextString(m) }
+func (*AccountStatePb) ProtoMessage()               {}
+func (*AccountStatePb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *AccountStatePb) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *AccountStatePb) GetBalance() []byte {
+	if m != nil {
+		return m.Balance
+	}
+	return nil
+}
+
+func (m *AccountStatePb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *AccountStatePb) GetIsCandidate() bool {
+	if m != nil {
+		return m.IsCandidate
+	}
+	return false
+}
+
+func (m *AccountStatePb) GetVotingWeight() []byte {
+	if m != nil {
+		return m.VotingWeight
+	}
+	return nil
+}
+
+func (m *AccountStatePb) GetVotee() string {
+	if m != nil {
+		return m.Votee
+	}
+	return ""
+}
+
+func (m *AccountStatePb) GetVoters() []*VoterPb {
+	if m != nil {
+		return m.Voters
+	}
+	return nil
+}
+
+func (m *AccountStatePb) GetLockedBalance() []byte {
+	if m != nil {
+		return m.LockedBalance
+	}
+	return nil
+}
+
+func (m *AccountStatePb) GetUnlockHeight() uint64 {
+	if m != nil {
+		return m.UnlockHeight
+	}
+	return 0
+}
+
+type VoterPb struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Weight  []byte `protobuf:"bytes,2,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (m *VoterPb) Reset()                    { *m = VoterPb{} }
+func (m *VoterPb) String() string            { return proto.CompactTextString(m) }
+func (*VoterPb) ProtoMessage()               {}
+func (*VoterPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *VoterPb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *VoterPb) GetWeight() []byte {
+	if m != nil {
+		return m.Weight
+	}
+	return nil
+}
+
+// candidate pools kept in the trie, whose candidates are sorted by the votes and then the addresses
+type CandidatePoolPb struct {
+	CandidateSize    uint32         `protobuf:"varint,1,opt,name=candidateSize" json:"candidateSize,omitempty"`
+	BufferSize       uint32         `protobuf:"varint,2,opt,name=bufferSize" json:"bufferSize,omitempty"`
+	Candidates       []*CandidatePb `protobuf:"bytes,3,rep,name=candidates" json:"candidates,omitempty"`
+	BufferCandidates []*CandidatePb `protobuf:"bytes,4,rep,name=bufferCandidates" json:"bufferCandidates,omitempty"`
+}
+
+func (m *CandidatePoolPb) Reset()                    { *m = CandidatePoolPb{} }
+func (m *CandidatePoolPb) String() string            { return proto.CompactTextString(m) }
+func (*CandidatePoolPb) ProtoMessage()               {}
+func (*CandidatePoolPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *CandidatePoolPb) GetCandidateSize() uint32 {
+	if m != nil {
+		return m.CandidateSize
+	}
+	return 0
+}
+
+func (m *CandidatePoolPb) GetBufferSize() uint32 {
+	if m != nil {
+		return m.BufferSize
+	}
+	return 0
+}
+
+func (m *CandidatePoolPb) GetCandidates() []*CandidatePb {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
+}
+
+func (m *CandidatePoolPb) GetBufferCandidates() []*CandidatePb {
+	if m != nil {
+		return m.BufferCandidates
+	}
+	return nil
+}
+
+type CandidatePb struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Votes   []byte `protobuf:"bytes,2,opt,name=votes,proto3" json:"votes,omitempty"`
+	PubKey  []byte `protobuf:"bytes,3,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
+}
+
+func (m *CandidatePb) Reset()                    { *m = CandidatePb{} }
+func (m *CandidatePb) String() string            { return proto.CompactTextString(m) }
+func (*CandidatePb) ProtoMessage()               {}
+func (*CandidatePb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *CandidatePb) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *CandidatePb) GetVotes() []byte {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
+
+func (m *CandidatePb) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+// voter rewards accrued by the delegates in the current reward epoch kept in the trie, sorted by the delegates
+type VoterRewardPoolPb struct {
+	Height  uint64              `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Rewards []*DelegateRewardPb `protobuf:"bytes,2,rep,name=rewards" json:"rewards,omitempty"`
+}
+
+func (m *VoterRewardPoolPb) Reset()                    { *m = VoterRewardPoolPb{} }
+func (m *VoterRewardPoolPb) String() string            { return proto.CompactTextString(m) }
+func (*VoterRewardPoolPb) ProtoMessage()               {}
+func (*VoterRewardPoolPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *VoterRewardPoolPb) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *VoterRewardPoolPb) GetRewards() []*DelegateRewardPb {
+	if m != nil {
+		return m.Rewards
+	}
+	return nil
+}
+
+type DelegateRewardPb struct {
+	Delegate string `protobuf:"bytes,1,opt,name=delegate" json:"delegate,omitempty"`
+	Amount   []byte `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (m *DelegateRewardPb) Reset()                    { *m = DelegateRewardPb{} }
+func (m *DelegateRewardPb) String() string            { return proto.CompactTextString(m) }
+func (*DelegateRewardPb) ProtoMessage()               {}
+func (*DelegateRewardPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *DelegateRewardPb) GetDelegate() string {
+	if m != nil {
+		return m.Delegate
+	}
+	return ""
+}
+
+func (m *DelegateRewardPb) GetAmount() []byte {
+	if m != nil {
+		return m.Amount
+	}
+	return nil
+}
+
+// receipts of the voter rewards credited to a voter kept in the chain DB, in the order of the heights
+type RewardReceiptsPb struct {
+	Receipts []*RewardReceiptPb `protobuf:"bytes,1,rep,name=receipts" json:"receipts,omitempty"`
+}
+
+func (m *RewardReceiptsPb) Reset()                    { *m = RewardReceiptsPb{} }
+func (m *RewardReceiptsPb) String() string            { return proto.CompactTextString(m) }
+func (*RewardReceiptsPb) ProtoMessage()               {}
+func (*RewardReceiptsPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *RewardReceiptsPb) GetReceipts() []*RewardReceiptPb {
+	if m != nil {
+		return m.Receipts
+	}
+	return nil
+}
+
+type RewardReceiptPb struct {
+	Height   uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Delegate string `protobuf:"bytes,2,opt,name=delegate" json:"delegate,omitempty"`
+	Voter    string `protobuf:"bytes,3,opt,name=voter" json:"voter,omitempty"`
+	Amount   []byte `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (m *RewardReceiptPb) Reset()                    { *m = RewardReceiptPb{} }
+func (m *RewardReceiptPb) String() string            { return proto.CompactTextString(m) }
+func (*RewardReceiptPb) ProtoMessage()               {}
+func (*RewardReceiptPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *RewardReceiptPb) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *RewardReceiptPb) GetDelegate() string {
+	if m != nil {
+		return m.Delegate
+	}
+	return ""
+}
+
+func (m *RewardReceiptPb) GetVoter() string {
+	if m != nil {
+		return m.Voter
+	}
+	return ""
+}
+
+func (m *RewardReceiptPb) GetAmount() []byte {
+	if m != nil {
+		return m.Amount
+	}
+	return nil
+}
+
+// changes of the accounts made by the actions of a block kept in the chain DB, sorted by the addresses
+type StateDiffPb struct {
+	Height   uint64           `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Accounts []*AccountDiffPb `protobuf:"bytes,2,rep,name=accounts" json:"accounts,omitempty"`
+}
+
+func (m *StateDiffPb) Reset()                    { *m = StateDiffPb{} }
+func (m *StateDiffPb) String() string            { return proto.CompactTextString(m) }
+func (*StateDiffPb) ProtoMessage()               {}
+func (*StateDiffPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *StateDiffPb) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *StateDiffPb) GetAccounts() []*AccountDiffPb {
+	if m != nil {
+		return m.Accounts
+	}
+	return nil
+}
+
+type AccountDiffPb struct {
+	Old *AccountStatePb `protobuf:"bytes,1,opt,name=old" json:"old,omitempty"`
+	New *AccountStatePb `protobuf:"bytes,2,opt,name=new" json:"new,omitempty"`
+}
+
+func (m *AccountDiffPb) Reset()                    { *m = AccountDiffPb{} }
+func (m *AccountDiffPb) String() string            { return proto.CompactTextString(m) }
+func (*AccountDiffPb) ProtoMessage()               {}
+func (*AccountDiffPb) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *AccountDiffPb) GetOld() *AccountStatePb {
+	if m != nil {
+		return m.Old
+	}
+	return nil
+}
+
+func (m *AccountDiffPb) GetNew() *AccountStatePb {
+	if m != nil {
+		return m.New
+	}
+	return nil
+}
+
 // //////////////////////////////////////////////////////////////////////////////////////////////////
 // BELOW ARE DEFINITIONS FOR ON-WIRE MESSAGES!
 // //////////////////////////////////////////////////////////////////////////////////////////////////
@@ -662,7 +1027,7 @@ type PingMsg struct {
 func (m *PingMsg) Reset()                    { *m = PingMsg{} }
 func (m *PingMsg) String() string            { return proto.CompactTextString(m) }
 func (*PingMsg) ProtoMessage()               {}
-func (*PingMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*PingMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *PingMsg) GetNonce() uint64 {
 	if m != nil {
@@ -678,7 +1043,7 @@ type PongMsg struct {
 func (m *PongMsg) Reset()                    { *m = PongMsg{} }
 func (m *PongMsg) String() string            { return proto.CompactTextString(m) }
 func (*PongMsg) ProtoMessage()               {}
-func (*PongMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*PongMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *PongMsg) GetAckNonce() uint64 {
 	if m != nil {
@@ -695,7 +1060,7 @@ type BlockSync struct {
 func (m *BlockSync) Reset()                    { *m = BlockSync{} }
 func (m *BlockSync) String() string            { return proto.CompactTextString(m) }
 func (*BlockSync) ProtoMessage()               {}
-func (*BlockSync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*BlockSync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *BlockSync) GetStart() uint64 {
 	if m != nil {
@@ -720,7 +1085,7 @@ type BlockContainer struct {
 func (m *BlockContainer) Reset()                    { *m = BlockContainer{} }
 func (m *BlockContainer) String() string            { return proto.CompactTextString(m) }
 func (*BlockContainer) ProtoMessage()               {}
-func (*BlockContainer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*BlockContainer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *BlockContainer) GetBlock() *BlockPb {
 	if m != nil {
@@ -729,6 +1094,126 @@ func (m *BlockContainer) GetBlock() *BlockPb {
 	return nil
 }
 
+// block header sync request
+// used to fetch the headers first in block sync
+type BlockHeaderSync struct {
+	Start uint64 `protobuf:"varint,1,opt,name=start" json:"start,omitempty"`
+	End   uint64 `protobuf:"varint,2,opt,name=end" json:"end,omitempty"`
+}
+
+func (m *BlockHeaderSync) Reset()                    { *m = BlockHeaderSync{} }
+func (m *BlockHeaderSync) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeaderSync) ProtoMessage()               {}
+func (*BlockHeaderSync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *BlockHeaderSync) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *BlockHeaderSync) GetEnd() uint64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+// block header container
+// used to send the headers of old/existing blocks in block sync
+type BlockHeaderContainer struct {
+	Headers []*BlockHeaderPb `protobuf:"bytes,1,rep,name=headers" json:"headers,omitempty"`
+}
+
+func (m *BlockHeaderContainer) Reset()                    { *m = BlockHeaderContainer{} }
+func (m *BlockHeaderContainer) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeaderContainer) ProtoMessage()               {}
+func (*BlockHeaderContainer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *BlockHeaderContainer) GetHeaders() []*BlockHeaderPb {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// state snapshot sync request
+// used to fetch the chunks of the state snapshot to fast sync a new node, height 0 asks for the latest snapshot
+type StateSnapshotSync struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Chunk  uint32 `protobuf:"varint,2,opt,name=chunk" json:"chunk,omitempty"`
+}
+
+func (m *StateSnapshotSync) Reset()                    { *m = StateSnapshotSync{} }
+func (m *StateSnapshotSync) String() string            { return proto.CompactTextString(m) }
+func (*StateSnapshotSync) ProtoMessage()               {}
+func (*StateSnapshotSync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *StateSnapshotSync) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *StateSnapshotSync) GetChunk() uint32 {
+	if m != nil {
+		return m.Chunk
+	}
+	return 0
+}
+
+// state snapshot chunk
+// used to send a chunk of the serialized state snapshot in state snapshot sync
+type StateSnapshotChunk struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Root   []byte `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	Chunk  uint32 `protobuf:"varint,3,opt,name=chunk" json:"chunk,omitempty"`
+	Total  uint32 `protobuf:"varint,4,opt,name=total" json:"total,omitempty"`
+	Data   []byte `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *StateSnapshotChunk) Reset()                    { *m = StateSnapshotChunk{} }
+func (m *StateSnapshotChunk) String() string            { return proto.CompactTextString(m) }
+func (*StateSnapshotChunk) ProtoMessage()               {}
+func (*StateSnapshotChunk) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *StateSnapshotChunk) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *StateSnapshotChunk) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *StateSnapshotChunk) GetChunk() uint32 {
+	if m != nil {
+		return m.Chunk
+	}
+	return 0
+}
+
+func (m *StateSnapshotChunk) GetTotal() uint32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *StateSnapshotChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 type ViewChangeMsg struct {
 	Vctype     ViewChangeMsg_ViewChangeType `protobuf:"varint,1,opt,name=vctype,enum=iproto.ViewChangeMsg_ViewChangeType" json:"vctype,omitempty"`
 	Block      *BlockPb                     `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
@@ -739,7 +1224,7 @@ type ViewChangeMsg struct {
 func (m *ViewChangeMsg) Reset()                    { *m = ViewChangeMsg{} }
 func (m *ViewChangeMsg) String() string            { return proto.CompactTextString(m) }
 func (*ViewChangeMsg) ProtoMessage()               {}
-func (*ViewChangeMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*ViewChangeMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 func (m *ViewChangeMsg) GetVctype() ViewChangeMsg_ViewChangeType {
 	if m != nil {
@@ -779,7 +1264,7 @@ type TestPayload struct {
 func (m *TestPayload) Reset()                    { *m = TestPayload{} }
 func (m *TestPayload) String() string            { return proto.CompactTextString(m) }
 func (*TestPayload) ProtoMessage()               {}
-func (*TestPayload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*TestPayload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *TestPayload) GetMsgBody() []byte {
 	if m != nil {
@@ -798,10 +1283,24 @@ func init() {
 	proto.RegisterType((*BlockHeaderPb)(nil), "iproto.BlockHeaderPb")
 	proto.RegisterType((*BlockPb)(nil), "iproto.BlockPb")
 	proto.RegisterType((*BlockIndex)(nil), "iproto.BlockIndex")
+	proto.RegisterType((*AccountStatePb)(nil), "iproto.AccountStatePb")
+	proto.RegisterType((*VoterPb)(nil), "iproto.VoterPb")
+	proto.RegisterType((*CandidatePoolPb)(nil), "iproto.CandidatePoolPb")
+	proto.RegisterType((*CandidatePb)(nil), "iproto.CandidatePb")
+	proto.RegisterType((*VoterRewardPoolPb)(nil), "iproto.VoterRewardPoolPb")
+	proto.RegisterType((*DelegateRewardPb)(nil), "iproto.DelegateRewardPb")
+	proto.RegisterType((*RewardReceiptsPb)(nil), "iproto.RewardReceiptsPb")
+	proto.RegisterType((*RewardReceiptPb)(nil), "iproto.RewardReceiptPb")
+	proto.RegisterType((*StateDiffPb)(nil), "iproto.StateDiffPb")
+	proto.RegisterType((*AccountDiffPb)(nil), "iproto.AccountDiffPb")
 	proto.RegisterType((*PingMsg)(nil), "iproto.PingMsg")
 	proto.RegisterType((*PongMsg)(nil), "iproto.PongMsg")
 	proto.RegisterType((*BlockSync)(nil), "iproto.BlockSync")
 	proto.RegisterType((*BlockContainer)(nil), "iproto.BlockContainer")
+	proto.RegisterType((*BlockHeaderSync)(nil), "iproto.BlockHeaderSync")
+	proto.RegisterType((*BlockHeaderContainer)(nil), "iproto.BlockHeaderContainer")
+	proto.RegisterType((*StateSnapshotSync)(nil), "iproto.StateSnapshotSync")
+	proto.RegisterType((*StateSnapshotChunk)(nil), "iproto.StateSnapshotChunk")
 	proto.RegisterType((*ViewChangeMsg)(nil), "iproto.ViewChangeMsg")
 	proto.RegisterType((*TestPayload)(nil), "iproto.TestPayload")
 	proto.RegisterEnum("iproto.ViewChangeMsg_ViewChangeType", ViewChangeMsg_ViewChangeType_name, ViewChangeMsg_ViewChangeType_value)
@@ -810,65 +1309,96 @@ func init() {
 func init() { proto.RegisterFile("blockchain.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 946 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xdd, 0x6e, 0xe3, 0x44,
-	0x14, 0xae, 0x9d, 0x1f, 0x27, 0x27, 0x4d, 0x09, 0xa3, 0xee, 0xca, 0x40, 0xb5, 0x54, 0x56, 0x77,
-	0x15, 0xad, 0x44, 0x85, 0xba, 0x17, 0xdc, 0x70, 0xd3, 0x9f, 0x88, 0x44, 0x2c, 0xad, 0x35, 0x8d,
-	0x82, 0xb8, 0x8a, 0xc6, 0xf6, 0x34, 0x31, 0x6d, 0xc6, 0xc6, 0x1e, 0x87, 0x84, 0x07, 0xe0, 0x0a,
-	0x9e, 0x84, 0x5b, 0xde, 0x83, 0x57, 0x42, 0x73, 0x66, 0xec, 0x38, 0xcb, 0x6a, 0xf7, 0x86, 0xab,
-	0xf6, 0xfb, 0xce, 0xf1, 0x9c, 0x6f, 0xce, 0xf9, 0xe6, 0x04, 0x06, 0xc1, 0x53, 0x12, 0x3e, 0x86,
-	0x4b, 0x16, 0x8b, 0xf3, 0x34, 0x4b, 0x64, 0x42, 0xda, 0x31, 0xfe, 0xf5, 0xfe, 0xb2, 0xa0, 0x3b,
-	0xdd, 0x4c, 0x44, 0x5a, 0x48, 0x3f, 0x20, 0xcf, 0xa1, 0x2d, 0x37, 0x63, 0x96, 0x2f, 0x5d, 0xeb,
-	0xd4, 0x1a, 0x1e, 0x52, 0x83, 0xc8, 0xe7, 0xd0, 0x49, 0x0a, 0x39, 0x11, 0x11, 0xdf, 0xb8, 0xf6,
-	0xa9, 0x35, 0x6c, 0xd1, 0x0a, 0x93, 0xd7, 0x30, 0x28, 0x84, 0x3a, 0xfe, 0x3e, 0xcc, 0xe2, 0x54,
-	0xde, 0xc7, 0xbf, 0x71, 0xb7, 0x71, 0x6a, 0x0d, 0xfb, 0xf4, 0x3f, 0x3c, 0xf1, 0xe0, 0xb0, 0xce,
-	0xb9, 0x4d, 0xac, 0xb2, 0xc7, 0xa9, 0x5a, 0x39, 0xff, 0xa5, 0xe0, 0x22, 0xe4, 0x6e, 0x0b, 0xcf,
-	0xa9, 0xb0, 0xf7, 0x33, 0xc0, 0x74, 0x73, 0x57, 0x48, 0xad, 0xf6, 0x18, 0x5a, 0x6b, 0xf6, 0x54,
-	0x70, 0x14, 0xdb, 0xa4, 0x1a, 0x90, 0x57, 0x70, 0xf4, 0x8e, 0x1a, 0x1b, 0x4f, 0x79, 0x87, 0x25,
-	0x2f, 0x00, 0x6a, 0x4a, 0x1a, 0xa8, 0xa4, 0xc6, 0x78, 0x7f, 0x5a, 0xd0, 0x9c, 0x6e, 0xfc, 0x80,
-	0xb8, 0xe0, 0xac, 0x79, 0x96, 0xc7, 0x89, 0xc0, 0x42, 0x7d, 0x5a, 0x42, 0x25, 0x55, 0x7d, 0x30,
-	0x8d, 0x57, 0x65, 0x91, 0x0a, 0x93, 0x97, 0xd0, 0x94, 0x9b, 0x89, 0x70, 0x9f, 0x9d, 0x36, 0x86,
-	0xbd, 0x8b, 0x4f, 0xcf, 0x75, 0xbf, 0xcf, 0xab, 0x5e, 0x53, 0x0c, 0x93, 0x21, 0xb4, 0xa4, 0xba,
-	0x91, 0xfb, 0x1c, 0xf3, 0xc8, 0x2e, 0xaf, 0xbc, 0x26, 0xd5, 0x09, 0xde, 0xef, 0x36, 0xc0, 0x34,
-	0x63, 0x22, 0x7f, 0xe0, 0xd9, 0x07, 0x55, 0x1d, 0x43, 0x4b, 0x24, 0xaa, 0x7b, 0xb6, 0x6e, 0x0b,
-	0x02, 0x72, 0x02, 0xdd, 0x3c, 0x5e, 0x08, 0x26, 0x8b, 0x8c, 0x9b, 0xdb, 0xee, 0x08, 0x35, 0x78,
-	0xb6, 0x4a, 0x0a, 0x51, 0x8e, 0xc4, 0x20, 0xc5, 0xe7, 0x5c, 0x44, 0x3c, 0xc3, 0x51, 0x74, 0xa9,
-	0x41, 0xea, 0xb4, 0x8c, 0x87, 0x71, 0x1a, 0x73, 0x21, 0xdd, 0x36, 0x86, 0x76, 0x84, 0xd2, 0x96,
-	0xb2, 0xed, 0x53, 0xc2, 0x22, 0xd7, 0xc1, 0xe3, 0x4a, 0xa8, 0x0c, 0xa0, 0x4f, 0xf0, 0x8b, 0xe0,
-	0x7b, 0xbe, 0x75, 0x3b, 0xda, 0x00, 0x75, 0x4e, 0x0d, 0x26, 0xce, 0xaf, 0x93, 0x58, 0x04, 0x2c,
-	0xe7, 0x6e, 0xf7, 0xd4, 0x1a, 0x76, 0x68, 0x8d, 0xf1, 0xfe, 0xb6, 0xa0, 0x3d, 0x4b, 0x24, 0xff,
-	0xdf, 0x9b, 0x70, 0x02, 0x5d, 0x19, 0xaf, 0x78, 0x2e, 0xd9, 0x2a, 0xc5, 0x3e, 0x34, 0xe9, 0x8e,
-	0x50, 0xb2, 0x72, 0xfe, 0xf4, 0xe0, 0x17, 0xc1, 0x23, 0xdf, 0x62, 0x3b, 0x0e, 0x69, 0x8d, 0x51,
-	0xf1, 0xb5, 0x52, 0xa5, 0xe3, 0x6d, 0x1d, 0xdf, 0x31, 0xde, 0x1f, 0x16, 0x74, 0x2e, 0x43, 0x19,
-	0x27, 0xc2, 0x0f, 0xc8, 0x0b, 0xb0, 0xe5, 0x06, 0x35, 0xf7, 0x2e, 0x0e, 0x77, 0x33, 0xf7, 0x83,
-	0xf1, 0x01, 0xb5, 0xe5, 0x86, 0x7c, 0x0d, 0x1d, 0x69, 0x66, 0x8d, 0x37, 0xa8, 0x3b, 0xa3, 0xf2,
-	0xc0, 0xf8, 0x80, 0x56, 0x59, 0xe4, 0x0c, 0x9a, 0xaa, 0x18, 0xde, 0xaa, 0x77, 0x71, 0x54, 0x66,
-	0xeb, 0x46, 0x8d, 0x0f, 0x28, 0x46, 0xaf, 0x3a, 0xd0, 0x66, 0xa8, 0xc1, 0xfb, 0xc7, 0x86, 0xfe,
-	0x95, 0x72, 0xeb, 0x98, 0xb3, 0xe8, 0x23, 0x8e, 0x72, 0xc1, 0xc1, 0xdd, 0x31, 0xb9, 0x31, 0x36,
-	0x2f, 0xa1, 0xf2, 0xc7, 0x92, 0xc7, 0x8b, 0xa5, 0x7e, 0x40, 0x4d, 0x6a, 0xd0, 0x47, 0x5a, 0x79,
-	0x06, 0xfd, 0x34, 0xe3, 0x6b, 0x5d, 0x5e, 0x6d, 0x1b, 0xdd, 0xcd, 0x7d, 0x52, 0x2f, 0x23, 0x9a,
-	0x24, 0xd2, 0x34, 0xd3, 0x20, 0x1c, 0xa2, 0x64, 0x92, 0x63, 0xc8, 0x31, 0x43, 0x2c, 0x09, 0x35,
-	0x06, 0x99, 0x89, 0xcd, 0x6d, 0xb1, 0x0a, 0x78, 0x86, 0xfe, 0xea, 0xd3, 0x1a, 0xa3, 0x1c, 0xa8,
-	0xd0, 0x0d, 0x93, 0x0c, 0x97, 0x43, 0x17, 0x33, 0xf6, 0xb8, 0x7d, 0x9b, 0xc0, 0x7b, 0xde, 0x4a,
-	0xaa, 0x87, 0xdc, 0xd3, 0xba, 0x34, 0xf2, 0x22, 0x70, 0x50, 0xbc, 0x1f, 0x90, 0xaf, 0x54, 0x5b,
-	0x54, 0x5b, 0xcd, 0x88, 0x9f, 0x95, 0xe3, 0xd8, 0xeb, 0x38, 0x35, 0x49, 0xe4, 0x35, 0x38, 0x7a,
-	0x2a, 0xb9, 0x6b, 0xe3, 0x1a, 0x18, 0x94, 0xf9, 0xa5, 0x61, 0x68, 0x99, 0xe0, 0xbd, 0x05, 0xc0,
-	0x43, 0xf4, 0xf2, 0x3d, 0x86, 0x56, 0x2e, 0x59, 0x26, 0xcb, 0x15, 0x88, 0x80, 0x0c, 0xa0, 0xc1,
-	0x45, 0x64, 0xac, 0xaf, 0xfe, 0x55, 0x9a, 0x93, 0x87, 0x87, 0x9c, 0xab, 0x39, 0x35, 0x86, 0x7d,
-	0x6a, 0x90, 0xf7, 0x25, 0x38, 0x7e, 0x2c, 0x16, 0x3f, 0xe4, 0x8b, 0xdd, 0x8b, 0xb1, 0x6a, 0x2f,
-	0xc6, 0x7b, 0x05, 0x8e, 0x9f, 0xe8, 0x84, 0x2f, 0xa0, 0xcb, 0xc2, 0xc7, 0x79, 0x3d, 0xa9, 0xc3,
-	0xc2, 0xc7, 0x5b, 0xcc, 0x7b, 0x03, 0x5d, 0x94, 0x75, 0xbf, 0x15, 0xe1, 0x4e, 0x95, 0xfd, 0x1e,
-	0x55, 0x8d, 0x4a, 0x95, 0xf7, 0x0d, 0x1c, 0xe1, 0x47, 0xd7, 0x89, 0x90, 0x2c, 0x16, 0x3c, 0x23,
-	0x2f, 0xa1, 0x85, 0x3f, 0x55, 0xa6, 0x6f, 0x9f, 0xec, 0xf5, 0x4d, 0xed, 0x42, 0x8c, 0xaa, 0x5d,
-	0xd8, 0x9f, 0xc5, 0xfc, 0xd7, 0xeb, 0x25, 0x13, 0x0b, 0xae, 0xc4, 0x7d, 0x0b, 0xed, 0x75, 0x28,
-	0xb7, 0xa9, 0x56, 0x76, 0x74, 0x71, 0x56, 0x3d, 0x80, 0x7a, 0x5a, 0x0d, 0x4d, 0xb7, 0x29, 0xa7,
-	0xe6, 0x9b, 0x5d, 0x59, 0xfb, 0x43, 0x65, 0x95, 0x2f, 0x82, 0xca, 0xb3, 0x66, 0x7d, 0x54, 0x84,
-	0x5e, 0x10, 0x6a, 0x8f, 0x5d, 0x46, 0x51, 0x86, 0xa6, 0xef, 0xd2, 0x1a, 0xe3, 0x51, 0x38, 0xda,
-	0x2f, 0x4f, 0x4e, 0xc0, 0x9d, 0xdc, 0xce, 0x2e, 0xdf, 0x4e, 0x6e, 0xe6, 0xb3, 0xc9, 0xe8, 0xc7,
-	0xf9, 0xf5, 0xf8, 0xf2, 0xf6, 0xbb, 0xd1, 0x7c, 0xfa, 0x93, 0x3f, 0x1a, 0x1c, 0x90, 0x1e, 0x38,
-	0x3e, 0xbd, 0xf3, 0xef, 0xee, 0x47, 0x03, 0x4b, 0x83, 0xd1, 0xec, 0x6e, 0x3a, 0x1a, 0xd8, 0xa4,
-	0x03, 0x4d, 0xfc, 0xaf, 0xe1, 0x0d, 0xa1, 0x37, 0xe5, 0xb9, 0xf4, 0xcd, 0x7a, 0xfd, 0x0c, 0x3a,
-	0xab, 0x7c, 0x31, 0x0f, 0x92, 0x68, 0x6b, 0x7e, 0xc1, 0x9d, 0x55, 0xbe, 0xb8, 0x4a, 0xa2, 0x6d,
-	0xd0, 0xc6, 0x1b, 0xbd, 0xf9, 0x37, 0x00, 0x00, 0xff, 0xff, 0x4a, 0xd5, 0x5b, 0x79, 0x0b, 0x08,
-	0x00, 0x00,
+	// 1454 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0xdd, 0x6e, 0x1b, 0xb7,
+	0x12, 0xf6, 0xea, 0x6f, 0xa5, 0x91, 0x65, 0x2b, 0x3c, 0x8e, 0xcf, 0x9e, 0x73, 0x82, 0x1c, 0x63,
+	0x91, 0xa4, 0x42, 0x80, 0xa6, 0xad, 0x7d, 0x51, 0x14, 0x2d, 0x50, 0xf8, 0xaf, 0xb1, 0x91, 0xd4,
+	0x5e, 0xd0, 0xaa, 0xd3, 0x5e, 0x19, 0xdc, 0x5d, 0x4a, 0xde, 0x5a, 0xe2, 0xaa, 0xbb, 0x94, 0x2d,
+	0xf5, 0xaa, 0x57, 0xbd, 0x6a, 0x1f, 0xa4, 0xe8, 0xab, 0xb4, 0xe8, 0x2b, 0x15, 0x1c, 0x72, 0xff,
+	0x9c, 0xd8, 0xb9, 0xe9, 0x95, 0xf6, 0xfb, 0x38, 0x1c, 0x0e, 0x67, 0x86, 0x1f, 0x29, 0xe8, 0xfb,
+	0x93, 0x38, 0xb8, 0x0a, 0x2e, 0x59, 0x24, 0x5e, 0xcc, 0x92, 0x58, 0xc6, 0xa4, 0x15, 0xe1, 0xaf,
+	0xfb, 0xbb, 0x05, 0x9d, 0xe1, 0xe2, 0x58, 0xcc, 0xe6, 0xd2, 0xf3, 0xc9, 0x26, 0xb4, 0xe4, 0xe2,
+	0x88, 0xa5, 0x97, 0x8e, 0xb5, 0x65, 0x0d, 0x56, 0xa9, 0x41, 0xe4, 0xbf, 0xd0, 0x8e, 0xe7, 0xf2,
+	0x58, 0x84, 0x7c, 0xe1, 0xd4, 0xb6, 0xac, 0x41, 0x93, 0xe6, 0x98, 0x3c, 0x87, 0xfe, 0x5c, 0x28,
+	0xf7, 0x67, 0x41, 0x12, 0xcd, 0xe4, 0x59, 0xf4, 0x23, 0x77, 0xea, 0x5b, 0xd6, 0xa0, 0x47, 0xdf,
+	0xe2, 0x89, 0x0b, 0xab, 0x65, 0xce, 0x69, 0xe0, 0x2a, 0x15, 0x4e, 0xad, 0x95, 0xf2, 0x1f, 0xe6,
+	0x5c, 0x04, 0xdc, 0x69, 0xa2, 0x9f, 0x1c, 0xbb, 0xdf, 0x03, 0x0c, 0x17, 0xa7, 0x73, 0xa9, 0xa3,
+	0xdd, 0x80, 0xe6, 0x35, 0x9b, 0xcc, 0x39, 0x06, 0xdb, 0xa0, 0x1a, 0x90, 0x67, 0xb0, 0x76, 0x2b,
+	0x9a, 0x1a, 0x7a, 0xb9, 0xc5, 0x92, 0xc7, 0x00, 0xa5, 0x48, 0xea, 0x18, 0x49, 0x89, 0x71, 0x7f,
+	0xb5, 0xa0, 0x31, 0x5c, 0x78, 0x3e, 0x71, 0xc0, 0xbe, 0xe6, 0x49, 0x1a, 0xc5, 0x02, 0x17, 0xea,
+	0xd1, 0x0c, 0xaa, 0x50, 0xd5, 0x84, 0x61, 0x34, 0xcd, 0x16, 0xc9, 0x31, 0x79, 0x0a, 0x0d, 0xb9,
+	0x38, 0x16, 0xce, 0xc3, 0xad, 0xfa, 0xa0, 0xbb, 0xfd, 0xe0, 0x85, 0xce, 0xf7, 0x8b, 0x3c, 0xd7,
+	0x14, 0x87, 0xc9, 0x00, 0x9a, 0x52, 0xed, 0xc8, 0xd9, 0x44, 0x3b, 0x52, 0xd8, 0x65, 0xdb, 0xa4,
+	0xda, 0xc0, 0xfd, 0xb9, 0x06, 0x30, 0x4c, 0x98, 0x48, 0x47, 0x3c, 0xb9, 0x37, 0xaa, 0x0d, 0x68,
+	0x8a, 0x58, 0x04, 0x3a, 0xa4, 0x06, 0xd5, 0x80, 0x3c, 0x82, 0x4e, 0x1a, 0x8d, 0x05, 0x93, 0xf3,
+	0x84, 0x9b, 0xdd, 0x16, 0x84, 0x2a, 0x3c, 0x9b, 0xc6, 0x73, 0x91, 0x95, 0xc4, 0x20, 0xc5, 0xa7,
+	0x5c, 0x84, 0x3c, 0xc1, 0x52, 0x74, 0xa8, 0x41, 0xca, 0x5b, 0xc2, 0x83, 0x68, 0x16, 0x71, 0x21,
+	0x9d, 0x16, 0x0e, 0x15, 0x84, 0x8a, 0x6d, 0xc6, 0x96, 0x93, 0x98, 0x85, 0x8e, 0x8d, 0xee, 0x32,
+	0xa8, 0x1a, 0x40, 0x7b, 0xf0, 0xe6, 0xfe, 0x2b, 0xbe, 0x74, 0xda, 0xba, 0x01, 0xca, 0x9c, 0x2a,
+	0x4c, 0x94, 0xee, 0xc7, 0x91, 0xf0, 0x59, 0xca, 0x9d, 0xce, 0x96, 0x35, 0x68, 0xd3, 0x12, 0xe3,
+	0xfe, 0x61, 0x41, 0xeb, 0x3c, 0x96, 0xfc, 0x1f, 0x4f, 0xc2, 0x23, 0xe8, 0xc8, 0x68, 0xca, 0x53,
+	0xc9, 0xa6, 0x33, 0xcc, 0x43, 0x83, 0x16, 0x84, 0x0a, 0x2b, 0xe5, 0x93, 0x91, 0x37, 0xf7, 0xaf,
+	0xf8, 0x12, 0xd3, 0xb1, 0x4a, 0x4b, 0x8c, 0x1a, 0xbf, 0x56, 0x51, 0xe9, 0xf1, 0x96, 0x1e, 0x2f,
+	0x18, 0x95, 0xca, 0xb9, 0x50, 0x18, 0x73, 0xd2, 0xa6, 0x06, 0xb9, 0xbf, 0x58, 0xd0, 0xde, 0x0d,
+	0x64, 0x14, 0x0b, 0xcf, 0x27, 0x8f, 0xa1, 0x26, 0x17, 0xb8, 0x97, 0xee, 0xf6, 0x6a, 0xd1, 0x0b,
+	0x9e, 0x7f, 0xb4, 0x42, 0x6b, 0x72, 0x41, 0x3e, 0x86, 0xb6, 0x34, 0x3d, 0x80, 0x3b, 0x2b, 0x77,
+	0x4c, 0xde, 0x1b, 0x47, 0x2b, 0x34, 0xb7, 0x22, 0x4f, 0xa0, 0x81, 0x8b, 0xd6, 0xd1, 0x7a, 0x2d,
+	0xb3, 0xd6, 0x09, 0x3c, 0x5a, 0xa1, 0x38, 0xba, 0xd7, 0x86, 0x16, 0xc3, 0x18, 0xdc, 0xbf, 0x6a,
+	0xd0, 0xdb, 0x53, 0x5d, 0x7c, 0xc4, 0x59, 0xf8, 0x9e, 0x4e, 0x73, 0xc0, 0x46, 0x4d, 0x39, 0x3e,
+	0x30, 0xed, 0x9f, 0x41, 0xb5, 0xd9, 0x4b, 0x1e, 0x8d, 0x2f, 0xf5, 0xc1, 0x6a, 0x50, 0x83, 0xde,
+	0x93, 0xe2, 0x27, 0xd0, 0x9b, 0x25, 0xfc, 0x5a, 0x2f, 0xaf, 0x54, 0x48, 0x67, 0xb9, 0x4a, 0x6a,
+	0x91, 0xa2, 0x71, 0x2c, 0x4d, 0x92, 0x0d, 0xc2, 0xe2, 0x4a, 0x26, 0x39, 0x0e, 0xd9, 0xa6, 0xb8,
+	0x19, 0xa1, 0xca, 0x23, 0x13, 0xb1, 0x38, 0x99, 0x4f, 0x7d, 0x9e, 0x60, 0xdf, 0xf5, 0x68, 0x89,
+	0x51, 0x9d, 0xa9, 0xd0, 0x01, 0x93, 0x0c, 0x45, 0xa3, 0x83, 0x16, 0x15, 0xae, 0xda, 0x3e, 0xf0,
+	0x8e, 0x33, 0x34, 0xd3, 0xc5, 0xef, 0xea, 0xb8, 0x34, 0x72, 0x43, 0xb0, 0x31, 0x78, 0xcf, 0x27,
+	0x1f, 0xaa, 0xb4, 0xa8, 0xb4, 0x9a, 0x12, 0x3f, 0xcc, 0xca, 0x51, 0xc9, 0x38, 0x35, 0x46, 0xe4,
+	0x39, 0xd8, 0xba, 0x2a, 0xa9, 0x53, 0x43, 0x79, 0xe8, 0x67, 0xf6, 0x59, 0xc3, 0xd0, 0xcc, 0xc0,
+	0x7d, 0x0d, 0x80, 0x4e, 0xb4, 0x28, 0x6f, 0x40, 0x33, 0x95, 0x2c, 0x91, 0x99, 0x34, 0x22, 0x20,
+	0x7d, 0xa8, 0x73, 0x11, 0x9a, 0x23, 0xa1, 0x3e, 0x55, 0xcc, 0xf1, 0x68, 0x94, 0x72, 0x55, 0xa7,
+	0xfa, 0xa0, 0x47, 0x0d, 0x72, 0x7f, 0xab, 0xc1, 0xda, 0x6e, 0x10, 0x28, 0x0d, 0x38, 0x53, 0x29,
+	0xd4, 0x6a, 0xab, 0x4f, 0x94, 0x55, 0x3e, 0x51, 0x0e, 0xd8, 0x3e, 0x9b, 0xb0, 0xec, 0xa4, 0xad,
+	0xd2, 0x0c, 0xaa, 0x11, 0x16, 0x86, 0x09, 0x4f, 0x53, 0xec, 0x81, 0x0e, 0xcd, 0x20, 0xd9, 0x82,
+	0x6e, 0x94, 0xee, 0x33, 0x11, 0x46, 0x21, 0x93, 0x1c, 0xdb, 0xa0, 0x4d, 0xcb, 0x94, 0x2a, 0xc6,
+	0x75, 0x2c, 0x23, 0x31, 0x7e, 0xa3, 0x9b, 0x48, 0xf7, 0x41, 0x85, 0x43, 0xf5, 0x8f, 0x25, 0xe7,
+	0x46, 0x7e, 0x34, 0x20, 0x1f, 0x40, 0x4b, 0x7d, 0x24, 0xa9, 0x63, 0x63, 0xc6, 0xd6, 0xcb, 0x0d,
+	0x8f, 0xb9, 0xd5, 0xc3, 0xaa, 0xd7, 0x54, 0xba, 0x78, 0xb8, 0x67, 0xc2, 0xd7, 0x52, 0x54, 0x25,
+	0x8b, 0x0b, 0xeb, 0x48, 0x07, 0xd2, 0xc1, 0xbd, 0x57, 0x38, 0xf7, 0x73, 0xb0, 0x8d, 0xf3, 0xf2,
+	0x9e, 0xad, 0xea, 0x9e, 0x37, 0xa1, 0x75, 0xa3, 0x5d, 0xe8, 0x34, 0x19, 0xe4, 0xfe, 0x69, 0xc1,
+	0x7a, 0xbe, 0x6f, 0x2f, 0x8e, 0x27, 0x9e, 0xaf, 0x42, 0x0b, 0x32, 0x0a, 0x7b, 0x51, 0x1f, 0xbb,
+	0x2a, 0xa9, 0x1a, 0xda, 0x9f, 0x8f, 0x46, 0x3c, 0x29, 0xdd, 0x71, 0x25, 0x86, 0xec, 0x00, 0xe4,
+	0x13, 0x52, 0x2c, 0x6f, 0x77, 0xfb, 0x5f, 0x59, 0x36, 0x8a, 0x25, 0x7d, 0x5a, 0x32, 0x23, 0x5f,
+	0x42, 0x5f, 0xbb, 0xd8, 0x2f, 0xa6, 0x36, 0xee, 0x9e, 0xfa, 0x96, 0xb1, 0xfb, 0x0d, 0x74, 0x4b,
+	0x06, 0xf7, 0x24, 0xc4, 0x94, 0x2f, 0x35, 0xf9, 0xd0, 0xc0, 0x9c, 0x21, 0x75, 0x33, 0xd4, 0xf3,
+	0x33, 0xf4, 0x8a, 0x2f, 0xdd, 0x0b, 0x78, 0x80, 0x39, 0xa6, 0xfc, 0x86, 0x25, 0xa1, 0xc9, 0x53,
+	0x21, 0x32, 0x56, 0x45, 0x64, 0xb6, 0xc1, 0x4e, 0xd0, 0x2e, 0x3b, 0x36, 0x4e, 0x16, 0xfb, 0x01,
+	0x9f, 0xf0, 0xb1, 0x52, 0x04, 0xed, 0xc6, 0xa7, 0x99, 0xa1, 0xfb, 0x15, 0xf4, 0x6f, 0x0f, 0xaa,
+	0xeb, 0x3d, 0x34, 0x9c, 0x89, 0x3e, 0xc7, 0xa5, 0x0b, 0xb3, 0x56, 0xbe, 0x30, 0xdd, 0x97, 0xd0,
+	0xd7, 0xf3, 0x29, 0x0f, 0x78, 0x34, 0x93, 0xa9, 0xe7, 0x93, 0x1d, 0x68, 0x27, 0x06, 0x39, 0x16,
+	0x06, 0xf4, 0xef, 0x2c, 0xa0, 0x8a, 0xad, 0xe7, 0xd3, 0xdc, 0xd0, 0x4d, 0x61, 0xfd, 0xd6, 0xe0,
+	0x9d, 0xfb, 0x2d, 0xc7, 0x59, 0xbb, 0x15, 0xa7, 0x49, 0x73, 0x62, 0xce, 0xa0, 0x06, 0x77, 0x5d,
+	0xf7, 0xee, 0xb7, 0xd0, 0xc5, 0xe3, 0x7e, 0x10, 0x8d, 0x46, 0xf7, 0x2c, 0xf8, 0x09, 0xb4, 0x99,
+	0x16, 0x87, 0x2c, 0xc3, 0x0f, 0x0b, 0x61, 0x42, 0x5e, 0x3b, 0xa0, 0xb9, 0x99, 0x1b, 0x40, 0xaf,
+	0x32, 0x44, 0x06, 0x50, 0x8f, 0x27, 0xa1, 0xd1, 0xc1, 0xcd, 0x5b, 0xd3, 0x8d, 0xe6, 0x50, 0x65,
+	0xa2, 0x2c, 0x05, 0xbf, 0x71, 0x6a, 0xf7, 0x5b, 0x0a, 0x7e, 0xe3, 0xfe, 0x1f, 0x6c, 0x2f, 0x12,
+	0xe3, 0xaf, 0xd3, 0xf1, 0xbb, 0xd5, 0xca, 0x7d, 0x06, 0xb6, 0x17, 0x6b, 0x83, 0xff, 0x41, 0x87,
+	0x05, 0x57, 0x17, 0x65, 0xa3, 0x36, 0x0b, 0xae, 0x4e, 0xd0, 0x6e, 0x07, 0x3a, 0x28, 0xa6, 0x67,
+	0x4b, 0x11, 0x14, 0x5a, 0x5a, 0x7b, 0x87, 0x96, 0xd6, 0x73, 0x2d, 0x75, 0x3f, 0x85, 0x35, 0x9c,
+	0xb4, 0x1f, 0x0b, 0xc9, 0x22, 0xc1, 0x13, 0xf2, 0x14, 0x9a, 0xf8, 0xf0, 0x36, 0xbb, 0x5c, 0xaf,
+	0xa8, 0xbd, 0x7a, 0xd9, 0xe1, 0xa8, 0xfb, 0x19, 0xac, 0x97, 0xf4, 0xbf, 0xba, 0xe6, 0xfd, 0xfa,
+	0xed, 0xbe, 0x84, 0x8d, 0xd2, 0xd4, 0x62, 0xe5, 0x8f, 0xc0, 0xd6, 0x77, 0x48, 0xd6, 0x71, 0x77,
+	0xdc, 0x34, 0x99, 0x95, 0xbb, 0x0b, 0x0f, 0x30, 0x95, 0x67, 0x82, 0xcd, 0xd2, 0xcb, 0x58, 0x62,
+	0x14, 0x77, 0xd5, 0x7f, 0x03, 0x9a, 0xc1, 0xe5, 0x5c, 0x5c, 0x19, 0xd5, 0xd1, 0xc0, 0xfd, 0xc9,
+	0x02, 0x52, 0xf1, 0xb1, 0xaf, 0xe8, 0x3b, 0x9d, 0x10, 0x68, 0x24, 0xea, 0xa6, 0xd6, 0xe7, 0x07,
+	0xbf, 0x0b, 0xc7, 0xf5, 0x92, 0x63, 0xc5, 0xca, 0x58, 0xb2, 0x09, 0x36, 0x6b, 0x8f, 0x6a, 0xa0,
+	0xe6, 0x87, 0x4c, 0x32, 0x73, 0x37, 0xe0, 0xb7, 0x7a, 0x23, 0xf7, 0xce, 0x23, 0x7e, 0xb3, 0x7f,
+	0xc9, 0xc4, 0x98, 0xab, 0x32, 0x7f, 0x01, 0xad, 0xeb, 0x40, 0x2e, 0x67, 0xba, 0xc6, 0x6b, 0xdb,
+	0x4f, 0xf2, 0xfb, 0xa0, 0x6c, 0x56, 0x42, 0xc3, 0xe5, 0x8c, 0x53, 0x33, 0xa7, 0x28, 0x60, 0xed,
+	0xbe, 0x02, 0xaa, 0x77, 0x81, 0x9f, 0xbf, 0x59, 0xcc, 0xb3, 0x32, 0x27, 0xf4, 0xc3, 0x51, 0xbd,
+	0x6f, 0x77, 0xc3, 0x30, 0xc1, 0x3d, 0x74, 0x68, 0x89, 0x71, 0x29, 0xac, 0x55, 0x97, 0x27, 0x8f,
+	0xc0, 0x39, 0x3e, 0x39, 0xdf, 0x7d, 0x7d, 0x7c, 0x70, 0x71, 0x7e, 0x7c, 0xf8, 0xe6, 0x62, 0xff,
+	0x68, 0xf7, 0xe4, 0xe5, 0xe1, 0xc5, 0xf0, 0x3b, 0xef, 0xb0, 0xbf, 0x42, 0xba, 0x60, 0x7b, 0xf4,
+	0xd4, 0x3b, 0x3d, 0x3b, 0xec, 0x5b, 0x1a, 0x1c, 0x9e, 0x9f, 0x0e, 0x0f, 0xfb, 0x35, 0xd2, 0x86,
+	0x06, 0x7e, 0xd5, 0xdd, 0x01, 0x74, 0x87, 0x3c, 0x95, 0x9e, 0x79, 0x76, 0xff, 0x07, 0xda, 0xd3,
+	0x74, 0x7c, 0xe1, 0xc7, 0xe1, 0xd2, 0xfc, 0xb3, 0xb3, 0xa7, 0xe9, 0x78, 0x2f, 0x0e, 0x97, 0x7e,
+	0x0b, 0x77, 0xb4, 0xf3, 0xf7, 0x00, 0x74, 0x20, 0x5f, 0xf4, 0x23, 0x0e, 0x00, 0x00,
 }
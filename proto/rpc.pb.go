@@ -38,7 +38,7 @@ func (m *CreateRawTransferRequest) Reset()         { *m = CreateRawTransferReque
 func (m *CreateRawTransferRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateRawTransferRequest) ProtoMessage()    {}
 func (*CreateRawTransferRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{0}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{0}
 }
 func (m *CreateRawTransferRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRawTransferRequest.Unmarshal(m, b)
@@ -104,7 +104,7 @@ func (m *CreateRawTransferResponse) Reset()         { *m = CreateRawTransferResp
 func (m *CreateRawTransferResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateRawTransferResponse) ProtoMessage()    {}
 func (*CreateRawTransferResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{1}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{1}
 }
 func (m *CreateRawTransferResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRawTransferResponse.Unmarshal(m, b)
@@ -144,7 +144,7 @@ func (m *CreateRawVoteRequest) Reset()         { *m = CreateRawVoteRequest{} }
 func (m *CreateRawVoteRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateRawVoteRequest) ProtoMessage()    {}
 func (*CreateRawVoteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{2}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{2}
 }
 func (m *CreateRawVoteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRawVoteRequest.Unmarshal(m, b)
@@ -196,7 +196,7 @@ func (m *CreateRawVoteResponse) Reset()         { *m = CreateRawVoteResponse{} }
 func (m *CreateRawVoteResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateRawVoteResponse) ProtoMessage()    {}
 func (*CreateRawVoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{3}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{3}
 }
 func (m *CreateRawVoteResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRawVoteResponse.Unmarshal(m, b)
@@ -234,7 +234,7 @@ func (m *SendTransferRequest) Reset()         { *m = SendTransferRequest{} }
 func (m *SendTransferRequest) String() string { return proto.CompactTextString(m) }
 func (*SendTransferRequest) ProtoMessage()    {}
 func (*SendTransferRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{4}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{4}
 }
 func (m *SendTransferRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendTransferRequest.Unmarshal(m, b)
@@ -271,7 +271,7 @@ func (m *SendTransferResponse) Reset()         { *m = SendTransferResponse{} }
 func (m *SendTransferResponse) String() string { return proto.CompactTextString(m) }
 func (*SendTransferResponse) ProtoMessage()    {}
 func (*SendTransferResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{5}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{5}
 }
 func (m *SendTransferResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendTransferResponse.Unmarshal(m, b)
@@ -302,7 +302,7 @@ func (m *SendVoteRequest) Reset()         { *m = SendVoteRequest{} }
 func (m *SendVoteRequest) String() string { return proto.CompactTextString(m) }
 func (*SendVoteRequest) ProtoMessage()    {}
 func (*SendVoteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{6}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{6}
 }
 func (m *SendVoteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendVoteRequest.Unmarshal(m, b)
@@ -339,7 +339,7 @@ func (m *SendVoteResponse) Reset()         { *m = SendVoteResponse{} }
 func (m *SendVoteResponse) String() string { return proto.CompactTextString(m) }
 func (*SendVoteResponse) ProtoMessage()    {}
 func (*SendVoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_9795d60f63b5a206, []int{7}
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{7}
 }
 func (m *SendVoteResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendVoteResponse.Unmarshal(m, b)
@@ -359,6 +359,340 @@ func (m *SendVoteResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SendVoteResponse proto.InternalMessageInfo
 
+// The progress of block sync
+type SyncStatus struct {
+	// Idle, Init or Active
+	State string `protobuf:"bytes,1,opt,name=state" json:"state,omitempty"`
+	// whether the old blocks are being synced
+	Syncing     bool   `protobuf:"varint,2,opt,name=syncing" json:"syncing,omitempty"`
+	LocalHeight uint64 `protobuf:"varint,3,opt,name=local_height,json=localHeight" json:"local_height,omitempty"`
+	// the highest block height known from the peers
+	BestHeight uint64 `protobuf:"varint,4,opt,name=best_height,json=bestHeight" json:"best_height,omitempty"`
+	// the speed of committing blocks over the last minute
+	BlocksPerSecond float64 `protobuf:"fixed64,5,opt,name=blocks_per_second,json=blocksPerSecond" json:"blocks_per_second,omitempty"`
+	// the estimated seconds to catch up with the best height
+	Eta int64 `protobuf:"varint,6,opt,name=eta" json:"eta,omitempty"`
+	// the peers which the blocks are being pulled from
+	ActivePeers          []string `protobuf:"bytes,7,rep,name=active_peers,json=activePeers" json:"active_peers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SyncStatus) Reset()         { *m = SyncStatus{} }
+func (m *SyncStatus) String() string { return proto.CompactTextString(m) }
+func (*SyncStatus) ProtoMessage()    {}
+func (*SyncStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{8}
+}
+func (m *SyncStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncStatus.Unmarshal(m, b)
+}
+func (m *SyncStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncStatus.Marshal(b, m, deterministic)
+}
+func (dst *SyncStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncStatus.Merge(dst, src)
+}
+func (m *SyncStatus) XXX_Size() int {
+	return xxx_messageInfo_SyncStatus.Size(m)
+}
+func (m *SyncStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncStatus proto.InternalMessageInfo
+
+func (m *SyncStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *SyncStatus) GetSyncing() bool {
+	if m != nil {
+		return m.Syncing
+	}
+	return false
+}
+
+func (m *SyncStatus) GetLocalHeight() uint64 {
+	if m != nil {
+		return m.LocalHeight
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetBestHeight() uint64 {
+	if m != nil {
+		return m.BestHeight
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetBlocksPerSecond() float64 {
+	if m != nil {
+		return m.BlocksPerSecond
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetEta() int64 {
+	if m != nil {
+		return m.Eta
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetActivePeers() []string {
+	if m != nil {
+		return m.ActivePeers
+	}
+	return nil
+}
+
+type GetSyncStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSyncStatusRequest) Reset()         { *m = GetSyncStatusRequest{} }
+func (m *GetSyncStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSyncStatusRequest) ProtoMessage()    {}
+func (*GetSyncStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{9}
+}
+func (m *GetSyncStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSyncStatusRequest.Unmarshal(m, b)
+}
+func (m *GetSyncStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSyncStatusRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetSyncStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSyncStatusRequest.Merge(dst, src)
+}
+func (m *GetSyncStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSyncStatusRequest.Size(m)
+}
+func (m *GetSyncStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSyncStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSyncStatusRequest proto.InternalMessageInfo
+
+type GetSyncStatusResponse struct {
+	Status               *SyncStatus `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetSyncStatusResponse) Reset()         { *m = GetSyncStatusResponse{} }
+func (m *GetSyncStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetSyncStatusResponse) ProtoMessage()    {}
+func (*GetSyncStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{10}
+}
+func (m *GetSyncStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSyncStatusResponse.Unmarshal(m, b)
+}
+func (m *GetSyncStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSyncStatusResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetSyncStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSyncStatusResponse.Merge(dst, src)
+}
+func (m *GetSyncStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetSyncStatusResponse.Size(m)
+}
+func (m *GetSyncStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSyncStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSyncStatusResponse proto.InternalMessageInfo
+
+func (m *GetSyncStatusResponse) GetStatus() *SyncStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type SimulateActionsRequest struct {
+	SerializedTransfers  [][]byte `protobuf:"bytes,1,rep,name=serialized_transfers,json=serializedTransfers,proto3" json:"serialized_transfers,omitempty"`
+	SerializedVotes      [][]byte `protobuf:"bytes,2,rep,name=serialized_votes,json=serializedVotes,proto3" json:"serialized_votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SimulateActionsRequest) Reset()         { *m = SimulateActionsRequest{} }
+func (m *SimulateActionsRequest) String() string { return proto.CompactTextString(m) }
+func (*SimulateActionsRequest) ProtoMessage()    {}
+func (*SimulateActionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{11}
+}
+func (m *SimulateActionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulateActionsRequest.Unmarshal(m, b)
+}
+func (m *SimulateActionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulateActionsRequest.Marshal(b, m, deterministic)
+}
+func (dst *SimulateActionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulateActionsRequest.Merge(dst, src)
+}
+func (m *SimulateActionsRequest) XXX_Size() int {
+	return xxx_messageInfo_SimulateActionsRequest.Size(m)
+}
+func (m *SimulateActionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulateActionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SimulateActionsRequest proto.InternalMessageInfo
+
+func (m *SimulateActionsRequest) GetSerializedTransfers() [][]byte {
+	if m != nil {
+		return m.SerializedTransfers
+	}
+	return nil
+}
+
+func (m *SimulateActionsRequest) GetSerializedVotes() [][]byte {
+	if m != nil {
+		return m.SerializedVotes
+	}
+	return nil
+}
+
+// The state of an account which the simulated actions would lead to
+type SimulatedAccount struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Balance              []byte   `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Nonce                uint64   `protobuf:"varint,3,opt,name=nonce" json:"nonce,omitempty"`
+	Votee                string   `protobuf:"bytes,4,opt,name=votee" json:"votee,omitempty"`
+	IsCandidate          bool     `protobuf:"varint,5,opt,name=is_candidate,json=isCandidate" json:"is_candidate,omitempty"`
+	VotingWeight         []byte   `protobuf:"bytes,6,opt,name=voting_weight,json=votingWeight,proto3" json:"voting_weight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SimulatedAccount) Reset()         { *m = SimulatedAccount{} }
+func (m *SimulatedAccount) String() string { return proto.CompactTextString(m) }
+func (*SimulatedAccount) ProtoMessage()    {}
+func (*SimulatedAccount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{12}
+}
+func (m *SimulatedAccount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulatedAccount.Unmarshal(m, b)
+}
+func (m *SimulatedAccount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulatedAccount.Marshal(b, m, deterministic)
+}
+func (dst *SimulatedAccount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulatedAccount.Merge(dst, src)
+}
+func (m *SimulatedAccount) XXX_Size() int {
+	return xxx_messageInfo_SimulatedAccount.Size(m)
+}
+func (m *SimulatedAccount) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulatedAccount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SimulatedAccount proto.InternalMessageInfo
+
+func (m *SimulatedAccount) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *SimulatedAccount) GetBalance() []byte {
+	if m != nil {
+		return m.Balance
+	}
+	return nil
+}
+
+func (m *SimulatedAccount) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *SimulatedAccount) GetVotee() string {
+	if m != nil {
+		return m.Votee
+	}
+	return ""
+}
+
+func (m *SimulatedAccount) GetIsCandidate() bool {
+	if m != nil {
+		return m.IsCandidate
+	}
+	return false
+}
+
+func (m *SimulatedAccount) GetVotingWeight() []byte {
+	if m != nil {
+		return m.VotingWeight
+	}
+	return nil
+}
+
+type SimulateActionsResponse struct {
+	// the error the actions would fail with, which is empty if they would succeed
+	Error string `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	// the accounts involved in the actions, in the order of the addresses
+	Accounts             []*SimulatedAccount `protobuf:"bytes,2,rep,name=accounts" json:"accounts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *SimulateActionsResponse) Reset()         { *m = SimulateActionsResponse{} }
+func (m *SimulateActionsResponse) String() string { return proto.CompactTextString(m) }
+func (*SimulateActionsResponse) ProtoMessage()    {}
+func (*SimulateActionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_a648e921d5ae66ba, []int{13}
+}
+func (m *SimulateActionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulateActionsResponse.Unmarshal(m, b)
+}
+func (m *SimulateActionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulateActionsResponse.Marshal(b, m, deterministic)
+}
+func (dst *SimulateActionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulateActionsResponse.Merge(dst, src)
+}
+func (m *SimulateActionsResponse) XXX_Size() int {
+	return xxx_messageInfo_SimulateActionsResponse.Size(m)
+}
+func (m *SimulateActionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulateActionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SimulateActionsResponse proto.InternalMessageInfo
+
+func (m *SimulateActionsResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *SimulateActionsResponse) GetAccounts() []*SimulatedAccount {
+	if m != nil {
+		return m.Accounts
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*CreateRawTransferRequest)(nil), "iproto.CreateRawTransferRequest")
 	proto.RegisterType((*CreateRawTransferResponse)(nil), "iproto.CreateRawTransferResponse")
@@ -368,6 +702,12 @@ func init() {
 	proto.RegisterType((*SendTransferResponse)(nil), "iproto.SendTransferResponse")
 	proto.RegisterType((*SendVoteRequest)(nil), "iproto.SendVoteRequest")
 	proto.RegisterType((*SendVoteResponse)(nil), "iproto.SendVoteResponse")
+	proto.RegisterType((*SyncStatus)(nil), "iproto.SyncStatus")
+	proto.RegisterType((*GetSyncStatusRequest)(nil), "iproto.GetSyncStatusRequest")
+	proto.RegisterType((*GetSyncStatusResponse)(nil), "iproto.GetSyncStatusResponse")
+	proto.RegisterType((*SimulateActionsRequest)(nil), "iproto.SimulateActionsRequest")
+	proto.RegisterType((*SimulatedAccount)(nil), "iproto.SimulatedAccount")
+	proto.RegisterType((*SimulateActionsResponse)(nil), "iproto.SimulateActionsResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -385,6 +725,8 @@ type ChainServiceClient interface {
 	CreateRawVote(ctx context.Context, in *CreateRawVoteRequest, opts ...grpc.CallOption) (*CreateRawVoteResponse, error)
 	SendTransfer(ctx context.Context, in *SendTransferRequest, opts ...grpc.CallOption) (*SendTransferResponse, error)
 	SendVote(ctx context.Context, in *SendVoteRequest, opts ...grpc.CallOption) (*SendVoteResponse, error)
+	GetSyncStatus(ctx context.Context, in *GetSyncStatusRequest, opts ...grpc.CallOption) (*GetSyncStatusResponse, error)
+	SimulateActions(ctx context.Context, in *SimulateActionsRequest, opts ...grpc.CallOption) (*SimulateActionsResponse, error)
 }
 
 type chainServiceClient struct {
@@ -431,6 +773,24 @@ func (c *chainServiceClient) SendVote(ctx context.Context, in *SendVoteRequest,
 	return out, nil
 }
 
+func (c *chainServiceClient) GetSyncStatus(ctx context.Context, in *GetSyncStatusRequest, opts ...grpc.CallOption) (*GetSyncStatusResponse, error) {
+	out := new(GetSyncStatusResponse)
+	err := grpc.Invoke(ctx, "/iproto.ChainService/GetSyncStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chainServiceClient) SimulateActions(ctx context.Context, in *SimulateActionsRequest, opts ...grpc.CallOption) (*SimulateActionsResponse, error) {
+	out := new(SimulateActionsResponse)
+	err := grpc.Invoke(ctx, "/iproto.ChainService/SimulateActions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ChainService service
 
 type ChainServiceServer interface {
@@ -438,6 +798,8 @@ type ChainServiceServer interface {
 	CreateRawVote(context.Context, *CreateRawVoteRequest) (*CreateRawVoteResponse, error)
 	SendTransfer(context.Context, *SendTransferRequest) (*SendTransferResponse, error)
 	SendVote(context.Context, *SendVoteRequest) (*SendVoteResponse, error)
+	GetSyncStatus(context.Context, *GetSyncStatusRequest) (*GetSyncStatusResponse, error)
+	SimulateActions(context.Context, *SimulateActionsRequest) (*SimulateActionsResponse, error)
 }
 
 func RegisterChainServiceServer(s *grpc.Server, srv ChainServiceServer) {
@@ -516,6 +878,42 @@ func _ChainService_SendVote_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChainService_GetSyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSyncStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServiceServer).GetSyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/iproto.ChainService/GetSyncStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServiceServer).GetSyncStatus(ctx, req.(*GetSyncStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChainService_SimulateActions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateActionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChainServiceServer).SimulateActions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/iproto.ChainService/SimulateActions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChainServiceServer).SimulateActions(ctx, req.(*SimulateActionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ChainService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "iproto.ChainService",
 	HandlerType: (*ChainServiceServer)(nil),
@@ -536,37 +934,67 @@ var _ChainService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SendVote",
 			Handler:    _ChainService_SendVote_Handler,
 		},
+		{
+			MethodName: "GetSyncStatus",
+			Handler:    _ChainService_GetSyncStatus_Handler,
+		},
+		{
+			MethodName: "SimulateActions",
+			Handler:    _ChainService_SimulateActions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
 }
 
-func init() { proto.RegisterFile("rpc.proto", fileDescriptor_rpc_9795d60f63b5a206) }
-
-var fileDescriptor_rpc_9795d60f63b5a206 = []byte{
-	// 374 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0x4d, 0x4b, 0xeb, 0x40,
-	0x14, 0x7d, 0xe9, 0x17, 0xaf, 0x97, 0xbc, 0x57, 0xbd, 0x8d, 0x35, 0xd6, 0x0a, 0x31, 0x1b, 0xbb,
-	0xaa, 0xa0, 0x3b, 0x37, 0x0a, 0x05, 0x37, 0x8a, 0x8b, 0x54, 0x44, 0xdc, 0xc8, 0x98, 0x5c, 0x31,
-	0xa0, 0x93, 0x38, 0x99, 0x56, 0xf0, 0x67, 0xf8, 0xab, 0xfc, 0x59, 0x92, 0x64, 0x92, 0x4c, 0xbf,
-	0x44, 0x57, 0xc9, 0xbd, 0xe7, 0x9e, 0x33, 0xe7, 0xdc, 0x19, 0x68, 0x8b, 0xd8, 0x1f, 0xc5, 0x22,
-	0x92, 0x11, 0xb6, 0xc2, 0xec, 0xeb, 0x7e, 0x18, 0x60, 0x8f, 0x05, 0x31, 0x49, 0x1e, 0x7b, 0xbb,
-	0x16, 0x8c, 0x27, 0x8f, 0x24, 0x3c, 0x7a, 0x9d, 0x52, 0x22, 0xb1, 0x07, 0xad, 0x84, 0x78, 0x40,
-	0xc2, 0x36, 0x1c, 0x63, 0xd8, 0xf6, 0x54, 0x85, 0x03, 0x68, 0x0b, 0xf2, 0xc3, 0x38, 0x24, 0x2e,
-	0xed, 0x5a, 0x06, 0x55, 0x8d, 0x94, 0xc5, 0x5e, 0xa2, 0x29, 0x97, 0x76, 0xdd, 0x31, 0x86, 0xa6,
-	0xa7, 0x2a, 0xb4, 0xa0, 0xc9, 0x23, 0xee, 0x93, 0xdd, 0x70, 0x8c, 0x61, 0xc3, 0xcb, 0x0b, 0x44,
-	0x68, 0x04, 0x4c, 0x32, 0xbb, 0x99, 0xcd, 0x66, 0xff, 0xee, 0x25, 0xec, 0xac, 0xf0, 0x94, 0xc4,
-	0x11, 0x4f, 0x08, 0x0f, 0xa1, 0x9b, 0x90, 0x08, 0xd9, 0x73, 0xf8, 0x4e, 0xc1, 0xbd, 0x54, 0x70,
-	0xe6, 0xd0, 0xf4, 0xb0, 0x82, 0x0a, 0xa2, 0x7b, 0x0b, 0x56, 0xa9, 0x76, 0x13, 0x49, 0x2a, 0xd2,
-	0x59, 0xd0, 0x9c, 0x45, 0xb2, 0xa4, 0xe6, 0x45, 0xd1, 0xa5, 0x2c, 0x97, 0xea, 0x52, 0xe5, 0xbd,
-	0xae, 0x79, 0x77, 0xcf, 0x60, 0x6b, 0x41, 0x59, 0x79, 0x3c, 0x80, 0x8e, 0xe6, 0x31, 0x95, 0x50,
-	0x87, 0xfc, 0xaf, 0xda, 0x29, 0xc1, 0x3d, 0x87, 0xee, 0x84, 0x78, 0xb0, 0xb8, 0xf8, 0x5f, 0x67,
-	0xec, 0x81, 0x35, 0xaf, 0x93, 0x1b, 0x71, 0x4f, 0xa0, 0x93, 0xf6, 0xf5, 0xd8, 0x3f, 0xf6, 0x86,
-	0xb0, 0x51, 0x71, 0x73, 0xbd, 0xa3, 0xcf, 0x1a, 0x98, 0xe3, 0x27, 0x16, 0xf2, 0x09, 0x89, 0x59,
-	0xe8, 0x13, 0xde, 0xc1, 0xe6, 0xd2, 0x55, 0xa1, 0x33, 0xca, 0x5f, 0xd7, 0x68, 0xdd, 0xcb, 0xea,
-	0xef, 0x7f, 0x33, 0xa1, 0xac, 0xff, 0xc1, 0x2b, 0xf8, 0x37, 0xb7, 0x5e, 0x1c, 0x2c, 0xb1, 0xb4,
-	0x60, 0xfd, 0xbd, 0x35, 0x68, 0xa9, 0x77, 0x01, 0xa6, 0xbe, 0x24, 0xdc, 0x2d, 0x08, 0x2b, 0xae,
-	0xa0, 0x3f, 0x58, 0x0d, 0x96, 0x62, 0xa7, 0xf0, 0xb7, 0xd8, 0x0e, 0x6e, 0xeb, 0xb3, 0xba, 0x25,
-	0x7b, 0x19, 0x28, 0x04, 0x1e, 0x5a, 0x19, 0x72, 0xfc, 0x15, 0x00, 0x00, 0xff, 0xff, 0xa5, 0xf5,
-	0x1c, 0x9e, 0x95, 0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("rpc.proto", fileDescriptor_rpc_a648e921d5ae66ba) }
+
+var fileDescriptor_rpc_a648e921d5ae66ba = []byte{
+	// 729 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xdb, 0x6e, 0x13, 0x49,
+	0x10, 0xdd, 0x89, 0x2f, 0x89, 0xcb, 0xce, 0xda, 0xe9, 0x38, 0xc9, 0xac, 0x37, 0xd9, 0x38, 0xb3,
+	0x0f, 0xeb, 0xcd, 0x43, 0x56, 0x1b, 0x78, 0xe2, 0x05, 0x22, 0x4b, 0x80, 0x04, 0x42, 0xd1, 0x38,
+	0x02, 0xc4, 0x8b, 0xd5, 0x9e, 0x29, 0x92, 0x16, 0x4e, 0x8f, 0xe9, 0x6e, 0x3b, 0x0a, 0x9f, 0xc1,
+	0xe7, 0xf0, 0x37, 0xfc, 0x00, 0xdf, 0x80, 0xfa, 0x32, 0x17, 0xdf, 0x10, 0x3c, 0xd9, 0x75, 0xaa,
+	0xfa, 0x4c, 0xd5, 0xa9, 0x0b, 0xd4, 0xc4, 0x24, 0x3a, 0x9b, 0x88, 0x44, 0x25, 0xa4, 0xca, 0xcc,
+	0x6f, 0xf0, 0xd9, 0x03, 0xbf, 0x2f, 0x90, 0x2a, 0x0c, 0xe9, 0xdd, 0x95, 0xa0, 0x5c, 0xbe, 0x47,
+	0x11, 0xe2, 0xc7, 0x29, 0x4a, 0x45, 0xf6, 0xa1, 0x2a, 0x91, 0xc7, 0x28, 0x7c, 0xaf, 0xeb, 0xf5,
+	0x6a, 0xa1, 0xb3, 0xc8, 0x21, 0xd4, 0x04, 0x46, 0x6c, 0xc2, 0x90, 0x2b, 0x7f, 0xc3, 0xb8, 0x72,
+	0x40, 0xbf, 0xa2, 0xb7, 0xc9, 0x94, 0x2b, 0xbf, 0xd4, 0xf5, 0x7a, 0x8d, 0xd0, 0x59, 0xa4, 0x0d,
+	0x15, 0x9e, 0xf0, 0x08, 0xfd, 0x72, 0xd7, 0xeb, 0x95, 0x43, 0x6b, 0x10, 0x02, 0xe5, 0x98, 0x2a,
+	0xea, 0x57, 0x4c, 0xac, 0xf9, 0x1f, 0xbc, 0x84, 0x3f, 0x56, 0xe4, 0x24, 0x27, 0x09, 0x97, 0x48,
+	0xfe, 0x83, 0x5d, 0x89, 0x82, 0xd1, 0x31, 0xfb, 0x84, 0xf1, 0x50, 0x39, 0xb7, 0xc9, 0xb0, 0x11,
+	0x92, 0xdc, 0x95, 0x3e, 0x0c, 0xde, 0x42, 0x3b, 0x63, 0x7b, 0x9d, 0x28, 0x4c, 0xab, 0x6b, 0x43,
+	0x65, 0x96, 0xa8, 0xec, 0xa9, 0x35, 0x52, 0x14, 0xfd, 0x8d, 0x1c, 0xc5, 0x3c, 0xf7, 0x52, 0x21,
+	0xf7, 0xe0, 0x09, 0xec, 0x2d, 0x30, 0xbb, 0x1c, 0xff, 0x81, 0x66, 0x21, 0x47, 0x4d, 0xe1, 0x3e,
+	0xf2, 0x7b, 0x0e, 0xeb, 0x07, 0xc1, 0x53, 0xd8, 0x1d, 0x20, 0x8f, 0x17, 0x85, 0xff, 0xe5, 0x1a,
+	0xf7, 0xa1, 0x3d, 0xcf, 0x63, 0x13, 0x09, 0x1e, 0x41, 0x53, 0xe3, 0xc5, 0xb2, 0x7f, 0x3a, 0x37,
+	0x02, 0xad, 0xfc, 0xad, 0xe3, 0xfb, 0xea, 0x01, 0x0c, 0xee, 0x79, 0x34, 0x50, 0x54, 0x4d, 0xa5,
+	0x96, 0x45, 0x2a, 0xea, 0x18, 0x6a, 0xa1, 0x35, 0x88, 0x0f, 0x9b, 0xf2, 0x9e, 0x47, 0x8c, 0x5f,
+	0x1b, 0x11, 0xb7, 0xc2, 0xd4, 0x24, 0x27, 0xd0, 0x18, 0x27, 0x11, 0x1d, 0x0f, 0x6f, 0x90, 0x5d,
+	0xdf, 0x28, 0xa7, 0x66, 0xdd, 0x60, 0xcf, 0x0d, 0x44, 0x8e, 0xa1, 0x3e, 0x42, 0xa9, 0xd2, 0x08,
+	0x3b, 0x2b, 0xa0, 0x21, 0x17, 0x70, 0x0a, 0x3b, 0xa3, 0x71, 0x12, 0x7d, 0x90, 0xc3, 0x09, 0x8a,
+	0xa1, 0xc4, 0x28, 0xe1, 0xb1, 0x99, 0x1e, 0x2f, 0x6c, 0x5a, 0xc7, 0x25, 0x8a, 0x81, 0x81, 0x49,
+	0x0b, 0x4a, 0xa8, 0xa8, 0x5f, 0xed, 0x7a, 0xbd, 0x52, 0xa8, 0xff, 0xea, 0x0c, 0x68, 0xa4, 0xd8,
+	0x0c, 0x87, 0x13, 0x44, 0x21, 0xfd, 0xcd, 0x6e, 0xa9, 0x57, 0x0b, 0xeb, 0x16, 0xbb, 0xd4, 0x90,
+	0xd6, 0xf2, 0x19, 0xaa, 0xbc, 0x4a, 0x27, 0x5c, 0xd0, 0x87, 0xbd, 0x05, 0xdc, 0x75, 0xfb, 0x14,
+	0xaa, 0xd2, 0x20, 0x46, 0x86, 0xfa, 0x39, 0x39, 0xb3, 0xcb, 0x75, 0x56, 0x88, 0x75, 0x11, 0xc1,
+	0x0c, 0xf6, 0x07, 0xec, 0x76, 0x3a, 0xa6, 0x0a, 0x2f, 0x22, 0xc5, 0x12, 0x9e, 0xd2, 0x93, 0xff,
+	0xa1, 0xbd, 0xa2, 0xe7, 0x9a, 0xb3, 0xd4, 0x6b, 0x84, 0xbb, 0xcb, 0x4d, 0x97, 0xe4, 0x5f, 0x68,
+	0x2d, 0xb4, 0x52, 0xfa, 0x1b, 0x26, 0xbc, 0x39, 0xdf, 0x4b, 0x19, 0x7c, 0xf1, 0xa0, 0x95, 0x7e,
+	0x38, 0xbe, 0x88, 0x22, 0xb3, 0x91, 0x3e, 0x6c, 0xd2, 0x38, 0x16, 0x28, 0xa5, 0x6b, 0x60, 0x6a,
+	0x6a, 0xcf, 0x88, 0x8e, 0xa9, 0x9e, 0x78, 0xbb, 0x07, 0xa9, 0xb9, 0x7a, 0x13, 0xf2, 0xad, 0x29,
+	0xdb, 0x41, 0x30, 0x86, 0x16, 0x9b, 0xc9, 0x61, 0x44, 0x79, 0xcc, 0x62, 0x3d, 0x25, 0x15, 0x33,
+	0x0d, 0x75, 0x26, 0xfb, 0x29, 0x44, 0xfe, 0x86, 0xed, 0x59, 0xa2, 0x18, 0xbf, 0x1e, 0xde, 0xd9,
+	0x86, 0x57, 0xcd, 0xe7, 0x1a, 0x16, 0x7c, 0x63, 0xb0, 0x00, 0xe1, 0x60, 0x49, 0x34, 0xa7, 0x7d,
+	0x1b, 0x2a, 0x28, 0x44, 0x92, 0x5e, 0x28, 0x6b, 0x90, 0x87, 0xb0, 0x45, 0x6d, 0x8d, 0x56, 0x90,
+	0xfa, 0xb9, 0x9f, 0xf5, 0x64, 0x41, 0x84, 0x30, 0x8b, 0x3c, 0xff, 0x56, 0x82, 0x46, 0xff, 0x86,
+	0x32, 0x3e, 0x40, 0x31, 0x63, 0x11, 0x92, 0x77, 0xb0, 0xb3, 0x74, 0x87, 0x48, 0x37, 0x65, 0x5a,
+	0x77, 0x36, 0x3b, 0x27, 0x3f, 0x88, 0x70, 0x7b, 0xf4, 0x1b, 0x79, 0x05, 0xdb, 0x73, 0xb7, 0x83,
+	0x1c, 0x2e, 0xbd, 0x2a, 0x6c, 0x6d, 0xe7, 0x68, 0x8d, 0x37, 0xe3, 0x7b, 0x01, 0x8d, 0xe2, 0x05,
+	0x20, 0x7f, 0x66, 0x05, 0x2f, 0xdf, 0x97, 0xce, 0xe1, 0x6a, 0x67, 0x46, 0xf6, 0x18, 0xb6, 0xd2,
+	0xd5, 0x27, 0x07, 0xc5, 0xd8, 0x62, 0x4a, 0xfe, 0xb2, 0xa3, 0x58, 0xdd, 0xdc, 0xae, 0xe4, 0xd5,
+	0xad, 0x5a, 0xad, 0xce, 0xd1, 0x1a, 0x6f, 0xc6, 0x77, 0x05, 0xcd, 0x85, 0x09, 0x20, 0x7f, 0x2d,
+	0x76, 0x74, 0x7e, 0x9f, 0x3a, 0xc7, 0x6b, 0xfd, 0x29, 0xeb, 0xa8, 0x6a, 0x02, 0x1e, 0x7c, 0x1f,
+	0x00, 0x1f, 0x10, 0x53, 0xba, 0x18, 0x07, 0x00, 0x00,
 }
@@ -32,6 +32,14 @@ const (
 	MsgBlockSyncDataType uint32 = 5
 	// MsgActionType is the action message
 	MsgActionType uint32 = 6
+	// MsgBlockHeaderSyncReqType is for requests among peers to sync block headers
+	MsgBlockHeaderSyncReqType uint32 = 7
+	// MsgBlockHeaderSyncDataType is the response to messages of type MsgBlockHeaderSyncReqType
+	MsgBlockHeaderSyncDataType uint32 = 8
+	// MsgStateSnapshotSyncReqType is for requests among peers to sync the state snapshot
+	MsgStateSnapshotSyncReqType uint32 = 9
+	// MsgStateSnapshotSyncDataType is the response to messages of type MsgStateSnapshotSyncReqType
+	MsgStateSnapshotSyncDataType uint32 = 10
 	// TestPayloadType is a test payload message type
 	TestPayloadType uint32 = 10001
 )
@@ -51,6 +59,14 @@ func GetTypeFromProtoMsg(msg proto.Message) (uint32, error) {
 		return MsgBlockSyncDataType, nil
 	case *ActionPb:
 		return MsgActionType, nil
+	case *BlockHeaderSync:
+		return MsgBlockHeaderSyncReqType, nil
+	case *BlockHeaderContainer:
+		return MsgBlockHeaderSyncDataType, nil
+	case *StateSnapshotSync:
+		return MsgStateSnapshotSyncReqType, nil
+	case *StateSnapshotChunk:
+		return MsgStateSnapshotSyncDataType, nil
 	case *TestPayload:
 		return TestPayloadType, nil
 	default:
@@ -74,6 +90,14 @@ func TypifyProtoMsg(tp uint32, msg []byte) (proto.Message, error) {
 		m = &BlockContainer{}
 	case MsgActionType:
 		m = &ActionPb{}
+	case MsgBlockHeaderSyncReqType:
+		m = &BlockHeaderSync{}
+	case MsgBlockHeaderSyncDataType:
+		m = &BlockHeaderContainer{}
+	case MsgStateSnapshotSyncReqType:
+		m = &StateSnapshotSync{}
+	case MsgStateSnapshotSyncDataType:
+		m = &StateSnapshotChunk{}
 	case TestPayloadType:
 		m = &TestPayload{}
 	default:
@@ -17,6 +17,8 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/blockchain/action"
+	"github.com/iotexproject/iotex-core/blocksync"
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/dispatch/dispatcher"
 	"github.com/iotexproject/iotex-core/iotxaddress"
@@ -29,17 +31,18 @@ type Chainserver struct {
 	blockchain  blockchain.Blockchain
 	config      config.RPC
 	dispatcher  dispatcher.Dispatcher
+	blocksync   blocksync.BlockSync
 	grpcserver  *grpc.Server
 	broadcastcb func(proto.Message) error
 }
 
 // NewChainServer creates an instance of chainserver
-func NewChainServer(c config.RPC, b blockchain.Blockchain, dp dispatcher.Dispatcher, cb func(proto.Message) error) *Chainserver {
+func NewChainServer(c config.RPC, b blockchain.Blockchain, dp dispatcher.Dispatcher, bs blocksync.BlockSync, cb func(proto.Message) error) *Chainserver {
 	if cb == nil {
 		logger.Error().Msg("cannot new chain server with nil callback")
 		return nil
 	}
-	return &Chainserver{blockchain: b, config: c, dispatcher: dp, broadcastcb: cb}
+	return &Chainserver{blockchain: b, config: c, dispatcher: dp, blocksync: bs, broadcastcb: cb}
 }
 
 // CreateRawTransfer creates an unsigned raw transaction
@@ -118,6 +121,59 @@ func (s *Chainserver) SendVote(ctx context.Context, in *pb.SendVoteRequest) (*pb
 	return &pb.SendVoteResponse{}, nil
 }
 
+// GetSyncStatus returns the progress of block sync
+func (s *Chainserver) GetSyncStatus(ctx context.Context, in *pb.GetSyncStatusRequest) (*pb.GetSyncStatusResponse, error) {
+	logger.Debug().Msg("receive get sync status request")
+
+	status, err := s.blocksync.SyncStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetSyncStatusResponse{Status: status}, nil
+}
+
+// SimulateActions previews the states which the actions would lead to in the next block without sending them out. The
+// actions which would fail are answered with the error instead of the states.
+func (s *Chainserver) SimulateActions(ctx context.Context, in *pb.SimulateActionsRequest) (*pb.SimulateActionsResponse, error) {
+	logger.Debug().Msg("receive simulate actions request")
+
+	if len(in.SerializedTransfers) == 0 && len(in.SerializedVotes) == 0 {
+		return nil, errors.New("invalid SimulateActionsRequest")
+	}
+	var transfers []*action.Transfer
+	for _, serialized := range in.SerializedTransfers {
+		tsf := &action.Transfer{}
+		if err := tsf.Deserialize(serialized); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, tsf)
+	}
+	var votes []*action.Vote
+	for _, serialized := range in.SerializedVotes {
+		vote := &action.Vote{}
+		if err := vote.Deserialize(serialized); err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	states, err := s.blockchain.SimulateActions(transfers, votes)
+	if err != nil {
+		return &pb.SimulateActionsResponse{Error: err.Error()}, nil
+	}
+	res := &pb.SimulateActionsResponse{}
+	for _, st := range states {
+		res.Accounts = append(res.Accounts, &pb.SimulatedAccount{
+			Address:      st.Address,
+			Balance:      st.Balance.Bytes(),
+			Nonce:        st.Nonce,
+			Votee:        st.Votee,
+			IsCandidate:  st.IsCandidate,
+			VotingWeight: st.VotingWeight.Bytes(),
+		})
+	}
+	return res, nil
+}
+
 // Start starts the chain server
 func (s *Chainserver) Start() error {
 	if s.config == (config.RPC{}) {
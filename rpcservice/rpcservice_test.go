@@ -22,7 +22,9 @@ import (
 	"github.com/iotexproject/iotex-core/config"
 	"github.com/iotexproject/iotex-core/iotxaddress"
 	pb "github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/state"
 	"github.com/iotexproject/iotex-core/test/mock/mock_blockchain"
+	"github.com/iotexproject/iotex-core/test/mock/mock_blocksync"
 	"github.com/iotexproject/iotex-core/test/mock/mock_dispatcher"
 )
 
@@ -56,7 +58,7 @@ func TestCreateRawTransfer(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s := NewChainServer(cfg.RPC, mbc, mdp, nil, bcb)
 	assert.NotNil(t, s)
 	s.Start()
 	defer s.Stop()
@@ -97,7 +99,7 @@ func TestCreateRawVote(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s := NewChainServer(cfg.RPC, mbc, mdp, nil, bcb)
 	assert.NotNil(t, s)
 	s.Start()
 	defer s.Stop()
@@ -140,7 +142,7 @@ func TestSendTransfer(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s := NewChainServer(cfg.RPC, mbc, mdp, nil, bcb)
 	assert.NotNil(t, s)
 	s.Start()
 	defer s.Stop()
@@ -182,7 +184,7 @@ func TestSendVote(t *testing.T) {
 		return nil
 	}
 
-	s := NewChainServer(cfg.RPC, mbc, mdp, bcb)
+	s := NewChainServer(cfg.RPC, mbc, mdp, nil, bcb)
 	assert.NotNil(t, s)
 	s.Start()
 	defer s.Stop()
@@ -205,3 +207,107 @@ func TestSendVote(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, cbinvoked)
 }
+
+func TestGetSyncStatus(t *testing.T) {
+	cfg := config.Config{
+		RPC: config.RPC{
+			Addr: "127.0.0.1:42124",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	mbs := mock_blocksync.NewMockBlockSync(ctrl)
+
+	bcb := func(msg proto.Message) error {
+		return nil
+	}
+
+	s := NewChainServer(cfg.RPC, mbc, mdp, mbs, bcb)
+	assert.NotNil(t, s)
+	s.Start()
+	defer s.Stop()
+
+	// Set up a connection to the server.
+	conn, err := grpc.Dial("127.0.0.1:42124", grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// Contact the server and print out its response.
+	c := pb.NewChainServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	status := &pb.SyncStatus{
+		State:           "Active",
+		Syncing:         true,
+		LocalHeight:     100,
+		BestHeight:      160,
+		BlocksPerSecond: 2,
+		Eta:             30,
+		ActivePeers:     []string{"127.0.0.1:40000"},
+	}
+	mbs.EXPECT().SyncStatus().Return(status, nil).Times(1)
+	r, err := c.GetSyncStatus(ctx, &pb.GetSyncStatusRequest{})
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(status, r.Status))
+}
+
+func TestSimulateActions(t *testing.T) {
+	cfg := config.Config{
+		RPC: config.RPC{
+			Addr: "127.0.0.1:42124",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mbc := mock_blockchain.NewMockBlockchain(ctrl)
+	mdp := mock_dispatcher.NewMockDispatcher(ctrl)
+	mbs := mock_blocksync.NewMockBlockSync(ctrl)
+
+	bcb := func(msg proto.Message) error {
+		return nil
+	}
+
+	s := NewChainServer(cfg.RPC, mbc, mdp, mbs, bcb)
+	assert.NotNil(t, s)
+	s.Start()
+	defer s.Stop()
+
+	// Set up a connection to the server.
+	conn, err := grpc.Dial("127.0.0.1:42124", grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// Contact the server and print out its response.
+	c := pb.NewChainServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tsf := testingTransfer()
+	stsf, err := tsf.Serialize()
+	assert.Nil(t, err)
+	mbc.EXPECT().SimulateActions(gomock.Any(), gomock.Any()).Return([]*state.State{
+		{Address: tsf.Sender, Nonce: 1, Balance: big.NewInt(10), VotingWeight: big.NewInt(0)},
+	}, nil).Times(1)
+	r, err := c.SimulateActions(ctx, &pb.SimulateActionsRequest{SerializedTransfers: [][]byte{stsf}})
+	assert.Nil(t, err)
+	assert.Equal(t, "", r.Error)
+	assert.Equal(t, 1, len(r.Accounts))
+	assert.Equal(t, tsf.Sender, r.Accounts[0].Address)
+	assert.Equal(t, uint64(1), r.Accounts[0].Nonce)
+	assert.Equal(t, "10", new(big.Int).SetBytes(r.Accounts[0].Balance).String())
+
+	// the actions which would fail are answered with the error
+	mbc.EXPECT().SimulateActions(gomock.Any(), gomock.Any()).Return(nil, state.ErrNotEnoughBalance).Times(1)
+	r, err = c.SimulateActions(ctx, &pb.SimulateActionsRequest{SerializedTransfers: [][]byte{stsf}})
+	assert.Nil(t, err)
+	assert.Equal(t, state.ErrNotEnoughBalance.Error(), r.Error)
+	assert.Equal(t, 0, len(r.Accounts))
+
+	_, err = c.SimulateActions(ctx, &pb.SimulateActionsRequest{})
+	assert.NotNil(t, err)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/iotexproject/iotex-core/dispatch"
 	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/network"
+	"github.com/iotexproject/iotex-core/trie"
 )
 
 // TODO: HeartbeatHandler opens encapsulation of a few structs to inspect the internal status, we need to find a better
@@ -79,6 +80,12 @@ func (h *HeartbeatHandler) Do() {
 		height = 0
 	}
 
+	// State metrics
+	var trieCache trie.CacheStats
+	if sf := h.s.Sf(); sf != nil {
+		trieCache = sf.TrieCacheStats()
+	}
+
 	logger.Info().
 		Uint("num-peers", numPeers).
 		Time("last-out", lastOutTime).
@@ -87,5 +94,7 @@ func (h *HeartbeatHandler) Do() {
 		Int("rolldpos-events", numCSEvts).
 		Str("fsm-state", string(state)).
 		Uint64("height", height).
+		Uint64("trie-cache-hits", trieCache.Hits).
+		Uint64("trie-cache-misses", trieCache.Misses).
 		Msg("node status")
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/network"
 	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/trie"
 )
 
 // Server is the iotex server instance containing all components.
@@ -27,6 +28,7 @@ type Server struct {
 	service.Service
 	bc  blockchain.Blockchain
 	ap  actpool.ActPool
+	bs  blocksync.BlockSync
 	o   *network.Overlay
 	dp  dispatcher.Dispatcher
 	cfg config.Config
@@ -36,7 +38,14 @@ type Server struct {
 // NewServer creates a new server
 func NewServer(cfg config.Config) *Server {
 	// create StateFactory
-	sf, err := state.NewFactoryFromTrieDBPath(cfg.Chain.TrieDBPath, false)
+	sf, err := state.NewFactoryFromTrieDBPath(
+		cfg.Chain.TrieDBPath,
+		false,
+		[]trie.Option{trie.GCOption(cfg.Chain.TrieRetainedRoots, cfg.Chain.TrieGCInterval)},
+		state.CandidatePoolOption(blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize),
+		state.UnbondingPeriodOption(blockchain.Gen.VoteUnbondingPeriod),
+		state.VoterRewardOption(blockchain.Gen.VoterRewardPercentage, blockchain.Gen.RewardEpoch),
+	)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create statefactory")
 		return nil
@@ -83,6 +92,11 @@ func (s *Server) Ap() actpool.ActPool {
 	return s.ap
 }
 
+// Bs returns the BlockSync
+func (s *Server) Bs() blocksync.BlockSync {
+	return s.bs
+}
+
 // P2p returns the P2P network
 func (s *Server) P2p() *network.Overlay {
 	return s.o
@@ -101,9 +115,14 @@ func (s *Server) Sf() state.Factory {
 func newServer(cfg config.Config, bc blockchain.Blockchain, sf state.Factory) *Server {
 	// create P2P network and BlockSync
 	o := network.NewOverlay(&cfg.Network)
+	if len(cfg.Chain.ProducerAddr.PrivateKey) > 0 {
+		o.AttachIdentity(&cfg.Chain.ProducerAddr)
+	}
+	o.AttachBlockchain(bc)
 	// Create ActPool
 	ap := actpool.NewActPool(sf)
 	pool := delegate.NewConfigBasedPool(&cfg.Delegate)
+	pool.AttachKeyResolver(o)
 	bs, err := blocksync.NewBlockSyncer(&cfg, bc, ap, o, pool)
 	if err != nil {
 		logger.Fatal().Err(err)
@@ -119,6 +138,7 @@ func newServer(cfg config.Config, bc blockchain.Blockchain, sf state.Factory) *S
 	return &Server{
 		bc:  bc,
 		ap:  ap,
+		bs:  bs,
 		o:   o,
 		dp:  dp,
 		cfg: cfg,
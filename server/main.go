@@ -76,7 +76,7 @@ func main() {
 		bcb := func(msg proto.Message) error {
 			return svr.P2p().Broadcast(msg)
 		}
-		cs := rpcservice.NewChainServer(cfg.RPC, svr.Bc(), svr.Dp(), bcb)
+		cs := rpcservice.NewChainServer(cfg.RPC, svr.Bc(), svr.Dp(), svr.Bs(), bcb)
 		if cs == nil {
 			os.Exit(1)
 		}
@@ -100,7 +100,7 @@ func main() {
 		if !ok {
 			logger.Fatal().Msg("unexpected dispatcher module")
 		}
-		explorer.StartJSONServer(svr.Bc(), d.Consensus(), isTest, httpPort, cfg.Explorer.TpsWindow)
+		explorer.StartJSONServer(svr.Bc(), d.Consensus(), svr.Bs(), isTest, httpPort, cfg.Explorer.TpsWindow)
 	}
 
 	select {}
@@ -34,6 +34,7 @@ import (
 	"github.com/iotexproject/iotex-core/network"
 	pb "github.com/iotexproject/iotex-core/simulator/proto/simulator"
 	"github.com/iotexproject/iotex-core/state"
+	"github.com/iotexproject/iotex-core/trie"
 )
 
 const (
@@ -101,7 +102,14 @@ func (s *server) Init(in *pb.InitRequest, stream pb.Simulator_InitServer) error
 		// set chain database path
 		cfg.Chain.ChainDBPath = "./chain" + strconv.Itoa(i) + ".db"
 
-		sf, _ := state.NewFactoryFromTrieDBPath(cfg.Chain.TrieDBPath, false)
+		sf, _ := state.NewFactoryFromTrieDBPath(
+			cfg.Chain.TrieDBPath,
+			false,
+			[]trie.Option{trie.GCOption(cfg.Chain.TrieRetainedRoots, cfg.Chain.TrieGCInterval)},
+			state.CandidatePoolOption(blockchain.Gen.CandidateSize, blockchain.Gen.CandidateBufferSize),
+			state.UnbondingPeriodOption(blockchain.Gen.VoteUnbondingPeriod),
+			state.VoterRewardOption(blockchain.Gen.VoterRewardPercentage, blockchain.Gen.RewardEpoch),
+		)
 		bc := blockchain.CreateBlockchain(cfg, sf)
 
 		if i >= int(in.NFS+in.NHonest) { // is byzantine node
@@ -9,6 +9,7 @@ package state
 import (
 	"container/heap"
 	"math/big"
+	"strings"
 )
 
 // Candidate is used in the heap
@@ -38,7 +39,7 @@ func (pqStruct CandidateMinPQ) Len() int {
 
 func (pqStruct CandidateMinPQ) Less(i, j int) bool {
 	pq := pqStruct.pq
-	return pq[i].Votes.Cmp(pq[j].Votes) < 0
+	return compareCandidates(pq[i], pq[j]) < 0
 }
 
 func (pqStruct CandidateMinPQ) Swap(i, j int) {
@@ -111,7 +112,7 @@ func (pqStruct CandidateMaxPQ) Len() int {
 
 func (pqStruct CandidateMaxPQ) Less(i, j int) bool {
 	pq := pqStruct.pq
-	return pq[i].Votes.Cmp(pq[j].Votes) > 0
+	return compareCandidates(pq[i], pq[j]) > 0
 }
 
 func (pqStruct CandidateMaxPQ) Swap(i, j int) {
@@ -163,3 +164,12 @@ func (pqStruct *CandidateMaxPQ) CandidateList() []*Candidate {
 	}
 	return candidates
 }
+
+// compareCandidates orders the candidates by the votes, and then by the addresses to break the ties, so that the pools
+// evolve in the same way on every node regardless of the layouts of the heaps
+func compareCandidates(a *Candidate, b *Candidate) int {
+	if cmp := a.Votes.Cmp(b.Votes); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(a.Address, b.Address)
+}
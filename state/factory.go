@@ -7,15 +7,20 @@
 package state
 
 import (
+	"bytes"
 	"container/heap"
 	"fmt"
 	"math/big"
+	"sort"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/blockchain/action"
 	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
 	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
 	"github.com/iotexproject/iotex-core/trie"
 )
 
@@ -24,19 +29,23 @@ const (
 	candidatePool = 1
 	// Level 2 is for candidate buffer pool
 	candidateBufferPool = candidatePool + 1
-
-	// TODO: make this configurable
-	//	delegateSize  = 101
-	//	candidateSize = 400
-	//	bufferSize    = 10000
 )
 
-// TODO: this is only for test config, use 101, 400, 10000 when in production
 const (
-	candidateSize       = 2
-	candidateBufferSize = 10
+	// DefaultCandidateSize is the size of the candidate pool unless configured
+	DefaultCandidateSize = 400
+	// DefaultCandidateBufferSize is the size of the candidate buffer pool unless configured
+	DefaultCandidateBufferSize = 10000
 )
 
+// candidatePoolKey is the trie key of the candidate pools, which is a hash as long as the public key hashes keying the
+// accounts
+var candidatePoolKey = iotxaddress.HashPubKey([]byte("candidatePool"))
+
+// stateHeightKey is the trie key of the height of the last block whose state changes are committed, which is kept in the
+// same commit so that a restarted node knows which blocks the state is up to
+var stateHeightKey = iotxaddress.HashPubKey([]byte("stateHeight"))
+
 var (
 	// ErrInvalidAddr is the error that the address format is invalid, cannot be decoded
 	ErrInvalidAddr = errors.New("address format is invalid")
@@ -52,6 +61,9 @@ var (
 
 	// ErrFailedToUnmarshalState is the error that the state un-marshaling is failed
 	ErrFailedToUnmarshalState = errors.New("failed to unmarshal state")
+
+	// ErrCandidatePoolSize is the error that the candidate pools kept in the state have different sizes than configured
+	ErrCandidatePoolSize = errors.New("candidate pool sizes don't match")
 )
 
 type (
@@ -60,45 +72,126 @@ type (
 		CreateState(string, uint64) (*State, error)
 		Balance(string) (*big.Int, error)
 		CommitStateChanges(uint64, []*action.Transfer, []*action.Vote) error
+		// SimulateStateChanges returns the states which the actions would lead to in the next block, without committing
+		// them. It must not run along with CommitStateChanges
+		SimulateStateChanges([]*action.Transfer, []*action.Vote) ([]*State, error)
 		// Note that nonce starts with 1.
 		Nonce(string) (uint64, error)
 		State(string) (*State, error)
 		RootHash() common.Hash32B
+		// Height returns the height of the last block whose state changes are committed, and false if there is none
+		Height() (uint64, bool)
+		// Reset empties the state, so that it can be rebuilt from the genesis states
+		Reset() error
 		Candidates() (uint64, []*Candidate)
+		// RewardReceipts returns the receipts of the voter rewards credited by the last state changes committed
+		RewardReceipts() []*RewardReceipt
+		// VoterRewards returns the voter rewards accrued by each delegate in the current reward epoch, which aren't
+		// distributed yet
+		VoterRewards() map[string]*big.Int
+		// StateDiff returns the changes of the accounts made by the last state changes committed
+		StateDiff() *StateDiff
+		// TrieCacheStats returns the hit and miss counters of the node cache of the trie holding the states
+		TrieCacheStats() trie.CacheStats
+		// IterateStates calls the function on the state of every account, in the order of the public key hashes
+		IterateStates(func(*State) error) error
+		// Snapshot exports the full state, and LoadSnapshot starts from it
+		Snapshot() (*Snapshot, error)
+		LoadSnapshot(*Snapshot) error
 	}
 
 	// factory implements StateFactory interface, tracks changes in a map and batch-commits to trie/db
 	factory struct {
 		currentChainHeight     uint64
+		committed              bool // whether the state changes of a block are committed
 		trie                   trie.Trie
+		candidateSize          int
+		candidateBufferSize    int
+		unbondingPeriod        uint64
+		voterRewardPercentage  uint64
+		rewardEpoch            uint64
+		voterRewards           map[string]*big.Int // the voter rewards accrued by the delegates in the reward epoch
+		pendingVoterRewards    map[string]*big.Int // the voter rewards updated by the state changes not committed yet
+		receipts               []*RewardReceipt
+		diff                   *StateDiff
 		candidateHeap          CandidateMinPQ
 		candidateBufferMinHeap CandidateMinPQ
 		candidateBufferMaxHeap CandidateMaxPQ
 	}
+
+	// FactoryOption sets an optional parameter of the state factory
+	FactoryOption func(*factory)
 )
 
-// NewFactory creates a new state factory
-func NewFactory(tr trie.Trie) Factory {
-	return &factory{
-		currentChainHeight:     0,
-		trie:                   tr,
-		candidateHeap:          CandidateMinPQ{candidateSize, make([]*Candidate, 0)},
-		candidateBufferMinHeap: CandidateMinPQ{candidateBufferSize, make([]*Candidate, 0)},
-		candidateBufferMaxHeap: CandidateMaxPQ{candidateBufferSize, make([]*Candidate, 0)},
+// CandidatePoolOption sets the sizes of the candidate pool and the candidate buffer pool, while 0 leaves the default
+func CandidatePoolOption(candidateSize uint, candidateBufferSize uint) FactoryOption {
+	return func(sf *factory) {
+		if candidateSize > 0 {
+			sf.candidateSize = int(candidateSize)
+		}
+		if candidateBufferSize > 0 {
+			sf.candidateBufferSize = int(candidateBufferSize)
+		}
 	}
 }
 
-// NewFactoryFromTrieDBPath creates a new stateFactory from give trie db path.
-func NewFactoryFromTrieDBPath(dbPath string, inMem bool) (Factory, error) {
+// UnbondingPeriodOption sets the number of blocks, for which the tokens of a withdrawn vote stay locked
+func UnbondingPeriodOption(period uint64) FactoryOption {
+	return func(sf *factory) {
+		sf.unbondingPeriod = period
+	}
+}
+
+// VoterRewardOption sets the percentage of the block reward which goes to the voters of the block producer, and the
+// number of blocks in a reward epoch, at the end of which the voter rewards accrued are distributed
+func VoterRewardOption(percentage uint, epoch uint64) FactoryOption {
+	return func(sf *factory) {
+		sf.voterRewardPercentage = uint64(percentage)
+		sf.rewardEpoch = epoch
+	}
+}
+
+// NewFactory creates a new state factory, whose candidate pools are rebuilt from the ones kept in the trie
+func NewFactory(tr trie.Trie, opts ...FactoryOption) (Factory, error) {
+	sf := &factory{
+		currentChainHeight:  0,
+		trie:                tr,
+		candidateSize:       DefaultCandidateSize,
+		candidateBufferSize: DefaultCandidateBufferSize,
+	}
+	for _, opt := range opts {
+		opt(sf)
+	}
+	if err := sf.loadCandidatePools(); err != nil {
+		return nil, err
+	}
+	if err := sf.loadVoterRewards(); err != nil {
+		return nil, err
+	}
+	if err := sf.loadHeight(); err != nil {
+		return nil, err
+	}
+	return sf, nil
+}
+
+// NewFactoryFromTrieDBPath creates a new stateFactory from give trie db path, opening the trie with the trie options.
+func NewFactoryFromTrieDBPath(
+	dbPath string,
+	inMem bool,
+	trieOpts []trie.Option,
+	opts ...FactoryOption,
+) (Factory, error) {
 	if len(dbPath) == 0 {
 		// TODO not return error here is a hack
 		return nil, nil
 	}
-	tr, err := trie.NewTrie(dbPath, inMem)
+	// the states kept in the encoding of the earlier versions are re-encoded when the trie DB is migrated
+	trieOpts = append(append([]trie.Option{}, trieOpts...), trie.ValueMigrationOption(migrateValue))
+	tr, err := trie.NewTrie(dbPath, inMem, trieOpts...)
 	if err != nil {
 		return nil, err
 	}
-	return NewFactory(tr), nil
+	return NewFactory(tr, opts...)
 }
 
 // CreateState adds a new State with initial balance to the factory
@@ -149,9 +242,38 @@ func (sf *factory) RootHash() common.Hash32B {
 	return sf.trie.RootHash()
 }
 
+// TrieCacheStats returns the hit and miss counters of the node cache of the trie holding the states
+func (sf *factory) TrieCacheStats() trie.CacheStats {
+	return sf.trie.CacheStats()
+}
+
+// Height returns the height of the last block whose state changes are committed, and false if there is none
+func (sf *factory) Height() (uint64, bool) {
+	return sf.currentChainHeight, sf.committed
+}
+
+// Reset empties the trie, the candidate pools and the voter rewards, so that the state can be rebuilt from the genesis
+// states
+func (sf *factory) Reset() error {
+	if err := sf.trie.Reset(trie.EmptyRoot); err != nil {
+		return errors.Wrap(err, "failed to empty the trie")
+	}
+	sf.currentChainHeight = 0
+	sf.committed = false
+	sf.resetCandidatePools()
+	sf.voterRewards = make(map[string]*big.Int)
+	sf.pendingVoterRewards = nil
+	sf.receipts = nil
+	sf.diff = nil
+	return nil
+}
+
 // CommitStateChanges updates a State from the given actions
 func (sf *factory) CommitStateChanges(chainHeight uint64, tsf []*action.Transfer, vote []*action.Vote) error {
 	sf.currentChainHeight = chainHeight
+	sf.pendingVoterRewards = nil
+	sf.receipts = nil
+	sf.diff = nil
 	pending := make(map[common.PKHash]*State)
 	addressToPKMap := make(map[string][]byte)
 
@@ -161,15 +283,34 @@ func (sf *factory) CommitStateChanges(chainHeight uint64, tsf []*action.Transfer
 	if err := sf.handleVote(pending, addressToPKMap, vote); err != nil {
 		return err
 	}
+	receipts, err := sf.distributeVoterRewards(pending)
+	if err != nil {
+		return err
+	}
 
-	// construct <k, v> list of pending state
+	// construct <k, v> list of pending state, in the order of the keys to update the candidate pools in the same way on
+	// every node
+	pkhashes := make([]common.PKHash, 0, len(pending))
+	for pkhash := range pending {
+		pkhashes = append(pkhashes, pkhash)
+	}
+	sort.Slice(pkhashes, func(i, j int) bool {
+		return bytes.Compare(pkhashes[i][:], pkhashes[j][:]) < 0
+	})
 	transferK := [][]byte{}
 	transferV := [][]byte{}
-	for pkhash, state := range pending {
+	poolsUpdated := false
+	diff := &StateDiff{Height: chainHeight}
+	for _, pkhash := range pkhashes {
+		state := pending[pkhash]
+		state.unlock(chainHeight)
 		ss, err := stateToBytes(state)
 		if err != nil {
 			return err
 		}
+		if err := sf.appendAccountDiff(diff, pkhash, state, ss); err != nil {
+			return err
+		}
 		addr := make([]byte, len(pkhash))
 		copy(addr, pkhash[:])
 		transferK = append(transferK, addr)
@@ -177,6 +318,10 @@ func (sf *factory) CommitStateChanges(chainHeight uint64, tsf []*action.Transfer
 
 		// Perform vote update operation on candidate and delegate pools
 		if !state.IsCandidate {
+			// the candidate unnominated is out of the pools at once
+			if sf.removeCandidate(state.Address) {
+				poolsUpdated = true
+			}
 			continue
 		}
 		totalWeight := big.NewInt(0)
@@ -186,6 +331,7 @@ func (sf *factory) CommitStateChanges(chainHeight uint64, tsf []*action.Transfer
 		}
 		if c, level := sf.inPool(state.Address); level > 0 {
 			sf.updateVotes(c, totalWeight)
+			poolsUpdated = true
 			continue
 		}
 		if pubKey, ok := addressToPKMap[state.Address]; ok {
@@ -197,24 +343,212 @@ func (sf *factory) CommitStateChanges(chainHeight uint64, tsf []*action.Transfer
 				maxIndex: 0,
 			}
 			sf.updateVotes(candidate, totalWeight)
+			poolsUpdated = true
 		}
 		// If the candidate who needs vote update but not in the pool
 		// and is not involved in a vote activity, then don't considert him
 	}
+	// keep the candidate pools along with the accounts
+	if poolsUpdated {
+		pools, err := sf.candidatePoolsToBytes()
+		if err != nil {
+			return err
+		}
+		transferK = append(transferK, candidatePoolKey)
+		transferV = append(transferV, pools)
+	}
+	if sf.pendingVoterRewards != nil {
+		rewards, err := voterRewardsToBytes(chainHeight, sf.pendingVoterRewards)
+		if err != nil {
+			return err
+		}
+		transferK = append(transferK, voterRewardKey)
+		transferV = append(transferV, rewards)
+	}
+	transferK = append(transferK, stateHeightKey)
+	transferV = append(transferV, utils.Uint64ToBytes(chainHeight))
 	// commit the state changes to Trie in a batch
-	return sf.trie.Commit(transferK, transferV)
+	if err := sf.trie.Commit(transferK, transferV); err != nil {
+		return err
+	}
+	sf.committed = true
+	// the voter rewards in memory follow the trie only once the state changes are committed
+	if sf.pendingVoterRewards != nil {
+		sf.voterRewards = sf.pendingVoterRewards
+		sf.pendingVoterRewards = nil
+	}
+	sf.receipts = receipts
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return diff.Accounts[i].Address() < diff.Accounts[j].Address()
+	})
+	sf.diff = diff
+	return nil
 }
 
-// Candidates returns array of candidates in candidate pool
+// SimulateStateChanges applies the actions on top of the current state as if they were in the next block, and returns
+// the resulting states of the accounts involved in the order of their addresses. The states are worked out in a
+// throwaway factory, which leaves the trie, the candidate pools and the voter rewards untouched. It fails with the
+// same error as committing the actions would, such as ErrNotEnoughBalance.
+func (sf *factory) SimulateStateChanges(tsf []*action.Transfer, vote []*action.Vote) ([]*State, error) {
+	for _, tx := range tsf {
+		if tx.IsCoinbase {
+			return nil, errors.New("coinbase transfer can't be simulated")
+		}
+	}
+	sim := &factory{
+		currentChainHeight: sf.currentChainHeight + 1,
+		trie:               sf.trie,
+		unbondingPeriod:    sf.unbondingPeriod,
+	}
+	pending := make(map[common.PKHash]*State)
+	addressToPKMap := make(map[string][]byte)
+	if err := sim.handleTsf(pending, addressToPKMap, tsf); err != nil {
+		return nil, err
+	}
+	if err := sim.handleVote(pending, addressToPKMap, vote); err != nil {
+		return nil, err
+	}
+	states := make([]*State, 0, len(pending))
+	for _, state := range pending {
+		state.unlock(sim.currentChainHeight)
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Address < states[j].Address
+	})
+	return states, nil
+}
+
+// Candidates returns array of candidates in candidate pool, in the descending order of the votes
 func (sf *factory) Candidates() (uint64, []*Candidate) {
-	return sf.currentChainHeight, sf.candidateHeap.CandidateList()
+	return sf.currentChainHeight, sortCandidates(sf.candidateHeap.CandidateList())
 }
 
 //======================================
 // private functions
 //=====================================
 func (sf *factory) candidatesBuffer() (uint64, []*Candidate) {
-	return sf.currentChainHeight, sf.candidateBufferMinHeap.CandidateList()
+	return sf.currentChainHeight, sortCandidates(sf.candidateBufferMinHeap.CandidateList())
+}
+
+// resetCandidatePools empties the candidate pools
+func (sf *factory) resetCandidatePools() {
+	sf.candidateHeap = CandidateMinPQ{sf.candidateSize, make([]*Candidate, 0)}
+	sf.candidateBufferMinHeap = CandidateMinPQ{sf.candidateBufferSize, make([]*Candidate, 0)}
+	sf.candidateBufferMaxHeap = CandidateMaxPQ{sf.candidateBufferSize, make([]*Candidate, 0)}
+}
+
+// loadCandidatePools rebuilds the candidate pools from the ones kept in the trie, or leaves them empty if there is none
+func (sf *factory) loadCandidatePools() error {
+	sf.resetCandidatePools()
+	value, err := sf.trie.Get(candidatePoolKey)
+	if errors.Cause(err) == trie.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get the candidate pools")
+	}
+	pools := &iproto.CandidatePoolPb{}
+	if err := proto.Unmarshal(value, pools); err != nil {
+		return errors.Wrap(err, "failed to decode the candidate pools")
+	}
+	if int(pools.CandidateSize) != sf.candidateSize || int(pools.BufferSize) != sf.candidateBufferSize {
+		return errors.Wrapf(
+			ErrCandidatePoolSize,
+			"%d and %d kept in the state, configured %d and %d",
+			pools.CandidateSize,
+			pools.BufferSize,
+			sf.candidateSize,
+			sf.candidateBufferSize,
+		)
+	}
+	for _, pb := range pools.Candidates {
+		heap.Push(&sf.candidateHeap, candidateFromPb(pb))
+	}
+	for _, pb := range pools.BufferCandidates {
+		c := candidateFromPb(pb)
+		heap.Push(&sf.candidateBufferMinHeap, c)
+		heap.Push(&sf.candidateBufferMaxHeap, c)
+	}
+	return nil
+}
+
+// loadHeight restores the height of the last block whose state changes are committed, if there is one
+func (sf *factory) loadHeight() error {
+	value, err := sf.trie.Get(stateHeightKey)
+	if errors.Cause(err) == trie.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get the state height")
+	}
+	sf.currentChainHeight = common.MachineEndian.Uint64(value)
+	sf.committed = true
+	return nil
+}
+
+// isAccountKey tells if the trie key is of an account, rather than the candidate pools, the voter rewards or the state
+// height kept in the trie as well
+func isAccountKey(key []byte) bool {
+	return !bytes.Equal(key, candidatePoolKey) && !bytes.Equal(key, voterRewardKey) && !bytes.Equal(key, stateHeightKey)
+}
+
+// candidatePoolsToBytes serializes the candidate pools, whose candidates are sorted so that the same pools are always
+// serialized into the same bytes regardless of the layouts of the heaps
+func (sf *factory) candidatePoolsToBytes() ([]byte, error) {
+	pools := &iproto.CandidatePoolPb{
+		CandidateSize: uint32(sf.candidateSize),
+		BufferSize:    uint32(sf.candidateBufferSize),
+	}
+	for _, c := range sortCandidates(sf.candidateHeap.CandidateList()) {
+		pools.Candidates = append(pools.Candidates, candidateToPb(c))
+	}
+	for _, c := range sortCandidates(sf.candidateBufferMinHeap.CandidateList()) {
+		pools.BufferCandidates = append(pools.BufferCandidates, candidateToPb(c))
+	}
+	value, err := proto.Marshal(pools)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the candidate pools")
+	}
+	return value, nil
+}
+
+func candidateToPb(c *Candidate) *iproto.CandidatePb {
+	return &iproto.CandidatePb{Address: c.Address, Votes: c.Votes.Bytes(), PubKey: c.PubKey}
+}
+
+func candidateFromPb(pb *iproto.CandidatePb) *Candidate {
+	return &Candidate{Address: pb.Address, Votes: new(big.Int).SetBytes(pb.Votes), PubKey: pb.PubKey}
+}
+
+// sortCandidates sorts the candidates in the descending order of the votes, and then the addresses
+func sortCandidates(candidates []*Candidate) []*Candidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		return compareCandidates(candidates[i], candidates[j]) > 0
+	})
+	return candidates
+}
+
+// appendAccountDiff appends the change of the account to the diff, unless the state to commit is the same as the one in
+// the trie
+func (sf *factory) appendAccountDiff(diff *StateDiff, pkhash common.PKHash, state *State, ss []byte) error {
+	old, err := sf.trie.Get(pkhash[:])
+	if errors.Cause(err) == trie.ErrNotExist {
+		diff.Accounts = append(diff.Accounts, &AccountDiff{New: state})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(old, ss) {
+		return nil
+	}
+	oldState, err := bytesToState(old)
+	if err != nil {
+		return err
+	}
+	diff.Accounts = append(diff.Accounts, &AccountDiff{Old: oldState, New: state})
+	return nil
 }
 
 // getState pulls an existing State
@@ -278,6 +612,27 @@ func (sf *factory) updateVotes(candidate *Candidate, votes *big.Int) {
 	}
 }
 
+// removeCandidate removes the candidate from the pools, and fills the vacancy in the candidate pool with the top one in
+// the candidate buffer pool. It returns false if the candidate is in neither pool.
+func (sf *factory) removeCandidate(address string) bool {
+	c, level := sf.inPool(address)
+	switch level {
+	case candidatePool:
+		heap.Remove(&sf.candidateHeap, c.minIndex)
+		if sf.candidateBufferMaxHeap.Len() > 0 {
+			top := heap.Pop(&sf.candidateBufferMaxHeap).(*Candidate)
+			heap.Remove(&sf.candidateBufferMinHeap, top.minIndex)
+			heap.Push(&sf.candidateHeap, top)
+		}
+	case candidateBufferPool:
+		heap.Remove(&sf.candidateBufferMinHeap, c.minIndex)
+		heap.Remove(&sf.candidateBufferMaxHeap, c.maxIndex)
+	default:
+		return false
+	}
+	return true
+}
+
 func (sf *factory) balance() {
 	if sf.candidateHeap.Len() > 0 && sf.candidateBufferMaxHeap.Len() > 0 && sf.candidateHeap.Top().(*Candidate).Votes.Cmp(sf.candidateBufferMaxHeap.Top().(*Candidate).Votes) < 0 {
 		cFromCandidatePool := heap.Pop(&sf.candidateHeap).(*Candidate)
@@ -312,9 +667,8 @@ func (sf *factory) upsert(pending map[common.PKHash]*State, address string) (*St
 		state, err = sf.getState(address)
 		switch {
 		case err == ErrAccountNotExist:
-			if state, err = sf.CreateState(address, 0); err != nil {
-				return nil, err
-			}
+			// the new account is put into the trie along with the other pending states
+			state = &State{Address: address, Balance: big.NewInt(0), VotingWeight: big.NewInt(0)}
 		case err != nil:
 			return nil, err
 		}
@@ -331,7 +685,8 @@ func (sf *factory) handleTsf(pending map[common.PKHash]*State, addressToPKMap ma
 			if err != nil {
 				return err
 			}
-			if tx.Amount.Cmp(sender.Balance) == 1 {
+			// the tokens of a withdrawn vote can't be spent during the unbonding period
+			if tx.Amount.Cmp(sender.SpendableBalance(sf.currentChainHeight)) == 1 {
 				return ErrNotEnoughBalance
 			}
 			// update sender balance
@@ -350,26 +705,40 @@ func (sf *factory) handleTsf(pending map[common.PKHash]*State, addressToPKMap ma
 					return err
 				}
 				voteeOfSender.VotingWeight.Sub(voteeOfSender.VotingWeight, tx.Amount)
+				voteeOfSender.addVoterWeight(sender.Address, new(big.Int).Neg(tx.Amount))
 			}
 		}
+		amount := tx.Amount
+		if tx.IsCoinbase {
+			amount = sf.splitCoinbase(tx.Recipient, tx.Amount)
+		}
 		// check recipient
 		recipient, err := sf.upsert(pending, tx.Recipient)
 		if err != nil {
 			return err
 		}
-		// update recipient balance
-		if err := recipient.AddBalance(tx.Amount); err != nil {
+		if err := sf.credit(pending, recipient, amount); err != nil {
 			return err
 		}
-		// Update recipient votes
-		if len(recipient.Votee) > 0 && recipient.Votee != recipient.Address {
-			// recipient already voted to a different person
-			voteeOfRecipient, err := sf.upsert(pending, recipient.Votee)
-			if err != nil {
-				return err
-			}
-			voteeOfRecipient.VotingWeight.Add(voteeOfRecipient.VotingWeight, tx.Amount)
+	}
+	return nil
+}
+
+// credit adds the amount to the balance of the recipient, as well as to the votes the recipient casts
+func (sf *factory) credit(pending map[common.PKHash]*State, recipient *State, amount *big.Int) error {
+	// update recipient balance
+	if err := recipient.AddBalance(amount); err != nil {
+		return err
+	}
+	// Update recipient votes
+	if len(recipient.Votee) > 0 && recipient.Votee != recipient.Address {
+		// recipient already voted to a different person
+		voteeOfRecipient, err := sf.upsert(pending, recipient.Votee)
+		if err != nil {
+			return err
 		}
+		voteeOfRecipient.VotingWeight.Add(voteeOfRecipient.VotingWeight, amount)
+		voteeOfRecipient.addVoterWeight(recipient.Address, amount)
 	}
 	return nil
 }
@@ -386,15 +755,21 @@ func (sf *factory) handleVote(pending map[common.PKHash]*State, addressToPKMap m
 		}
 		addressToPKMap[voteFrom.Address] = v.SelfPubkey
 
-		voteAddress, err := iotxaddress.GetAddress(v.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
-		if err != nil {
-			return err
-		}
-
 		// update voteFrom nonce
 		if v.Nonce > voteFrom.Nonce {
 			voteFrom.Nonce = v.Nonce
 		}
+		if v.IsUnvote() {
+			if err := sf.unvote(pending, voteFrom); err != nil {
+				return err
+			}
+			continue
+		}
+
+		voteAddress, err := iotxaddress.GetAddress(v.VotePubkey, iotxaddress.IsTestnet, iotxaddress.ChainID)
+		if err != nil {
+			return err
+		}
 		voteTo, err := sf.upsert(pending, voteAddress.RawAddress)
 		if err != nil {
 			return err
@@ -402,19 +777,14 @@ func (sf *factory) handleVote(pending map[common.PKHash]*State, addressToPKMap m
 		addressToPKMap[voteTo.Address] = v.VotePubkey
 
 		// Update old votee's weight
-		if len(voteFrom.Votee) > 0 && voteFrom.Votee != voteFrom.Address {
-			// voter already voted
-			oldVotee, err := sf.upsert(pending, voteFrom.Votee)
-			if err != nil {
-				return err
-			}
-			oldVotee.VotingWeight.Sub(oldVotee.VotingWeight, voteFrom.Balance)
-			voteFrom.Votee = ""
+		if err := sf.withdrawVote(pending, voteFrom); err != nil {
+			return err
 		}
 
 		if voteFrom.Address != voteTo.Address {
 			// Voter votes to a different person
 			voteTo.VotingWeight.Add(voteTo.VotingWeight, voteFrom.Balance)
+			voteTo.addVoterWeight(voteFrom.Address, voteFrom.Balance)
 			voteFrom.Votee = voteTo.Address
 		} else {
 			voteFrom.Votee = voteFrom.Address
@@ -423,3 +793,35 @@ func (sf *factory) handleVote(pending map[common.PKHash]*State, addressToPKMap m
 	}
 	return nil
 }
+
+// withdrawVote takes the weight of the voter and the voter itself off its votee, unless the voter votes for itself
+func (sf *factory) withdrawVote(pending map[common.PKHash]*State, voter *State) error {
+	if len(voter.Votee) > 0 && voter.Votee != voter.Address {
+		votee, err := sf.upsert(pending, voter.Votee)
+		if err != nil {
+			return err
+		}
+		votee.VotingWeight.Sub(votee.VotingWeight, voter.Balance)
+		delete(votee.Voters, voter.Address)
+	}
+	voter.Votee = ""
+	return nil
+}
+
+// unvote withdraws the vote of the voter, which stops the voter from being a candidate as well. The weight stops
+// counting at once, while the tokens voted stay locked for the unbonding period.
+func (sf *factory) unvote(pending map[common.PKHash]*State, voter *State) error {
+	if len(voter.Votee) == 0 && !voter.IsCandidate {
+		// nothing to withdraw
+		return nil
+	}
+	if err := sf.withdrawVote(pending, voter); err != nil {
+		return err
+	}
+	voter.IsCandidate = false
+	if sf.unbondingPeriod > 0 {
+		voter.LockedBalance = new(big.Int).Set(voter.Balance)
+		voter.UnlockHeight = sf.currentChainHeight + sf.unbondingPeriod
+	}
+	return nil
+}
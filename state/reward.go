@@ -0,0 +1,220 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/proto"
+	"github.com/iotexproject/iotex-core/trie"
+)
+
+// voterRewardKey is the trie key of the voter rewards accrued in the current reward epoch
+var voterRewardKey = iotxaddress.HashPubKey([]byte("voterRewards"))
+
+// RewardReceipt records the voter reward credited to the voter of the delegate at the end of a reward epoch. The part
+// of the reward left by the integer division, or the whole of it if the delegate has no voter, goes to the delegate,
+// which is recorded with the delegate as the voter.
+type RewardReceipt struct {
+	Height   uint64
+	Delegate string
+	Voter    string
+	Amount   *big.Int
+}
+
+// RewardReceiptToPb converts the receipt into RewardReceiptPb
+func RewardReceiptToPb(r *RewardReceipt) *iproto.RewardReceiptPb {
+	return &iproto.RewardReceiptPb{Height: r.Height, Delegate: r.Delegate, Voter: r.Voter, Amount: r.Amount.Bytes()}
+}
+
+// RewardReceiptFromPb converts RewardReceiptPb into the receipt
+func RewardReceiptFromPb(pb *iproto.RewardReceiptPb) *RewardReceipt {
+	return &RewardReceipt{
+		Height:   pb.Height,
+		Delegate: pb.Delegate,
+		Voter:    pb.Voter,
+		Amount:   new(big.Int).SetBytes(pb.Amount),
+	}
+}
+
+// RewardReceipts returns the receipts of the voter rewards credited by the last state changes committed
+func (sf *factory) RewardReceipts() []*RewardReceipt {
+	return sf.receipts
+}
+
+// VoterRewards returns a copy of the voter rewards accrued by each delegate in the current reward epoch, which aren't
+// distributed yet. They are kept out of the accounts until the end of the epoch.
+func (sf *factory) VoterRewards() map[string]*big.Int {
+	rewards := make(map[string]*big.Int, len(sf.voterRewards))
+	for delegate, reward := range sf.voterRewards {
+		rewards[delegate] = new(big.Int).Set(reward)
+	}
+	return rewards
+}
+
+//======================================
+// private functions
+//=====================================
+
+// splitCoinbase keeps the voter share of the block reward paid to the producer aside until the end of the reward
+// epoch, and returns the rest of it, which is paid to the producer at once. The transfers in the genesis block aren't
+// block rewards, and are paid in full.
+func (sf *factory) splitCoinbase(producer string, amount *big.Int) *big.Int {
+	if sf.currentChainHeight == 0 || sf.voterRewardPercentage == 0 || sf.rewardEpoch == 0 {
+		return amount
+	}
+	share := new(big.Int).Mul(amount, new(big.Int).SetUint64(sf.voterRewardPercentage))
+	share.Div(share, big.NewInt(100))
+	if share.Sign() == 0 {
+		return amount
+	}
+	rewards := sf.updateVoterRewards()
+	if accrued, ok := rewards[producer]; ok {
+		accrued.Add(accrued, share)
+	} else {
+		rewards[producer] = share
+	}
+	return new(big.Int).Sub(amount, share)
+}
+
+// updateVoterRewards returns the voter rewards pending to be committed, which start from a copy of the ones accrued, so
+// that the latter are left untouched until the state changes are committed
+func (sf *factory) updateVoterRewards() map[string]*big.Int {
+	if sf.pendingVoterRewards == nil {
+		sf.pendingVoterRewards = make(map[string]*big.Int, len(sf.voterRewards))
+		for delegate, reward := range sf.voterRewards {
+			sf.pendingVoterRewards[delegate] = new(big.Int).Set(reward)
+		}
+	}
+	return sf.pendingVoterRewards
+}
+
+// distributeVoterRewards credits the voter rewards accrued by each delegate to its voters in proportion to their
+// weights at the end of the reward epoch. The delegates and the voters are walked in the order of their addresses, and
+// the shares are rounded down, so that every node credits the same amounts. It returns the receipts of the rewards
+// credited.
+func (sf *factory) distributeVoterRewards(pending map[common.PKHash]*State) ([]*RewardReceipt, error) {
+	height := sf.currentChainHeight
+	if sf.rewardEpoch == 0 || height == 0 || height%sf.rewardEpoch != 0 {
+		return nil, nil
+	}
+	rewards := sf.voterRewards
+	if sf.pendingVoterRewards != nil {
+		rewards = sf.pendingVoterRewards
+	}
+	if len(rewards) == 0 {
+		return nil, nil
+	}
+	delegates := make([]string, 0, len(rewards))
+	for delegate := range rewards {
+		delegates = append(delegates, delegate)
+	}
+	sort.Strings(delegates)
+	var receipts []*RewardReceipt
+	for _, delegate := range delegates {
+		reward := rewards[delegate]
+		delegateState, err := sf.upsert(pending, delegate)
+		if err != nil {
+			return nil, err
+		}
+		voters := make([]string, 0, len(delegateState.Voters))
+		totalWeight := big.NewInt(0)
+		for voter, weight := range delegateState.Voters {
+			voters = append(voters, voter)
+			totalWeight.Add(totalWeight, weight)
+		}
+		sort.Strings(voters)
+		// work out all the shares before crediting any of them, as crediting a voter adds to its weight
+		shares := make([]*big.Int, len(voters))
+		left := new(big.Int).Set(reward)
+		for i, voter := range voters {
+			shares[i] = new(big.Int).Mul(reward, delegateState.Voters[voter])
+			shares[i].Div(shares[i], totalWeight)
+			left.Sub(left, shares[i])
+		}
+		for i, voter := range voters {
+			if shares[i].Sign() == 0 {
+				continue
+			}
+			voterState, err := sf.upsert(pending, voter)
+			if err != nil {
+				return nil, err
+			}
+			if err := sf.credit(pending, voterState, shares[i]); err != nil {
+				return nil, err
+			}
+			receipts = append(receipts, &RewardReceipt{
+				Height:   height,
+				Delegate: delegate,
+				Voter:    voter,
+				Amount:   shares[i],
+			})
+		}
+		if left.Sign() > 0 {
+			if err := sf.credit(pending, delegateState, left); err != nil {
+				return nil, err
+			}
+			receipts = append(receipts, &RewardReceipt{
+				Height:   height,
+				Delegate: delegate,
+				Voter:    delegate,
+				Amount:   left,
+			})
+		}
+	}
+	sf.pendingVoterRewards = make(map[string]*big.Int)
+	return receipts, nil
+}
+
+// loadVoterRewards restores the voter rewards accrued from the ones kept in the trie, or leaves them empty if there is
+// none
+func (sf *factory) loadVoterRewards() error {
+	sf.voterRewards = make(map[string]*big.Int)
+	value, err := sf.trie.Get(voterRewardKey)
+	if errors.Cause(err) == trie.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get the voter rewards")
+	}
+	pool := &iproto.VoterRewardPoolPb{}
+	if err := proto.Unmarshal(value, pool); err != nil {
+		return errors.Wrap(err, "failed to decode the voter rewards")
+	}
+	for _, pb := range pool.Rewards {
+		sf.voterRewards[pb.Delegate] = new(big.Int).SetBytes(pb.Amount)
+	}
+	return nil
+}
+
+// voterRewardsToBytes serializes the voter rewards accrued, sorted by the delegates. The height of the update keeps the
+// record from being empty once the rewards are distributed.
+func voterRewardsToBytes(height uint64, rewards map[string]*big.Int) ([]byte, error) {
+	delegates := make([]string, 0, len(rewards))
+	for delegate := range rewards {
+		delegates = append(delegates, delegate)
+	}
+	sort.Strings(delegates)
+	pool := &iproto.VoterRewardPoolPb{Height: height}
+	for _, delegate := range delegates {
+		pool.Rewards = append(pool.Rewards, &iproto.DelegateRewardPb{
+			Delegate: delegate,
+			Amount:   rewards[delegate].Bytes(),
+		})
+	}
+	value, err := proto.Marshal(pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the voter rewards")
+	}
+	return value, nil
+}
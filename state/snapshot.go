@@ -0,0 +1,188 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/gob"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/trie"
+)
+
+// ErrSnapshotMismatch is the error that the accounts of the state snapshot don't match its root
+var ErrSnapshotMismatch = errors.New("state snapshot doesn't match its root")
+
+// Snapshot is the full state at a block height, which consists of all the accounts, the candidate pools and the voter
+// rewards accrued stored as the trie leaves. The candidate pools are also carried separately, because the older nodes
+// don't keep them in the trie. A node can start from a snapshot instead of replaying all the blocks.
+type Snapshot struct {
+	Height uint64
+	Root   common.Hash32B
+	// Keys and Values are the trie leaves in the order of the keys
+	Keys             [][]byte
+	Values           [][]byte
+	Candidates       []*Candidate
+	BufferCandidates []*Candidate
+}
+
+// Serialize returns the serialized byte stream of the snapshot
+func (ss *Snapshot) Serialize() ([]byte, error) {
+	var stream bytes.Buffer
+	if err := gob.NewEncoder(&stream).Encode(ss); err != nil {
+		return nil, errors.Wrap(err, "failed to encode state snapshot")
+	}
+	return stream.Bytes(), nil
+}
+
+// Deserialize parses the byte stream into the snapshot
+func (ss *Snapshot) Deserialize(stream []byte) error {
+	*ss = Snapshot{}
+	if err := gob.NewDecoder(bytes.NewBuffer(stream)).Decode(ss); err != nil {
+		return errors.Wrap(err, "failed to decode state snapshot")
+	}
+	return nil
+}
+
+// Snapshot exports all the accounts and the candidate pools
+func (sf *factory) Snapshot() (*Snapshot, error) {
+	ss := &Snapshot{Height: sf.currentChainHeight, Root: sf.trie.RootHash()}
+	if err := sf.trie.Iterate(func(k, v []byte) error {
+		ss.Keys = append(ss.Keys, k)
+		ss.Values = append(ss.Values, v)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to export the accounts")
+	}
+	ss.Candidates = copyCandidates(sf.candidateHeap.CandidateList())
+	ss.BufferCandidates = copyCandidates(sf.candidateBufferMinHeap.CandidateList())
+	return ss, nil
+}
+
+// IterateStates decodes the accounts in the order of their keys and calls the function on each of them, skipping the
+// candidate pools, the voter rewards and the state height kept in the trie as well. It stops at the first error the
+// function returns.
+func (sf *factory) IterateStates(fn func(*State) error) error {
+	return sf.trie.Iterate(func(k, v []byte) error {
+		if !isAccountKey(k) {
+			return nil
+		}
+		state, err := bytesToState(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode account %x", k)
+		}
+		return fn(state)
+	})
+}
+
+// LoadSnapshot checks the accounts of the snapshot against its root, and then puts them into the trie and restores the
+// candidate pools. The trie is expected to hold no account other than those in the snapshot.
+func (sf *factory) LoadSnapshot(ss *Snapshot) error {
+	if len(ss.Keys) != len(ss.Values) {
+		return errors.Wrap(ErrSnapshotMismatch, "keys and values size not match")
+	}
+	// rebuild the accounts in a scratch trie first, so that a bad snapshot leaves the state untouched
+	tr, err := trie.NewTrie("", true)
+	if err != nil {
+		return err
+	}
+	defer tr.Close()
+	if err := tr.Commit(ss.Keys, ss.Values); err != nil {
+		return errors.Wrap(err, "failed to rebuild the accounts")
+	}
+	if root := tr.RootHash(); root != ss.Root {
+		return errors.Wrapf(ErrSnapshotMismatch, "root %x, expecting %x", root, ss.Root)
+	}
+	if err := sf.trie.Commit(ss.Keys, ss.Values); err != nil {
+		return err
+	}
+	if root := sf.trie.RootHash(); root != ss.Root {
+		return errors.Wrapf(ErrSnapshotMismatch, "state root %x after loading, expecting %x", root, ss.Root)
+	}
+
+	sf.currentChainHeight = ss.Height
+	sf.committed = true
+	if err := sf.loadVoterRewards(); err != nil {
+		return err
+	}
+	if err := sf.loadCandidatePools(); err != nil {
+		return err
+	}
+	if sf.candidateHeap.Len() > 0 || sf.candidateBufferMinHeap.Len() > 0 {
+		return nil
+	}
+	// the snapshot taken before keeping the candidate pools in the trie carries them along with the accounts
+	for _, c := range copyCandidates(ss.Candidates) {
+		heap.Push(&sf.candidateHeap, c)
+	}
+	for _, c := range copyCandidates(ss.BufferCandidates) {
+		heap.Push(&sf.candidateBufferMinHeap, c)
+		heap.Push(&sf.candidateBufferMaxHeap, c)
+	}
+	return nil
+}
+
+// MigrateSnapshot re-encodes the accounts of the snapshot taken while the states were gob encoded, and updates its root
+// accordingly. The accounts encoded into AccountStatePb already are left as they are.
+func MigrateSnapshot(ss *Snapshot) error {
+	if len(ss.Keys) != len(ss.Values) {
+		return errors.Wrap(ErrSnapshotMismatch, "keys and values size not match")
+	}
+	for i, value := range ss.Values {
+		var err error
+		if ss.Values[i], err = migrateValue(ss.Keys[i], value); err != nil {
+			return err
+		}
+	}
+	tr, err := trie.NewTrie("", true)
+	if err != nil {
+		return err
+	}
+	defer tr.Close()
+	if err := tr.Commit(ss.Keys, ss.Values); err != nil {
+		return errors.Wrap(err, "failed to rebuild the accounts")
+	}
+	ss.Root = tr.RootHash()
+	return nil
+}
+
+// migrateValue re-encodes the account state kept in the gob encoding into AccountStatePb. The other values and the
+// accounts encoded into AccountStatePb already are left as they are.
+func migrateValue(key []byte, value []byte) ([]byte, error) {
+	if !isAccountKey(key) {
+		return value, nil
+	}
+	state, err := legacyBytesToState(value)
+	if err != nil {
+		if _, err := bytesToState(value); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode account %x", key)
+		}
+		return value, nil
+	}
+	migrated, err := stateToBytes(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode account %x", key)
+	}
+	return migrated, nil
+}
+
+// copyCandidates returns the copies of the candidates, which don't share the votes with the originals
+func copyCandidates(candidates []*Candidate) []*Candidate {
+	copied := make([]*Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		copied = append(copied, &Candidate{
+			Address: c.Address,
+			Votes:   new(big.Int).Set(c.Votes),
+			PubKey:  c.PubKey,
+		})
+	}
+	return copied
+}
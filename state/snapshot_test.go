@@ -0,0 +1,156 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain/action"
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/iotxaddress"
+	"github.com/iotexproject/iotex-core/trie"
+)
+
+func TestSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	tr, err := trie.NewTrie("", true)
+	require.Nil(err)
+	sf, err := NewFactory(tr, CandidatePoolOption(2, 10))
+	require.Nil(err)
+	addrs := []*iotxaddress.Address{}
+	votes := []*action.Vote{}
+	for i := 0; i < 3; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(100*(i+1)))
+		require.Nil(err)
+		addrs = append(addrs, addr)
+		votes = append(votes, action.NewVote(1, addr.PublicKey, addr.PublicKey))
+	}
+	tsf := action.Transfer{Sender: addrs[0].RawAddress, Recipient: addrs[1].RawAddress, Nonce: 1, Amount: big.NewInt(10)}
+	require.Nil(sf.CommitStateChanges(5, []*action.Transfer{&tsf}, votes))
+
+	ss, err := sf.Snapshot()
+	require.Nil(err)
+	require.Equal(uint64(5), ss.Height)
+	require.Equal(sf.RootHash(), ss.Root)
+	// the accounts, the candidate pools and the state height
+	require.Equal(5, len(ss.Keys))
+	require.Equal(2, len(ss.Candidates))
+	require.Equal(1, len(ss.BufferCandidates))
+	stream, err := ss.Serialize()
+	require.Nil(err)
+	ss = &Snapshot{}
+	require.Nil(ss.Deserialize(stream))
+
+	// the accounts and the candidate pools are restored from the snapshot
+	tr, err = trie.NewTrie("", true)
+	require.Nil(err)
+	loaded, err := NewFactory(tr, CandidatePoolOption(2, 10))
+	require.Nil(err)
+	require.Nil(loaded.LoadSnapshot(ss))
+	require.Equal(sf.RootHash(), loaded.RootHash())
+	balance, err := loaded.Balance(addrs[1].RawAddress)
+	require.Nil(err)
+	require.Equal(big.NewInt(210), balance)
+	require.Equal(voteForm(sf.Candidates()), voteForm(loaded.Candidates()))
+	require.Equal(voteForm(sf.(*factory).candidatesBuffer()), voteForm(loaded.(*factory).candidatesBuffer()))
+
+	// the snapshot whose accounts don't match its root is rejected before touching the state
+	tr, err = trie.NewTrie("", true)
+	require.Nil(err)
+	forged, err := NewFactory(tr, CandidatePoolOption(2, 10))
+	require.Nil(err)
+	root := forged.RootHash()
+	ss.Values[0] = ss.Values[1]
+	require.Equal(ErrSnapshotMismatch, errors.Cause(forged.LoadSnapshot(ss)))
+	require.Equal(root, forged.RootHash())
+}
+
+func TestMigrateSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	tr, err := trie.NewTrie("", true)
+	require.Nil(err)
+	sf, err := NewFactory(tr, CandidatePoolOption(2, 10))
+	require.Nil(err)
+	for i := 0; i < 3; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(100*(i+1)))
+		require.Nil(err)
+	}
+	ss, err := sf.Snapshot()
+	require.Nil(err)
+
+	// the snapshot taken while the states were gob encoded
+	legacy := &Snapshot{Height: ss.Height, Root: common.ZeroHash32B, Keys: ss.Keys}
+	for _, value := range ss.Values {
+		state, err := bytesToState(value)
+		require.Nil(err)
+		var stream bytes.Buffer
+		require.Nil(gob.NewEncoder(&stream).Encode(state))
+		legacy.Values = append(legacy.Values, stream.Bytes())
+	}
+	require.Nil(MigrateSnapshot(legacy))
+	require.Equal(ss.Root, legacy.Root)
+	require.Equal(ss.Values, legacy.Values)
+
+	// the migrated snapshot is left as it is
+	require.Nil(MigrateSnapshot(legacy))
+	require.Equal(ss.Root, legacy.Root)
+	require.Equal(ss.Values, legacy.Values)
+}
+
+func TestIterateStates(t *testing.T) {
+	require := require.New(t)
+
+	tr, err := trie.NewTrie("", true)
+	require.Nil(err)
+	sf, err := NewFactory(tr, CandidatePoolOption(2, 10), VoterRewardOption(50, 10))
+	require.Nil(err)
+	balances := map[string]uint64{}
+	votes := []*action.Vote{}
+	for i := 0; i < 3; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(100*(i+1)))
+		require.Nil(err)
+		balances[addr.RawAddress] = uint64(100 * (i + 1))
+		votes = append(votes, action.NewVote(1, addr.PublicKey, addr.PublicKey))
+	}
+	require.Nil(sf.CommitStateChanges(1, nil, votes))
+
+	// the accounts only, in the order of their keys
+	var keys [][]byte
+	require.Nil(sf.IterateStates(func(s *State) error {
+		require.Equal(balances[s.Address], s.Balance.Uint64())
+		require.True(s.IsCandidate)
+		keys = append(keys, iotxaddress.GetPubkeyHash(s.Address))
+		return nil
+	}))
+	require.Equal(3, len(keys))
+	for i := 1; i < len(keys); i++ {
+		require.True(bytes.Compare(keys[i-1], keys[i]) < 0)
+	}
+
+	// the iteration stops at the first error
+	count := 0
+	stop := errors.New("stop")
+	require.Equal(stop, errors.Cause(sf.IterateStates(func(*State) error {
+		count++
+		return stop
+	})))
+	require.Equal(1, count)
+}
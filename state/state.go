@@ -10,6 +10,12 @@ import (
 	"bytes"
 	"encoding/gob"
 	"math/big"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/proto"
 )
 
 // State is the canonical representation of an account.
@@ -23,18 +29,102 @@ type State struct {
 	VotingWeight *big.Int
 	Votee        string
 	Voters       map[string]*big.Int
+	// LockedBalance is the part of the balance voted before, which can't be spent until UnlockHeight, when the unbonding
+	// period of the withdrawn vote ends
+	LockedBalance *big.Int
+	UnlockHeight  uint64
 }
 
+// stateToBytes encodes the state into AccountStatePb. The voters are sorted by their addresses, so that the same state
+// is always encoded into the same bytes, which the trie root is computed over.
 func stateToBytes(s *State) ([]byte, error) {
-	var ss bytes.Buffer
-	e := gob.NewEncoder(&ss)
-	if err := e.Encode(s); err != nil {
+	statePb, err := stateToPb(s)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := proto.Marshal(statePb)
+	if err != nil {
 		return nil, ErrFailedToMarshalState
 	}
-	return ss.Bytes(), nil
+	return ss, nil
+}
+
+// stateToPb converts the state into AccountStatePb, whose voters are sorted by their addresses
+func stateToPb(s *State) (*iproto.AccountStatePb, error) {
+	balance, err := bigIntToBytes(s.Balance)
+	if err != nil {
+		return nil, err
+	}
+	votingWeight, err := bigIntToBytes(s.VotingWeight)
+	if err != nil {
+		return nil, err
+	}
+	lockedBalance, err := bigIntToBytes(s.LockedBalance)
+	if err != nil {
+		return nil, err
+	}
+	statePb := &iproto.AccountStatePb{
+		Nonce:         s.Nonce,
+		Balance:       balance,
+		Address:       s.Address,
+		IsCandidate:   s.IsCandidate,
+		VotingWeight:  votingWeight,
+		Votee:         s.Votee,
+		LockedBalance: lockedBalance,
+		UnlockHeight:  s.UnlockHeight,
+	}
+	voters := make([]string, 0, len(s.Voters))
+	for voter := range s.Voters {
+		voters = append(voters, voter)
+	}
+	sort.Strings(voters)
+	for _, voter := range voters {
+		weight, err := bigIntToBytes(s.Voters[voter])
+		if err != nil {
+			return nil, err
+		}
+		statePb.Voters = append(statePb.Voters, &iproto.VoterPb{Address: voter, Weight: weight})
+	}
+	return statePb, nil
 }
 
 func bytesToState(ss []byte) (*State, error) {
+	// an account state always has its address at least
+	if len(ss) == 0 {
+		return nil, ErrFailedToUnmarshalState
+	}
+	statePb := &iproto.AccountStatePb{}
+	if err := proto.Unmarshal(ss, statePb); err != nil {
+		return nil, ErrFailedToUnmarshalState
+	}
+	return stateFromPb(statePb), nil
+}
+
+// stateFromPb converts AccountStatePb into the state
+func stateFromPb(statePb *iproto.AccountStatePb) *State {
+	state := &State{
+		Nonce:        statePb.Nonce,
+		Balance:      new(big.Int).SetBytes(statePb.Balance),
+		Address:      statePb.Address,
+		IsCandidate:  statePb.IsCandidate,
+		VotingWeight: new(big.Int).SetBytes(statePb.VotingWeight),
+		Votee:        statePb.Votee,
+		UnlockHeight: statePb.UnlockHeight,
+	}
+	if len(statePb.LockedBalance) > 0 {
+		state.LockedBalance = new(big.Int).SetBytes(statePb.LockedBalance)
+	}
+	if len(statePb.Voters) > 0 {
+		state.Voters = make(map[string]*big.Int, len(statePb.Voters))
+		for _, voter := range statePb.Voters {
+			state.Voters[voter.Address] = new(big.Int).SetBytes(voter.Weight)
+		}
+	}
+	return state
+}
+
+// legacyBytesToState decodes the state in the gob encoding, in which the states were kept before AccountStatePb
+func legacyBytesToState(ss []byte) (*State, error) {
 	var state State
 	e := gob.NewDecoder(bytes.NewBuffer(ss))
 	if err := e.Decode(&state); err != nil {
@@ -43,6 +133,17 @@ func bytesToState(ss []byte) (*State, error) {
 	return &state, nil
 }
 
+// bigIntToBytes returns the big-endian bytes of the amount, which is expected to be non-negative
+func bigIntToBytes(amount *big.Int) ([]byte, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	if amount.Sign() < 0 {
+		return nil, errors.Wrapf(ErrFailedToMarshalState, "negative amount %s", amount)
+	}
+	return amount.Bytes(), nil
+}
+
 // AddBalance adds balance for state
 func (st *State) AddBalance(amount *big.Int) error {
 	st.Balance.Add(st.Balance, amount)
@@ -58,3 +159,40 @@ func (st *State) SubBalance(amount *big.Int) error {
 	st.Balance.Sub(st.Balance, amount)
 	return nil
 }
+
+// SpendableBalance returns the balance which isn't locked at the height
+func (st *State) SpendableBalance(height uint64) *big.Int {
+	if st.LockedBalance == nil || height >= st.UnlockHeight {
+		return st.Balance
+	}
+	spendable := new(big.Int).Sub(st.Balance, st.LockedBalance)
+	if spendable.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return spendable
+}
+
+// unlock releases the locked balance if the unbonding period has ended at the height
+func (st *State) unlock(height uint64) {
+	if st.LockedBalance != nil && height >= st.UnlockHeight {
+		st.LockedBalance = nil
+		st.UnlockHeight = 0
+	}
+}
+
+// addVoterWeight adds the amount, which is negative for a decrease, to the weight of the voter, and drops the voter
+// whose weight is no longer positive
+func (st *State) addVoterWeight(voter string, amount *big.Int) {
+	weight := new(big.Int).Set(amount)
+	if w, ok := st.Voters[voter]; ok {
+		weight.Add(weight, w)
+	}
+	if weight.Sign() <= 0 {
+		delete(st.Voters, voter)
+		return
+	}
+	if st.Voters == nil {
+		st.Voters = make(map[string]*big.Int)
+	}
+	st.Voters[voter] = weight
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package state
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/proto"
+)
+
+// StateDiff is the changes of the accounts made by the actions of a block, in the order of the addresses. It is kept
+// for every block, so that the changes can be consumed without replaying the blocks, and the accounts can be rolled
+// back to their states before the block.
+type StateDiff struct {
+	Height   uint64
+	Accounts []*AccountDiff
+}
+
+// AccountDiff is the change of an account, whose Old state is nil if the account is created by the block
+type AccountDiff struct {
+	Old *State
+	New *State
+}
+
+// Address returns the address of the account changed
+func (d *AccountDiff) Address() string {
+	return d.New.Address
+}
+
+// Serialize returns the serialized byte stream of the state diff
+func (diff *StateDiff) Serialize() ([]byte, error) {
+	diffPb := &iproto.StateDiffPb{Height: diff.Height}
+	for _, account := range diff.Accounts {
+		accountPb := &iproto.AccountDiffPb{}
+		var err error
+		if account.Old != nil {
+			if accountPb.Old, err = stateToPb(account.Old); err != nil {
+				return nil, err
+			}
+		}
+		if accountPb.New, err = stateToPb(account.New); err != nil {
+			return nil, err
+		}
+		diffPb.Accounts = append(diffPb.Accounts, accountPb)
+	}
+	value, err := proto.Marshal(diffPb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode the state diff at height %d", diff.Height)
+	}
+	return value, nil
+}
+
+// Deserialize parses the byte stream into the state diff
+func (diff *StateDiff) Deserialize(value []byte) error {
+	diffPb := &iproto.StateDiffPb{}
+	if err := proto.Unmarshal(value, diffPb); err != nil {
+		return errors.Wrap(err, "failed to decode the state diff")
+	}
+	*diff = StateDiff{Height: diffPb.Height}
+	for _, accountPb := range diffPb.Accounts {
+		if accountPb.New == nil {
+			return errors.Errorf("account diff at height %d misses the new state", diffPb.Height)
+		}
+		account := &AccountDiff{New: stateFromPb(accountPb.New)}
+		if accountPb.Old != nil {
+			account.Old = stateFromPb(accountPb.Old)
+		}
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	return nil
+}
+
+// StateDiff returns the changes of the accounts made by the last state changes committed
+func (sf *factory) StateDiff() *StateDiff {
+	return sf.diff
+}
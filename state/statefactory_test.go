@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -42,12 +43,47 @@ func TestEncodeDecode(t *testing.T) {
 	assert.Equal(t, uint64(0x10), state.Nonce)
 }
 
+func TestEncodeDecode_Voters(t *testing.T) {
+	require := require.New(t)
+
+	state := &State{
+		Nonce:        3,
+		Balance:      big.NewInt(100),
+		Address:      "a",
+		IsCandidate:  true,
+		VotingWeight: big.NewInt(60),
+		Votee:        "a",
+		Voters:       map[string]*big.Int{},
+	}
+	for i := 0; i < 10; i++ {
+		state.Voters[strconv.Itoa(i)] = big.NewInt(int64(i))
+	}
+	// the same state is always encoded into the same bytes regardless of the map order
+	ss, err := stateToBytes(state)
+	require.Nil(err)
+	for i := 0; i < 10; i++ {
+		again, err := stateToBytes(state)
+		require.Nil(err)
+		require.Equal(ss, again)
+	}
+	decoded, err := bytesToState(ss)
+	require.Nil(err)
+	require.Equal(state, decoded)
+
+	// the negative amounts are not encoded
+	state.Voters["0"] = big.NewInt(-1)
+	_, err = stateToBytes(state)
+	require.NotNil(err)
+}
+
 func TestRootHash(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	trie := mock_trie.NewMockTrie(ctrl)
-	sf := NewFactory(trie)
+	expectNoPools(trie)
+	sf, err := NewFactory(trie)
+	require.Nil(t, err)
 	trie.EXPECT().RootHash().Times(1).Return(common.ZeroHash32B)
 	assert.Equal(t, common.ZeroHash32B, sf.RootHash())
 }
@@ -57,7 +93,9 @@ func TestCreateState(t *testing.T) {
 	defer ctrl.Finish()
 
 	trie := mock_trie.NewMockTrie(ctrl)
-	sf := NewFactory(trie)
+	expectNoPools(trie)
+	sf, err := NewFactory(trie)
+	require.Nil(t, err)
 	trie.EXPECT().Upsert(gomock.Any(), gomock.Any()).Times(1)
 	addr, err := iotxaddress.NewAddress(true, []byte{0xa4, 0x00, 0x00, 0x00})
 	assert.Nil(t, err)
@@ -89,7 +127,9 @@ func TestNonce(t *testing.T) {
 	defer ctrl.Finish()
 
 	trie := mock_trie.NewMockTrie(ctrl)
-	sf := NewFactory(trie)
+	expectNoPools(trie)
+	sf, err := NewFactory(trie)
+	require.Nil(t, err)
 
 	// Add 10 so the balance should be 10
 	addr, err := iotxaddress.NewAddress(true, []byte{0xa4, 0x00, 0x00, 0x00})
@@ -229,12 +269,9 @@ func TestCandidate(t *testing.T) {
 	util.CleanupPath(t, testTriePath)
 	defer util.CleanupPath(t, testTriePath)
 	tr, _ := trie.NewTrie(testTriePath, false)
-	sf := &factory{
-		trie:                   tr,
-		candidateHeap:          CandidateMinPQ{candidateSize, make([]*Candidate, 0)},
-		candidateBufferMinHeap: CandidateMinPQ{candidateBufferSize, make([]*Candidate, 0)},
-		candidateBufferMaxHeap: CandidateMaxPQ{candidateBufferSize, make([]*Candidate, 0)},
-	}
+	created, err := NewFactory(tr, CandidatePoolOption(2, 10))
+	require.Nil(t, err)
+	sf := created.(*factory)
 	sf.CreateState(a.RawAddress, uint64(100))
 	sf.CreateState(b.RawAddress, uint64(200))
 	sf.CreateState(c.RawAddress, uint64(300))
@@ -245,7 +282,7 @@ func TestCandidate(t *testing.T) {
 	// a:100(0) b:200(0) c:300(0)
 	tx1 := action.Transfer{Sender: a.RawAddress, Recipient: b.RawAddress, Nonce: uint64(1), Amount: big.NewInt(10)}
 	tx2 := action.Transfer{Sender: a.RawAddress, Recipient: c.RawAddress, Nonce: uint64(2), Amount: big.NewInt(20)}
-	err := sf.CommitStateChanges(0, []*action.Transfer{&tx1, &tx2}, []*action.Vote{})
+	err = sf.CommitStateChanges(0, []*action.Transfer{&tx1, &tx2}, []*action.Vote{})
 	require.Nil(t, err)
 	require.True(t, compareStrings(voteForm(sf.Candidates()), []string{}))
 	require.True(t, compareStrings(voteForm(sf.candidatesBuffer()), []string{}))
@@ -435,6 +472,382 @@ func TestCandidate(t *testing.T) {
 	// a(b):100(0) b(c):200(500) c(c):100(+200=300) d(b): 400(100) e(e):200(+0=200) f(d):100(0)
 }
 
+func TestCandidatePools(t *testing.T) {
+	require := require.New(t)
+
+	addrs := []*iotxaddress.Address{}
+	votes := []*action.Vote{}
+	for i := 0; i < 6; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		addrs = append(addrs, addr)
+		votes = append(votes, action.NewVote(0, addr.PublicKey, addr.PublicKey))
+	}
+	newNode := func(path string) (trie.Trie, Factory) {
+		tr, err := trie.NewTrie(path, path == "")
+		require.Nil(err)
+		sf, err := NewFactory(tr, CandidatePoolOption(2, 3))
+		require.Nil(err)
+		for i, addr := range addrs {
+			// the candidates tie in pairs
+			_, err = sf.CreateState(addr.RawAddress, uint64(100*(i/2+1)))
+			require.Nil(err)
+		}
+		require.Nil(sf.CommitStateChanges(1, nil, votes))
+		return tr, sf
+	}
+
+	_, sf := newNode("")
+	_, candidates := sf.Candidates()
+	require.Equal(2, len(candidates))
+	require.True(compareCandidates(candidates[0], candidates[1]) > 0)
+	_, buffer := sf.(*factory).candidatesBuffer()
+	require.Equal(3, len(buffer))
+
+	// the candidate pools are rebuilt from the trie after restarting
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	tr, _ := newNode(testTriePath)
+	tr = reopenTrie(t, tr)
+	defer tr.Close()
+	restarted, err := NewFactory(tr, CandidatePoolOption(2, 3))
+	require.Nil(err)
+	height, committed := restarted.Height()
+	require.True(committed)
+	require.Equal(uint64(1), height)
+	require.Equal(voteForm(sf.Candidates()), voteForm(restarted.Candidates()))
+	require.Equal(voteForm(sf.(*factory).candidatesBuffer()), voteForm(restarted.(*factory).candidatesBuffer()))
+
+	// the same actions lead to the same candidates and the same root afterwards
+	tsf := &action.Transfer{Sender: candidates[0].Address, Recipient: buffer[0].Address, Nonce: 1, Amount: big.NewInt(50)}
+	require.Nil(sf.CommitStateChanges(2, []*action.Transfer{tsf}, nil))
+	require.Nil(restarted.CommitStateChanges(2, []*action.Transfer{tsf}, nil))
+	require.Equal(voteForm(sf.Candidates()), voteForm(restarted.Candidates()))
+	require.Equal(voteForm(sf.(*factory).candidatesBuffer()), voteForm(restarted.(*factory).candidatesBuffer()))
+	require.Equal(sf.RootHash(), restarted.RootHash())
+
+	// the candidate pools of different sizes are rejected
+	_, err = NewFactory(tr, CandidatePoolOption(3, 3))
+	require.Equal(ErrCandidatePoolSize, errors.Cause(err))
+}
+
+func TestUnvote(t *testing.T) {
+	require := require.New(t)
+
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	tr, err := trie.NewTrie(testTriePath, false)
+	require.Nil(err)
+	created, err := NewFactory(tr, CandidatePoolOption(1, 2), UnbondingPeriodOption(10))
+	require.Nil(err)
+	sf := created.(*factory)
+	addrs := []*iotxaddress.Address{}
+	for i := 0; i < 3; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(100*(i+1)))
+		require.Nil(err)
+		addrs = append(addrs, addr)
+	}
+	a, b, c := addrs[0], addrs[1], addrs[2]
+
+	// a and b nominate themselves, and c votes for a
+	votes := []*action.Vote{
+		action.NewVote(1, a.PublicKey, a.PublicKey),
+		action.NewVote(1, b.PublicKey, b.PublicKey),
+		action.NewVote(1, c.PublicKey, a.PublicKey),
+	}
+	require.Nil(sf.CommitStateChanges(1, nil, votes))
+	require.Equal([]string{a.RawAddress + ":400"}, voteForm(sf.Candidates()))
+	require.Equal([]string{b.RawAddress + ":200"}, voteForm(sf.candidatesBuffer()))
+
+	// c withdraws the vote, whose weight stops counting while the tokens stay locked
+	require.Nil(sf.CommitStateChanges(5, nil, []*action.Vote{action.NewUnvote(2, c.PublicKey)}))
+	require.Equal([]string{b.RawAddress + ":200"}, voteForm(sf.Candidates()))
+	require.Equal([]string{a.RawAddress + ":100"}, voteForm(sf.candidatesBuffer()))
+	state, err := sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal("0", state.VotingWeight.String())
+	require.Equal(0, len(state.Voters))
+	state, err = sf.State(c.RawAddress)
+	require.Nil(err)
+	require.Equal("", state.Votee)
+	require.Equal("300", state.LockedBalance.String())
+	require.Equal(uint64(15), state.UnlockHeight)
+	require.Equal("0", state.SpendableBalance(14).String())
+
+	tsf := &action.Transfer{Sender: c.RawAddress, Recipient: a.RawAddress, Nonce: 3, Amount: big.NewInt(40)}
+	require.Equal(ErrNotEnoughBalance, sf.CommitStateChanges(6, []*action.Transfer{tsf}, nil))
+	// the tokens received afterwards can be spent
+	received := &action.Transfer{Sender: b.RawAddress, Recipient: c.RawAddress, Nonce: 2, Amount: big.NewInt(40)}
+	require.Nil(sf.CommitStateChanges(7, []*action.Transfer{received, tsf}, nil))
+	require.Equal([]string{b.RawAddress + ":160"}, voteForm(sf.Candidates()))
+	require.Equal([]string{a.RawAddress + ":140"}, voteForm(sf.candidatesBuffer()))
+	// and the locked ones are released after the unbonding period
+	tsf = &action.Transfer{Sender: c.RawAddress, Recipient: a.RawAddress, Nonce: 4, Amount: big.NewInt(300)}
+	require.Equal(ErrNotEnoughBalance, sf.CommitStateChanges(14, []*action.Transfer{tsf}, nil))
+	require.Nil(sf.CommitStateChanges(15, []*action.Transfer{tsf}, nil))
+	state, err = sf.State(c.RawAddress)
+	require.Nil(err)
+	require.Equal("0", state.Balance.String())
+	require.Nil(state.LockedBalance)
+	require.Equal(uint64(0), state.UnlockHeight)
+	require.Equal([]string{a.RawAddress + ":440"}, voteForm(sf.Candidates()))
+	require.Equal([]string{b.RawAddress + ":160"}, voteForm(sf.candidatesBuffer()))
+
+	// a unnominates, and b in the buffer takes its place
+	require.Nil(sf.CommitStateChanges(16, nil, []*action.Vote{action.NewUnvote(2, a.PublicKey)}))
+	require.Equal([]string{b.RawAddress + ":160"}, voteForm(sf.Candidates()))
+	require.Equal([]string{}, voteForm(sf.candidatesBuffer()))
+	state, err = sf.State(a.RawAddress)
+	require.Nil(err)
+	require.False(state.IsCandidate)
+	require.Equal("440", state.LockedBalance.String())
+	require.Equal(uint64(26), state.UnlockHeight)
+
+	// the candidate pools after the unnomination are rebuilt from the trie
+	tr = reopenTrie(t, tr)
+	defer tr.Close()
+	restarted, err := NewFactory(tr, CandidatePoolOption(1, 2), UnbondingPeriodOption(10))
+	require.Nil(err)
+	require.Equal(voteForm(sf.Candidates()), voteForm(restarted.Candidates()))
+	require.Equal(sf.RootHash(), restarted.RootHash())
+}
+
+func TestVoterRewards(t *testing.T) {
+	require := require.New(t)
+
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	tr, err := trie.NewTrie(testTriePath, false)
+	require.Nil(err)
+	created, err := NewFactory(tr, CandidatePoolOption(1, 2), VoterRewardOption(30, 4))
+	require.Nil(err)
+	sf := created.(*factory)
+	addrs := []*iotxaddress.Address{}
+	for i := 0; i < 4; i++ {
+		addr, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+		require.Nil(err)
+		_, err = sf.CreateState(addr.RawAddress, uint64(100*(i+1)))
+		require.Nil(err)
+		addrs = append(addrs, addr)
+	}
+	a, b, c, d := addrs[0], addrs[1], addrs[2], addrs[3]
+
+	// a nominates itself, and b and c vote for it
+	votes := []*action.Vote{
+		action.NewVote(1, a.PublicKey, a.PublicKey),
+		action.NewVote(1, b.PublicKey, a.PublicKey),
+		action.NewVote(1, c.PublicKey, a.PublicKey),
+	}
+	require.Nil(sf.CommitStateChanges(1, nil, votes))
+	state, err := sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal(2, len(state.Voters))
+	require.Equal("200", state.Voters[b.RawAddress].String())
+	require.Equal("300", state.Voters[c.RawAddress].String())
+
+	// the transfers of the voters change their weights
+	tsf := &action.Transfer{Sender: c.RawAddress, Recipient: d.RawAddress, Nonce: 2, Amount: big.NewInt(100)}
+	require.Nil(sf.CommitStateChanges(2, []*action.Transfer{tsf}, nil))
+	state, err = sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal("200", state.Voters[c.RawAddress].String())
+	require.Equal("400", state.VotingWeight.String())
+
+	// a produces a block, whose voter share is kept aside until the end of the epoch
+	require.Nil(sf.CommitStateChanges(3, []*action.Transfer{action.NewCoinBaseTransfer(big.NewInt(101), a.RawAddress)}, nil))
+	require.Equal(0, len(sf.RewardReceipts()))
+	state, err = sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal("171", state.Balance.String())
+	require.Equal("30", sf.voterRewards[a.RawAddress].String())
+	// the rewards reported are a copy of the ones accrued, which aren't in any account yet
+	rewards := sf.VoterRewards()
+	require.Equal(map[string]string{a.RawAddress: "30"}, amountStrings(rewards))
+	rewards[a.RawAddress].SetUint64(0)
+	require.Equal("30", sf.voterRewards[a.RawAddress].String())
+
+	// the voter rewards accrued are restored from the trie, and the node carries on after restarting
+	tr = reopenTrie(t, tr)
+	defer tr.Close()
+	restarted, err := NewFactory(tr, CandidatePoolOption(1, 2), VoterRewardOption(30, 4))
+	require.Nil(err)
+	require.Equal("30", restarted.(*factory).voterRewards[a.RawAddress].String())
+	sf = restarted.(*factory)
+
+	// the state changes which fail to commit leave the voter rewards accrued untouched
+	overdrawn := &action.Transfer{Sender: d.RawAddress, Recipient: a.RawAddress, Nonce: 1, Amount: big.NewInt(10000)}
+	coinbase := action.NewCoinBaseTransfer(big.NewInt(11), a.RawAddress)
+	require.NotNil(sf.CommitStateChanges(4, []*action.Transfer{coinbase, overdrawn}, nil))
+	require.Equal("30", sf.voterRewards[a.RawAddress].String())
+
+	// at the end of the epoch, b and c share the voter rewards in proportion to their weights, and the remainder goes
+	// to a
+	coinbase = action.NewCoinBaseTransfer(big.NewInt(11), a.RawAddress)
+	require.Nil(sf.CommitStateChanges(4, []*action.Transfer{coinbase}, nil))
+	receipts := sf.RewardReceipts()
+	require.Equal(3, len(receipts))
+	credited := map[string]string{}
+	for _, receipt := range receipts {
+		require.Equal(uint64(4), receipt.Height)
+		require.Equal(a.RawAddress, receipt.Delegate)
+		credited[receipt.Voter] = receipt.Amount.String()
+	}
+	require.Equal(map[string]string{b.RawAddress: "16", c.RawAddress: "16", a.RawAddress: "1"}, credited)
+	state, err = sf.State(b.RawAddress)
+	require.Nil(err)
+	require.Equal("216", state.Balance.String())
+	state, err = sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal("180", state.Balance.String())
+	require.Equal("432", state.VotingWeight.String())
+	require.Equal("216", state.Voters[b.RawAddress].String())
+	require.Equal([]string{a.RawAddress + ":612"}, voteForm(sf.Candidates()))
+	require.Equal(0, len(sf.voterRewards))
+	require.Equal(0, len(sf.VoterRewards()))
+
+	// the rewards of the next epoch don't go to the voter who has withdrawn the vote
+	require.Nil(sf.CommitStateChanges(5, []*action.Transfer{action.NewCoinBaseTransfer(big.NewInt(10), a.RawAddress)}, nil))
+	require.Nil(sf.CommitStateChanges(8, nil, []*action.Vote{action.NewUnvote(2, c.PublicKey)}))
+	require.Equal(1, len(sf.RewardReceipts()))
+	require.Equal(b.RawAddress, sf.RewardReceipts()[0].Voter)
+	require.Equal("3", sf.RewardReceipts()[0].Amount.String())
+}
+
+func TestSimulateStateChanges(t *testing.T) {
+	require := require.New(t)
+
+	tr, err := trie.NewTrie("", true)
+	require.Nil(err)
+	created, err := NewFactory(tr, CandidatePoolOption(1, 2), UnbondingPeriodOption(10))
+	require.Nil(err)
+	sf := created.(*factory)
+	a, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	require.Nil(err)
+	b, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	require.Nil(err)
+	_, err = sf.CreateState(a.RawAddress, 100)
+	require.Nil(err)
+	require.Nil(sf.CommitStateChanges(1, nil, []*action.Vote{action.NewVote(1, a.PublicKey, a.PublicKey)}))
+	root := sf.RootHash()
+	candidates := voteForm(sf.Candidates())
+
+	// the transfer to the new account and the vote for it are previewed
+	tsf := &action.Transfer{Sender: a.RawAddress, Recipient: b.RawAddress, Nonce: 2, Amount: big.NewInt(30)}
+	vote := action.NewVote(3, a.PublicKey, b.PublicKey)
+	states, err := sf.SimulateStateChanges([]*action.Transfer{tsf}, []*action.Vote{vote})
+	require.Nil(err)
+	require.Equal(2, len(states))
+	simulated := map[string]*State{}
+	for _, state := range states {
+		simulated[state.Address] = state
+	}
+	require.Equal("70", simulated[a.RawAddress].Balance.String())
+	require.Equal(uint64(3), simulated[a.RawAddress].Nonce)
+	require.Equal(b.RawAddress, simulated[a.RawAddress].Votee)
+	require.Equal("30", simulated[b.RawAddress].Balance.String())
+	require.Equal("70", simulated[b.RawAddress].VotingWeight.String())
+
+	// nothing is committed
+	require.Equal(root, sf.RootHash())
+	require.Equal(candidates, voteForm(sf.Candidates()))
+	_, err = sf.State(b.RawAddress)
+	require.Equal(ErrAccountNotExist, err)
+	state, err := sf.State(a.RawAddress)
+	require.Nil(err)
+	require.Equal("100", state.Balance.String())
+
+	// the actions which would fail get the same error as committing them
+	tsf = &action.Transfer{Sender: a.RawAddress, Recipient: b.RawAddress, Nonce: 2, Amount: big.NewInt(101)}
+	_, err = sf.SimulateStateChanges([]*action.Transfer{tsf}, nil)
+	require.Equal(ErrNotEnoughBalance, err)
+	require.Nil(sf.CommitStateChanges(2, nil, []*action.Vote{action.NewUnvote(2, a.PublicKey)}))
+	tsf = &action.Transfer{Sender: a.RawAddress, Recipient: b.RawAddress, Nonce: 3, Amount: big.NewInt(1)}
+	_, err = sf.SimulateStateChanges([]*action.Transfer{tsf}, nil)
+	require.Equal(ErrNotEnoughBalance, err)
+	_, err = sf.SimulateStateChanges([]*action.Transfer{action.NewCoinBaseTransfer(big.NewInt(1), a.RawAddress)}, nil)
+	require.NotNil(err)
+}
+
+func TestStateDiff(t *testing.T) {
+	require := require.New(t)
+
+	tr, err := trie.NewTrie("", true)
+	require.Nil(err)
+	sf, err := NewFactory(tr, CandidatePoolOption(1, 2))
+	require.Nil(err)
+	a, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	require.Nil(err)
+	b, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	require.Nil(err)
+	c, err := iotxaddress.NewAddress(iotxaddress.IsTestnet, iotxaddress.ChainID)
+	require.Nil(err)
+	_, err = sf.CreateState(a.RawAddress, 100)
+	require.Nil(err)
+	_, err = sf.CreateState(c.RawAddress, 50)
+	require.Nil(err)
+	require.Nil(sf.StateDiff())
+
+	// the transfer to the new account and the vote are recorded, and the untouched account isn't
+	tsf := &action.Transfer{Sender: a.RawAddress, Recipient: b.RawAddress, Nonce: 1, Amount: big.NewInt(30)}
+	vote := action.NewVote(2, a.PublicKey, a.PublicKey)
+	require.Nil(sf.CommitStateChanges(1, []*action.Transfer{tsf}, []*action.Vote{vote}))
+	diff := sf.StateDiff()
+	require.Equal(uint64(1), diff.Height)
+	require.Equal(2, len(diff.Accounts))
+	changes := map[string]*AccountDiff{}
+	for _, account := range diff.Accounts {
+		changes[account.Address()] = account
+	}
+	require.Nil(changes[c.RawAddress])
+	require.Nil(changes[b.RawAddress].Old)
+	require.Equal("30", changes[b.RawAddress].New.Balance.String())
+	require.Equal("100", changes[a.RawAddress].Old.Balance.String())
+	require.Equal(uint64(0), changes[a.RawAddress].Old.Nonce)
+	require.Equal("", changes[a.RawAddress].Old.Votee)
+	require.Equal("70", changes[a.RawAddress].New.Balance.String())
+	require.Equal(uint64(2), changes[a.RawAddress].New.Nonce)
+	require.Equal(a.RawAddress, changes[a.RawAddress].New.Votee)
+	require.False(changes[a.RawAddress].Old.IsCandidate)
+	require.True(changes[a.RawAddress].New.IsCandidate)
+
+	// the diff survives the serialization
+	serialized, err := diff.Serialize()
+	require.Nil(err)
+	decoded := &StateDiff{}
+	require.Nil(decoded.Deserialize(serialized))
+	require.Equal(diff.Height, decoded.Height)
+	require.Equal(len(diff.Accounts), len(decoded.Accounts))
+	for i, account := range diff.Accounts {
+		require.Equal(account.Address(), decoded.Accounts[i].Address())
+		require.Equal(account.Old == nil, decoded.Accounts[i].Old == nil)
+		require.Equal(account.New.Balance.String(), decoded.Accounts[i].New.Balance.String())
+		require.Equal(account.New.Votee, decoded.Accounts[i].New.Votee)
+	}
+
+	// the block without actions changes nothing
+	require.Nil(sf.CommitStateChanges(2, nil, nil))
+	require.Equal(uint64(2), sf.StateDiff().Height)
+	require.Equal(0, len(sf.StateDiff().Accounts))
+}
+
+// reopenTrie closes the trie on the DB file and opens it again, as a restarted node does
+func reopenTrie(t *testing.T, tr trie.Trie) trie.Trie {
+	require.Nil(t, tr.Close())
+	reopened, err := trie.NewTrie(testTriePath, false)
+	require.Nil(t, err)
+	return reopened
+}
+
+// expectNoPools lets the mock trie answer that it keeps neither the candidate pools, the voter rewards nor the state
+// height
+func expectNoPools(tr *mock_trie.MockTrie) {
+	tr.EXPECT().Get(candidatePoolKey).Times(1).Return(nil, trie.ErrNotExist)
+	tr.EXPECT().Get(voterRewardKey).Times(1).Return(nil, trie.ErrNotExist)
+	tr.EXPECT().Get(stateHeightKey).Times(1).Return(nil, trie.ErrNotExist)
+}
+
 func compareStrings(actual []string, expected []string) bool {
 	act := make(map[string]bool)
 	for i := 0; i < len(actual); i++ {
@@ -450,3 +863,11 @@ func compareStrings(actual []string, expected []string) bool {
 	}
 	return len(act) == 0
 }
+
+func amountStrings(amounts map[string]*big.Int) map[string]string {
+	strs := make(map[string]string, len(amounts))
+	for key, amount := range amounts {
+		strs[key] = amount.String()
+	}
+	return strs
+}
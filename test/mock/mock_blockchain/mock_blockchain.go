@@ -126,6 +126,19 @@ func (mr *MockBlockchainMockRecorder) GetBlockByHash(hash interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockByHash", reflect.TypeOf((*MockBlockchain)(nil).GetBlockByHash), hash)
 }
 
+// GetBlockHeaderByHeight mocks base method
+func (m *MockBlockchain) GetBlockHeaderByHeight(height uint64) (*blockchain.Block, error) {
+	ret := m.ctrl.Call(m, "GetBlockHeaderByHeight", height)
+	ret0, _ := ret[0].(*blockchain.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHeaderByHeight indicates an expected call of GetBlockHeaderByHeight
+func (mr *MockBlockchainMockRecorder) GetBlockHeaderByHeight(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHeaderByHeight", reflect.TypeOf((*MockBlockchain)(nil).GetBlockHeaderByHeight), height)
+}
+
 // GetTotalTransfers mocks base method
 func (m *MockBlockchain) GetTotalTransfers() (uint64, error) {
 	ret := m.ctrl.Call(m, "GetTotalTransfers")
@@ -282,6 +295,58 @@ func (mr *MockBlockchainMockRecorder) TipHeight() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TipHeight", reflect.TypeOf((*MockBlockchain)(nil).TipHeight))
 }
 
+// GetStateDiff mocks base method
+func (m *MockBlockchain) GetStateDiff(height uint64) (*state.StateDiff, error) {
+	ret := m.ctrl.Call(m, "GetStateDiff", height)
+	ret0, _ := ret[0].(*state.StateDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStateDiff indicates an expected call of GetStateDiff
+func (mr *MockBlockchainMockRecorder) GetStateDiff(height interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateDiff", reflect.TypeOf((*MockBlockchain)(nil).GetStateDiff), height)
+}
+
+// GetStateHistoryByAddress mocks base method
+func (m *MockBlockchain) GetStateHistoryByAddress(address string) ([]*state.StateDiff, error) {
+	ret := m.ctrl.Call(m, "GetStateHistoryByAddress", address)
+	ret0, _ := ret[0].([]*state.StateDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStateHistoryByAddress indicates an expected call of GetStateHistoryByAddress
+func (mr *MockBlockchainMockRecorder) GetStateHistoryByAddress(address interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateHistoryByAddress", reflect.TypeOf((*MockBlockchain)(nil).GetStateHistoryByAddress), address)
+}
+
+// SimulateActions mocks base method
+func (m *MockBlockchain) SimulateActions(tsf []*action.Transfer, vote []*action.Vote) ([]*state.State, error) {
+	ret := m.ctrl.Call(m, "SimulateActions", tsf, vote)
+	ret0, _ := ret[0].([]*state.State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateActions indicates an expected call of SimulateActions
+func (mr *MockBlockchainMockRecorder) SimulateActions(tsf, vote interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateActions", reflect.TypeOf((*MockBlockchain)(nil).SimulateActions), tsf, vote)
+}
+
+// GetRewardReceiptsByAddress mocks base method
+func (m *MockBlockchain) GetRewardReceiptsByAddress(address string) ([]*state.RewardReceipt, error) {
+	ret := m.ctrl.Call(m, "GetRewardReceiptsByAddress", address)
+	ret0, _ := ret[0].([]*state.RewardReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRewardReceiptsByAddress indicates an expected call of GetRewardReceiptsByAddress
+func (mr *MockBlockchainMockRecorder) GetRewardReceiptsByAddress(address interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardReceiptsByAddress", reflect.TypeOf((*MockBlockchain)(nil).GetRewardReceiptsByAddress), address)
+}
+
 // StateByAddr mocks base method
 func (m *MockBlockchain) StateByAddr(address string) (*state.State, error) {
 	ret := m.ctrl.Call(m, "StateByAddr", address)
@@ -295,6 +360,56 @@ func (mr *MockBlockchainMockRecorder) StateByAddr(address interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateByAddr", reflect.TypeOf((*MockBlockchain)(nil).StateByAddr), address)
 }
 
+// IterateStates mocks base method
+func (m *MockBlockchain) IterateStates(fn func(*state.State) error) error {
+	ret := m.ctrl.Call(m, "IterateStates", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateStates indicates an expected call of IterateStates
+func (mr *MockBlockchainMockRecorder) IterateStates(fn interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateStates", reflect.TypeOf((*MockBlockchain)(nil).IterateStates), fn)
+}
+
+// VoterRewards mocks base method
+func (m *MockBlockchain) VoterRewards() (map[string]*big.Int, error) {
+	ret := m.ctrl.Call(m, "VoterRewards")
+	ret0, _ := ret[0].(map[string]*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoterRewards indicates an expected call of VoterRewards
+func (mr *MockBlockchainMockRecorder) VoterRewards() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoterRewards", reflect.TypeOf((*MockBlockchain)(nil).VoterRewards))
+}
+
+// StateSnapshot mocks base method
+func (m *MockBlockchain) StateSnapshot() (*state.Snapshot, error) {
+	ret := m.ctrl.Call(m, "StateSnapshot")
+	ret0, _ := ret[0].(*state.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateSnapshot indicates an expected call of StateSnapshot
+func (mr *MockBlockchainMockRecorder) StateSnapshot() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateSnapshot", reflect.TypeOf((*MockBlockchain)(nil).StateSnapshot))
+}
+
+// ImportSnapshot mocks base method
+func (m *MockBlockchain) ImportSnapshot(ss *state.Snapshot, next *blockchain.Block) error {
+	ret := m.ctrl.Call(m, "ImportSnapshot", ss, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportSnapshot indicates an expected call of ImportSnapshot
+func (mr *MockBlockchainMockRecorder) ImportSnapshot(ss, next interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportSnapshot", reflect.TypeOf((*MockBlockchain)(nil).ImportSnapshot), ss, next)
+}
+
 // MintNewBlock mocks base method
 func (m *MockBlockchain) MintNewBlock(tsf []*action.Transfer, vote []*action.Vote, address *iotxaddress.Address, data string) (*blockchain.Block, error) {
 	ret := m.ctrl.Call(m, "MintNewBlock", tsf, vote, address, data)
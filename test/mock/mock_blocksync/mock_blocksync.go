@@ -106,3 +106,64 @@ func (m *MockBlockSync) ProcessBlockSync(blk *blockchain.Block) error {
 func (mr *MockBlockSyncMockRecorder) ProcessBlockSync(blk interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBlockSync", reflect.TypeOf((*MockBlockSync)(nil).ProcessBlockSync), blk)
 }
+
+// ProcessHeaderSyncRequest mocks base method
+func (m *MockBlockSync) ProcessHeaderSyncRequest(sender string, sync *proto.BlockHeaderSync) error {
+	ret := m.ctrl.Call(m, "ProcessHeaderSyncRequest", sender, sync)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessHeaderSyncRequest indicates an expected call of ProcessHeaderSyncRequest
+func (mr *MockBlockSyncMockRecorder) ProcessHeaderSyncRequest(sender, sync interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessHeaderSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessHeaderSyncRequest), sender, sync)
+}
+
+// ProcessBlockHeaders mocks base method
+func (m *MockBlockSync) ProcessBlockHeaders(sender string, headers *proto.BlockHeaderContainer) error {
+	ret := m.ctrl.Call(m, "ProcessBlockHeaders", sender, headers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessBlockHeaders indicates an expected call of ProcessBlockHeaders
+func (mr *MockBlockSyncMockRecorder) ProcessBlockHeaders(sender, headers interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBlockHeaders", reflect.TypeOf((*MockBlockSync)(nil).ProcessBlockHeaders), sender, headers)
+}
+
+// ProcessSnapshotSyncRequest mocks base method
+func (m *MockBlockSync) ProcessSnapshotSyncRequest(sender string, sync *proto.StateSnapshotSync) error {
+	ret := m.ctrl.Call(m, "ProcessSnapshotSyncRequest", sender, sync)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessSnapshotSyncRequest indicates an expected call of ProcessSnapshotSyncRequest
+func (mr *MockBlockSyncMockRecorder) ProcessSnapshotSyncRequest(sender, sync interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSnapshotSyncRequest", reflect.TypeOf((*MockBlockSync)(nil).ProcessSnapshotSyncRequest), sender, sync)
+}
+
+// ProcessSnapshotChunk mocks base method
+func (m *MockBlockSync) ProcessSnapshotChunk(sender string, chunk *proto.StateSnapshotChunk) error {
+	ret := m.ctrl.Call(m, "ProcessSnapshotChunk", sender, chunk)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessSnapshotChunk indicates an expected call of ProcessSnapshotChunk
+func (mr *MockBlockSyncMockRecorder) ProcessSnapshotChunk(sender, chunk interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSnapshotChunk", reflect.TypeOf((*MockBlockSync)(nil).ProcessSnapshotChunk), sender, chunk)
+}
+
+// SyncStatus mocks base method
+func (m *MockBlockSync) SyncStatus() (*proto.SyncStatus, error) {
+	ret := m.ctrl.Call(m, "SyncStatus")
+	ret0, _ := ret[0].(*proto.SyncStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncStatus indicates an expected call of SyncStatus
+func (mr *MockBlockSyncMockRecorder) SyncStatus() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockBlockSync)(nil).SyncStatus))
+}
@@ -83,3 +83,52 @@ func (m *MockPool) NumDelegatesPerEpoch() (uint, error) {
 func (mr *MockPoolMockRecorder) NumDelegatesPerEpoch() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NumDelegatesPerEpoch", reflect.TypeOf((*MockPool)(nil).NumDelegatesPerEpoch))
 }
+
+// PublicKey mocks base method
+func (m *MockPool) PublicKey(addr net.Addr) ([]byte, error) {
+	ret := m.ctrl.Call(m, "PublicKey", addr)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicKey indicates an expected call of PublicKey
+func (mr *MockPoolMockRecorder) PublicKey(addr interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockPool)(nil).PublicKey), addr)
+}
+
+// MockKeyResolver is a mock of KeyResolver interface
+type MockKeyResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyResolverMockRecorder
+}
+
+// MockKeyResolverMockRecorder is the mock recorder for MockKeyResolver
+type MockKeyResolverMockRecorder struct {
+	mock *MockKeyResolver
+}
+
+// NewMockKeyResolver creates a new mock instance
+func NewMockKeyResolver(ctrl *gomock.Controller) *MockKeyResolver {
+	mock := &MockKeyResolver{ctrl: ctrl}
+	mock.recorder = &MockKeyResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockKeyResolver) EXPECT() *MockKeyResolverMockRecorder {
+	return m.recorder
+}
+
+// PublicKey mocks base method
+func (m *MockKeyResolver) PublicKey(addr string) ([]byte, bool) {
+	ret := m.ctrl.Call(m, "PublicKey", addr)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// PublicKey indicates an expected call of PublicKey
+func (mr *MockKeyResolverMockRecorder) PublicKey(addr interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockKeyResolver)(nil).PublicKey), addr)
+}
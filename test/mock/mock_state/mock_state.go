@@ -9,6 +9,7 @@ import (
 	action "github.com/iotexproject/iotex-core/blockchain/action"
 	common "github.com/iotexproject/iotex-core/common"
 	state "github.com/iotexproject/iotex-core/state"
+	trie "github.com/iotexproject/iotex-core/trie"
 	big "math/big"
 	reflect "reflect"
 )
@@ -112,6 +113,31 @@ func (mr *MockFactoryMockRecorder) RootHash() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RootHash", reflect.TypeOf((*MockFactory)(nil).RootHash))
 }
 
+// Height mocks base method
+func (m *MockFactory) Height() (uint64, bool) {
+	ret := m.ctrl.Call(m, "Height")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Height indicates an expected call of Height
+func (mr *MockFactoryMockRecorder) Height() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Height", reflect.TypeOf((*MockFactory)(nil).Height))
+}
+
+// Reset mocks base method
+func (m *MockFactory) Reset() error {
+	ret := m.ctrl.Call(m, "Reset")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockFactoryMockRecorder) Reset() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockFactory)(nil).Reset))
+}
+
 // Candidates mocks base method
 func (m *MockFactory) Candidates() (uint64, []*state.Candidate) {
 	ret := m.ctrl.Call(m, "Candidates")
@@ -124,3 +150,101 @@ func (m *MockFactory) Candidates() (uint64, []*state.Candidate) {
 func (mr *MockFactoryMockRecorder) Candidates() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Candidates", reflect.TypeOf((*MockFactory)(nil).Candidates))
 }
+
+// SimulateStateChanges mocks base method
+func (m *MockFactory) SimulateStateChanges(arg0 []*action.Transfer, arg1 []*action.Vote) ([]*state.State, error) {
+	ret := m.ctrl.Call(m, "SimulateStateChanges", arg0, arg1)
+	ret0, _ := ret[0].([]*state.State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateStateChanges indicates an expected call of SimulateStateChanges
+func (mr *MockFactoryMockRecorder) SimulateStateChanges(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateStateChanges", reflect.TypeOf((*MockFactory)(nil).SimulateStateChanges), arg0, arg1)
+}
+
+// StateDiff mocks base method
+func (m *MockFactory) StateDiff() *state.StateDiff {
+	ret := m.ctrl.Call(m, "StateDiff")
+	ret0, _ := ret[0].(*state.StateDiff)
+	return ret0
+}
+
+// StateDiff indicates an expected call of StateDiff
+func (mr *MockFactoryMockRecorder) StateDiff() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateDiff", reflect.TypeOf((*MockFactory)(nil).StateDiff))
+}
+
+// RewardReceipts mocks base method
+func (m *MockFactory) RewardReceipts() []*state.RewardReceipt {
+	ret := m.ctrl.Call(m, "RewardReceipts")
+	ret0, _ := ret[0].([]*state.RewardReceipt)
+	return ret0
+}
+
+// RewardReceipts indicates an expected call of RewardReceipts
+func (mr *MockFactoryMockRecorder) RewardReceipts() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewardReceipts", reflect.TypeOf((*MockFactory)(nil).RewardReceipts))
+}
+
+// VoterRewards mocks base method
+func (m *MockFactory) VoterRewards() map[string]*big.Int {
+	ret := m.ctrl.Call(m, "VoterRewards")
+	ret0, _ := ret[0].(map[string]*big.Int)
+	return ret0
+}
+
+// VoterRewards indicates an expected call of VoterRewards
+func (mr *MockFactoryMockRecorder) VoterRewards() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoterRewards", reflect.TypeOf((*MockFactory)(nil).VoterRewards))
+}
+
+// TrieCacheStats mocks base method
+func (m *MockFactory) TrieCacheStats() trie.CacheStats {
+	ret := m.ctrl.Call(m, "TrieCacheStats")
+	ret0, _ := ret[0].(trie.CacheStats)
+	return ret0
+}
+
+// TrieCacheStats indicates an expected call of TrieCacheStats
+func (mr *MockFactoryMockRecorder) TrieCacheStats() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrieCacheStats", reflect.TypeOf((*MockFactory)(nil).TrieCacheStats))
+}
+
+// IterateStates mocks base method
+func (m *MockFactory) IterateStates(arg0 func(*state.State) error) error {
+	ret := m.ctrl.Call(m, "IterateStates", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateStates indicates an expected call of IterateStates
+func (mr *MockFactoryMockRecorder) IterateStates(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateStates", reflect.TypeOf((*MockFactory)(nil).IterateStates), arg0)
+}
+
+// Snapshot mocks base method
+func (m *MockFactory) Snapshot() (*state.Snapshot, error) {
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(*state.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot
+func (mr *MockFactoryMockRecorder) Snapshot() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockFactory)(nil).Snapshot))
+}
+
+// LoadSnapshot mocks base method
+func (m *MockFactory) LoadSnapshot(arg0 *state.Snapshot) error {
+	ret := m.ctrl.Call(m, "LoadSnapshot", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadSnapshot indicates an expected call of LoadSnapshot
+func (mr *MockFactoryMockRecorder) LoadSnapshot(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSnapshot", reflect.TypeOf((*MockFactory)(nil).LoadSnapshot), arg0)
+}
@@ -7,6 +7,7 @@ package mock_trie
 import (
 	gomock "github.com/golang/mock/gomock"
 	common "github.com/iotexproject/iotex-core/common"
+	trie "github.com/iotexproject/iotex-core/trie"
 	reflect "reflect"
 )
 
@@ -105,3 +106,112 @@ func (m *MockTrie) RootHash() common.Hash32B {
 func (mr *MockTrieMockRecorder) RootHash() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RootHash", reflect.TypeOf((*MockTrie)(nil).RootHash))
 }
+
+// Iterate mocks base method
+func (m *MockTrie) Iterate(arg0 func([]byte, []byte) error) error {
+	ret := m.ctrl.Call(m, "Iterate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Iterate indicates an expected call of Iterate
+func (mr *MockTrieMockRecorder) Iterate(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterate", reflect.TypeOf((*MockTrie)(nil).Iterate), arg0)
+}
+
+// CacheStats mocks base method
+func (m *MockTrie) CacheStats() trie.CacheStats {
+	ret := m.ctrl.Call(m, "CacheStats")
+	ret0, _ := ret[0].(trie.CacheStats)
+	return ret0
+}
+
+// CacheStats indicates an expected call of CacheStats
+func (mr *MockTrieMockRecorder) CacheStats() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStats", reflect.TypeOf((*MockTrie)(nil).CacheStats))
+}
+
+// View mocks base method
+func (m *MockTrie) View(arg0 common.Hash32B) (trie.Reader, error) {
+	ret := m.ctrl.Call(m, "View", arg0)
+	ret0, _ := ret[0].(trie.Reader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// View indicates an expected call of View
+func (mr *MockTrieMockRecorder) View(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "View", reflect.TypeOf((*MockTrie)(nil).View), arg0)
+}
+
+// Reset mocks base method
+func (m *MockTrie) Reset(arg0 common.Hash32B) error {
+	ret := m.ctrl.Call(m, "Reset", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockTrieMockRecorder) Reset(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockTrie)(nil).Reset), arg0)
+}
+
+// MockReader is a mock of Reader interface
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockReader) Get(arg0 []byte) ([]byte, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockReaderMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReader)(nil).Get), arg0)
+}
+
+// RootHash mocks base method
+func (m *MockReader) RootHash() common.Hash32B {
+	ret := m.ctrl.Call(m, "RootHash")
+	ret0, _ := ret[0].(common.Hash32B)
+	return ret0
+}
+
+// RootHash indicates an expected call of RootHash
+func (mr *MockReaderMockRecorder) RootHash() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RootHash", reflect.TypeOf((*MockReader)(nil).RootHash))
+}
+
+// Iterate mocks base method
+func (m *MockReader) Iterate(arg0 func([]byte, []byte) error) error {
+	ret := m.ctrl.Call(m, "Iterate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Iterate indicates an expected call of Iterate
+func (mr *MockReaderMockRecorder) Iterate(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterate", reflect.TypeOf((*MockReader)(nil).Iterate), arg0)
+}
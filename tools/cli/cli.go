@@ -26,6 +26,10 @@ func (cli *CLI) printUsage() {
 	fmt.Println("  createchain -address ADDRESS          # create a new blockchain with an address")
 	fmt.Println("  getbalance -address ADDRESS           # get the balance of the address")
 	fmt.Println("  send -from FROM -to TO -amount AMOUNT # send from one address to another")
+	fmt.Println("  export -file FILE [-start START] [-end END] # export the blocks of the heights to a file")
+	fmt.Println("  import -file FILE                     # validate and commit the blocks exported to a file")
+	fmt.Println("  verify                                # check the consistency of the chain DB and the trie DB")
+	fmt.Println("  dump-state [-file FILE]               # write the states of all the accounts as JSON lines")
 }
 
 func (cli *CLI) validateArgs() {
@@ -52,6 +56,19 @@ func (cli *CLI) Run() {
 	sendCmdTo := sendCmd.String("to", "", "send to address")
 	sendCmdAmount := sendCmd.Int("amount", 0, "send amount")
 
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	exportCmdFile := exportCmd.String("file", "", "file to export to")
+	exportCmdStart := exportCmd.Uint64("start", 0, "first height to export")
+	exportCmdEnd := exportCmd.Uint64("end", 0, "last height to export, 0 for the tip height")
+
+	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	importCmdFile := importCmd.String("file", "", "file to import from")
+
+	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
+
+	dumpStateCmd := flag.NewFlagSet("dump-state", flag.ExitOnError)
+	dumpStateCmdFile := dumpStateCmd.String("file", "", "file to dump to, the standard output by default")
+
 	switch os.Args[1] {
 	case "printchain":
 		printChainCmd.Parse(os.Args[2:])
@@ -61,6 +78,14 @@ func (cli *CLI) Run() {
 		getBalanceCmd.Parse(os.Args[2:])
 	case "send":
 		sendCmd.Parse(os.Args[2:])
+	case "export":
+		exportCmd.Parse(os.Args[2:])
+	case "import":
+		importCmd.Parse(os.Args[2:])
+	case "verify":
+		verifyCmd.Parse(os.Args[2:])
+	case "dump-state":
+		dumpStateCmd.Parse(os.Args[2:])
 	default:
 		cli.printUsage()
 		os.Exit(1)
@@ -91,4 +116,24 @@ func (cli *CLI) Run() {
 		}
 		cli.send(*sendCmdFrom, *sendCmdTo, uint64(*sendCmdAmount), config)
 	}
+	if exportCmd.Parsed() {
+		if *exportCmdFile == "" {
+			exportCmd.Usage()
+			os.Exit(1)
+		}
+		cli.exportChain(*exportCmdFile, *exportCmdStart, *exportCmdEnd, config)
+	}
+	if importCmd.Parsed() {
+		if *importCmdFile == "" {
+			importCmd.Usage()
+			os.Exit(1)
+		}
+		cli.importChain(*importCmdFile, config)
+	}
+	if verifyCmd.Parsed() {
+		cli.verifyChain(config)
+	}
+	if dumpStateCmd.Parsed() {
+		cli.dumpState(*dumpStateCmdFile, config)
+	}
 }
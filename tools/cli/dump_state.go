@@ -0,0 +1,119 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+	"github.com/iotexproject/iotex-core/state"
+)
+
+// accountRecord is a line of the state dump. The amounts are decimal strings, so that they don't lose precision.
+type accountRecord struct {
+	Address       string `json:"address"`
+	Nonce         uint64 `json:"nonce"`
+	Balance       string `json:"balance"`
+	IsCandidate   bool   `json:"isCandidate"`
+	VotingWeight  string `json:"votingWeight"`
+	Votee         string `json:"votee"`
+	LockedBalance string `json:"lockedBalance"`
+	UnlockHeight  uint64 `json:"unlockHeight"`
+}
+
+func (cli *CLI) dumpState(file string, config *config.Config) {
+	cli.bc = blockchain.CreateBlockchain(config, nil)
+	if cli.bc == nil {
+		logger.Fatal().Msg("ERROR: Failed to open the blockchain")
+	}
+	defer cli.bc.Stop()
+
+	// the accounts go to the standard output unless a file is given, and the summary to the standard error then
+	out, summary := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("ERROR: Failed to create the dump file")
+		}
+		defer f.Close()
+		out, summary = f, os.Stdout
+	}
+	w := bufio.NewWriter(out)
+	count, total, err := dumpStates(cli.bc, w)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to dump the states")
+	}
+	if err := w.Flush(); err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to write the dump")
+	}
+	rewards, err := cli.bc.VoterRewards()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to get the voter rewards")
+	}
+	pool := reportVoterRewards(rewards, summary)
+	held := new(big.Int).Add(total, pool)
+	supply := new(big.Int).SetUint64(blockchain.Gen.TotalSupply)
+	fmt.Fprintf(summary, "Dumped %d accounts holding %s in total, and %s of voter rewards not distributed yet, "+
+		"against the total supply %s of the genesis (%s)\n", count, total, pool, supply, new(big.Int).Sub(held, supply))
+}
+
+// dumpStates writes a JSON line for each account at the tip of the chain, and returns the number of the accounts and
+// the sum of their balances
+func dumpStates(bc blockchain.Blockchain, w io.Writer) (int, *big.Int, error) {
+	count, total := 0, big.NewInt(0)
+	encoder := json.NewEncoder(w)
+	err := bc.IterateStates(func(s *state.State) error {
+		count++
+		if s.Balance != nil {
+			total.Add(total, s.Balance)
+		}
+		return encoder.Encode(&accountRecord{
+			Address:       s.Address,
+			Nonce:         s.Nonce,
+			Balance:       amountString(s.Balance),
+			IsCandidate:   s.IsCandidate,
+			VotingWeight:  amountString(s.VotingWeight),
+			Votee:         s.Votee,
+			LockedBalance: amountString(s.LockedBalance),
+			UnlockHeight:  s.UnlockHeight,
+		})
+	})
+	return count, total, err
+}
+
+// reportVoterRewards writes a line for each delegate whose voter rewards are kept aside until the end of the reward
+// epoch, in the order of the addresses, and returns their sum. They aren't in any account, so that the balances alone
+// fall short of the supply by them.
+func reportVoterRewards(rewards map[string]*big.Int, w io.Writer) *big.Int {
+	delegates := make([]string, 0, len(rewards))
+	for delegate := range rewards {
+		delegates = append(delegates, delegate)
+	}
+	sort.Strings(delegates)
+	pool := big.NewInt(0)
+	for _, delegate := range delegates {
+		pool.Add(pool, rewards[delegate])
+		fmt.Fprintf(w, "Voter rewards of %s not distributed yet: %s\n", delegate, rewards[delegate])
+	}
+	return pool
+}
+
+// amountString formats the amount in decimal, with 0 for a missing one
+func amountString(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
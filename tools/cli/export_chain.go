@@ -0,0 +1,51 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+func (cli *CLI) exportChain(file string, start uint64, end uint64, config *config.Config) {
+	cli.bc = blockchain.CreateBlockchain(config, nil)
+	if cli.bc == nil {
+		logger.Fatal().Msg("ERROR: Failed to open the blockchain")
+	}
+	defer cli.bc.Stop()
+
+	tipHeight, err := cli.bc.TipHeight()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to get the tip height")
+	}
+	// export up to the tip by default
+	if end == 0 || end > tipHeight {
+		end = tipHeight
+	}
+	if start > end {
+		logger.Fatal().Uint64("start", start).Uint64("end", end).Msg("ERROR: Height range is empty")
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to create the export file")
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if err := blockchain.ExportBlocks(cli.bc, w, start, end); err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to export the blocks")
+	}
+	if err := w.Flush(); err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to write the export file")
+	}
+	fmt.Printf("Exported blocks %d to %d into '%s'\n", start, end, file)
+}
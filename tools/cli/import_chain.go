@@ -0,0 +1,38 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+func (cli *CLI) importChain(file string, config *config.Config) {
+	f, err := os.Open(file)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to open the import file")
+	}
+	defer f.Close()
+
+	cli.bc = blockchain.CreateBlockchain(config, nil)
+	if cli.bc == nil {
+		logger.Fatal().Msg("ERROR: Failed to open the blockchain")
+	}
+	defer cli.bc.Stop()
+
+	imported, err := blockchain.ImportBlocks(cli.bc, bufio.NewReader(f))
+	if err != nil {
+		logger.Fatal().Err(err).Int("imported", imported).Msg("ERROR: Failed to import the blocks")
+	}
+	tipHeight, _ := cli.bc.TipHeight()
+	fmt.Printf("Imported %d blocks from '%s', tip height %d\n", imported, file, tipHeight)
+}
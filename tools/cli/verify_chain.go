@@ -0,0 +1,30 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iotexproject/iotex-core/blockchain"
+	"github.com/iotexproject/iotex-core/config"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+func (cli *CLI) verifyChain(config *config.Config) {
+	report, err := blockchain.VerifyChain(config)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("ERROR: Failed to verify the blockchain")
+	}
+	for _, inconsistency := range report {
+		fmt.Println(inconsistency)
+	}
+	fmt.Printf("Found %d inconsistencies in '%s' and '%s'\n", len(report), config.Chain.ChainDBPath, config.Chain.TrieDBPath)
+	if len(report) > 0 {
+		os.Exit(1)
+	}
+}
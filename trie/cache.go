@@ -0,0 +1,116 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package trie
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/iotexproject/iotex-core/common"
+)
+
+// defaultNodeCacheSize is the number of the decoded nodes kept in the cache of a trie
+const defaultNodeCacheSize = 4096
+
+type (
+	// CacheStats is a snapshot of the counters of the node cache of a trie
+	CacheStats struct {
+		Hits   uint64
+		Misses uint64
+	}
+
+	// nodeCache is an LRU cache of the decoded patricia nodes, keyed by their hashes. The nodes are copied in and out,
+	// as the trie updates the nodes on the path in place.
+	nodeCache struct {
+		mutex  sync.Mutex
+		size   int
+		lru    *list.List
+		nodes  map[common.Hash32B]*list.Element
+		hits   uint64
+		misses uint64
+	}
+
+	// cacheEntry is an element of the LRU list
+	cacheEntry struct {
+		key  common.Hash32B
+		node patricia
+	}
+)
+
+// newNodeCache creates a node cache holding up to size nodes, or caching nothing if the size is 0
+func newNodeCache(size int) *nodeCache {
+	return &nodeCache{size: size, lru: list.New(), nodes: make(map[common.Hash32B]*list.Element)}
+}
+
+// get returns a copy of the node of the hash, and whether it is in the cache
+func (c *nodeCache) get(key common.Hash32B) (patricia, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.nodes[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	c.lru.MoveToFront(e)
+	return clonePatricia(e.Value.(*cacheEntry).node), true
+}
+
+// put keeps a copy of the node, and evicts the least recently used one if the cache is full
+func (c *nodeCache) put(key common.Hash32B, node patricia) {
+	if c.size == 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.nodes[key]; ok {
+		e.Value.(*cacheEntry).node = clonePatricia(node)
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.nodes[key] = c.lru.PushFront(&cacheEntry{key, clonePatricia(node)})
+	if c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.nodes, e.Value.(*cacheEntry).key)
+	}
+}
+
+// remove drops the node of the hash
+func (c *nodeCache) remove(key common.Hash32B) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.nodes[key]; ok {
+		c.lru.Remove(e)
+		delete(c.nodes, key)
+	}
+}
+
+// stats returns a snapshot of the hit and miss counters
+func (c *nodeCache) stats() CacheStats {
+	return CacheStats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+// clonePatricia copies the node, so that updating the copy in place doesn't change the original. The paths are copied
+// as well, as extending the path of a leaf may write into the spare capacity of its slice.
+func clonePatricia(ptr patricia) patricia {
+	switch node := ptr.(type) {
+	case *branch:
+		b := *node
+		return &b
+	case *leaf:
+		l := *node
+		l.Path = make(ptrcKey, len(node.Path))
+		copy(l.Path, node.Path)
+		return &l
+	}
+	return ptr
+}
@@ -0,0 +1,364 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package trie
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+const (
+	// defaultRetainedRoots is the number of the recent roots whose nodes are kept by default. The nodes only reachable
+	// from the earlier roots are collected in the background.
+	defaultRetainedRoots = 16
+	// defaultGCInterval is the number of the roots falling out of the retained window which kick off a collection by
+	// default
+	defaultGCInterval = 8
+)
+
+var (
+	// trieHistoryNameSpace keeps the root, the nodes put and the stale nodes of every version of the trie flushed
+	trieHistoryNameSpace = "TrieHistory"
+
+	rootPrefix   = []byte("root.")
+	stalePrefix  = []byte("stale.")
+	freshPrefix  = []byte("fresh.")
+	versionKey   = []byte("version")
+	collectedKey = []byte("collected")
+	countsKey    = []byte("counts")
+)
+
+// Option sets an optional parameter of the trie
+type Option func(*trie)
+
+// GCOption sets the number of the recent roots whose nodes are kept, and the number of the roots falling out of them
+// which kick off a collection, while 0 leaves the default
+func GCOption(retainedRoots uint64, gcInterval uint64) Option {
+	return func(t *trie) {
+		if retainedRoots > 0 {
+			t.retainedRoots = retainedRoots
+		}
+		if gcInterval > 0 {
+			t.gcInterval = gcInterval
+		}
+	}
+}
+
+// loadHistory restores the latest version flushed and the latest version collected, which are 0 for a new trie DB, and
+// then the root of the latest version
+func (t *trie) loadHistory() error {
+	for _, record := range []struct {
+		key   []byte
+		value *uint64
+	}{{versionKey, &t.version}, {collectedKey, &t.collected}} {
+		value, err := t.dao.Get(trieHistoryNameSpace, record.key)
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s of the trie history", record.key)
+		}
+		*record.value = common.MachineEndian.Uint64(value)
+	}
+	if t.version == 0 {
+		return nil
+	}
+	if err := t.loadRoots(); err != nil {
+		return err
+	}
+	return t.loadRoot()
+}
+
+// loadRoots indexes the roots of the versions retained by their hashes
+func (t *trie) loadRoots() error {
+	for version := t.collected; version <= t.version; version++ {
+		if version == 0 {
+			continue
+		}
+		key := append(append([]byte{}, rootPrefix...), utils.Uint64ToBytes(version)...)
+		value, err := t.dao.Get(trieHistoryNameSpace, key)
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the root of version %d", version)
+		}
+		t.retainRoot(value, version)
+	}
+	return nil
+}
+
+// retainRoot indexes the root record of the version, which holds the root hash followed by the numbers of its nodes.
+// The versions flushed before the numbers are recorded with the root hold the root hash only.
+func (t *trie) retainRoot(record []byte, version uint64) {
+	if t.roots == nil {
+		t.roots = make(map[common.Hash32B]retainedRoot)
+	}
+	var root common.Hash32B
+	copy(root[:], record)
+	t.roots[root] = retainedRoot{version: version, counts: record[common.HashSize:]}
+}
+
+// setCounts sets the numbers of the entries, branches, extensions and leaves recorded, or returns false if they aren't
+func (t *trie) setCounts(counts []byte) bool {
+	if len(counts) != 4*8 {
+		return false
+	}
+	t.numEntry = common.MachineEndian.Uint64(counts[0:])
+	t.numBranch = common.MachineEndian.Uint64(counts[8:])
+	t.numExt = common.MachineEndian.Uint64(counts[16:])
+	t.numLeaf = common.MachineEndian.Uint64(counts[24:])
+	return true
+}
+
+// loadRoot restores the root of the latest version, together with the numbers of its nodes. The numbers are counted
+// over the trie if they aren't recorded, which is the case for the versions flushed before they were.
+func (t *trie) loadRoot() error {
+	key := append(append([]byte{}, rootPrefix...), utils.Uint64ToBytes(t.version)...)
+	value, err := t.dao.Get(trieHistoryNameSpace, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the root of version %d", t.version)
+	}
+	var root common.Hash32B
+	copy(root[:], value)
+	if root == EmptyRoot {
+		return nil
+	}
+	ptr, err := t.loadPatricia(root[:])
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the root of version %d", t.version)
+	}
+	t.root = ptr
+	counts, err := t.dao.Get(trieHistoryNameSpace, countsKey)
+	if err == nil && t.setCounts(counts) {
+		return nil
+	}
+	if cause := errors.Cause(err); err != nil && cause != db.ErrNotExist && cause != bolt.ErrBucketNotFound {
+		return errors.Wrap(err, "failed to get the numbers of the nodes")
+	}
+	numBranch, numExt, numLeaf, err := t.countNodes(ptr)
+	if err != nil {
+		return err
+	}
+	t.numBranch, t.numExt, t.numLeaf, t.numEntry = numBranch, numExt, numLeaf, numLeaf+1
+	return nil
+}
+
+// rootRecord returns the record of the current root, which holds the root hash followed by the numbers of its nodes
+func (t *trie) rootRecord() []byte {
+	root := t.root.hash()
+	record := make([]byte, 0, common.HashSize+4*8)
+	record = append(record, root[:]...)
+	for _, n := range []uint64{t.numEntry, t.numBranch, t.numExt, t.numLeaf} {
+		record = append(record, utils.Uint64ToBytes(n)...)
+	}
+	return record
+}
+
+// historyToFlush returns the records of the next version, with the current root and the numbers of its nodes, the
+// nodes put and the nodes turned stale since the last flush
+func (t *trie) historyToFlush() ([][]byte, [][]byte) {
+	version := utils.Uint64ToBytes(t.version + 1)
+	root := t.rootRecord()
+	counts := root[common.HashSize:]
+	// the nodes revived by a reset are live again as if they were put
+	fresh := make([]byte, 0, (len(t.dirty)+len(t.revived))*common.HashSize)
+	for key := range t.dirty {
+		fresh = append(fresh, key[:]...)
+	}
+	for _, key := range t.revived {
+		fresh = append(fresh, key[:]...)
+	}
+	keys := [][]byte{
+		append(append([]byte{}, rootPrefix...), version...),
+		append(append([]byte{}, freshPrefix...), version...),
+		versionKey,
+		countsKey,
+	}
+	values := [][]byte{root, fresh, version, counts}
+	if len(t.stale) > 0 {
+		stale := make([]byte, 0, len(t.stale)*common.HashSize)
+		for _, key := range t.stale {
+			stale = append(stale, key[:]...)
+		}
+		keys = append(keys, append(append([]byte{}, stalePrefix...), version...))
+		values = append(values, stale)
+	}
+	return keys, values
+}
+
+// kickGC wakes up the collection in the background, unless it is running already
+func (t *trie) kickGC() {
+	select {
+	case t.gcKick <- struct{}{}:
+	default:
+	}
+}
+
+// runGC collects the stale nodes each time it is kicked, until the trie is closed
+func (t *trie) runGC() {
+	defer close(t.gcDone)
+	for range t.gcKick {
+		if err := t.collect(); err != nil {
+			logger.Error().Err(err).Msg("failed to collect the stale trie nodes")
+		}
+	}
+}
+
+// collect deletes the nodes turned stale in the versions whose earlier roots have fallen out of the retained window,
+// unless they are put again by a later version. A node is only reachable from the roots of the versions from the one
+// putting it till the one turning it stale, so the nodes are collected from the records of every version, without
+// walking the retained roots or reading the nodes at all. The versions are swept one by one, each holding the trie
+// lock only briefly, so that Commit isn't blocked for long.
+func (t *trie) collect() error {
+	t.gcMutex.Lock()
+	defer t.gcMutex.Unlock()
+
+	t.mutex.RLock()
+	version, collected := t.version, t.collected
+	t.mutex.RUnlock()
+	// the nodes turned stale in a version belong to the root of the version before it
+	if version < t.retainedRoots || version-t.retainedRoots+1 < collected+t.gcInterval {
+		return nil
+	}
+	last := version - t.retainedRoots + 1
+
+	// the latest version putting each of the nodes, among the versions from the first one to sweep
+	added := &freshNodes{latest: make(map[common.Hash32B]uint64), loaded: collected}
+	deleted := 0
+	for v := collected + 1; v <= last; v++ {
+		n, err := t.sweep(v, added)
+		if err != nil {
+			return err
+		}
+		deleted += n
+	}
+	logger.Info().
+		Uint64("from", collected+1).
+		Uint64("to", last).
+		Int("deleted", deleted).
+		Msg("Collected stale trie nodes")
+	return nil
+}
+
+// freshNodes tracks the latest version putting each of the nodes, from the records of the versions loaded
+type freshNodes struct {
+	latest map[common.Hash32B]uint64
+	loaded uint64 // the versions up to which the records are loaded
+	// the latest version without the record, which is flushed before the nodes put are recorded. The nodes turned
+	// stale up to it are kept, as they may be put again without a record.
+	unknown uint64
+}
+
+// loadFresh adds the nodes put by the versions flushed since the last load
+func (t *trie) loadFresh(added *freshNodes) error {
+	for v := added.loaded + 1; v <= t.version; v++ {
+		fresh, err := t.dao.Get(trieHistoryNameSpace, append(append([]byte{}, freshPrefix...), utils.Uint64ToBytes(v)...))
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			added.unknown = v
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the nodes put by version %d", v)
+		}
+		for i := 0; i+common.HashSize <= len(fresh); i += common.HashSize {
+			var hash common.Hash32B
+			copy(hash[:], fresh[i:i+common.HashSize])
+			added.latest[hash] = v
+		}
+	}
+	added.loaded = t.version
+	return nil
+}
+
+// sweep deletes the nodes turned stale in the version which are neither put again since then nor put since the last
+// flush, and drops the records of the version and the root of the version before it. It returns the number of the
+// nodes deleted.
+func (t *trie) sweep(version uint64, added *freshNodes) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// the versions flushed in the meantime may put the nodes again
+	if err := t.loadFresh(added); err != nil {
+		return 0, err
+	}
+	suffix := utils.Uint64ToBytes(version)
+	staleKey := append(append([]byte{}, stalePrefix...), suffix...)
+	freshKey := append(append([]byte{}, freshPrefix...), suffix...)
+	rootKey := append(append([]byte{}, rootPrefix...), utils.Uint64ToBytes(version-1)...)
+	stale, err := t.dao.Get(trieHistoryNameSpace, staleKey)
+	if cause := errors.Cause(err); err != nil && cause != db.ErrNotExist && cause != bolt.ErrBucketNotFound {
+		return 0, errors.Wrapf(err, "failed to get the stale nodes of version %d", version)
+	}
+	var keys [][]byte
+	for i := 0; version > added.unknown && i+common.HashSize <= len(stale); i += common.HashSize {
+		var hash common.Hash32B
+		copy(hash[:], stale[i:i+common.HashSize])
+		if added.latest[hash] >= version {
+			continue
+		}
+		if _, ok := t.dirty[hash]; ok {
+			continue
+		}
+		keys = append(keys, hash[:])
+		t.cache.remove(hash)
+	}
+	if len(keys) > 0 {
+		if err := t.dao.BatchDelete(t.bucket, keys); err != nil && errors.Cause(err) != bolt.ErrBucketNotFound {
+			return 0, errors.Wrapf(err, "failed to delete the stale nodes of version %d", version)
+		}
+	}
+	if err := t.dao.BatchDelete(trieHistoryNameSpace, [][]byte{rootKey, staleKey, freshKey}); err != nil {
+		return 0, errors.Wrapf(err, "failed to delete the history of version %d", version)
+	}
+	if err := t.dao.Put(trieHistoryNameSpace, collectedKey, suffix); err != nil {
+		return 0, errors.Wrapf(err, "failed to put the version collected")
+	}
+	t.collected = version
+	for root, retained := range t.roots {
+		if retained.version < version {
+			delete(t.roots, root)
+		}
+	}
+	return len(keys), nil
+}
+
+// mark adds the nodes reachable from the node of the key to the set. The nodes are read from DB without going through
+// the cache, so that walking the trie doesn't evict the nodes in use.
+func (t *trie) mark(key []byte, reachable map[common.Hash32B]bool) error {
+	var hash common.Hash32B
+	copy(hash[:], key)
+	if hash == EmptyRoot || reachable[hash] {
+		return nil
+	}
+	ptr, err := t.readPatricia(key)
+	if err != nil {
+		return err
+	}
+	reachable[hash] = true
+	switch node := ptr.(type) {
+	case *branch:
+		for i := 0; i < RADIX; i++ {
+			if len(node.Path[i]) == 0 {
+				continue
+			}
+			if err := t.mark(node.Path[i], reachable); err != nil {
+				return err
+			}
+		}
+	case *leaf:
+		if node.Ext == 1 {
+			return t.mark(node.Value, reachable)
+		}
+	}
+	return nil
+}
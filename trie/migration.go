@@ -0,0 +1,176 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package trie
+
+import (
+	"bytes"
+	"container/list"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/db"
+	"github.com/iotexproject/iotex-core/logger"
+)
+
+// ValueMigrationOption sets the function re-encoding the value of a key kept in the encoding of the earlier schema
+// versions, which the migrations changing the encoding of the values run on every entry of the trie
+func ValueMigrationOption(migrateValue func([]byte, []byte) ([]byte, error)) Option {
+	return func(t *trie) {
+		t.migrateValue = migrateValue
+	}
+}
+
+// valueMigration returns the function re-encoding the values set by the options
+func valueMigration(opts []Option) func([]byte, []byte) ([]byte, error) {
+	t := &trie{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t.migrateValue
+}
+
+// trieMigrations returns the migrations of the trie DB schema in the order of their versions, which re-encode the values
+// with the given function. A migration is appended for every change to the trie nodes or the values kept in them.
+func trieMigrations(migrateValue func([]byte, []byte) ([]byte, error)) []db.Migration {
+	return []db.Migration{
+		{
+			Version:     1,
+			Description: "version the trie DB schema",
+			Migrate:     func(db.KVStore) error { return nil },
+		},
+		{
+			Version:     2,
+			Description: "re-encode the gob encoded states in the trie nodes",
+			Migrate: func(kvStore db.KVStore) error {
+				return migrateValues(kvStore, migrateValue)
+			},
+		},
+		{
+			// the nodes kept by the earlier versions are all live, as the replaced ones were deleted at once
+			Version:     3,
+			Description: "keep the nodes of the recent roots and collect the stale ones in the background",
+			Migrate:     func(db.KVStore) error { return nil },
+		},
+	}
+}
+
+// migrateValues re-encodes the values kept in the trie DB, and rebuilds the trie over them as the node hashes change
+// with the values. The root isn't recorded before the trie history is, so it is the node which no other node refers to.
+// The new nodes are written before the old ones are deleted. If the migration is interrupted in between, it runs again
+// over both roots, which hold the same entries once re-encoded.
+func migrateValues(kvStore db.KVStore, migrateValue func([]byte, []byte) ([]byte, error)) error {
+	nodes := make(map[common.Hash32B]patricia)
+	referred := make(map[common.Hash32B]bool)
+	refer := func(key []byte) {
+		var hash common.Hash32B
+		copy(hash[:], key)
+		referred[hash] = true
+	}
+	if err := kvStore.Iterate(trieKVNameSpace, func(key, value []byte) error {
+		ptr, err := decodePatricia(value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode node %x", key)
+		}
+		var hash common.Hash32B
+		copy(hash[:], key)
+		nodes[hash] = ptr
+		switch node := ptr.(type) {
+		case *branch:
+			for i := 0; i < RADIX; i++ {
+				if len(node.Path[i]) > 0 {
+					refer(node.Path[i])
+				}
+			}
+		case *leaf:
+			if node.Ext == 1 {
+				refer(node.Value)
+			}
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "failed to read the trie nodes")
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	if migrateValue == nil {
+		return errors.Wrapf(ErrInvalidTrie, "no value migration for the %d trie nodes kept", len(nodes))
+	}
+
+	// collect the entries under the roots, re-encoded
+	old := &trie{dao: kvStore, bucket: trieKVNameSpace, cache: newNodeCache(defaultNodeCacheSize)}
+	entries := make(map[string][]byte)
+	for hash, ptr := range nodes {
+		if referred[hash] {
+			continue
+		}
+		if err := old.iterate(ptr, nil, func(key, value []byte) error {
+			migrated, err := migrateValue(key, value)
+			if err != nil {
+				return errors.Wrapf(err, "failed to re-encode the value of key %x", key)
+			}
+			if prev, ok := entries[string(key)]; ok && !bytes.Equal(prev, migrated) {
+				return errors.Wrapf(ErrInvalidTrie, "roots disagree on the value of key %x", key)
+			}
+			entries[string(key)] = migrated
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	// rebuild the trie over the entries, and record it as the first version of the trie history
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	t := &trie{
+		dao:       kvStore,
+		root:      &branch{},
+		toRoot:    list.New(),
+		cache:     newNodeCache(defaultNodeCacheSize),
+		dirty:     make(map[common.Hash32B]patricia),
+		bucket:    trieKVNameSpace,
+		numEntry:  1,
+		numBranch: 1,
+	}
+	for _, key := range keys {
+		if err := t.upsert([]byte(key), entries[key]); err != nil {
+			return errors.Wrapf(err, "failed to put key %x", key)
+		}
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	// delete the old nodes
+	root := t.root.hash()
+	reachable := make(map[common.Hash32B]bool)
+	if err := t.mark(root[:], reachable); err != nil {
+		return err
+	}
+	var stale [][]byte
+	for hash := range nodes {
+		if !reachable[hash] {
+			stale = append(stale, append([]byte{}, hash[:]...))
+		}
+	}
+	if len(stale) > 0 {
+		if err := kvStore.BatchDelete(trieKVNameSpace, stale); err != nil {
+			return errors.Wrapf(err, "failed to delete %d old nodes", len(stale))
+		}
+	}
+	logger.Info().
+		Int("entries", len(entries)).
+		Int("deleted", len(stale)).
+		Hex("root", root[:]).
+		Msg("Re-encoded the values in the trie")
+	return nil
+}
@@ -9,7 +9,9 @@ package trie
 import (
 	"container/list"
 	"sync"
+	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/pkg/errors"
 
 	"github.com/iotexproject/iotex-core/common"
@@ -17,6 +19,9 @@ import (
 	"github.com/iotexproject/iotex-core/logger"
 )
 
+// trieDBOpenTimeout is how long to wait for the lock of the trie DB file, which the running node holds
+const trieDBOpenTimeout = time.Second
+
 var (
 	trieKVNameSpace = "Trie"
 
@@ -25,23 +30,37 @@ var (
 
 	// ErrNotExist indicates entry does not exist
 	ErrNotExist = errors.New("not exist in trie")
+
+	// ErrNotRetained indicates the root is neither the current one nor one of the recent ones retained
+	ErrNotRetained = errors.New("root not retained in trie")
 )
 
 var (
-	// emptyRoot is the root hash of an empty trie
-	emptyRoot = common.Hash32B{0xe, 0x57, 0x51, 0xc0, 0x26, 0xe5, 0x43, 0xb2, 0xe8, 0xab, 0x2e, 0xb0, 0x60, 0x99,
+	// EmptyRoot is the root hash of an empty trie
+	EmptyRoot = common.Hash32B{0xe, 0x57, 0x51, 0xc0, 0x26, 0xe5, 0x43, 0xb2, 0xe8, 0xab, 0x2e, 0xb0, 0x60, 0x99,
 		0xda, 0xa1, 0xd1, 0xe5, 0xdf, 0x47, 0x77, 0x8f, 0x77, 0x87, 0xfa, 0xab, 0x45, 0xcd, 0xf1, 0x2f, 0xe3, 0xa8}
 )
 
 type (
 	// Trie is the interface of Merkle Patricia Trie
 	Trie interface {
-		Upsert([]byte, []byte) error     // insert a new entry
-		Get([]byte) ([]byte, error)      // retrieve an existing entry
-		Delete([]byte) error             // delete an entry
-		Commit([][]byte, [][]byte) error // commit the state changes in a batch
-		Close() error                    // close the trie DB
-		RootHash() common.Hash32B        // returns trie's root hash
+		Upsert([]byte, []byte) error              // insert a new entry
+		Get([]byte) ([]byte, error)               // retrieve an existing entry
+		Delete([]byte) error                      // delete an entry
+		Commit([][]byte, [][]byte) error          // commit the state changes in a batch
+		Close() error                             // close the trie DB
+		RootHash() common.Hash32B                 // returns trie's root hash
+		Iterate(func([]byte, []byte) error) error // iterate over all the entries
+		CacheStats() CacheStats                   // returns the hit and miss counters of the node cache
+		View(common.Hash32B) (Reader, error)      // open a read-only view at a retained root
+		Reset(common.Hash32B) error               // reset the trie to a retained root
+	}
+
+	// Reader is the read-only interface of Merkle Patricia Trie at a root
+	Reader interface {
+		Get([]byte) ([]byte, error)               // retrieve an existing entry
+		RootHash() common.Hash32B                 // returns the root hash
+		Iterate(func([]byte, []byte) error) error // iterate over all the entries
 	}
 
 	// trie implements the Trie interface
@@ -49,19 +68,36 @@ type (
 		mutex     sync.RWMutex
 		dao       db.KVStore
 		root      patricia
-		toRoot    *list.List // stores the path from root to diverging node
-		bucket    string     // bucket name to store the nodes
-		clpsK     []byte     // path if the node can collapse after deleting an entry
-		clpsV     []byte     // value if the node can collapse after deleting an entry
-		numEntry  uint64     // number of entries added to the trie
+		toRoot    *list.List                  // stores the path from root to diverging node
+		cache     *nodeCache                  // caches the decoded nodes read from and written to DB
+		dirty     map[common.Hash32B]patricia // nodes to write to DB on the next flush
+		stale     []common.Hash32B            // nodes replaced since the last flush, to collect once out of the window
+		revived   []common.Hash32B            // nodes live again after a reset since the last flush
+		version   uint64                      // number of the flushes which have changed the trie
+		collected uint64                      // the versions up to which the stale nodes are collected
+		gcMutex   sync.Mutex
+		gcKick    chan struct{}
+		gcDone    chan struct{}
+		bucket    string // bucket name to store the nodes
+		clpsK     []byte // path if the node can collapse after deleting an entry
+		clpsV     []byte // value if the node can collapse after deleting an entry
+		numEntry  uint64 // number of entries added to the trie
 		numBranch uint64
 		numExt    uint64
 		numLeaf   uint64
+		// number of the recent roots whose nodes are kept, and number of the roots falling out of them which kick off
+		// a collection
+		retainedRoots uint64
+		gcInterval    uint64
+		// roots are the roots of the versions retained, by their hashes
+		roots map[common.Hash32B]retainedRoot
+		// migrateValue re-encodes the value of a key kept in the encoding of the earlier schema versions
+		migrateValue func([]byte, []byte) ([]byte, error)
 	}
 )
 
-// NewTrie creates a trie with DB filename
-func NewTrie(path string, inMem bool) (Trie, error) {
+// NewTrie creates a trie with DB filename, which starts from the root committed last if the DB holds one
+func NewTrie(path string, inMem bool, opts ...Option) (Trie, error) {
 	var kvStore db.KVStore
 	if inMem {
 		kvStore = db.NewMemKVStore()
@@ -74,11 +110,41 @@ func NewTrie(path string, inMem bool) (Trie, error) {
 	if err := kvStore.Start(); err != nil {
 		return nil, err
 	}
-	return newTrie(kvStore)
+	if err := db.Migrate(kvStore, trieMigrations(valueMigration(opts))); err != nil {
+		kvStore.Stop()
+		return nil, err
+	}
+	return newTrie(kvStore, opts...)
+}
+
+// HasRoot checks if the trie DB at the path holds the root of the given hash and every node under it, which is expected
+// when the trie is in sync with the chain. The trie DB is opened read-only, and the node is expected to be stopped.
+func HasRoot(path string, root common.Hash32B) (bool, error) {
+	kvStore := db.NewBoltDB(path, &bolt.Options{ReadOnly: true, Timeout: trieDBOpenTimeout})
+	if err := kvStore.Start(); err != nil {
+		return false, err
+	}
+	defer kvStore.Stop()
+	_, err := kvStore.Get(trieKVNameSpace, root[:])
+	if err == nil {
+		t := &trie{dao: kvStore, bucket: trieKVNameSpace}
+		err = t.mark(root[:], map[common.Hash32B]bool{})
+	}
+	if err == nil {
+		return true, nil
+	}
+	if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+		return false, nil
+	}
+	return false, err
 }
 
-// Close close the DB
+// Close stops the collection of the stale nodes and closes the DB
 func (t *trie) Close() error {
+	if t.gcKick != nil {
+		close(t.gcKick)
+		<-t.gcDone
+	}
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
@@ -90,7 +156,10 @@ func (t *trie) Upsert(key, value []byte) error {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
-	return t.upsert(key, value)
+	if err := t.upsert(key, value); err != nil {
+		return err
+	}
+	return t.flush()
 }
 
 // Get an existing entry
@@ -99,17 +168,7 @@ func (t *trie) Get(key []byte) ([]byte, error) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
-	ptr, size, err := t.query(key)
-	t.clear()
-	if size != len(key) {
-		return nil, errors.Wrapf(ErrNotExist, "key = %x not exist", key)
-	}
-	if err != nil {
-		return nil, err
-	}
-	// retrieve the value from terminal patricia node
-	size = len(key)
-	return t.getValue(ptr, key[size-1])
+	return t.get(t.root, key)
 }
 
 // Delete an entry
@@ -154,10 +213,14 @@ func (t *trie) Delete(key []byte) error {
 		clpsType = 0
 	}
 	// update upstream nodes on path ascending to root
-	return t.updateDelete(ptr, childClps, clpsType)
+	if err := t.updateDelete(ptr, childClps, clpsType); err != nil {
+		return err
+	}
+	return t.flush()
 }
 
-// Commit writes an array <k[], v[]> as a batch
+// Commit writes an array <k[], v[]> as a batch. The nodes updated are written to DB at once after all the entries are
+// upserted. If an entry fails, the nodes updated before it are written by the next flush.
 func (t *trie) Commit(k, v [][]byte) error {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -171,7 +234,7 @@ func (t *trie) Commit(k, v [][]byte) error {
 			return err
 		}
 	}
-	return nil
+	return t.flush()
 }
 
 // RootHash returns the root hash of merkle patricia trie
@@ -182,10 +245,68 @@ func (t *trie) RootHash() common.Hash32B {
 	return t.root.hash()
 }
 
+// Iterate calls the function on every <k, v> stored in the trie, in the order of the keys, and stops at the first error
+func (t *trie) Iterate(fn func(key, value []byte) error) error {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.iterate(t.root, nil, fn)
+}
+
+// CacheStats returns the hit and miss counters of the node cache
+func (t *trie) CacheStats() CacheStats {
+	return t.cache.stats()
+}
+
 //======================================
 // private functions
 //======================================
 
+// iterate walks the sub-trie rooted at the node, whose path from the root is prefix
+func (t *trie) iterate(ptr patricia, prefix []byte, fn func(key, value []byte) error) error {
+	switch node := ptr.(type) {
+	case *branch:
+		for i := 0; i < RADIX; i++ {
+			if len(node.Path[i]) == 0 {
+				continue
+			}
+			child, err := t.getPatricia(node.Path[i])
+			if err != nil {
+				return err
+			}
+			if err := t.iterate(child, append(append([]byte{}, prefix...), byte(i)), fn); err != nil {
+				return err
+			}
+		}
+	case *leaf:
+		path := append(append([]byte{}, prefix...), node.Path...)
+		if node.Ext == 0 {
+			return fn(path, node.Value)
+		}
+		child, err := t.getPatricia(node.Value)
+		if err != nil {
+			return err
+		}
+		return t.iterate(child, path, fn)
+	}
+	return nil
+}
+
+// get retrieves an existing entry from the trie at the root
+func (t *trie) get(root patricia, key []byte) ([]byte, error) {
+	ptr, size, err := t.queryFrom(root, key)
+	t.clear()
+	if size != len(key) {
+		return nil, errors.Wrapf(ErrNotExist, "key = %x not exist", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// retrieve the value from terminal patricia node
+	size = len(key)
+	return t.getValue(ptr, key[size-1])
+}
+
 // upsert a new entry
 func (t *trie) upsert(key, value []byte) error {
 	var ptr patricia
@@ -210,8 +331,7 @@ func (t *trie) upsert(key, value []byte) error {
 				return errors.Wrapf(ErrInvalidPatricia, "cannot decode node = %v", n.Value)
 			}
 			hashChild = ptr.hash()
-			// hash of new node should NOT exist in DB
-			if err := t.putPatriciaNew(ptr); err != nil {
+			if err := t.putPatricia(ptr); err != nil {
 				return err
 			}
 			addNode.Remove(n)
@@ -222,8 +342,11 @@ func (t *trie) upsert(key, value []byte) error {
 		t.numEntry++
 		// if the diverging node is leaf, it will be replaced and no need to update
 		n := t.toRoot.Back()
-		if _, ok := n.Value.(patricia).(*leaf); ok {
+		if l, ok := n.Value.(patricia).(*leaf); ok {
 			logger.Warn().Msg("discard leaf")
+			if err := t.delPatricia(l); err != nil {
+				return err
+			}
 			t.toRoot.Remove(n)
 		}
 	} else {
@@ -263,7 +386,12 @@ func (t *trie) upsert(key, value []byte) error {
 
 // query returns the diverging patricia node, and length of matching path in bytes
 func (t *trie) query(key []byte) (patricia, int, error) {
-	ptr := t.root
+	return t.queryFrom(t.root, key)
+}
+
+// queryFrom returns the diverging patricia node of the trie at the root, and length of matching path in bytes
+func (t *trie) queryFrom(root patricia, key []byte) (patricia, int, error) {
+	ptr := root
 	size := 0
 	for len(key) > 0 {
 		// keep descending the trie
@@ -351,8 +479,8 @@ func (t *trie) updateInsert(hashChild []byte) error {
 		}
 		hashCurr = curr.hash()
 		hashChild = hashCurr[:]
-		// when adding an entry, hash of nodes along the path changes and is expected NOT to exist in DB
-		if err := t.putPatriciaNew(curr); err != nil {
+		// when adding an entry, hash of nodes along the path changes, and may be one of an earlier root kept in DB
+		if err := t.putPatricia(curr); err != nil {
 			return err
 		}
 	}
@@ -424,21 +552,74 @@ func (t *trie) updateDelete(curr patricia, currClps bool, clpsType byte) error {
 	return nil
 }
 
-//======================================
+// ======================================
 // helper functions to operate patricia
-//======================================
+// ======================================
 // newTrie creates a trie
-func newTrie(dao db.KVStore) (Trie, error) {
-	t := trie{dao: dao, root: &branch{}, toRoot: list.New(), bucket: trieKVNameSpace, numEntry: 1, numBranch: 1}
+func newTrie(dao db.KVStore, opts ...Option) (Trie, error) {
+	t := trie{
+		dao:           dao,
+		root:          &branch{},
+		toRoot:        list.New(),
+		cache:         newNodeCache(defaultNodeCacheSize),
+		dirty:         make(map[common.Hash32B]patricia),
+		gcKick:        make(chan struct{}, 1),
+		gcDone:        make(chan struct{}),
+		bucket:        trieKVNameSpace,
+		numEntry:      1,
+		numBranch:     1,
+		retainedRoots: defaultRetainedRoots,
+		gcInterval:    defaultGCInterval,
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	if err := t.loadHistory(); err != nil {
+		return nil, err
+	}
+	go t.runGC()
 	return &t, nil
 }
 
-// getPatricia retrieves the patricia node from DB according to key
+// getPatricia retrieves the patricia node according to key, from the nodes not flushed yet, the cache or DB
 func (t *trie) getPatricia(key []byte) (patricia, error) {
+	var hash common.Hash32B
+	copy(hash[:], key)
+	if ptr, ok := t.dirty[hash]; ok {
+		return clonePatricia(ptr), nil
+	}
+	return t.loadPatricia(key)
+}
+
+// loadPatricia retrieves the patricia node flushed according to key, from the cache or DB
+func (t *trie) loadPatricia(key []byte) (patricia, error) {
+	var hash common.Hash32B
+	copy(hash[:], key)
+	if ptr, ok := t.cache.get(hash); ok {
+		return ptr, nil
+	}
+	ptr, err := t.readPatricia(key)
+	if err != nil {
+		return nil, err
+	}
+	t.cache.put(hash, ptr)
+	return ptr, nil
+}
+
+// readPatricia reads the patricia node flushed according to key from DB, bypassing the cache
+func (t *trie) readPatricia(key []byte) (patricia, error) {
 	node, err := t.dao.Get(t.bucket, key)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get key %x", key[:8])
 	}
+	return decodePatricia(node)
+}
+
+// decodePatricia decodes the patricia node serialized
+func decodePatricia(node []byte) (patricia, error) {
+	if len(node) == 0 {
+		return nil, errors.Wrap(ErrInvalidPatricia, "empty node")
+	}
 	var ptr patricia
 	// first byte of serialized data is type
 	switch node[0] {
@@ -457,43 +638,78 @@ func (t *trie) getPatricia(key []byte) (patricia, error) {
 	return ptr, nil
 }
 
-// putPatricia stores the patricia node into DB
+// putPatricia stores the patricia node on the next flush
 // the node may already exist in DB
 func (t *trie) putPatricia(ptr patricia) error {
-	value, err := ptr.serialize()
-	if err != nil {
-		return errors.Wrapf(err, "failed to encode node")
-	}
 	key := ptr.hash()
-	if err := t.dao.Put(t.bucket, key[:], value); err != nil {
-		return errors.Wrapf(err, "failed to put key = %x", key[:8])
-	}
+	t.dirty[key] = clonePatricia(ptr)
+	t.cache.put(key, ptr)
 	logger.Debug().Hex("key", key[:8]).Msg("put")
 	return nil
 }
 
-// putPatriciaNew stores a new patricia node into DB
-// it is expected the node does not exist yet, will return error if already exist
-func (t *trie) putPatriciaNew(ptr patricia) error {
-	value, err := ptr.serialize()
-	if err != nil {
-		return err
-	}
+// delPatricia marks the patricia node replaced, which is deleted from DB once no retained root reaches it. A node put
+// since the last flush is dropped instead, so that only the nodes reachable from the root are written on the flush.
+func (t *trie) delPatricia(ptr patricia) error {
 	key := ptr.hash()
-	if err := t.dao.PutIfNotExists(t.bucket, key[:], value); err != nil {
-		return errors.Wrapf(err, "failed to put non-existing key = %x", key[:8])
+	if _, ok := t.dirty[key]; ok {
+		delete(t.dirty, key)
+		t.cache.remove(key)
+		logger.Debug().Hex("key", key[:8]).Msg("drop")
+		return nil
 	}
-	logger.Debug().Hex("key", key[:8]).Msg("putnew")
+	t.stale = append(t.stale, key)
+	logger.Debug().Hex("key", key[:8]).Msg("del")
 	return nil
 }
 
-// delPatricia deletes the patricia node from DB
-func (t *trie) delPatricia(ptr patricia) error {
-	key := ptr.hash()
-	if err := t.dao.Delete(t.bucket, key[:]); err != nil {
-		return errors.Wrapf(err, "failed to delete key = %x", key[:8])
+// flush writes the nodes put since the last flush to DB in a batch, and records the new version of the trie with its
+// root and the nodes turned stale, unless nothing has changed
+func (t *trie) flush() error {
+	if len(t.dirty) == 0 && len(t.stale) == 0 {
+		return nil
 	}
-	logger.Debug().Hex("key", key[:8]).Msg("del")
+	return t.writeVersion()
+}
+
+// writeVersion writes the nodes put since the last flush, and records the new version of the trie. The nodes replaced
+// before the flush are dropped from the ones put already, so that only the nodes reachable from the root are written.
+func (t *trie) writeVersion() error {
+	var keys, values [][]byte
+	for key, ptr := range t.dirty {
+		k := make([]byte, len(key))
+		copy(k, key[:])
+		value, err := ptr.serialize()
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode node")
+		}
+		keys = append(keys, k)
+		values = append(values, value)
+	}
+	if len(keys) > 0 {
+		if err := t.dao.BatchPut(t.bucket, keys, values); err != nil {
+			return errors.Wrapf(err, "failed to put %d nodes", len(keys))
+		}
+	}
+	historyKeys, historyValues := t.historyToFlush()
+	if err := t.dao.BatchPut(trieHistoryNameSpace, historyKeys, historyValues); err != nil {
+		return errors.Wrapf(err, "failed to put version %d of the trie history", t.version+1)
+	}
+	t.version++
+	t.retainRoot(t.rootRecord(), t.version)
+	t.dirty = make(map[common.Hash32B]patricia)
+	stale := len(t.stale)
+	t.stale = nil
+	t.revived = nil
+	stats := t.cache.stats()
+	logger.Debug().
+		Uint64("version", t.version).
+		Int("put", len(keys)).
+		Int("stale", stale).
+		Uint64("cache-hits", stats.Hits).
+		Uint64("cache-misses", stats.Misses).
+		Msg("flushed trie nodes")
+	t.kickGC()
 	return nil
 }
 
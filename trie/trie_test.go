@@ -7,14 +7,20 @@
 package trie
 
 import (
+	"bytes"
 	"container/list"
+	"fmt"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/blake2b"
 
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
 	"github.com/iotexproject/iotex-core/db"
 	"github.com/iotexproject/iotex-core/logger"
 	"github.com/iotexproject/iotex-core/test/util"
@@ -29,7 +35,7 @@ func TestEmptyTrie(t *testing.T) {
 	defer util.CleanupPath(t, testTriePath)
 	tr, err := NewTrie(testTriePath, true)
 	assert.Nil(err)
-	assert.Equal(tr.RootHash(), emptyRoot)
+	assert.Equal(tr.RootHash(), EmptyRoot)
 	assert.Nil(tr.Close())
 }
 
@@ -38,8 +44,16 @@ func TestInsert(t *testing.T) {
 	l := logger.Logger().Level(zerolog.DebugLevel)
 	logger.SetLogger(&l)
 
-	tr := trie{dao: db.NewMemKVStore(), root: &branch{}, toRoot: list.New(), numEntry: 1, numBranch: 1}
-	root := emptyRoot
+	tr := trie{
+		dao:       db.NewMemKVStore(),
+		root:      &branch{},
+		toRoot:    list.New(),
+		cache:     newNodeCache(defaultNodeCacheSize),
+		dirty:     make(map[common.Hash32B]patricia),
+		numEntry:  1,
+		numBranch: 1,
+	}
+	root := EmptyRoot
 	assert.Equal(uint64(1), tr.numBranch)
 	// query non-existing entry
 	ptr, match, err := tr.query(cat)
@@ -226,7 +240,7 @@ func TestInsert(t *testing.T) {
 	logger.Info().Msg("Del[cat]")
 	err = tr.Delete(cat)
 	assert.Nil(err)
-	assert.Equal(emptyRoot, tr.RootHash())
+	assert.Equal(EmptyRoot, tr.RootHash())
 	assert.Equal(uint64(1), tr.numEntry)
 }
 
@@ -239,7 +253,7 @@ func Test1kEntries(t *testing.T) {
 	defer util.CleanupPath(t, testTriePath)
 	tr, err := NewTrie(testTriePath, false)
 	assert.Nil(err)
-	root := emptyRoot
+	root := EmptyRoot
 	seed := time.Now().Nanosecond()
 	// insert 64k entries
 	var k [32]byte
@@ -254,7 +268,7 @@ func Test1kEntries(t *testing.T) {
 		err := tr.Upsert(k[:8], v)
 		assert.Nil(err)
 		newRoot := tr.RootHash()
-		assert.NotEqual(newRoot, emptyRoot)
+		assert.NotEqual(newRoot, EmptyRoot)
 		assert.NotEqual(newRoot, root)
 		root = newRoot
 		b, err := tr.Get(k[:8])
@@ -285,7 +299,7 @@ func Test1kEntries(t *testing.T) {
 	assert.Nil(tr.Delete(d2[:8]))
 	assert.Nil(tr.Delete(d3[:8]))
 	// trie should fallback to empty
-	assert.Equal(emptyRoot, tr.RootHash())
+	assert.Equal(EmptyRoot, tr.RootHash())
 }
 
 func TestPressure(t *testing.T) {
@@ -301,7 +315,7 @@ func TestPressure(t *testing.T) {
 	defer util.CleanupPath(t, testTriePath)
 	tr, err := NewTrie(testTriePath, true)
 	assert.Nil(err)
-	root := emptyRoot
+	root := EmptyRoot
 	seed := time.Now().Nanosecond()
 	// insert 64k entries
 	var k [32]byte
@@ -316,7 +330,7 @@ func TestPressure(t *testing.T) {
 		err := tr.Upsert(k[:8], v)
 		assert.Nil(err)
 		newRoot := tr.RootHash()
-		assert.NotEqual(newRoot, emptyRoot)
+		assert.NotEqual(newRoot, EmptyRoot)
 		assert.NotEqual(newRoot, root)
 		root = newRoot
 		b, err := tr.Get(k[:8])
@@ -340,7 +354,7 @@ func TestPressure(t *testing.T) {
 	assert.Nil(tr.Delete(d2[:8]))
 	assert.Nil(tr.Delete(d3[:8]))
 	// trie should fallback to empty
-	assert.Equal(emptyRoot, tr.RootHash())
+	assert.Equal(EmptyRoot, tr.RootHash())
 }
 
 func TestQuery(t *testing.T) {
@@ -348,7 +362,15 @@ func TestQuery(t *testing.T) {
 	l := logger.Logger().Level(zerolog.DebugLevel)
 	logger.SetLogger(&l)
 
-	tr := trie{dao: db.NewMemKVStore(), root: &branch{}, toRoot: list.New(), numEntry: 1, numBranch: 1}
+	tr := trie{
+		dao:       db.NewMemKVStore(),
+		root:      &branch{},
+		toRoot:    list.New(),
+		cache:     newNodeCache(defaultNodeCacheSize),
+		dirty:     make(map[common.Hash32B]patricia),
+		numEntry:  1,
+		numBranch: 1,
+	}
 	assert.Equal(uint64(1), tr.numBranch)
 	// key length > 0
 	ptr, match, err := tr.query(cat)
@@ -361,3 +383,787 @@ func TestQuery(t *testing.T) {
 	assert.Equal(0, match)
 	assert.Nil(err)
 }
+
+func TestIterate(t *testing.T) {
+	assert := assert.New(t)
+
+	tr, err := NewTrie("", true)
+	assert.Nil(err)
+	assert.Nil(tr.Iterate(func(k, v []byte) error {
+		assert.Fail("empty trie has no entry")
+		return nil
+	}))
+	entries := map[string][]byte{}
+	var k [32]byte
+	for i := 0; i < 100; i++ {
+		k = blake2b.Sum256(k[:])
+		v := testV[k[0]&7]
+		assert.Nil(tr.Upsert(k[:20], v))
+		entries[string(k[:20])] = v
+	}
+
+	// all the entries are visited in the order of the keys, and a trie rebuilt from them has the same root
+	copied, err := NewTrie("", true)
+	assert.Nil(err)
+	var last []byte
+	assert.Nil(tr.Iterate(func(k, v []byte) error {
+		assert.Equal(entries[string(k)], v)
+		assert.True(bytes.Compare(last, k) < 0)
+		last = k
+		delete(entries, string(k))
+		return copied.Upsert(k, v)
+	}))
+	assert.Empty(entries)
+	assert.Equal(tr.RootHash(), copied.RootHash())
+
+	// the iteration stops at the first error
+	stop := errors.New("stop")
+	count := 0
+	assert.Equal(stop, tr.Iterate(func(k, v []byte) error {
+		count++
+		return stop
+	}))
+	assert.Equal(1, count)
+}
+
+func TestNewTrie_Migrate(t *testing.T) {
+	assert := assert.New(t)
+
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	var keys, legacy, current [][]byte
+	var k [32]byte
+	for i := 0; i < 20; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		legacy = append(legacy, []byte(fmt.Sprintf("legacy-%d", i)))
+		current = append(current, []byte(fmt.Sprintf("current-%d", i)))
+	}
+	migrate := func(_ []byte, value []byte) ([]byte, error) {
+		return bytes.Replace(value, []byte("legacy"), []byte("current"), 1), nil
+	}
+	writeLegacy := func() {
+		// the trie DB written before the schema is versioned keeps the nodes without the root
+		kvStore := db.NewBoltDB(testTriePath, nil)
+		assert.Nil(kvStore.Start())
+		tr := &trie{
+			dao:       kvStore,
+			root:      &branch{},
+			toRoot:    list.New(),
+			cache:     newNodeCache(defaultNodeCacheSize),
+			dirty:     make(map[common.Hash32B]patricia),
+			bucket:    trieKVNameSpace,
+			numEntry:  1,
+			numBranch: 1,
+		}
+		assert.Nil(tr.Commit(keys, legacy))
+		assert.Nil(kvStore.DeleteNamespace(trieHistoryNameSpace))
+		assert.Nil(kvStore.Stop())
+	}
+
+	// the node refuses to drop the nodes whose values it can't re-encode
+	writeLegacy()
+	_, err := NewTrie(testTriePath, false)
+	assert.Equal(ErrInvalidTrie, errors.Cause(err))
+
+	// the values are re-encoded in place, even if an earlier migration is interrupted after writing the new nodes
+	util.CleanupPath(t, testTriePath)
+	writeLegacy()
+	created, err := newTrie(db.NewMemKVStore())
+	assert.Nil(err)
+	expected := created.(*trie)
+	defer expected.Close()
+	assert.Nil(expected.Commit(keys, current))
+	kvStore := db.NewBoltDB(testTriePath, nil)
+	assert.Nil(kvStore.Start())
+	expectedNodes := 0
+	assert.Nil(expected.dao.Iterate(trieKVNameSpace, func(key, value []byte) error {
+		expectedNodes++
+		return kvStore.Put(trieKVNameSpace, key, value)
+	}))
+	assert.Nil(kvStore.Stop())
+
+	tr, err := NewTrie(testTriePath, false, ValueMigrationOption(migrate))
+	assert.Nil(err)
+	assert.Equal(expected.RootHash(), tr.RootHash())
+	for i, key := range keys {
+		value, err := tr.Get(key)
+		assert.Nil(err)
+		assert.Equal(current[i], value)
+	}
+	assert.Nil(tr.Close())
+	ok, err := HasRoot(testTriePath, expected.RootHash())
+	assert.Nil(err)
+	assert.True(ok)
+
+	// only the nodes of the new root are left
+	kvStore = db.NewBoltDB(testTriePath, nil)
+	assert.Nil(kvStore.Start())
+	nodes := 0
+	assert.Nil(kvStore.Iterate(trieKVNameSpace, func([]byte, []byte) error {
+		nodes++
+		return nil
+	}))
+	assert.Equal(expectedNodes, nodes)
+	version, err := db.SchemaVersion(kvStore)
+	assert.Nil(err)
+	migrations := trieMigrations(nil)
+	assert.Equal(migrations[len(migrations)-1].Version, version)
+	assert.Nil(kvStore.Stop())
+}
+
+func TestNewTrie_Reopen(t *testing.T) {
+	assert := assert.New(t)
+
+	util.CleanupPath(t, testTriePath)
+	defer util.CleanupPath(t, testTriePath)
+	tr, err := NewTrie(testTriePath, false)
+	assert.Nil(err)
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 20; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys, values))
+	assert.Nil(tr.Delete(keys[0]))
+	root := tr.RootHash()
+	counts := func(tr Trie) []uint64 {
+		t := tr.(*trie)
+		return []uint64{t.numEntry, t.numBranch, t.numExt, t.numLeaf}
+	}
+	expected := counts(tr)
+	assert.Nil(tr.Close())
+
+	// the trie reopened starts from the root committed last
+	tr, err = NewTrie(testTriePath, false)
+	assert.Nil(err)
+	assert.Equal(root, tr.RootHash())
+	assert.Equal(expected, counts(tr))
+	_, err = tr.Get(keys[0])
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	for i := 1; i < len(keys); i++ {
+		v, err := tr.Get(keys[i])
+		assert.Nil(err)
+		assert.Equal(values[i], v)
+	}
+	assert.Nil(tr.Close())
+
+	// the numbers of the nodes are counted if they aren't recorded
+	kvStore := db.NewBoltDB(testTriePath, nil)
+	assert.Nil(kvStore.Start())
+	assert.Nil(kvStore.Delete(trieHistoryNameSpace, countsKey))
+	assert.Nil(kvStore.Stop())
+	tr, err = NewTrie(testTriePath, false)
+	assert.Nil(err)
+	assert.Equal(root, tr.RootHash())
+	assert.Equal(expected[0], counts(tr)[0])
+	assert.Nil(tr.Delete(keys[1]))
+	assert.Nil(tr.Close())
+
+	// the root is held only if the nodes under it are held as well
+	ok, err := HasRoot(testTriePath, root)
+	assert.Nil(err)
+	assert.True(ok)
+	kvStore = db.NewBoltDB(testTriePath, nil)
+	assert.Nil(kvStore.Start())
+	reachable := map[common.Hash32B]bool{}
+	assert.Nil((&trie{dao: kvStore, bucket: trieKVNameSpace}).mark(root[:], reachable))
+	for hash := range reachable {
+		if hash != root {
+			assert.Nil(kvStore.Delete(trieKVNameSpace, hash[:]))
+			break
+		}
+	}
+	assert.Nil(kvStore.Stop())
+	ok, err = HasRoot(testTriePath, root)
+	assert.Nil(err)
+	assert.False(ok)
+}
+
+func TestCommit_Flush(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := newNodeCountingStore()
+	created, err := newTrie(kvStore)
+	assert.Nil(err)
+	tr := created.(*trie)
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 100; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys, values))
+	root := tr.RootHash()
+	// the nodes are written to DB once the batch is committed, leaving out the ones replaced within the batch
+	assert.Equal(0, len(tr.dirty))
+	_, err = kvStore.Get(trieKVNameSpace, root[:])
+	assert.Nil(err)
+	reachable := map[common.Hash32B]bool{}
+	assert.Nil(tr.mark(root[:], reachable))
+	assert.Equal(len(reachable), len(kvStore.nodes))
+
+	// the entries are read back from DB once the cache is dropped, and from the cache afterwards
+	tr.cache = newNodeCache(defaultNodeCacheSize)
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		assert.Nil(err)
+		assert.Equal(values[i], v)
+	}
+	misses := tr.CacheStats().Misses
+	assert.NotEqual(uint64(0), misses)
+	for _, key := range keys {
+		_, err := tr.Get(key)
+		assert.Nil(err)
+	}
+	assert.Equal(misses, tr.CacheStats().Misses)
+	assert.NotEqual(uint64(0), tr.CacheStats().Hits)
+
+	// updating the entries adds the nodes on their paths to DB, and keeps the ones of the earlier root
+	for i := range values {
+		values[i] = testV[7-keys[i][0]&7]
+	}
+	assert.Nil(tr.Commit(keys, values))
+	_, err = kvStore.Get(trieKVNameSpace, root[:])
+	assert.Nil(err)
+	newRoot := tr.RootHash()
+	_, err = kvStore.Get(trieKVNameSpace, newRoot[:])
+	assert.Nil(err)
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		assert.Nil(err)
+		assert.Equal(values[i], v)
+	}
+}
+
+func TestGC(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := db.NewMemKVStore()
+	created, err := newTrie(kvStore)
+	assert.Nil(err)
+	tr := created.(*trie)
+	// collect synchronously instead of in the background
+	close(tr.gcKick)
+	<-tr.gcDone
+	tr.gcKick = nil
+
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 50; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys, values))
+	roots := []common.Hash32B{tr.RootHash()}
+	// every version updates 5 of the entries, so that most of the nodes are shared by the versions
+	for v := 2; v <= 41; v++ {
+		for i := v % 10 * 5; i < v%10*5+5; i++ {
+			values[i] = []byte(fmt.Sprintf("value of version %d", v))
+		}
+		assert.Nil(tr.Commit(keys[v%10*5:v%10*5+5], values[v%10*5:v%10*5+5]))
+		roots = append(roots, tr.RootHash())
+	}
+	assert.Equal(uint64(41), tr.version)
+
+	// the nodes only reachable from the roots out of the retained window are deleted, without reading any node
+	stats := tr.CacheStats()
+	assert.Nil(tr.collect())
+	assert.Equal(stats, tr.CacheStats())
+	assert.Equal(uint64(41-defaultRetainedRoots+1), tr.collected)
+	for v := 1; v <= 41; v++ {
+		_, err := kvStore.Get(trieKVNameSpace, roots[v-1][:])
+		if v <= 41-defaultRetainedRoots {
+			assert.Equal(db.ErrNotExist, errors.Cause(err))
+		} else {
+			assert.Nil(err)
+		}
+	}
+	_, err = kvStore.Get(trieHistoryNameSpace, append(append([]byte{}, rootPrefix...), utils.Uint64ToBytes(1)...))
+	assert.Equal(db.ErrNotExist, errors.Cause(err))
+	_, err = kvStore.Get(trieHistoryNameSpace, append(append([]byte{}, freshPrefix...), utils.Uint64ToBytes(2)...))
+	assert.Equal(db.ErrNotExist, errors.Cause(err))
+	tr.cache = newNodeCache(defaultNodeCacheSize)
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		assert.Nil(err)
+		assert.Equal(values[i], v)
+	}
+
+	// nothing is collected until enough versions fall out of the window
+	assert.Nil(tr.Commit(keys[:1], [][]byte{testV[0]}))
+	assert.Nil(tr.collect())
+	assert.Equal(uint64(41-defaultRetainedRoots+1), tr.collected)
+
+	// the entries can be set back to the values of an earlier version, whose nodes may still be kept
+	assert.Nil(tr.Commit(keys[:1], [][]byte{values[0]}))
+	assert.Equal(roots[len(roots)-1], tr.RootHash())
+
+	// the versions are restored when the trie DB is opened again
+	reopened, err := newTrie(kvStore)
+	assert.Nil(err)
+	assert.Equal(uint64(43), reopened.(*trie).version)
+	assert.Equal(uint64(41-defaultRetainedRoots+1), reopened.(*trie).collected)
+}
+
+func TestGC_Option(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := db.NewMemKVStore()
+	created, err := newTrie(kvStore, GCOption(2, 1))
+	assert.Nil(err)
+	tr := created.(*trie)
+	close(tr.gcKick)
+	<-tr.gcDone
+	tr.gcKick = nil
+
+	key := []byte("key")
+	roots := []common.Hash32B{}
+	for i := 0; i < 4; i++ {
+		assert.Nil(tr.Upsert(key, []byte(fmt.Sprintf("value %d", i))))
+		roots = append(roots, tr.RootHash())
+	}
+	// the 2 latest roots are retained
+	assert.Nil(tr.collect())
+	assert.Equal(uint64(3), tr.collected)
+	for i, root := range roots {
+		_, err := kvStore.Get(trieKVNameSpace, root[:])
+		if i < 2 {
+			assert.Equal(db.ErrNotExist, errors.Cause(err))
+		} else {
+			assert.Nil(err)
+		}
+	}
+
+	// the nodes put by the versions flushed without the records are kept
+	assert.Nil(tr.Upsert(key, []byte("value 4")))
+	assert.Nil(kvStore.Delete(trieHistoryNameSpace, append(append([]byte{}, freshPrefix...), utils.Uint64ToBytes(5)...)))
+	assert.Nil(tr.collect())
+	assert.Equal(uint64(4), tr.collected)
+	_, err = kvStore.Get(trieKVNameSpace, roots[2][:])
+	assert.Nil(err)
+}
+
+func TestGC_NoLeak(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := newNodeCountingStore()
+	created, err := newTrie(kvStore, GCOption(1, 1))
+	assert.Nil(err)
+	tr := created.(*trie)
+	close(tr.gcKick)
+	<-tr.gcDone
+	tr.gcKick = nil
+
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 100; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys[:50], values[:50]))
+	// once every root but the current one is collected, DB keeps exactly the nodes the current root reaches
+	requireNoLeak := func() {
+		assert.Nil(tr.collect())
+		assert.Equal(tr.version, tr.collected)
+		root := tr.RootHash()
+		reachable := map[common.Hash32B]bool{}
+		assert.Nil(tr.mark(root[:], reachable))
+		assert.Equal(len(reachable), len(kvStore.nodes))
+	}
+	requireNoLeak()
+	// updating an entry at a time
+	for v := 0; v < 50; v++ {
+		assert.Nil(tr.Commit(keys[v:v+1], [][]byte{[]byte(fmt.Sprintf("value of version %d", v))}))
+	}
+	requireNoLeak()
+	// updating 5 entries at a time, so that the nodes put early in a batch are replaced later in it
+	for v := 0; v < 50; v++ {
+		batch := make([][]byte, 5)
+		for i := range batch {
+			batch[i] = []byte(fmt.Sprintf("value of batch %d", v))
+		}
+		assert.Nil(tr.Commit(keys[v%10*5:v%10*5+5], batch))
+	}
+	requireNoLeak()
+	// inserting 5 entries at a time, which replaces the leaves the paths diverge at
+	for i := 50; i < 100; i += 5 {
+		assert.Nil(tr.Commit(keys[i:i+5], values[i:i+5]))
+	}
+	requireNoLeak()
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		assert.Nil(err)
+		if i >= 50 {
+			assert.Equal(values[i], v)
+		}
+	}
+}
+
+func TestGC_Background(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := db.NewMemKVStore()
+	created, err := newTrie(kvStore)
+	assert.Nil(err)
+	tr := created.(*trie)
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 50; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys, values))
+	// the collection runs along with the commits, and keeps every node the live trie reaches
+	for v := 2; v <= 100; v++ {
+		i := v % 50
+		values[i] = []byte(fmt.Sprintf("value of version %d", v))
+		assert.Nil(tr.Commit(keys[i:i+1], values[i:i+1]))
+		got, err := tr.Get(keys[(v+25)%50])
+		assert.Nil(err)
+		assert.Equal(values[(v+25)%50], got)
+	}
+	assert.Nil(tr.collect())
+	tr.cache = newNodeCache(defaultNodeCacheSize)
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		assert.Nil(err)
+		assert.Equal(values[i], v)
+	}
+	assert.True(tr.collected > 0)
+	assert.Nil(tr.Close())
+}
+
+func TestView_Reset(t *testing.T) {
+	assert := assert.New(t)
+
+	created, err := newTrie(db.NewMemKVStore())
+	assert.Nil(err)
+	tr := created.(*trie)
+	close(tr.gcKick)
+	<-tr.gcDone
+	tr.gcKick = nil
+
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 21; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys[:20], values[:20]))
+	root1 := tr.RootHash()
+	assert.Nil(tr.Upsert(keys[0], []byte("updated")))
+	assert.Nil(tr.Upsert(keys[20], values[20]))
+	root3 := tr.RootHash()
+
+	// the view reads the entries at an earlier root
+	v1, err := tr.View(root1)
+	assert.Nil(err)
+	assert.Equal(root1, v1.RootHash())
+	v, err := v1.Get(keys[0])
+	assert.Nil(err)
+	assert.Equal(values[0], v)
+	_, err = v1.Get(keys[20])
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	count := 0
+	assert.Nil(v1.Iterate(func(k, v []byte) error {
+		count++
+		return nil
+	}))
+	assert.Equal(20, count)
+
+	// the view of the current root isn't changed by the later updates
+	v3, err := tr.View(root3)
+	assert.Nil(err)
+	assert.Nil(tr.Upsert(keys[1], []byte("updated")))
+	root4 := tr.RootHash()
+	assert.Equal(root3, v3.RootHash())
+	v, err = v3.Get(keys[1])
+	assert.Nil(err)
+	assert.Equal(values[1], v)
+
+	_, err = tr.View(common.Hash32B{1})
+	assert.Equal(ErrNotRetained, errors.Cause(err))
+	assert.Equal(ErrNotRetained, errors.Cause(tr.Reset(common.Hash32B{1})))
+
+	// the trie is reset to an earlier root, and goes on from it
+	assert.Nil(tr.Reset(root1))
+	assert.Equal(root1, tr.RootHash())
+	assert.Equal(uint64(21), tr.numEntry)
+	v, err = tr.Get(keys[0])
+	assert.Nil(err)
+	assert.Equal(values[0], v)
+	_, err = tr.Get(keys[20])
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	assert.Nil(tr.Upsert(keys[0], []byte("updated")))
+	assert.Nil(tr.Upsert(keys[20], values[20]))
+	assert.Equal(root3, tr.RootHash())
+	assert.Nil(tr.Delete(keys[20]))
+	assert.Equal(uint64(21), tr.numEntry)
+
+	// and back to a later one as long as it is retained
+	assert.Nil(tr.Reset(root4))
+	v, err = tr.Get(keys[1])
+	assert.Nil(err)
+	assert.Equal([]byte("updated"), v)
+
+	// the nodes only reachable from the roots reset from are collected once they fall out of the window
+	for i := 0; i < defaultRetainedRoots+defaultGCInterval; i++ {
+		assert.Nil(tr.Upsert(keys[2], []byte(fmt.Sprintf("value %d", i))))
+	}
+	assert.Nil(tr.collect())
+	_, err = tr.View(root1)
+	assert.Equal(ErrNotRetained, errors.Cause(err))
+	// the view opened earlier doesn't read the nodes collected
+	_, err = v1.Get(keys[0])
+	assert.Equal(ErrNotRetained, errors.Cause(err))
+	assert.Equal(ErrNotRetained, errors.Cause(v1.Iterate(func(k, v []byte) error { return nil })))
+	// while the view of an empty trie reaches no node to collect
+	empty, err := tr.View(EmptyRoot)
+	assert.Nil(err)
+	_, err = empty.Get(keys[0])
+	assert.Equal(ErrNotExist, errors.Cause(err))
+	_, err = tr.dao.Get(trieKVNameSpace, root1[:])
+	assert.Equal(db.ErrNotExist, errors.Cause(err))
+	tr.cache = newNodeCache(defaultNodeCacheSize)
+	for i, key := range keys {
+		v, err := tr.Get(key)
+		switch i {
+		case 0, 1:
+			assert.Equal([]byte("updated"), v)
+		case 2:
+			assert.Equal([]byte(fmt.Sprintf("value %d", defaultRetainedRoots+defaultGCInterval-1)), v)
+		default:
+			assert.Equal(values[i], v)
+		}
+		assert.Nil(err)
+	}
+}
+
+func TestReset_UndoVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	kvStore := db.NewMemKVStore()
+	created, err := newTrie(kvStore)
+	assert.Nil(err)
+	tr := created.(*trie)
+	close(tr.gcKick)
+	<-tr.gcDone
+	tr.gcKick = nil
+
+	keys := [][]byte{}
+	values := [][]byte{}
+	var k [32]byte
+	for i := 0; i < 40; i++ {
+		k = blake2b.Sum256(k[:])
+		keys = append(keys, append([]byte{}, k[:20]...))
+		values = append(values, testV[k[0]&7])
+	}
+	assert.Nil(tr.Commit(keys[:20], values[:20]))
+	roots := []common.Hash32B{tr.RootHash()}
+	for v := 0; v < 10; v++ {
+		// updating an entry and inserting another one
+		assert.Nil(tr.Commit(
+			[][]byte{keys[v], keys[20+v]},
+			[][]byte{[]byte(fmt.Sprintf("value of version %d", v)), values[20+v]},
+		))
+		roots = append(roots, tr.RootHash())
+	}
+	// the versions after a reset are undone as well
+	assert.Nil(tr.Reset(roots[5]))
+	assert.Nil(tr.Commit(keys[30:35], values[30:35]))
+
+	// undoing the versions after a root turns the same nodes stale and revives the same nodes as walking both roots
+	set := func(hashes []common.Hash32B) map[common.Hash32B]bool {
+		s := map[common.Hash32B]bool{}
+		for _, hash := range hashes {
+			s[hash] = true
+		}
+		return s
+	}
+	current := tr.RootHash()
+	for _, root := range roots {
+		_, version, err := tr.rootAt(root)
+		assert.Nil(err)
+		stale, revived, ok, err := tr.undoVersions(version)
+		assert.Nil(err)
+		assert.True(ok)
+		expectedStale, expectedRevived, err := tr.diffRoots(root, current)
+		assert.Nil(err)
+		assert.Equal(set(expectedStale), set(stale))
+		assert.Equal(set(expectedRevived), set(revived))
+	}
+
+	// the roots retained are indexed again when the trie is reopened
+	reopened, err := newTrie(kvStore)
+	assert.Nil(err)
+	assert.Equal(tr.roots, reopened.(*trie).roots)
+	// the root reset to is indexed at the version of the reset
+	assert.Equal(len(roots)+1, len(tr.roots))
+	_, version, err := tr.rootAt(roots[5])
+	assert.Nil(err)
+	assert.Equal(tr.version-1, version)
+	assert.Nil(reopened.Close())
+}
+
+func TestNodeCache(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &leaf{0, []byte{1}, []byte{1}}
+	b := &leaf{0, []byte{2}, []byte{2}}
+	c := &branch{}
+	c.Path[3] = []byte{3}
+	cache := newNodeCache(2)
+	cache.put(a.hash(), a)
+	cache.put(b.hash(), b)
+	// the least recently used node is evicted
+	_, ok := cache.get(a.hash())
+	assert.True(ok)
+	cache.put(c.hash(), c)
+	_, ok = cache.get(b.hash())
+	assert.False(ok)
+	assert.Equal(CacheStats{Hits: 1, Misses: 1}, cache.stats())
+
+	// updating a node got from the cache doesn't change the cached one
+	ptr, ok := cache.get(a.hash())
+	assert.True(ok)
+	assert.Nil(ptr.set([]byte{9}, 0))
+	ptr, ok = cache.get(a.hash())
+	assert.True(ok)
+	assert.Equal(a.hash(), ptr.hash())
+	ptr, ok = cache.get(c.hash())
+	assert.True(ok)
+	assert.Nil(ptr.ascend(make([]byte, common.HashSize), 3))
+	ptr, ok = cache.get(c.hash())
+	assert.True(ok)
+	assert.Equal(c.hash(), ptr.hash())
+
+	cache.remove(a.hash())
+	_, ok = cache.get(a.hash())
+	assert.False(ok)
+
+	// the cache of size 0 keeps nothing
+	cache = newNodeCache(0)
+	cache.put(a.hash(), a)
+	_, ok = cache.get(a.hash())
+	assert.False(ok)
+}
+
+// BenchmarkCommit updates the accounts touched by a block in a trie of 10k accounts on disk. Both cases commit the same
+// blocks, and the uncached one reads every node from DB, so that the difference is down to the node cache alone.
+func BenchmarkCommit(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("uncached-%d", size), func(b *testing.B) {
+			benchmarkCommit(b, size, false)
+		})
+		b.Run(fmt.Sprintf("cached-%d", size), func(b *testing.B) {
+			benchmarkCommit(b, size, true)
+		})
+	}
+}
+
+// nodeCountingStore is an in-memory KV store which tracks the trie nodes stored
+type nodeCountingStore struct {
+	db.KVStore
+	nodes map[string]bool
+}
+
+func newNodeCountingStore() *nodeCountingStore {
+	return &nodeCountingStore{KVStore: db.NewMemKVStore(), nodes: map[string]bool{}}
+}
+
+func (s *nodeCountingStore) Put(namespace string, key []byte, value []byte) error {
+	if namespace == trieKVNameSpace {
+		s.nodes[string(key)] = true
+	}
+	return s.KVStore.Put(namespace, key, value)
+}
+
+func (s *nodeCountingStore) BatchPut(namespace string, keys [][]byte, values [][]byte) error {
+	for _, key := range keys {
+		if namespace == trieKVNameSpace {
+			s.nodes[string(key)] = true
+		}
+	}
+	return s.KVStore.BatchPut(namespace, keys, values)
+}
+
+func (s *nodeCountingStore) Delete(namespace string, key []byte) error {
+	if namespace == trieKVNameSpace {
+		delete(s.nodes, string(key))
+	}
+	return s.KVStore.Delete(namespace, key)
+}
+
+func (s *nodeCountingStore) BatchDelete(namespace string, keys [][]byte) error {
+	for _, key := range keys {
+		if namespace == trieKVNameSpace {
+			delete(s.nodes, string(key))
+		}
+	}
+	return s.KVStore.BatchDelete(namespace, keys)
+}
+
+func benchmarkCommit(b *testing.B, blockSize int, cached bool) {
+	path := "/tmp/trie-benchmark"
+	os.Remove(path)
+	defer os.Remove(path)
+	created, err := NewTrie(path, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer created.Close()
+	tr := created.(*trie)
+
+	const numAccounts = 10000
+	keys := make([][]byte, numAccounts)
+	values := make([][]byte, numAccounts)
+	var k [32]byte
+	for i := 0; i < numAccounts; i++ {
+		k = blake2b.Sum256(k[:])
+		keys[i] = append([]byte{}, k[:20]...)
+		values[i] = append(k[:], k[:]...)
+	}
+	if err := tr.Commit(keys, values); err != nil {
+		b.Fatal(err)
+	}
+	if !cached {
+		tr.cache = newNodeCache(0)
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		blockKeys := make([][]byte, blockSize)
+		blockValues := make([][]byte, blockSize)
+		for i := 0; i < blockSize; i++ {
+			index := (n*blockSize + i) % numAccounts
+			blockKeys[i] = keys[index]
+			value := blake2b.Sum256(values[index])
+			blockValues[i] = append(value[:], byte(n))
+		}
+		if err := tr.Commit(blockKeys, blockValues); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	stats := tr.CacheStats()
+	if cached && stats.Hits == 0 {
+		b.Fatal("the node cache is never hit")
+	}
+	if !cached && stats.Hits != 0 {
+		b.Fatal("the node cache is hit while disabled")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2018 IoTeX
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package trie
+
+import (
+	"math"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/common"
+	"github.com/iotexproject/iotex-core/common/utils"
+	"github.com/iotexproject/iotex-core/db"
+)
+
+// view is a read-only view of the trie at a root. The nodes are keyed by their hashes and never updated in DB, so the
+// view keeps reading the same entries while the trie moves on, until its root falls out of the retained window and the
+// nodes are collected. From then on, it fails with ErrNotRetained.
+type view struct {
+	t       *trie
+	root    patricia
+	version uint64 // the version of the root, whose nodes are kept until the version is collected
+}
+
+// retainedRoot is the latest version of a root retained, with the numbers of its nodes recorded
+type retainedRoot struct {
+	version uint64
+	counts  []byte // the numbers of the entries, branches, extensions and leaves, empty if they aren't recorded
+}
+
+// View opens a read-only view of the trie at the root, which is either the current one or one of the recent ones
+// retained
+func (t *trie) View(root common.Hash32B) (Reader, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	ptr, version, err := t.rootAt(root)
+	if err != nil {
+		return nil, err
+	}
+	return &view{t: t, root: ptr, version: version}, nil
+}
+
+// Reset resets the trie to the root, which is either the current one or one of the recent ones retained. The nodes
+// only reachable from the root reset from are turned stale, and the reset is recorded as a new version. The changes
+// left by a failed commit are flushed first, so that they are turned stale as well.
+func (t *trie) Reset(root common.Hash32B) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err := t.flush(); err != nil {
+		return err
+	}
+	current := t.root.hash()
+	if root == current {
+		return nil
+	}
+	ptr, version, err := t.rootAt(root)
+	if err != nil {
+		return err
+	}
+	stale, revived, ok, err := t.undoVersions(version)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if stale, revived, err = t.diffRoots(root, current); err != nil {
+			return err
+		}
+	}
+	t.stale = append(t.stale, stale...)
+	// the nodes only reachable from the root reset to may have turned stale since, and are live again
+	t.revived = append(t.revived, revived...)
+	t.root = ptr
+	if !t.setCounts(t.roots[root].counts) {
+		numBranch, numExt, numLeaf, err := t.countNodes(ptr)
+		if err != nil {
+			return err
+		}
+		t.numBranch, t.numExt, t.numLeaf, t.numEntry = numBranch, numExt, numLeaf, numLeaf+1
+	}
+	return t.writeVersion()
+}
+
+// Get retrieves an existing entry from the trie at the root of the view
+func (v *view) Get(key []byte) ([]byte, error) {
+	// Use write lock because t.clear() will mutate toRoot
+	v.t.mutex.Lock()
+	defer v.t.mutex.Unlock()
+
+	if err := v.retained(); err != nil {
+		return nil, err
+	}
+	return v.t.get(v.root, key)
+}
+
+// RootHash returns the root hash of the view
+func (v *view) RootHash() common.Hash32B {
+	return v.root.hash()
+}
+
+// Iterate calls the function on every <k, v> stored in the trie at the root of the view, in the order of the keys
+func (v *view) Iterate(fn func(key, value []byte) error) error {
+	v.t.mutex.RLock()
+	defer v.t.mutex.RUnlock()
+
+	if err := v.retained(); err != nil {
+		return err
+	}
+	return v.t.iterate(v.root, nil, fn)
+}
+
+// retained returns ErrNotRetained if the nodes of the root of the view may have been collected. It is called with the
+// trie lock held, which the collection holds as well while deleting the nodes.
+func (v *view) retained() error {
+	// the stale nodes of a version are those of the root of the version before it
+	if v.t.collected > v.version {
+		return errors.Wrapf(ErrNotRetained, "root = %x", v.root.hash())
+	}
+	return nil
+}
+
+//======================================
+// private functions
+//======================================
+
+// rootAt returns a copy of the root node of the hash, if it is the current root or the root of a version retained,
+// together with the latest version of the root
+func (t *trie) rootAt(root common.Hash32B) (patricia, uint64, error) {
+	if root == EmptyRoot {
+		// the empty root reaches no node to collect
+		return &branch{}, math.MaxUint64, nil
+	}
+	if root == t.root.hash() {
+		return clonePatricia(t.root), t.version, nil
+	}
+	if retained, ok := t.roots[root]; ok && retained.version >= t.collected {
+		ptr, err := t.loadPatricia(root[:])
+		return ptr, retained.version, err
+	}
+	return nil, 0, errors.Wrapf(ErrNotRetained, "root = %x", root)
+}
+
+// undoVersions returns the nodes live at the current version but not at the version, and the nodes live at the version
+// but not at the current one, from the records of the nodes put and turned stale by the versions after it. A node is
+// live at the version if the first record after it turns the node stale, and is live at the current version if the
+// last record puts the node. It returns false if the records of a version are missing, which is the case for the
+// versions flushed before the nodes put are recorded, or for the empty root.
+func (t *trie) undoVersions(version uint64) ([]common.Hash32B, []common.Hash32B, bool, error) {
+	if version >= t.version {
+		return nil, nil, false, nil
+	}
+	before := make(map[common.Hash32B]bool)
+	after := make(map[common.Hash32B]bool)
+	for v := version + 1; v <= t.version; v++ {
+		suffix := utils.Uint64ToBytes(v)
+		stale, err := t.dao.Get(trieHistoryNameSpace, append(append([]byte{}, stalePrefix...), suffix...))
+		if cause := errors.Cause(err); err != nil && cause != db.ErrNotExist && cause != bolt.ErrBucketNotFound {
+			return nil, nil, false, errors.Wrapf(err, "failed to get the stale nodes of version %d", v)
+		}
+		fresh, err := t.dao.Get(trieHistoryNameSpace, append(append([]byte{}, freshPrefix...), suffix...))
+		if cause := errors.Cause(err); cause == db.ErrNotExist || cause == bolt.ErrBucketNotFound {
+			return nil, nil, false, nil
+		}
+		if err != nil {
+			return nil, nil, false, errors.Wrapf(err, "failed to get the nodes put by version %d", v)
+		}
+		// a node turned stale and put again by the same version is live after it
+		for _, record := range []struct {
+			nodes []byte
+			live  bool
+		}{{stale, false}, {fresh, true}} {
+			for i := 0; i+common.HashSize <= len(record.nodes); i += common.HashSize {
+				var hash common.Hash32B
+				copy(hash[:], record.nodes[i:i+common.HashSize])
+				if _, ok := before[hash]; !ok {
+					before[hash] = !record.live
+				}
+				after[hash] = record.live
+			}
+		}
+	}
+	var stale, revived []common.Hash32B
+	for hash, live := range before {
+		switch {
+		case after[hash] && !live:
+			stale = append(stale, hash)
+		case !after[hash] && live:
+			revived = append(revived, hash)
+		}
+	}
+	return stale, revived, true, nil
+}
+
+// diffRoots returns the nodes only reachable from the current root, and the nodes only reachable from the root, by
+// walking the tries at both roots
+func (t *trie) diffRoots(root common.Hash32B, current common.Hash32B) ([]common.Hash32B, []common.Hash32B, error) {
+	reachable := make(map[common.Hash32B]bool)
+	if err := t.mark(root[:], reachable); err != nil {
+		return nil, nil, err
+	}
+	live := make(map[common.Hash32B]bool)
+	if err := t.mark(current[:], live); err != nil {
+		return nil, nil, err
+	}
+	var stale, revived []common.Hash32B
+	for hash := range live {
+		if !reachable[hash] {
+			stale = append(stale, hash)
+		}
+	}
+	for hash := range reachable {
+		if !live[hash] {
+			revived = append(revived, hash)
+		}
+	}
+	return stale, revived, nil
+}
+
+// countNodes returns the number of the branches, extensions and leaves of the trie at the root
+func (t *trie) countNodes(ptr patricia) (uint64, uint64, uint64, error) {
+	switch node := ptr.(type) {
+	case *branch:
+		numBranch, numExt, numLeaf := uint64(1), uint64(0), uint64(0)
+		for i := 0; i < RADIX; i++ {
+			if len(node.Path[i]) == 0 {
+				continue
+			}
+			child, err := t.getPatricia(node.Path[i])
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			b, e, l, err := t.countNodes(child)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			numBranch, numExt, numLeaf = numBranch+b, numExt+e, numLeaf+l
+		}
+		return numBranch, numExt, numLeaf, nil
+	case *leaf:
+		if node.Ext == 0 {
+			return 0, 0, 1, nil
+		}
+		child, err := t.getPatricia(node.Value)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		b, e, l, err := t.countNodes(child)
+		return b, e + 1, l, err
+	}
+	return 0, 0, 0, errors.Wrapf(ErrInvalidPatricia, "invalid node = %v", ptr)
+}